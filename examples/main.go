@@ -4,6 +4,8 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -86,4 +88,39 @@ func main() {
 	)
 
 	optimizelyClient.Close()
+
+	/************* Custom Event Dispatcher ********************/
+
+	// events can be forwarded over any transport, here they are handed over to a channel
+	dispatcher := &channelEventDispatcher{events: make(chan event.LogEvent, 10)}
+	go func() {
+		for logEvent := range dispatcher.events {
+			payload, _ := json.Marshal(logEvent.Event)
+			fmt.Printf("Received log event: %s\n", payload)
+		}
+	}()
+
+	optimizelyClient, _ = optimizelyFactory.Client(
+		client.WithEventDispatcher(dispatcher),
+	)
+
+	optimizelyClient.Track("sample_conversion", user, nil)
+	optimizelyClient.Close()
+	close(dispatcher.events)
+}
+
+// channelEventDispatcher is an event.Dispatcher which sends the log events to a channel instead of over HTTP
+type channelEventDispatcher struct {
+	events chan event.LogEvent
+}
+
+// DispatchEvent sends the log event to the channel, it reports a failure when the channel is full so that the
+// event is kept queued and retried on the next flush
+func (d *channelEventDispatcher) DispatchEvent(logEvent event.LogEvent) (bool, error) {
+	select {
+	case d.events <- logEvent:
+		return true, nil
+	default:
+		return false, errors.New("event channel is full")
+	}
 }
@@ -0,0 +1,31 @@
+// to run the graceful shutdown example: go run shutdown/main.go, then stop it with Ctrl-C
+
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/optimizely/go-sdk/pkg/client"
+	"github.com/optimizely/go-sdk/pkg/entities"
+)
+
+func main() {
+	optimizelyClient, err := (&client.OptimizelyFactory{SDKKey: "4SLpaJA1r1pgE6T2CoMs9q"}).Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go func() {
+		user := entities.UserContext{ID: "test_user_1"}
+		for range time.Tick(time.Second) {
+			enabled, _ := optimizelyClient.IsFeatureEnabled("mutext_feat", user)
+			log.Printf("mutext_feat enabled: %v", enabled)
+		}
+	}()
+
+	// blocks until SIGINT or SIGTERM, then gives the queued events 5 seconds to be dispatched
+	undispatched := client.CloseOnSignal(context.Background(), optimizelyClient, 5*time.Second)
+	log.Printf("Optimizely client closed, %d events undispatched", undispatched)
+}
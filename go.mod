@@ -10,6 +10,7 @@ require (
 	github.com/stretchr/testify v1.4.0
 	github.com/twmb/murmur3 v1.0.0
 	golang.org/x/sync v0.0.0-20190911185100-cd5d95a43a6e
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 // Work around issue wtih git.apache.org/thrift.git
@@ -18,11 +18,17 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"reflect"
 	"runtime/debug"
+	"sort"
 	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/optimizely/go-sdk/pkg/config"
 	"github.com/optimizely/go-sdk/pkg/decision"
@@ -35,6 +41,17 @@ import (
 
 var logger = logging.GetLogger("Client")
 
+// VariableTypeMismatchError is returned by the typed feature variable getters when the variable is defined with a different type
+type VariableTypeMismatchError struct {
+	VariableKey  string
+	ExpectedType entities.VariableType
+	ActualType   entities.VariableType
+}
+
+func (e VariableTypeMismatchError) Error() string {
+	return fmt.Sprintf(`variable "%s" is of type "%s", not "%s"`, e.VariableKey, e.ActualType, e.ExpectedType)
+}
+
 // OptimizelyClient is the entry point to the Optimizely SDK
 type OptimizelyClient struct {
 	ConfigManager      config.ProjectConfigManager
@@ -42,11 +59,37 @@ type OptimizelyClient struct {
 	EventProcessor     event.Processor
 	notificationCenter notification.Center
 	execGroup          *utils.ExecGroup
+	userProfileService decision.UserProfileService
+
+	defaultDecideOptions []DecideOption
+	defaultAttributes    map[string]interface{}
+	defaultEventTags     map[string]interface{}
+	// allowedEventKeys holds the keys of the events tracked, all the events are tracked if nil
+	allowedEventKeys map[string]bool
+	// clock is the source of the event timestamps, the system clock if nil
+	clock utils.Clock
 }
 
 // Activate returns the key of the variation the user is bucketed into and queues up an impression event to be sent to
 // the Optimizely log endpoint for results processing.
 func (o *OptimizelyClient) Activate(experimentKey string, userContext entities.UserContext) (result string, err error) {
+	variation, err := o.activate(context.Background(), experimentKey, userContext)
+	return variationKey(variation), err
+}
+
+// ActivateWithContext is like Activate, passing the given context to the decision service and the event processor.
+func (o *OptimizelyClient) ActivateWithContext(ctx context.Context, experimentKey string, userContext entities.UserContext) (result string, err error) {
+	variation, err := o.activate(ctx, experimentKey, userContext)
+	return variationKey(variation), err
+}
+
+// ActivateVariationObject is like Activate, returning the variation the user is bucketed into as defined in the project
+// config, with the values of the feature variables it overrides, or nil when the user is not bucketed.
+func (o *OptimizelyClient) ActivateVariationObject(experimentKey string, userContext entities.UserContext) (*entities.Variation, error) {
+	return o.activate(context.Background(), experimentKey, userContext)
+}
+
+func (o *OptimizelyClient) activate(ctx context.Context, experimentKey string, userContext entities.UserContext) (result *entities.Variation, err error) {
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -64,17 +107,21 @@ func (o *OptimizelyClient) Activate(experimentKey string, userContext entities.U
 		}
 	}()
 
-	decisionContext, experimentDecision, err := o.getExperimentDecision(experimentKey, userContext)
+	userContext = o.withDefaultAttributes(userContext)
+
+	decisionContext, experimentDecision, err := o.getExperimentDecision(ctx, experimentKey, userContext)
 	if err != nil {
 		logger.Error("received an error while computing experiment decision", err)
 		return result, err
 	}
 
 	if experimentDecision.Variation != nil && decisionContext.Experiment != nil {
-		// send an impression event
-		result = experimentDecision.Variation.Key
-		impressionEvent := event.CreateImpressionUserEvent(decisionContext.ProjectConfig, *decisionContext.Experiment, *experimentDecision.Variation, userContext)
-		o.EventProcessor.ProcessEvent(impressionEvent)
+		result = copyVariation(*experimentDecision.Variation)
+		if !isOptedOut(userContext) {
+			// send an impression event
+			impressionEvent := o.createImpressionUserEvent(decisionContext.ProjectConfig, *decisionContext.Experiment, *experimentDecision.Variation, userContext)
+			o.processEvent(ctx, impressionEvent)
+		}
 	}
 
 	return result, err
@@ -83,6 +130,16 @@ func (o *OptimizelyClient) Activate(experimentKey string, userContext entities.U
 // IsFeatureEnabled returns true if the feature is enabled for the given user. If the user is part of a feature test
 // then an impression event will be queued up to be sent to the Optimizely log endpoint for results processing.
 func (o *OptimizelyClient) IsFeatureEnabled(featureKey string, userContext entities.UserContext) (result bool, err error) {
+	return o.isFeatureEnabled(context.Background(), featureKey, userContext)
+}
+
+// IsFeatureEnabledWithContext is like IsFeatureEnabled, passing the given context to the decision service and the event
+// processor.
+func (o *OptimizelyClient) IsFeatureEnabledWithContext(ctx context.Context, featureKey string, userContext entities.UserContext) (result bool, err error) {
+	return o.isFeatureEnabled(ctx, featureKey, userContext)
+}
+
+func (o *OptimizelyClient) isFeatureEnabled(ctx context.Context, featureKey string, userContext entities.UserContext) (result bool, err error) {
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -100,7 +157,9 @@ func (o *OptimizelyClient) IsFeatureEnabled(featureKey string, userContext entit
 		}
 	}()
 
-	decisionContext, featureDecision, err := o.getFeatureDecision(featureKey, "", userContext)
+	userContext = o.withDefaultAttributes(userContext)
+
+	decisionContext, featureDecision, err := o.getFeatureDecision(ctx, featureKey, "", userContext)
 	if err != nil {
 		logger.Error("received an error while computing feature decision", err)
 		return result, err
@@ -118,16 +177,16 @@ func (o *OptimizelyClient) IsFeatureEnabled(featureKey string, userContext entit
 		logger.Info(fmt.Sprintf(`Feature "%s" is not enabled for user "%s".`, featureKey, userContext.ID))
 	}
 
-	if featureDecision.Source == decision.FeatureTest && featureDecision.Variation != nil {
+	if featureDecision.Source == decision.FeatureTest && featureDecision.Variation != nil && !isOptedOut(userContext) {
 		// send impression event for feature tests
-		impressionEvent := event.CreateImpressionUserEvent(decisionContext.ProjectConfig, featureDecision.Experiment, *featureDecision.Variation, userContext)
-		o.EventProcessor.ProcessEvent(impressionEvent)
+		impressionEvent := o.createImpressionUserEvent(decisionContext.ProjectConfig, featureDecision.Experiment, *featureDecision.Variation, userContext)
+		o.processEvent(ctx, impressionEvent)
 	}
 	return result, err
 }
 
 // GetEnabledFeatures returns an array containing the keys of all features in the project that are enabled for the given
-// user. For features tests, impression events will be queued up to be sent to the Optimizely log endpoint for results processing.
+// user, sorted. For features tests, impression events will be queued up to be sent to the Optimizely log endpoint for results processing.
 func (o *OptimizelyClient) GetEnabledFeatures(userContext entities.UserContext) (enabledFeatures []string, err error) {
 
 	defer func() {
@@ -152,10 +211,15 @@ func (o *OptimizelyClient) GetEnabledFeatures(userContext entities.UserContext)
 		return enabledFeatures, err
 	}
 
-	featureList := projectConfig.GetFeatureList()
-	for _, feature := range featureList {
-		if isEnabled, _ := o.IsFeatureEnabled(feature.Key, userContext); isEnabled {
-			enabledFeatures = append(enabledFeatures, feature.Key)
+	var featureKeys []string
+	for _, feature := range projectConfig.GetFeatureList() {
+		featureKeys = append(featureKeys, feature.Key)
+	}
+	// the features are evaluated in order so that the impression events are queued in a deterministic order too
+	sort.Strings(featureKeys)
+	for _, featureKey := range featureKeys {
+		if isEnabled, _ := o.IsFeatureEnabled(featureKey, userContext); isEnabled {
+			enabledFeatures = append(enabledFeatures, featureKey)
 		}
 	}
 	return enabledFeatures, err
@@ -163,63 +227,99 @@ func (o *OptimizelyClient) GetEnabledFeatures(userContext entities.UserContext)
 
 // GetFeatureVariableBoolean returns the feature variable value of type bool associated with the given feature and variable keys.
 func (o *OptimizelyClient) GetFeatureVariableBoolean(featureKey, variableKey string, userContext entities.UserContext) (value bool, err error) {
+	return o.GetFeatureVariableBooleanWithContext(context.Background(), featureKey, variableKey, userContext)
+}
+
+// GetFeatureVariableBooleanWithContext is like GetFeatureVariableBoolean, passing the given context to the decision service.
+func (o *OptimizelyClient) GetFeatureVariableBooleanWithContext(ctx context.Context, featureKey, variableKey string, userContext entities.UserContext) (value bool, err error) {
 
-	val, valueType, err := o.GetFeatureVariable(featureKey, variableKey, userContext)
+	val, valueType, err := o.GetFeatureVariableWithContext(ctx, featureKey, variableKey, userContext)
 	if err != nil {
 		return false, err
 	}
+	if valueType != entities.Boolean {
+		return false, VariableTypeMismatchError{VariableKey: variableKey, ExpectedType: entities.Boolean, ActualType: valueType}
+	}
 	convertedValue, err := strconv.ParseBool(val)
-	if err != nil || valueType != entities.Boolean {
-		return false, fmt.Errorf("variable value for key %s is invalid or wrong type", variableKey)
+	if err != nil {
+		return false, fmt.Errorf("variable value for key %s is invalid", variableKey)
 	}
 	return convertedValue, err
 }
 
 // GetFeatureVariableDouble returns the feature variable value of type double associated with the given feature and variable keys.
 func (o *OptimizelyClient) GetFeatureVariableDouble(featureKey, variableKey string, userContext entities.UserContext) (value float64, err error) {
+	return o.GetFeatureVariableDoubleWithContext(context.Background(), featureKey, variableKey, userContext)
+}
 
-	val, valueType, err := o.GetFeatureVariable(featureKey, variableKey, userContext)
+// GetFeatureVariableDoubleWithContext is like GetFeatureVariableDouble, passing the given context to the decision service.
+func (o *OptimizelyClient) GetFeatureVariableDoubleWithContext(ctx context.Context, featureKey, variableKey string, userContext entities.UserContext) (value float64, err error) {
+
+	val, valueType, err := o.GetFeatureVariableWithContext(ctx, featureKey, variableKey, userContext)
 	if err != nil {
 		return 0, err
 	}
+	if valueType != entities.Double {
+		return 0, VariableTypeMismatchError{VariableKey: variableKey, ExpectedType: entities.Double, ActualType: valueType}
+	}
 	convertedValue, err := strconv.ParseFloat(val, 64)
-	if err != nil || valueType != entities.Double {
-		return 0, fmt.Errorf("variable value for key %s is invalid or wrong type", variableKey)
+	if err != nil {
+		return 0, fmt.Errorf("variable value for key %s is invalid", variableKey)
 	}
 	return convertedValue, err
 }
 
 // GetFeatureVariableInteger returns the feature variable value of type int associated with the given feature and variable keys.
 func (o *OptimizelyClient) GetFeatureVariableInteger(featureKey, variableKey string, userContext entities.UserContext) (value int, err error) {
+	return o.GetFeatureVariableIntegerWithContext(context.Background(), featureKey, variableKey, userContext)
+}
+
+// GetFeatureVariableIntegerWithContext is like GetFeatureVariableInteger, passing the given context to the decision service.
+func (o *OptimizelyClient) GetFeatureVariableIntegerWithContext(ctx context.Context, featureKey, variableKey string, userContext entities.UserContext) (value int, err error) {
 
-	val, valueType, err := o.GetFeatureVariable(featureKey, variableKey, userContext)
+	val, valueType, err := o.GetFeatureVariableWithContext(ctx, featureKey, variableKey, userContext)
 	if err != nil {
 		return 0, err
 	}
+	if valueType != entities.Integer {
+		return 0, VariableTypeMismatchError{VariableKey: variableKey, ExpectedType: entities.Integer, ActualType: valueType}
+	}
 	convertedValue, err := strconv.Atoi(val)
-	if err != nil || valueType != entities.Integer {
-		return 0, fmt.Errorf("variable value for key %s is invalid or wrong type", variableKey)
+	if err != nil {
+		return 0, fmt.Errorf("variable value for key %s is invalid", variableKey)
 	}
 	return convertedValue, err
 }
 
 // GetFeatureVariableString returns the feature variable value of type string associated with the given feature and variable keys.
 func (o *OptimizelyClient) GetFeatureVariableString(featureKey, variableKey string, userContext entities.UserContext) (value string, err error) {
+	return o.GetFeatureVariableStringWithContext(context.Background(), featureKey, variableKey, userContext)
+}
+
+// GetFeatureVariableStringWithContext is like GetFeatureVariableString, passing the given context to the decision service.
+func (o *OptimizelyClient) GetFeatureVariableStringWithContext(ctx context.Context, featureKey, variableKey string, userContext entities.UserContext) (value string, err error) {
 
-	value, valueType, err := o.GetFeatureVariable(featureKey, variableKey, userContext)
+	value, valueType, err := o.GetFeatureVariableWithContext(ctx, featureKey, variableKey, userContext)
 	if err != nil {
 		return "", err
 	}
 	if valueType != entities.String {
-		return "", fmt.Errorf("variable value for key %s is wrong type", variableKey)
+		return "", VariableTypeMismatchError{VariableKey: variableKey, ExpectedType: entities.String, ActualType: valueType}
 	}
 	return value, err
 }
 
-// GetFeatureVariable returns feature variable as a string along with it's associated type.
+// GetFeatureVariable returns feature variable as a string along with it's associated type, leaving the conversion to the
+// caller. An ErrEntityNotFound is returned when the feature or the variable can't be found.
 func (o *OptimizelyClient) GetFeatureVariable(featureKey, variableKey string, userContext entities.UserContext) (value string, valueType entities.VariableType, err error) {
+	return o.GetFeatureVariableWithContext(context.Background(), featureKey, variableKey, userContext)
+}
 
-	featureDecisionContext, featureDecision, err := o.getFeatureDecision(featureKey, variableKey, userContext)
+// GetFeatureVariableWithContext is like GetFeatureVariable, passing the given context to the decision service.
+func (o *OptimizelyClient) GetFeatureVariableWithContext(ctx context.Context, featureKey, variableKey string, userContext entities.UserContext) (value string, valueType entities.VariableType, err error) {
+	userContext = o.withDefaultAttributes(userContext)
+
+	featureDecisionContext, featureDecision, err := o.getFeatureDecision(ctx, featureKey, variableKey, userContext)
 	if err != nil {
 		return "", "", err
 	}
@@ -237,9 +337,15 @@ func (o *OptimizelyClient) GetFeatureVariable(featureKey, variableKey string, us
 
 // GetAllFeatureVariables returns all the variables for a given feature along with the enabled state.
 func (o *OptimizelyClient) GetAllFeatureVariables(featureKey string, userContext entities.UserContext) (enabled bool, variableMap map[string]interface{}, err error) {
+	return o.GetAllFeatureVariablesWithContext(context.Background(), featureKey, userContext)
+}
+
+// GetAllFeatureVariablesWithContext is like GetAllFeatureVariables, passing the given context to the decision service.
+func (o *OptimizelyClient) GetAllFeatureVariablesWithContext(ctx context.Context, featureKey string, userContext entities.UserContext) (enabled bool, variableMap map[string]interface{}, err error) {
+	userContext = o.withDefaultAttributes(userContext)
 
 	variableMap = make(map[string]interface{})
-	decisionContext, featureDecision, err := o.getFeatureDecision(featureKey, "", userContext)
+	decisionContext, featureDecision, err := o.getFeatureDecision(ctx, featureKey, "", userContext)
 	if err != nil {
 		logger.Error("Optimizely SDK tracking error", err)
 		return enabled, variableMap, err
@@ -249,11 +355,14 @@ func (o *OptimizelyClient) GetAllFeatureVariables(featureKey string, userContext
 		enabled = featureDecision.Variation.FeatureEnabled
 	}
 
-	feature := decisionContext.Feature
-	if feature == nil {
-		logger.Warning(fmt.Sprintf(`feature "%s" does not exist`, featureKey))
-		return enabled, variableMap, nil
-	}
+	variableMap, err = getFeatureVariableMap(*decisionContext.Feature, featureDecision)
+	return enabled, variableMap, err
+}
+
+// getFeatureVariableMap returns the typed values of all the variables of the feature for the given decision
+func getFeatureVariableMap(feature entities.Feature, featureDecision decision.FeatureDecision) (variableMap map[string]interface{}, err error) {
+	variableMap = make(map[string]interface{})
+	enabled := featureDecision.Variation != nil && featureDecision.Variation.FeatureEnabled
 
 	for _, v := range feature.VariableMap {
 		val := v.DefaultValue
@@ -264,28 +373,57 @@ func (o *OptimizelyClient) GetAllFeatureVariables(featureKey string, userContext
 			}
 		}
 
-		var out interface{}
-		out = val
-		switch varType := v.Type; varType {
-		case entities.Boolean:
-			out, err = strconv.ParseBool(val)
-		case entities.Double:
-			out, err = strconv.ParseFloat(val, 64)
-		case entities.Integer:
-			out, err = strconv.Atoi(val)
-		case entities.String:
-		default:
-			logger.Warning(fmt.Sprintf(`type "%s" is unknown, returning string`, varType))
+		if !v.Type.IsKnown() {
+			logger.Warning(fmt.Sprintf(`type "%s" is unknown, returning string`, v.Type))
 		}
+		var out interface{}
+		out, err = v.ConvertValue(val)
 
 		variableMap[v.Key] = out
 	}
 
-	return enabled, variableMap, err
+	return variableMap, err
 }
 
 // GetVariation returns the key of the variation the user is bucketed into. Does not generate impression events.
 func (o *OptimizelyClient) GetVariation(experimentKey string, userContext entities.UserContext) (result string, err error) {
+	variation, err := o.getVariation(context.Background(), experimentKey, userContext)
+	return variationKey(variation), err
+}
+
+// GetVariationWithContext is like GetVariation, passing the given context to the decision service.
+func (o *OptimizelyClient) GetVariationWithContext(ctx context.Context, experimentKey string, userContext entities.UserContext) (result string, err error) {
+	variation, err := o.getVariation(ctx, experimentKey, userContext)
+	return variationKey(variation), err
+}
+
+// GetVariationObject is like GetVariation, returning the variation the user is bucketed into as defined in the project
+// config, with the values of the feature variables it overrides, or nil when the user is not bucketed.
+func (o *OptimizelyClient) GetVariationObject(experimentKey string, userContext entities.UserContext) (*entities.Variation, error) {
+	return o.getVariation(context.Background(), experimentKey, userContext)
+}
+
+// GetVariations returns all the variations of the experiment with the given key in the active project config, with
+// the values of the feature variables they override, e.g. for admin and debug UIs. An ErrEntityNotFound is returned
+// for an unknown experiment key. The project config has to be a config.VariationsProjectConfig.
+func (o *OptimizelyClient) GetVariations(experimentKey string) ([]entities.Variation, error) {
+	projectConfig, err := o.getProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	variationsConfig, ok := projectConfig.(config.VariationsProjectConfig)
+	if !ok {
+		return nil, errors.New("the project config does not list the variations of the experiments")
+	}
+	variations, err := variationsConfig.GetVariations(experimentKey)
+	if err != nil {
+		return nil, &ErrEntityNotFound{Kind: EntityKindExperiment, Key: experimentKey}
+	}
+	return variations, nil
+}
+
+func (o *OptimizelyClient) getVariation(ctx context.Context, experimentKey string, userContext entities.UserContext) (result *entities.Variation, err error) {
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -303,21 +441,57 @@ func (o *OptimizelyClient) GetVariation(experimentKey string, userContext entiti
 		}
 	}()
 
-	_, experimentDecision, err := o.getExperimentDecision(experimentKey, userContext)
+	userContext = o.withDefaultAttributes(userContext)
+
+	_, experimentDecision, err := o.getExperimentDecision(ctx, experimentKey, userContext)
 	if err != nil {
 		logger.Error("received an error while computing experiment decision", err)
 	}
 
 	if experimentDecision.Variation != nil {
-		result = experimentDecision.Variation.Key
+		result = copyVariation(*experimentDecision.Variation)
 	}
 
 	return result, err
 }
 
+// variationKey returns the key of the variation, "" for no variation
+func variationKey(variation *entities.Variation) string {
+	if variation == nil {
+		return ""
+	}
+	return variation.Key
+}
+
+// copyVariation returns a copy of the variation of the project config, so that the callers can't alter the config
+func copyVariation(variation entities.Variation) *entities.Variation {
+	variables := make(map[string]entities.VariationVariable, len(variation.Variables))
+	for id, variable := range variation.Variables {
+		variables[id] = variable
+	}
+	variation.Variables = variables
+	return &variation
+}
+
 // Track generates a conversion event with the given event key if it exists and queues it up to be sent to the Optimizely
 // log endpoint for results processing.
 func (o *OptimizelyClient) Track(eventKey string, userContext entities.UserContext, eventTags map[string]interface{}) (err error) {
+	return o.track(context.Background(), eventKey, userContext, eventTags, time.Time{})
+}
+
+// TrackWithContext is like Track, passing the given context to the event processor. The event is not tracked and the
+// error of the context is returned if the context is already done.
+func (o *OptimizelyClient) TrackWithContext(ctx context.Context, eventKey string, userContext entities.UserContext, eventTags map[string]interface{}) (err error) {
+	return o.track(ctx, eventKey, userContext, eventTags, time.Time{})
+}
+
+// TrackWithTimestamp is like Track, recording the conversion at the given time instead of now, e.g. to backfill events
+// collected offline. A zero timestamp defaults to the current time.
+func (o *OptimizelyClient) TrackWithTimestamp(eventKey string, userContext entities.UserContext, eventTags map[string]interface{}, timestamp time.Time) (err error) {
+	return o.track(context.Background(), eventKey, userContext, eventTags, timestamp)
+}
+
+func (o *OptimizelyClient) track(ctx context.Context, eventKey string, userContext entities.UserContext, eventTags map[string]interface{}, timestamp time.Time) (err error) {
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -335,6 +509,17 @@ func (o *OptimizelyClient) Track(eventKey string, userContext entities.UserConte
 		}
 	}()
 
+	if e := ctx.Err(); e != nil {
+		return e
+	}
+
+	if o.allowedEventKeys != nil && !o.allowedEventKeys[eventKey] {
+		logger.Debug(fmt.Sprintf(`Event "%s" is not in the allowed event keys, it is not tracked`, eventKey))
+		return nil
+	}
+
+	userContext = o.withDefaultAttributes(userContext)
+
 	projectConfig, e := o.getProjectConfig()
 	if e != nil {
 		logger.Error("Optimizely SDK tracking error", e)
@@ -349,8 +534,15 @@ func (o *OptimizelyClient) Track(eventKey string, userContext entities.UserConte
 		return nil
 	}
 
-	userEvent := event.CreateConversionUserEvent(projectConfig, configEvent, userContext, eventTags)
-	if o.EventProcessor.ProcessEvent(userEvent) && o.notificationCenter != nil {
+	if isOptedOut(userContext) {
+		return nil
+	}
+
+	eventTags = o.withDefaultEventTags(eventTags)
+	userEvent := event.CreateConversionUserEventWithClock(projectConfig, configEvent, userContext, eventTags, timestamp,
+		o.getClock())
+	userEvent.Conversion.Decisions = o.getConversionDecisions(projectConfig, userContext.ID)
+	if o.processEvent(ctx, userEvent) && o.notificationCenter != nil {
 		trackNotification := notification.TrackNotification{EventKey: eventKey, UserContext: userContext, EventTags: eventTags, ConversionEvent: *userEvent.Conversion}
 		if err = o.notificationCenter.Send(notification.Track, trackNotification); err != nil {
 			logger.Warning("Problem with sending notification")
@@ -360,7 +552,113 @@ func (o *OptimizelyClient) Track(eventKey string, userContext entities.UserConte
 	return nil
 }
 
-func (o *OptimizelyClient) getFeatureDecision(featureKey, variableKey string, userContext entities.UserContext) (decisionContext decision.FeatureDecisionContext, featureDecision decision.FeatureDecision, err error) {
+// getConversionDecisions returns the experiment exposures of the user saved in the user profile service, so that a
+// conversion is attributed to all the experiments the user is bucketed into
+func (o *OptimizelyClient) getConversionDecisions(projectConfig config.ProjectConfig, userID string) []event.Decision {
+	if o.userProfileService == nil {
+		return nil
+	}
+
+	userProfile := o.userProfileService.Lookup(userID)
+	if len(userProfile.ExperimentBucketMap) == 0 {
+		return nil
+	}
+
+	experiments := map[string]entities.Experiment{}
+	for _, experiment := range projectConfig.GetExperimentList() {
+		experiments[experiment.ID] = experiment
+	}
+
+	decisions := []event.Decision{}
+	for decisionKey, variationID := range userProfile.ExperimentBucketMap {
+		if decisionKey != decision.NewUserDecisionKey(decisionKey.ExperimentID) {
+			continue
+		}
+		// exposures to experiments or variations no longer in the config are not attributed
+		experiment, ok := experiments[decisionKey.ExperimentID]
+		if !ok {
+			continue
+		}
+		if _, ok := experiment.Variations[variationID]; !ok {
+			continue
+		}
+		decisions = append(decisions, event.Decision{CampaignID: experiment.LayerID, ExperimentID: experiment.ID, VariationID: variationID})
+	}
+
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].ExperimentID < decisions[j].ExperimentID
+	})
+	return decisions
+}
+
+// withDefaultAttributes returns the user context with the default attributes of the client merged into its attributes,
+// the attributes of the user context take precedence
+func (o *OptimizelyClient) withDefaultAttributes(userContext entities.UserContext) entities.UserContext {
+	if len(o.defaultAttributes) == 0 {
+		return userContext
+	}
+
+	attributes := make(map[string]interface{}, len(o.defaultAttributes)+len(userContext.Attributes))
+	for key, value := range o.defaultAttributes {
+		attributes[key] = value
+	}
+	for key, value := range userContext.Attributes {
+		attributes[key] = value
+	}
+	userContext.Attributes = attributes
+	return userContext
+}
+
+// createImpressionUserEvent creates the impression event of the user, timestamped with the clock of the client
+func (o *OptimizelyClient) createImpressionUserEvent(projectConfig config.ProjectConfig, experiment entities.Experiment,
+	variation entities.Variation, userContext entities.UserContext) event.UserEvent {
+	return event.CreateImpressionUserEventWithClock(projectConfig, experiment, variation, userContext, time.Time{},
+		o.getClock())
+}
+
+// getClock returns the clock the events are timestamped with, the system clock if none is set
+func (o *OptimizelyClient) getClock() utils.Clock {
+	if o.clock == nil {
+		return utils.DefaultClock{}
+	}
+	return o.clock
+}
+
+// withDefaultEventTags returns the event tags with the default event tags of the client merged into them, the tags of
+// the tracked event take precedence
+func (o *OptimizelyClient) withDefaultEventTags(eventTags map[string]interface{}) map[string]interface{} {
+	if len(o.defaultEventTags) == 0 {
+		return eventTags
+	}
+
+	tags := make(map[string]interface{}, len(o.defaultEventTags)+len(eventTags))
+	for key, value := range o.defaultEventTags {
+		tags[key] = value
+	}
+	for key, value := range eventTags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// isOptedOut returns whether events must not be sent for the user since they opted out of event tracking
+func isOptedOut(userContext entities.UserContext) bool {
+	if userContext.IsOptedOut() {
+		logger.Debug(fmt.Sprintf(`User "%s" opted out of event tracking, not sending event.`, userContext.ID))
+		return true
+	}
+	return false
+}
+
+// processEvent hands the event to the event processor, passing it the context if the processor accepts one
+func (o *OptimizelyClient) processEvent(ctx context.Context, userEvent event.UserEvent) bool {
+	if processor, ok := o.EventProcessor.(event.ContextProcessor); ok {
+		return processor.ProcessEventWithContext(ctx, userEvent)
+	}
+	return o.EventProcessor.ProcessEvent(userEvent)
+}
+
+func (o *OptimizelyClient) getFeatureDecision(ctx context.Context, featureKey, variableKey string, userContext entities.UserContext) (decisionContext decision.FeatureDecisionContext, featureDecision decision.FeatureDecision, err error) {
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -378,19 +676,48 @@ func (o *OptimizelyClient) getFeatureDecision(featureKey, variableKey string, us
 		}
 	}()
 
-	userID := userContext.ID
-	logger.Debug(fmt.Sprintf(`Evaluating feature "%s" for user "%s".`, featureKey, userID))
-
 	projectConfig, e := o.getProjectConfig()
 	if e != nil {
 		logger.Error("Error calling getFeatureDecision", e)
 		return decisionContext, featureDecision, e
 	}
 
+	return o.getFeatureDecisionWithConfig(ctx, projectConfig, featureKey, variableKey, userContext)
+}
+
+// getFeatureDecisionWithConfig is like getFeatureDecision, making the decision with the given project config instead of
+// the current one of the config manager
+func (o *OptimizelyClient) getFeatureDecisionWithConfig(ctx context.Context, projectConfig config.ProjectConfig, featureKey, variableKey string, userContext entities.UserContext) (decisionContext decision.FeatureDecisionContext, featureDecision decision.FeatureDecision, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch t := r.(type) {
+			case error:
+				err = t
+			case string:
+				err = errors.New(t)
+			default:
+				err = errors.New("unexpected error")
+			}
+			errorMessage := fmt.Sprintf("getFeatureDecision call, optimizely SDK is panicking with the error:")
+			logger.Error(errorMessage, err)
+			logger.Debug(string(debug.Stack()))
+		}
+	}()
+
+	// no decision is made for a call which is already done, it would not get its impression event
+	if e := ctx.Err(); e != nil {
+		return decisionContext, featureDecision, e
+	}
+
+	userID := userContext.ID
+	logger.Debug(fmt.Sprintf(`Evaluating feature "%s" for user "%s".`, featureKey, userID))
+	validateUserContext(userContext)
+
 	feature, e := projectConfig.GetFeatureByKey(featureKey)
 	if e != nil {
 		logger.Warning(fmt.Sprintf(`Could not get feature for key "%s": %s`, featureKey, e))
-		return decisionContext, featureDecision, nil
+		return decisionContext, featureDecision, o.entityNotFound(EntityKindFeature, featureKey, userContext)
 	}
 
 	variable := entities.Variable{}
@@ -398,7 +725,7 @@ func (o *OptimizelyClient) getFeatureDecision(featureKey, variableKey string, us
 		variable, err = projectConfig.GetVariableByKey(feature.Key, variableKey)
 		if err != nil {
 			logger.Warning(fmt.Sprintf(`Could not get variable for key "%s": %s`, variableKey, err))
-			return decisionContext, featureDecision, nil
+			return decisionContext, featureDecision, o.entityNotFound(EntityKindVariable, variableKey, userContext)
 		}
 	}
 
@@ -408,7 +735,11 @@ func (o *OptimizelyClient) getFeatureDecision(featureKey, variableKey string, us
 		Variable:      variable,
 	}
 
-	featureDecision, err = o.DecisionService.GetFeatureDecision(decisionContext, userContext)
+	if contextService, ok := o.DecisionService.(decision.ContextService); ok {
+		featureDecision, err = contextService.GetFeatureDecisionWithContext(ctx, decisionContext, userContext)
+	} else {
+		featureDecision, err = o.DecisionService.GetFeatureDecision(decisionContext, userContext)
+	}
 	if err != nil {
 		logger.Warning(fmt.Sprintf(`Received error while making a decision for feature "%s": %s`, featureKey, err))
 		return decisionContext, featureDecision, nil
@@ -417,20 +748,33 @@ func (o *OptimizelyClient) getFeatureDecision(featureKey, variableKey string, us
 	return decisionContext, featureDecision, nil
 }
 
-func (o *OptimizelyClient) getExperimentDecision(experimentKey string, userContext entities.UserContext) (decisionContext decision.ExperimentDecisionContext, experimentDecision decision.ExperimentDecision, err error) {
-
-	userID := userContext.ID
-	logger.Debug(fmt.Sprintf(`Evaluating experiment "%s" for user "%s".`, experimentKey, userID))
+func (o *OptimizelyClient) getExperimentDecision(ctx context.Context, experimentKey string, userContext entities.UserContext) (decisionContext decision.ExperimentDecisionContext, experimentDecision decision.ExperimentDecision, err error) {
 
 	projectConfig, e := o.getProjectConfig()
 	if e != nil {
 		return decisionContext, experimentDecision, e
 	}
 
+	return o.getExperimentDecisionWithConfig(ctx, projectConfig, experimentKey, userContext)
+}
+
+// getExperimentDecisionWithConfig is like getExperimentDecision, making the decision with the given project config
+// instead of the current one of the config manager
+func (o *OptimizelyClient) getExperimentDecisionWithConfig(ctx context.Context, projectConfig config.ProjectConfig, experimentKey string, userContext entities.UserContext) (decisionContext decision.ExperimentDecisionContext, experimentDecision decision.ExperimentDecision, err error) {
+
+	// no decision is made for a call which is already done, it would not get its impression event
+	if e := ctx.Err(); e != nil {
+		return decisionContext, experimentDecision, e
+	}
+
+	userID := userContext.ID
+	logger.Debug(fmt.Sprintf(`Evaluating experiment "%s" for user "%s".`, experimentKey, userID))
+	validateUserContext(userContext)
+
 	experiment, e := projectConfig.GetExperimentByKey(experimentKey)
 	if e != nil {
 		logger.Warning(fmt.Sprintf(`Could not get experiment for key "%s": %s`, experimentKey, e))
-		return decisionContext, experimentDecision, nil
+		return decisionContext, experimentDecision, o.entityNotFound(EntityKindExperiment, experimentKey, userContext)
 	}
 
 	decisionContext = decision.ExperimentDecisionContext{
@@ -438,7 +782,11 @@ func (o *OptimizelyClient) getExperimentDecision(experimentKey string, userConte
 		ProjectConfig: projectConfig,
 	}
 
-	experimentDecision, err = o.DecisionService.GetExperimentDecision(decisionContext, userContext)
+	if contextService, ok := o.DecisionService.(decision.ContextService); ok {
+		experimentDecision, err = contextService.GetExperimentDecisionWithContext(ctx, decisionContext, userContext)
+	} else {
+		experimentDecision, err = o.DecisionService.GetExperimentDecision(decisionContext, userContext)
+	}
 	if err != nil {
 		logger.Warning(fmt.Sprintf(`Received error while making a decision for experiment "%s": %s`, experimentKey, err))
 		return decisionContext, experimentDecision, nil
@@ -454,6 +802,17 @@ func (o *OptimizelyClient) getExperimentDecision(experimentKey string, userConte
 	return decisionContext, experimentDecision, err
 }
 
+// entityNotFound notifies the UnknownKey handlers of the unknown key and returns the error for it
+func (o *OptimizelyClient) entityNotFound(kind, key string, userContext entities.UserContext) error {
+	if o.notificationCenter != nil {
+		unknownKeyNotification := notification.UnknownKeyNotification{Kind: kind, Key: key, UserContext: userContext}
+		if e := o.notificationCenter.Send(notification.UnknownKey, unknownKeyNotification); e != nil {
+			logger.Warning("Problem with sending notification")
+		}
+	}
+	return &ErrEntityNotFound{Kind: kind, Key: key}
+}
+
 // OnTrack registers a handler for Track notifications
 func (o *OptimizelyClient) OnTrack(callback func(eventKey string, userContext entities.UserContext, eventTags map[string]interface{}, conversionEvent event.ConversionEvent)) (int, error) {
 	if o.notificationCenter == nil {
@@ -492,6 +851,192 @@ func (o *OptimizelyClient) RemoveOnTrack(id int) error {
 	return nil
 }
 
+// OnProjectConfigUpdate registers a handler for ProjectConfigUpdate notifications of the client config manager
+func (o *OptimizelyClient) OnProjectConfigUpdate(callback func(notification.ProjectConfigUpdateNotification)) (int, error) {
+	if o.ConfigManager == nil {
+		return 0, fmt.Errorf("no config manager found")
+	}
+
+	id, err := o.ConfigManager.OnProjectConfigUpdate(callback)
+	if err != nil {
+		logger.Warning("Problem with adding notification handler")
+		return 0, err
+	}
+	return id, nil
+}
+
+// RemoveOnProjectConfigUpdate removes handler for ProjectConfigUpdate notification with given id
+func (o *OptimizelyClient) RemoveOnProjectConfigUpdate(id int) error {
+	if o.ConfigManager == nil {
+		return fmt.Errorf("no config manager found")
+	}
+	if err := o.ConfigManager.RemoveOnProjectConfigUpdate(id); err != nil {
+		logger.Warning("Problem with removing notification handler")
+		return err
+	}
+	return nil
+}
+
+// OnLogEvent registers a handler for LogEvent notifications, which are sent with the destination and payload of each
+// batch right before it is dispatched
+func (o *OptimizelyClient) OnLogEvent(callback func(logEvent event.LogEvent)) (int, error) {
+	if o.EventProcessor == nil {
+		return 0, fmt.Errorf("no event processor found")
+	}
+
+	id, err := o.EventProcessor.OnEventDispatch(callback)
+	if err != nil {
+		logger.Warning("Problem with adding notification handler")
+		return 0, err
+	}
+	return id, nil
+}
+
+// RemoveOnLogEvent removes handler for LogEvent notification with given id
+func (o *OptimizelyClient) RemoveOnLogEvent(id int) error {
+	if o.EventProcessor == nil {
+		return fmt.Errorf("no event processor found")
+	}
+	if err := o.EventProcessor.RemoveOnEventDispatch(id); err != nil {
+		logger.Warning("Problem with removing notification handler")
+		return err
+	}
+	return nil
+}
+
+// OnDispatchFailure registers a handler for DispatchFailure notifications, which are sent with the log event and the
+// last error when a batch failed to be dispatched after all retries. The handler is registered with the event processor
+// when it is an event.DispatchFailureProcessor, as it sends the notifications to its own notification center.
+func (o *OptimizelyClient) OnDispatchFailure(callback func(logEvent event.LogEvent, err error)) (int, error) {
+	if failureProcessor, ok := o.EventProcessor.(event.DispatchFailureProcessor); ok {
+		id, err := failureProcessor.OnDispatchFailure(callback)
+		if err != nil {
+			logger.Warning("Problem with adding notification handler")
+			return 0, err
+		}
+		return id, nil
+	}
+
+	if o.notificationCenter == nil {
+		return 0, fmt.Errorf("no notification center found")
+	}
+
+	handler := func(payload interface{}) {
+		if failureNotification, ok := payload.(notification.DispatchFailureNotification); ok {
+			if logEvent, ok := failureNotification.LogEvent.(event.LogEvent); ok {
+				callback(logEvent, failureNotification.Err)
+				return
+			}
+		}
+		logger.Warning(fmt.Sprintf("Unable to convert notification payload %v into DispatchFailureNotification", payload))
+	}
+	id, err := o.notificationCenter.AddHandler(notification.DispatchFailure, handler)
+	if err != nil {
+		logger.Warning("Problem with adding notification handler")
+		return 0, err
+	}
+	return id, nil
+}
+
+// RemoveOnDispatchFailure removes handler for DispatchFailure notification with given id
+func (o *OptimizelyClient) RemoveOnDispatchFailure(id int) error {
+	if failureProcessor, ok := o.EventProcessor.(event.DispatchFailureProcessor); ok {
+		if err := failureProcessor.RemoveOnDispatchFailure(id); err != nil {
+			logger.Warning("Problem with removing notification handler")
+			return err
+		}
+		return nil
+	}
+
+	if o.notificationCenter == nil {
+		return fmt.Errorf("no notification center found")
+	}
+	if err := o.notificationCenter.RemoveHandler(id, notification.DispatchFailure); err != nil {
+		logger.Warning("Problem with removing notification handler")
+		return err
+	}
+	return nil
+}
+
+// WarmUserProfiles retrieves the profiles of the given users ahead of their decisions, for instance at the start of a
+// request for a known cohort, see decision.CompositeExperimentService.WarmUserProfiles. It has no effect when the
+// decision service is not a decision.UserProfileWarmingService.
+func (o *OptimizelyClient) WarmUserProfiles(userIDs []string) {
+	if warmingService, ok := o.DecisionService.(decision.UserProfileWarmingService); ok {
+		warmingService.WarmUserProfiles(userIDs)
+		return
+	}
+	logger.Debug("Decision service does not support warming user profiles.")
+}
+
+// OnDecisionOfTypes registers a handler for the Decision notifications of the given decision types only, e.g.
+// notification.FeatureVariable, it can be removed with RemoveOnDecision
+func (o *OptimizelyClient) OnDecisionOfTypes(callback func(notification.DecisionNotification), decisionTypes ...notification.DecisionNotificationType) (int, error) {
+	if o.DecisionService == nil {
+		return 0, fmt.Errorf("no decision service found")
+	}
+
+	if filteredService, ok := o.DecisionService.(decision.FilteredService); ok {
+		return filteredService.OnDecisionOfTypes(callback, decisionTypes...)
+	}
+	// the other decision services notify every decision, the handler skips the ones of other types
+	filter := notification.DecisionTypeFilter(decisionTypes...)
+	return o.DecisionService.OnDecision(func(decisionNotification notification.DecisionNotification) {
+		if filter(decisionNotification) {
+			callback(decisionNotification)
+		}
+	})
+}
+
+// RemoveOnDecision removes handler for Decision notification with given id
+func (o *OptimizelyClient) RemoveOnDecision(id int) error {
+	if o.DecisionService == nil {
+		return fmt.Errorf("no decision service found")
+	}
+	return o.DecisionService.RemoveOnDecision(id)
+}
+
+// OnUnknownKey registers a handler for UnknownKey notifications, which are sent with the kind and the key of the
+// experiments, features and feature variables requested by a key which is not in the project config
+func (o *OptimizelyClient) OnUnknownKey(callback func(kind, key string, userContext entities.UserContext)) (int, error) {
+	if o.notificationCenter == nil {
+		return 0, fmt.Errorf("no notification center found")
+	}
+
+	handler := func(payload interface{}) {
+		if unknownKeyNotification, ok := payload.(notification.UnknownKeyNotification); ok {
+			callback(unknownKeyNotification.Kind, unknownKeyNotification.Key, unknownKeyNotification.UserContext)
+			return
+		}
+		logger.Warning(fmt.Sprintf("Unable to convert notification payload %v into UnknownKeyNotification", payload))
+	}
+	id, err := o.notificationCenter.AddHandler(notification.UnknownKey, handler)
+	if err != nil {
+		logger.Warning("Problem with adding notification handler")
+		return 0, err
+	}
+	return id, nil
+}
+
+// RemoveOnUnknownKey removes handler for UnknownKey notification with given id
+func (o *OptimizelyClient) RemoveOnUnknownKey(id int) error {
+	if o.notificationCenter == nil {
+		return fmt.Errorf("no notification center found")
+	}
+	if err := o.notificationCenter.RemoveHandler(id, notification.UnknownKey); err != nil {
+		logger.Warning("Problem with removing notification handler")
+		return err
+	}
+	return nil
+}
+
+// validateUserContext warns about reserved attributes the SDK cannot use as provided
+func validateUserContext(userContext entities.UserContext) {
+	for _, err := range userContext.ValidateReservedAttributes() {
+		logger.Warning(fmt.Sprintf(`Invalid attributes for user "%s": %s`, userContext.ID, err))
+	}
+}
+
 func (o *OptimizelyClient) getProjectConfig() (projectConfig config.ProjectConfig, err error) {
 
 	if isNil(o.ConfigManager) {
@@ -505,6 +1050,67 @@ func (o *OptimizelyClient) getProjectConfig() (projectConfig config.ProjectConfi
 	return projectConfig, nil
 }
 
+// HealthStatus reports whether the client is ready to serve decisions and whether its events are being dispatched
+type HealthStatus struct {
+	// ConfigReady is true once a project config is loaded
+	ConfigReady bool
+	// ConfigError is the reason no project config is loaded yet
+	ConfigError error
+	// DispatchHealthy is false while the events persistently fail to be dispatched
+	DispatchHealthy bool
+}
+
+// Health returns the readiness of the project config and the health of the event dispatching of the client. The
+// dispatching is reported healthy by event processors which do not implement event.HealthCheckProcessor.
+func (o *OptimizelyClient) Health() HealthStatus {
+	status := HealthStatus{DispatchHealthy: true}
+
+	projectConfig, err := o.getProjectConfig()
+	switch {
+	case err != nil:
+		status.ConfigError = err
+	case isNil(projectConfig):
+		status.ConfigError = decision.ErrConfigNotReady
+	default:
+		status.ConfigReady = true
+	}
+
+	if healthCheckProcessor, ok := o.EventProcessor.(event.HealthCheckProcessor); ok {
+		status.DispatchHealthy = healthCheckProcessor.DispatchHealthy()
+	}
+	return status
+}
+
+// IsReady returns true if the client has a project config to serve decisions with and its events are being
+// dispatched, e.g. to answer readiness probes
+func (o *OptimizelyClient) IsReady() bool {
+	status := o.Health()
+	return status.ConfigReady && status.DispatchHealthy
+}
+
+// GetProjectConfig returns the current ProjectConfig of the client, it should be treated as read-only
+func (o *OptimizelyClient) GetProjectConfig() (projectConfig config.ProjectConfig, err error) {
+	return o.getProjectConfig()
+}
+
+// GetRevision returns the revision of the current datafile
+func (o *OptimizelyClient) GetRevision() (string, error) {
+	projectConfig, err := o.getProjectConfig()
+	if err != nil {
+		return "", err
+	}
+	return projectConfig.GetRevision(), nil
+}
+
+// GetProjectID returns the project ID of the current datafile
+func (o *OptimizelyClient) GetProjectID() (string, error) {
+	projectConfig, err := o.getProjectConfig()
+	if err != nil {
+		return "", err
+	}
+	return projectConfig.GetProjectID(), nil
+}
+
 // GetOptimizelyConfig returns OptimizelyConfig object
 func (o *OptimizelyClient) GetOptimizelyConfig() (optimizelyConfig *config.OptimizelyConfig) {
 
@@ -517,6 +1123,50 @@ func (o *OptimizelyClient) Close() {
 	o.execGroup.TerminateAndWait()
 }
 
+// CloseWithTimeout closes the Optimizely instance like Close, but gives up on the final event flush and on waiting for
+// the children components once the timeout elapses. It returns the number of events left undispatched.
+func (o *OptimizelyClient) CloseWithTimeout(timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	undispatched := 0
+	if drainableProcessor, ok := o.EventProcessor.(event.DrainableProcessor); ok {
+		undispatched = drainableProcessor.FlushWithTimeout(timeout)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		o.execGroup.TerminateAndWait()
+	}()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		logger.Warning(fmt.Sprintf("Optimizely client did not shut down within %v", timeout))
+	}
+	return undispatched
+}
+
+// CloseOnSignal blocks until one of the signals is received, SIGINT or SIGTERM by default, or until the context is done,
+// then closes the client with CloseWithTimeout so that its events are drained before the application exits. It returns
+// the number of events left undispatched once the client shut down or the timeout elapsed.
+func CloseOnSignal(ctx context.Context, client *OptimizelyClient, timeout time.Duration, signals ...os.Signal) int {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, signals...)
+	defer signal.Stop(signalChan)
+
+	select {
+	case received := <-signalChan:
+		logger.Info(fmt.Sprintf("Received %v, closing the Optimizely client", received))
+	case <-ctx.Done():
+	}
+	return client.CloseWithTimeout(timeout)
+}
+
 func isNil(v interface{}) bool {
 	return v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil())
 }
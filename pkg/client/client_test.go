@@ -20,14 +20,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/optimizely/go-sdk/pkg/config"
 	"github.com/optimizely/go-sdk/pkg/decision"
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/event"
+	"github.com/optimizely/go-sdk/pkg/event/eventtest"
 	"github.com/optimizely/go-sdk/pkg/notification"
 	"github.com/optimizely/go-sdk/pkg/utils"
 
@@ -128,6 +133,9 @@ func (TestConfig) GetAnonymizeIP() bool {
 func (TestConfig) GetAttributeID(key string) string { // returns "" if there is no id
 	return ""
 }
+func (TestConfig) GetAttributeByKey(key string) (entities.Attribute, error) {
+	return entities.Attribute{ID: "attribute_" + key, Key: key}, nil
+}
 func (TestConfig) GetBotFiltering() bool {
 	return false
 }
@@ -158,6 +166,314 @@ func TestTrack(t *testing.T) {
 
 }
 
+func TestTrackWithTimestamp(t *testing.T) {
+	mockProcessor := new(MockProcessor)
+	mockProcessor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent")).Return(true)
+
+	client := OptimizelyClient{
+		ConfigManager:   ValidProjectConfigManager(),
+		DecisionService: new(MockDecisionService),
+		EventProcessor:  mockProcessor,
+	}
+
+	timestamp := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+	err := client.TrackWithTimestamp("sample_conversion", entities.UserContext{ID: "1212121"}, nil, timestamp)
+
+	assert.NoError(t, err)
+	if assert.Len(t, mockProcessor.Events, 1) {
+		assert.Equal(t, timestamp.UnixNano()/int64(time.Millisecond), mockProcessor.Events[0].Timestamp)
+	}
+}
+
+func TestTrackWithContext(t *testing.T) {
+	type contextKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "span"))
+	contextEventProcessor := new(ContextEventProcessor)
+	contextEventProcessor.On("ProcessEventWithContext", ctx, mock.AnythingOfType("event.UserEvent")).Return(true)
+
+	client := OptimizelyClient{
+		ConfigManager:   ValidProjectConfigManager(),
+		DecisionService: new(MockDecisionService),
+		EventProcessor:  contextEventProcessor,
+	}
+
+	assert.NoError(t, client.TrackWithContext(ctx, "sample_conversion", entities.UserContext{ID: "1212121"}, nil))
+	contextEventProcessor.AssertExpectations(t)
+
+	cancel()
+	assert.Equal(t, context.Canceled, client.TrackWithContext(ctx, "sample_conversion", entities.UserContext{ID: "1212121"}, nil))
+	contextEventProcessor.AssertNumberOfCalls(t, "ProcessEventWithContext", 1)
+}
+
+func TestTrackWithDefaultAttributes(t *testing.T) {
+	mockProcessor := new(MockProcessor)
+	mockProcessor.On("ProcessEvent", mock.AnythingOfType("UserEvent")).Return(true)
+
+	client := OptimizelyClient{
+		ConfigManager:     ValidProjectConfigManager(),
+		DecisionService:   new(MockDecisionService),
+		EventProcessor:    mockProcessor,
+		defaultAttributes: map[string]interface{}{"app_version": "1.0.0", "platform": "ios"},
+	}
+
+	userAttributes := map[string]interface{}{"platform": "android"}
+	err := client.Track("sample_conversion", entities.UserContext{ID: "1212121", Attributes: userAttributes}, map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"platform": "android"}, userAttributes)
+
+	if assert.Len(t, mockProcessor.Events, 1) {
+		attributes := map[string]interface{}{}
+		for _, attribute := range mockProcessor.Events[0].Conversion.Attributes {
+			attributes[attribute.Key] = attribute.Value
+		}
+		assert.Equal(t, "1.0.0", attributes["app_version"])
+		assert.Equal(t, "android", attributes["platform"])
+	}
+}
+
+func TestTrackWithDefaultEventTags(t *testing.T) {
+	mockProcessor := new(MockProcessor)
+	mockProcessor.On("ProcessEvent", mock.AnythingOfType("UserEvent")).Return(true)
+
+	client := OptimizelyClient{
+		ConfigManager:    ValidProjectConfigManager(),
+		DecisionService:  new(MockDecisionService),
+		EventProcessor:   mockProcessor,
+		defaultEventTags: map[string]interface{}{"environment": "production", "revenue": 100},
+	}
+
+	eventTags := map[string]interface{}{"revenue": 250, "category": "shoes"}
+	err := client.Track("sample_conversion", entities.UserContext{ID: "1212121"}, eventTags)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"revenue": 250, "category": "shoes"}, eventTags)
+
+	err = client.Track("sample_conversion", entities.UserContext{ID: "1212121"}, nil)
+	assert.NoError(t, err)
+
+	if assert.Len(t, mockProcessor.Events, 2) {
+		conversion := mockProcessor.Events[0].Conversion
+		assert.Equal(t, map[string]interface{}{"environment": "production", "revenue": 250, "category": "shoes"}, conversion.Tags)
+		if assert.NotNil(t, conversion.Revenue) {
+			assert.Equal(t, int64(250), *conversion.Revenue)
+		}
+		assert.Equal(t, map[string]interface{}{"environment": "production", "revenue": 100}, mockProcessor.Events[1].Conversion.Tags)
+	}
+}
+
+func TestTrackWithAllowedEventKeys(t *testing.T) {
+	mockProcessor := new(MockProcessor)
+	mockProcessor.On("ProcessEvent", mock.AnythingOfType("UserEvent")).Return(true)
+
+	client := OptimizelyClient{
+		ConfigManager:    ValidProjectConfigManager(),
+		DecisionService:  new(MockDecisionService),
+		EventProcessor:   mockProcessor,
+		allowedEventKeys: map[string]bool{"other_conversion": true},
+	}
+
+	// the events outside of the allowed keys are dropped without an error
+	assert.NoError(t, client.Track("sample_conversion", entities.UserContext{ID: "1212121"}, nil))
+	assert.Empty(t, mockProcessor.Events)
+
+	client.allowedEventKeys = map[string]bool{"other_conversion": true, "sample_conversion": true}
+	assert.NoError(t, client.Track("sample_conversion", entities.UserContext{ID: "1212121"}, nil))
+	if assert.Len(t, mockProcessor.Events, 1) {
+		assert.Equal(t, "sample_conversion", mockProcessor.Events[0].Conversion.Key)
+	}
+
+	// no event is tracked with an empty set of keys
+	client.allowedEventKeys = map[string]bool{}
+	assert.NoError(t, client.Track("sample_conversion", entities.UserContext{ID: "1212121"}, nil))
+	assert.Len(t, mockProcessor.Events, 1)
+}
+
+type fixedClock struct {
+	utils.Clock
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestEventTimestampsFromClock(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","projectId":"1","experiments":[{"id":"11","key":"exp_key","status":"Running",` +
+		`"layerId":"1","audienceIds":[],"variations":[{"id":"21","key":"variation_key"}],` +
+		`"trafficAllocation":[{"entityId":"21","endOfRange":10000}],"forcedVariations":{}}],` +
+		`"events":[{"id":"31","key":"purchase","experimentIds":["11"]}]}`)
+	// a fixed time far in the past, the timestamp overrides are checked against the clock rather than the system time
+	now := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+	mockProcessor := new(MockProcessor)
+	mockProcessor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent")).Return(true)
+
+	client, err := (&OptimizelyFactory{Datafile: datafile}).Client(WithClock(fixedClock{now: now}),
+		WithEventProcessor(mockProcessor))
+	assert.NoError(t, err)
+	userContext := entities.UserContext{ID: "test_user"}
+	_, err = client.Activate("exp_key", userContext)
+	assert.NoError(t, err)
+	assert.NoError(t, client.Track("purchase", userContext, nil))
+
+	// an explicit timestamp takes precedence over the clock
+	timestamp := now.Add(-time.Hour)
+	assert.NoError(t, client.TrackWithTimestamp("purchase", userContext, nil, timestamp))
+
+	if assert.Len(t, mockProcessor.Events, 3) {
+		assert.NotNil(t, mockProcessor.Events[0].Impression)
+		assert.Equal(t, now.UnixNano()/int64(time.Millisecond), mockProcessor.Events[0].Timestamp)
+		assert.Equal(t, now.UnixNano()/int64(time.Millisecond), mockProcessor.Events[1].Timestamp)
+		assert.Equal(t, timestamp.UnixNano()/int64(time.Millisecond), mockProcessor.Events[2].Timestamp)
+	}
+	client.Close()
+}
+
+func TestTrackOptedOut(t *testing.T) {
+	mockProcessor := new(MockProcessor)
+	mockProcessor.On("ProcessEvent", mock.AnythingOfType("UserEvent")).Return(true)
+
+	client := OptimizelyClient{
+		ConfigManager:   ValidProjectConfigManager(),
+		DecisionService: new(MockDecisionService),
+		EventProcessor:  mockProcessor,
+	}
+
+	userContext := entities.UserContext{ID: "1212121", Attributes: map[string]interface{}{entities.OptOutAttributeName: true}}
+	err := client.Track("sample_conversion", userContext, map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Empty(t, mockProcessor.Events)
+}
+
+type revisionTestConfig struct {
+	TestConfig
+	revision string
+}
+
+func (c revisionTestConfig) GetRevision() string {
+	return c.revision
+}
+
+func TestTrackAttributesRevisionAcrossConfigUpdate(t *testing.T) {
+	mockConfigManager := new(MockProjectConfigManager)
+	mockConfigManager.On("GetConfig").Return(revisionTestConfig{revision: "1"}, nil).Once()
+	mockConfigManager.On("GetConfig").Return(revisionTestConfig{revision: "2"}, nil).Once()
+
+	dispatcher := &MockDispatcher{}
+	processor := event.NewBatchEventProcessor(event.WithEventDispatcher(dispatcher), event.WithBatchSize(10))
+
+	client := OptimizelyClient{
+		ConfigManager:   mockConfigManager,
+		DecisionService: new(MockDecisionService),
+		EventProcessor:  processor,
+	}
+
+	userContext := entities.UserContext{ID: "1212121", Attributes: map[string]interface{}{}}
+	assert.NoError(t, client.Track("sample_conversion", userContext, map[string]interface{}{}))
+	assert.NoError(t, client.Track("sample_conversion", userContext, map[string]interface{}{}))
+
+	processor.Flush()
+
+	// events created against different revisions are never batched together
+	if assert.Len(t, dispatcher.Events, 2) {
+		assert.Equal(t, "1", dispatcher.Events[0].Event.Revision)
+		assert.Equal(t, "2", dispatcher.Events[1].Event.Revision)
+	}
+	mockConfigManager.AssertExpectations(t)
+}
+
+func TestOnLogEvent(t *testing.T) {
+	dispatcher := &MockDispatcher{}
+	processor := event.NewBatchEventProcessor(event.WithEventDispatcher(dispatcher), event.WithSDKKey("test_on_log_event"))
+
+	client := OptimizelyClient{
+		ConfigManager:   ValidProjectConfigManager(),
+		DecisionService: new(MockDecisionService),
+		EventProcessor:  processor,
+	}
+
+	var logEvents []event.LogEvent
+	id, err := client.OnLogEvent(func(logEvent event.LogEvent) {
+		logEvents = append(logEvents, logEvent)
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, id)
+
+	assert.NoError(t, client.Track("sample_conversion", entities.UserContext{ID: "1212121"}, nil))
+	processor.Flush()
+
+	if assert.Len(t, logEvents, 1) {
+		assert.Equal(t, "https://logx.optimizely.com/v1/events", logEvents[0].EndPoint)
+		assert.Equal(t, "1212121", logEvents[0].Event.Visitors[0].VisitorID)
+		assert.Equal(t, dispatcher.Events, logEvents)
+	}
+
+	assert.NoError(t, client.RemoveOnLogEvent(id))
+	assert.NoError(t, client.Track("sample_conversion", entities.UserContext{ID: "1212121"}, nil))
+	processor.Flush()
+	assert.Len(t, logEvents, 1)
+	assert.Len(t, dispatcher.Events, 2)
+
+	client = OptimizelyClient{}
+	_, err = client.OnLogEvent(func(logEvent event.LogEvent) {})
+	assert.Error(t, err)
+	assert.Error(t, client.RemoveOnLogEvent(id))
+}
+
+func TestOnDispatchFailure(t *testing.T) {
+	notificationCenter := notification.NewNotificationCenter()
+	client := OptimizelyClient{notificationCenter: notificationCenter}
+
+	var failedEvents []event.LogEvent
+	var failedErrs []error
+	id, err := client.OnDispatchFailure(func(logEvent event.LogEvent, err error) {
+		failedEvents = append(failedEvents, logEvent)
+		failedErrs = append(failedErrs, err)
+	})
+	assert.NoError(t, err)
+
+	logEvent := event.LogEvent{EndPoint: "https://logx.optimizely.com/v1/events"}
+	dispatchErr := errors.New("dispatch event failed")
+	assert.NoError(t, notificationCenter.Send(notification.DispatchFailure, notification.DispatchFailureNotification{LogEvent: logEvent, Err: dispatchErr}))
+	assert.Equal(t, []event.LogEvent{logEvent}, failedEvents)
+	assert.Equal(t, []error{dispatchErr}, failedErrs)
+
+	assert.NoError(t, client.RemoveOnDispatchFailure(id))
+	assert.NoError(t, notificationCenter.Send(notification.DispatchFailure, notification.DispatchFailureNotification{LogEvent: logEvent, Err: dispatchErr}))
+	assert.Len(t, failedEvents, 1)
+
+	client = OptimizelyClient{}
+	_, err = client.OnDispatchFailure(func(logEvent event.LogEvent, err error) {})
+	assert.Error(t, err)
+	assert.Error(t, client.RemoveOnDispatchFailure(id))
+}
+
+func TestOnDispatchFailureWithCustomEventProcessor(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","projectId":"11","accountId":"12","events":[{"id":"31","key":"event_key","experimentIds":[]}]}`)
+	// the processor sends its notifications to the center registered for its SDK key, not to the one of the client
+	dispatcher := eventtest.NewDispatcher()
+	dispatcher.SetShouldFail(true)
+	processor := event.NewBatchEventProcessor(event.WithSDKKey("test_on_dispatch_failure"), event.WithEventDispatcher(dispatcher))
+	client, err := (&OptimizelyFactory{Datafile: datafile}).Client(WithEventProcessor(processor))
+	assert.NoError(t, err)
+
+	failedErrs := make(chan error, 1)
+	id, err := client.OnDispatchFailure(func(logEvent event.LogEvent, err error) {
+		failedErrs <- err
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.Track("event_key", entities.UserContext{ID: "test_user"}, nil))
+	processor.Flush()
+	select {
+	case err := <-failedErrs:
+		assert.Equal(t, eventtest.ErrDispatchFailed, err)
+	case <-time.After(100 * time.Millisecond):
+		t.Error("the dispatch failure was not notified")
+	}
+
+	assert.NoError(t, client.RemoveOnDispatchFailure(id))
+	client.Close()
+}
+
 func TestTrackFailEventNotFound(t *testing.T) {
 	mockProcessor := &MockProcessor{}
 	mockDecisionService := new(MockDecisionService)
@@ -253,6 +569,51 @@ func TestGetFeatureVariableBooleanWithValidValue(t *testing.T) {
 	mockDecisionService.AssertExpectations(t)
 }
 
+func TestGetFeatureVariableWithContext(t *testing.T) {
+	type contextKey struct{}
+	ctx := context.WithValue(context.Background(), contextKey{}, "span")
+	testFeatureKey := "test_feature_key"
+	testVariableKey := "test_feature_flag_key"
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	testVariationVariable := entities.VariationVariable{
+		ID:    "1",
+		Value: "5",
+	}
+	testVariable := entities.Variable{
+		DefaultValue: "1",
+		ID:           "1",
+		Key:          testVariableKey,
+		Type:         entities.Integer,
+	}
+	testVariation := getTestVariationWithFeatureVariable(true, testVariationVariable)
+	testExperiment := entities.Experiment{
+		ID:         "111111",
+		Variations: map[string]entities.Variation{"22222": testVariation},
+	}
+	testFeature := getTestFeature(testFeatureKey, testExperiment)
+	mockConfig := getMockConfig(testFeatureKey, testVariableKey, testFeature, testVariable)
+	mockConfigManager := new(MockProjectConfigManager)
+	mockConfigManager.On("GetConfig").Return(mockConfig, nil)
+
+	testDecisionContext := decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: mockConfig,
+		Variable:      testVariable,
+	}
+
+	contextDecisionService := new(ContextDecisionService)
+	contextDecisionService.On("GetFeatureDecisionWithContext", ctx, testDecisionContext, testUserContext).Return(getTestFeatureDecision(testExperiment, testVariation), nil)
+
+	client := OptimizelyClient{
+		ConfigManager:   mockConfigManager,
+		DecisionService: contextDecisionService,
+	}
+	result, err := client.GetFeatureVariableIntegerWithContext(ctx, testFeatureKey, testVariableKey, testUserContext)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result)
+	contextDecisionService.AssertExpectations(t)
+}
+
 func TestGetFeatureVariableBooleanWithInvalidValue(t *testing.T) {
 	testFeatureKey := "test_feature_key"
 	testVariableKey := "test_feature_flag_key"
@@ -341,7 +702,7 @@ func TestGetFeatureVariableBooleanWithInvalidValueType(t *testing.T) {
 	}
 	result, err := client.GetFeatureVariableBoolean(testFeatureKey, testVariableKey, testUserContext)
 	assert.Equal(t, false, result)
-	assert.Error(t, err)
+	assert.Equal(t, VariableTypeMismatchError{VariableKey: testVariableKey, ExpectedType: entities.Boolean, ActualType: entities.Integer}, err)
 	mockConfig.AssertExpectations(t)
 	mockConfigManager.AssertExpectations(t)
 	mockDecisionService.AssertExpectations(t)
@@ -388,7 +749,7 @@ func TestGetFeatureVariableBooleanWithEmptyValueType(t *testing.T) {
 	}
 	result, err := client.GetFeatureVariableBoolean(testFeatureKey, testVariableKey, testUserContext)
 	assert.Equal(t, false, result)
-	assert.Error(t, err)
+	assert.Equal(t, VariableTypeMismatchError{VariableKey: testVariableKey, ExpectedType: entities.Boolean, ActualType: ""}, err)
 	mockConfig.AssertExpectations(t)
 	mockConfigManager.AssertExpectations(t)
 	mockDecisionService.AssertExpectations(t)
@@ -593,7 +954,7 @@ func TestGetFeatureVariableDoubleWithInvalidValueType(t *testing.T) {
 	}
 	result, err := client.GetFeatureVariableDouble(testFeatureKey, testVariableKey, testUserContext)
 	assert.Equal(t, float64(0), result)
-	assert.Error(t, err)
+	assert.Equal(t, VariableTypeMismatchError{VariableKey: testVariableKey, ExpectedType: entities.Double, ActualType: entities.Integer}, err)
 	mockConfig.AssertExpectations(t)
 	mockConfigManager.AssertExpectations(t)
 	mockDecisionService.AssertExpectations(t)
@@ -640,7 +1001,7 @@ func TestGetFeatureVariableDoubleWithEmptyValueType(t *testing.T) {
 	}
 	result, err := client.GetFeatureVariableDouble(testFeatureKey, testVariableKey, testUserContext)
 	assert.Equal(t, float64(0), result)
-	assert.Error(t, err)
+	assert.Equal(t, VariableTypeMismatchError{VariableKey: testVariableKey, ExpectedType: entities.Double, ActualType: ""}, err)
 	mockConfig.AssertExpectations(t)
 	mockConfigManager.AssertExpectations(t)
 	mockDecisionService.AssertExpectations(t)
@@ -845,7 +1206,7 @@ func TestGetFeatureVariableIntegerWithInvalidValueType(t *testing.T) {
 	}
 	result, err := client.GetFeatureVariableInteger(testFeatureKey, testVariableKey, testUserContext)
 	assert.Equal(t, 0, result)
-	assert.Error(t, err)
+	assert.Equal(t, VariableTypeMismatchError{VariableKey: testVariableKey, ExpectedType: entities.Integer, ActualType: entities.Boolean}, err)
 	mockConfig.AssertExpectations(t)
 	mockConfigManager.AssertExpectations(t)
 	mockDecisionService.AssertExpectations(t)
@@ -892,7 +1253,7 @@ func TestGetFeatureVariableIntegerWithEmptyValueType(t *testing.T) {
 	}
 	result, err := client.GetFeatureVariableInteger(testFeatureKey, testVariableKey, testUserContext)
 	assert.Equal(t, 0, result)
-	assert.Error(t, err)
+	assert.Equal(t, VariableTypeMismatchError{VariableKey: testVariableKey, ExpectedType: entities.Integer, ActualType: ""}, err)
 	mockConfig.AssertExpectations(t)
 	mockConfigManager.AssertExpectations(t)
 	mockDecisionService.AssertExpectations(t)
@@ -1050,7 +1411,7 @@ func TestGetFeatureVariableStringWithInvalidValueType(t *testing.T) {
 	}
 	result, err := client.GetFeatureVariableString(testFeatureKey, testVariableKey, testUserContext)
 	assert.Equal(t, "", result)
-	assert.Error(t, err)
+	assert.Equal(t, VariableTypeMismatchError{VariableKey: testVariableKey, ExpectedType: entities.String, ActualType: entities.Boolean}, err)
 	mockConfig.AssertExpectations(t)
 	mockConfigManager.AssertExpectations(t)
 	mockDecisionService.AssertExpectations(t)
@@ -1097,7 +1458,7 @@ func TestGetFeatureVariableStringWithEmptyValueType(t *testing.T) {
 	}
 	result, err := client.GetFeatureVariableString(testFeatureKey, testVariableKey, testUserContext)
 	assert.Equal(t, "", result)
-	assert.Error(t, err)
+	assert.Equal(t, VariableTypeMismatchError{VariableKey: testVariableKey, ExpectedType: entities.String, ActualType: ""}, err)
 	mockConfig.AssertExpectations(t)
 	mockConfigManager.AssertExpectations(t)
 	mockDecisionService.AssertExpectations(t)
@@ -1150,6 +1511,66 @@ func TestGetFeatureVariableStringReturnsDefaultValueIfFeatureNotEnabled(t *testi
 	mockDecisionService.AssertExpectations(t)
 }
 
+func TestGetFeatureVariable(t *testing.T) {
+	testFeatureKey := "test_feature_key"
+	testVariableKey := "test_feature_flag_key"
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	testVariable := entities.Variable{
+		DefaultValue: "1.0",
+		ID:           "1",
+		Key:          testVariableKey,
+		Type:         entities.Double,
+	}
+
+	for _, featureEnabled := range []bool{true, false} {
+		testVariation := getTestVariationWithFeatureVariable(featureEnabled, entities.VariationVariable{ID: "1", Value: "5.5"})
+		testExperiment := entities.Experiment{
+			ID:         "111111",
+			Variations: map[string]entities.Variation{"22222": testVariation},
+		}
+		testFeature := getTestFeature(testFeatureKey, testExperiment)
+		mockConfig := getMockConfig(testFeatureKey, testVariableKey, testFeature, testVariable)
+		mockConfigManager := new(MockProjectConfigManager)
+		mockConfigManager.On("GetConfig").Return(mockConfig, nil)
+
+		testDecisionContext := decision.FeatureDecisionContext{
+			Feature:       &testFeature,
+			ProjectConfig: mockConfig,
+			Variable:      testVariable,
+		}
+		mockDecisionService := new(MockDecisionService)
+		mockDecisionService.On("GetFeatureDecision", testDecisionContext, testUserContext).Return(getTestFeatureDecision(testExperiment, testVariation), nil)
+
+		client := OptimizelyClient{
+			ConfigManager:   mockConfigManager,
+			DecisionService: mockDecisionService,
+		}
+		value, valueType, err := client.GetFeatureVariable(testFeatureKey, testVariableKey, testUserContext)
+		assert.NoError(t, err)
+		assert.Equal(t, entities.Double, valueType)
+		if featureEnabled {
+			assert.Equal(t, "5.5", value)
+		} else {
+			assert.Equal(t, "1.0", value)
+		}
+		mockDecisionService.AssertExpectations(t)
+	}
+}
+
+func TestGetFeatureVariableWithError(t *testing.T) {
+	mockConfigManager := new(MockProjectConfigManager)
+	mockConfigManager.On("GetConfig").Return(nil, errors.New("no project config available"))
+
+	client := OptimizelyClient{
+		ConfigManager:   mockConfigManager,
+		DecisionService: new(MockDecisionService),
+	}
+	value, valueType, err := client.GetFeatureVariable("test_feature_key", "test_variable_key", entities.UserContext{ID: "test_user_1"})
+	assert.Error(t, err)
+	assert.Equal(t, "", value)
+	assert.Equal(t, entities.VariableType(""), valueType)
+}
+
 func TestGetFeatureVariableStringPanic(t *testing.T) {
 	testUserContext := entities.UserContext{ID: "test_user_1"}
 	testFeatureKey := "test_feature_key"
@@ -1217,68 +1638,205 @@ func TestGetProjectConfigIsInValid(t *testing.T) {
 	assert.Nil(t, actual)
 }
 
-func TestGetOptimizelyConfig(t *testing.T) {
+func TestGetProjectConfig(t *testing.T) {
 	mockConfigManager := ValidProjectConfigManager()
 
 	client := OptimizelyClient{
 		ConfigManager: mockConfigManager,
 	}
 
-	optimizelyConfig := client.GetOptimizelyConfig()
+	actual, err := client.GetProjectConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, mockConfigManager.projectConfig, actual)
 
-	assert.Equal(t, &config.OptimizelyConfig{Revision: "232"}, optimizelyConfig)
+	client = OptimizelyClient{}
+	actual, err = client.GetProjectConfig()
+	assert.EqualError(t, err, "project config manager is not initialized")
+	assert.Nil(t, actual)
 }
 
-func TestGetFeatureDecisionValid(t *testing.T) {
-	testFeatureKey := "test_feature_key"
-	testVariableKey := "test_feature_flag_key"
-	testVariableValue := "teststring"
-	testUserContext := entities.UserContext{ID: "test_user_1"}
-	testVariationVariable := entities.VariationVariable{
-		ID:    "1",
-		Value: testVariableValue,
-	}
-	testVariable := entities.Variable{
-		DefaultValue: "defaultString",
-		ID:           "1",
-		Key:          "test_feature_flag_key",
-		Type:         entities.String,
-	}
-	testVariation := getTestVariationWithFeatureVariable(false, testVariationVariable)
-	testExperiment := entities.Experiment{
-		ID:         "111111",
-		Variations: map[string]entities.Variation{"22222": testVariation},
-	}
-	testFeature := getTestFeature(testFeatureKey, testExperiment)
-	mockConfig := getMockConfig(testFeatureKey, testVariableKey, testFeature, testVariable)
-	mockConfigManager := new(MockProjectConfigManager)
-	mockConfigManager.On("GetConfig").Return(mockConfig, nil)
+type healthCheckProcessor struct {
+	MockProcessor
+	healthy bool
+}
 
-	testDecisionContext := decision.FeatureDecisionContext{
-		Feature:       &testFeature,
-		ProjectConfig: mockConfig,
-		Variable:      testVariable,
-	}
+func (p *healthCheckProcessor) DispatchHealthy() bool {
+	return p.healthy
+}
 
-	expectedFeatureDecision := getTestFeatureDecision(testExperiment, testVariation)
-	mockDecisionService := new(MockDecisionService)
-	mockDecisionService.On("GetFeatureDecision", testDecisionContext, testUserContext).Return(expectedFeatureDecision, nil)
+func TestHealth(t *testing.T) {
+	client := OptimizelyClient{ConfigManager: ValidProjectConfigManager(), EventProcessor: new(MockProcessor)}
+	assert.Equal(t, HealthStatus{ConfigReady: true, DispatchHealthy: true}, client.Health())
+	assert.True(t, client.IsReady())
 
-	client := OptimizelyClient{
-		ConfigManager:   mockConfigManager,
-		DecisionService: mockDecisionService,
-	}
+	client.EventProcessor = &healthCheckProcessor{healthy: false}
+	assert.Equal(t, HealthStatus{ConfigReady: true, DispatchHealthy: false}, client.Health())
+	assert.False(t, client.IsReady())
 
-	_, featureDecision, err := client.getFeatureDecision(testFeatureKey, testVariableKey, testUserContext)
-	assert.Nil(t, err)
-	assert.Equal(t, expectedFeatureDecision, featureDecision)
+	client = OptimizelyClient{ConfigManager: config.NewStaticProjectConfigManager(nil), EventProcessor: &healthCheckProcessor{healthy: true}}
+	assert.Equal(t, HealthStatus{ConfigError: decision.ErrConfigNotReady, DispatchHealthy: true}, client.Health())
+	assert.False(t, client.IsReady())
+
+	client = OptimizelyClient{}
+	status := client.Health()
+	assert.False(t, status.ConfigReady)
+	assert.EqualError(t, status.ConfigError, "project config manager is not initialized")
+	assert.False(t, client.IsReady())
 }
 
-func TestGetFeatureDecisionErrProjectConfig(t *testing.T) {
-	testFeatureKey := "test_feature_key"
-	testVariableKey := "test_feature_flag_key"
-	testVariableValue := "teststring"
-	testUserContext := entities.UserContext{ID: "test_user_1"}
+func TestGetRevisionAndProjectID(t *testing.T) {
+	mockConfigManager := new(MockProjectConfigManager)
+	mockConfigManager.On("GetConfig").Return(new(MockProjectConfig), nil).Once()
+	mockConfigManager.On("GetConfig").Return(revisionTestConfig{TestConfig: TestConfig{new(MockProjectConfig)}, revision: "8"}, nil).Once()
+	mockConfigManager.On("GetConfig").Return(new(MockProjectConfig), nil).Once()
+
+	client := OptimizelyClient{
+		ConfigManager: mockConfigManager,
+	}
+
+	revision, err := client.GetRevision()
+	assert.NoError(t, err)
+	assert.Equal(t, "7", revision)
+
+	// reflects the config currently held by the config manager
+	revision, err = client.GetRevision()
+	assert.NoError(t, err)
+	assert.Equal(t, "8", revision)
+
+	projectID, err := client.GetProjectID()
+	assert.NoError(t, err)
+	assert.Equal(t, "15389410617", projectID)
+	mockConfigManager.AssertExpectations(t)
+}
+
+func TestGetRevisionAndProjectIDInvalidConfig(t *testing.T) {
+	client := OptimizelyClient{
+		ConfigManager: InValidProjectConfigManager(),
+	}
+
+	revision, err := client.GetRevision()
+	assert.Error(t, err)
+	assert.Equal(t, "", revision)
+
+	projectID, err := client.GetProjectID()
+	assert.Error(t, err)
+	assert.Equal(t, "", projectID)
+}
+
+func TestGetOptimizelyConfig(t *testing.T) {
+	mockConfigManager := ValidProjectConfigManager()
+
+	client := OptimizelyClient{
+		ConfigManager: mockConfigManager,
+	}
+
+	optimizelyConfig := client.GetOptimizelyConfig()
+
+	assert.Equal(t, &config.OptimizelyConfig{Revision: "232"}, optimizelyConfig)
+}
+
+type MockConfigUpdateManager struct {
+	MockProjectConfigManager
+}
+
+func (p *MockConfigUpdateManager) OnProjectConfigUpdate(callback func(notification.ProjectConfigUpdateNotification)) (int, error) {
+	args := p.Called(callback)
+	return args.Int(0), args.Error(1)
+}
+
+func (p *MockConfigUpdateManager) RemoveOnProjectConfigUpdate(id int) error {
+	args := p.Called(id)
+	return args.Error(0)
+}
+
+func TestOnProjectConfigUpdate(t *testing.T) {
+	mockConfigManager := new(MockConfigUpdateManager)
+	mockConfigManager.On("OnProjectConfigUpdate", mock.Anything).Return(3, nil)
+	mockConfigManager.On("RemoveOnProjectConfigUpdate", 3).Return(nil)
+
+	client := OptimizelyClient{
+		ConfigManager: mockConfigManager,
+	}
+
+	id, err := client.OnProjectConfigUpdate(func(notification.ProjectConfigUpdateNotification) {})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
+
+	assert.NoError(t, client.RemoveOnProjectConfigUpdate(id))
+	mockConfigManager.AssertExpectations(t)
+}
+
+func TestOnProjectConfigUpdateWithError(t *testing.T) {
+	mockConfigManager := new(MockConfigUpdateManager)
+	mockConfigManager.On("OnProjectConfigUpdate", mock.Anything).Return(0, errors.New("no notification center"))
+	mockConfigManager.On("RemoveOnProjectConfigUpdate", 3).Return(errors.New("no notification center"))
+
+	client := OptimizelyClient{
+		ConfigManager: mockConfigManager,
+	}
+
+	id, err := client.OnProjectConfigUpdate(func(notification.ProjectConfigUpdateNotification) {})
+	assert.Error(t, err)
+	assert.Equal(t, 0, id)
+
+	assert.Error(t, client.RemoveOnProjectConfigUpdate(3))
+
+	client = OptimizelyClient{}
+	_, err = client.OnProjectConfigUpdate(func(notification.ProjectConfigUpdateNotification) {})
+	assert.Error(t, err)
+	assert.Error(t, client.RemoveOnProjectConfigUpdate(3))
+}
+
+func TestGetFeatureDecisionValid(t *testing.T) {
+	testFeatureKey := "test_feature_key"
+	testVariableKey := "test_feature_flag_key"
+	testVariableValue := "teststring"
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	testVariationVariable := entities.VariationVariable{
+		ID:    "1",
+		Value: testVariableValue,
+	}
+	testVariable := entities.Variable{
+		DefaultValue: "defaultString",
+		ID:           "1",
+		Key:          "test_feature_flag_key",
+		Type:         entities.String,
+	}
+	testVariation := getTestVariationWithFeatureVariable(false, testVariationVariable)
+	testExperiment := entities.Experiment{
+		ID:         "111111",
+		Variations: map[string]entities.Variation{"22222": testVariation},
+	}
+	testFeature := getTestFeature(testFeatureKey, testExperiment)
+	mockConfig := getMockConfig(testFeatureKey, testVariableKey, testFeature, testVariable)
+	mockConfigManager := new(MockProjectConfigManager)
+	mockConfigManager.On("GetConfig").Return(mockConfig, nil)
+
+	testDecisionContext := decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: mockConfig,
+		Variable:      testVariable,
+	}
+
+	expectedFeatureDecision := getTestFeatureDecision(testExperiment, testVariation)
+	mockDecisionService := new(MockDecisionService)
+	mockDecisionService.On("GetFeatureDecision", testDecisionContext, testUserContext).Return(expectedFeatureDecision, nil)
+
+	client := OptimizelyClient{
+		ConfigManager:   mockConfigManager,
+		DecisionService: mockDecisionService,
+	}
+
+	_, featureDecision, err := client.getFeatureDecision(context.Background(), testFeatureKey, testVariableKey, testUserContext)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedFeatureDecision, featureDecision)
+}
+
+func TestGetFeatureDecisionErrProjectConfig(t *testing.T) {
+	testFeatureKey := "test_feature_key"
+	testVariableKey := "test_feature_flag_key"
+	testVariableValue := "teststring"
+	testUserContext := entities.UserContext{ID: "test_user_1"}
 	testVariationVariable := entities.VariationVariable{
 		ID:    "1",
 		Value: testVariableValue,
@@ -1314,7 +1872,7 @@ func TestGetFeatureDecisionErrProjectConfig(t *testing.T) {
 		DecisionService: mockDecisionService,
 	}
 
-	_, _, err := client.getFeatureDecision(testFeatureKey, testVariableKey, testUserContext)
+	_, _, err := client.getFeatureDecision(context.Background(), testFeatureKey, testVariableKey, testUserContext)
 	assert.Error(t, err)
 }
 
@@ -1357,7 +1915,7 @@ func TestGetFeatureDecisionPanicProjectConfig(t *testing.T) {
 		DecisionService: mockDecisionService,
 	}
 
-	_, _, err := client.getFeatureDecision(testFeatureKey, testVariableKey, testUserContext)
+	_, _, err := client.getFeatureDecision(context.Background(), testFeatureKey, testVariableKey, testUserContext)
 	assert.Error(t, err)
 }
 
@@ -1391,7 +1949,7 @@ func TestGetFeatureDecisionPanicDecisionService(t *testing.T) {
 		DecisionService: &PanickingDecisionService{},
 	}
 
-	_, _, err := client.getFeatureDecision(testFeatureKey, testVariableKey, testUserContext)
+	_, _, err := client.getFeatureDecision(context.Background(), testFeatureKey, testVariableKey, testUserContext)
 	assert.Error(t, err)
 	assert.EqualError(t, err, "I'm panicking")
 }
@@ -1436,7 +1994,7 @@ func TestGetFeatureDecisionErrFeatureDecision(t *testing.T) {
 		DecisionService: mockDecisionService,
 	}
 
-	_, decision, err := client.getFeatureDecision(testFeatureKey, testVariableKey, testUserContext)
+	_, decision, err := client.getFeatureDecision(context.Background(), testFeatureKey, testVariableKey, testUserContext)
 	assert.Equal(t, expectedFeatureDecision, decision)
 	assert.NoError(t, err)
 }
@@ -1458,6 +2016,7 @@ func TestGetAllFeatureVariables(t *testing.T) {
 		{key: "var_bool", defaultVal: "false", varVal: "true", varType: entities.Boolean, expected: true},
 		{key: "var_int", defaultVal: "10", varVal: "20", varType: entities.Integer, expected: 20},
 		{key: "var_double", defaultVal: "1.0", varVal: "2.0", varType: entities.Double, expected: 2.0},
+		{key: "var_json", defaultVal: "{}", varVal: `{"key":"value"}`, varType: entities.VariableType("json"), expected: `{"key":"value"}`},
 	}
 
 	mockConfig := new(MockProjectConfig)
@@ -1588,10 +2147,171 @@ func TestGetAllFeatureVariablesWithoutFeature(t *testing.T) {
 
 	enabled, variationMap, err := client.GetAllFeatureVariables(invalidFeatureKey, testUserContext)
 
-	// if we have a decision, but also a non-fatal error, we should return the decision
 	assert.False(t, enabled)
 	assert.Equal(t, 0, len(variationMap))
+	assert.Equal(t, &ErrEntityNotFound{Kind: EntityKindFeature, Key: invalidFeatureKey}, err)
+}
+
+func TestGetFeatureVariableUnknownVariableKey(t *testing.T) {
+	testFeatureKey := "test_feature_key"
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+
+	mockConfig := new(MockProjectConfig)
+	mockConfig.On("GetFeatureByKey", testFeatureKey).Return(entities.Feature{Key: testFeatureKey}, nil)
+	mockConfig.On("GetVariableByKey", testFeatureKey, "unknown_variable").Return(entities.Variable{}, errors.New("variable not found"))
+	mockConfigManager := new(MockProjectConfigManager)
+	mockConfigManager.On("GetConfig").Return(mockConfig, nil)
+	mockDecisionService := new(MockDecisionService)
+
+	client := OptimizelyClient{
+		ConfigManager:   mockConfigManager,
+		DecisionService: mockDecisionService,
+	}
+
+	expectedErr := &ErrEntityNotFound{Kind: EntityKindVariable, Key: "unknown_variable"}
+	_, valueType, err := client.GetFeatureVariable(testFeatureKey, "unknown_variable", testUserContext)
+	assert.Equal(t, entities.VariableType(""), valueType)
+	assert.Equal(t, expectedErr, err)
+	_, err = client.GetFeatureVariableBoolean(testFeatureKey, "unknown_variable", testUserContext)
+	assert.Equal(t, expectedErr, err)
+	_, err = client.GetFeatureVariableDouble(testFeatureKey, "unknown_variable", testUserContext)
+	assert.Equal(t, expectedErr, err)
+	_, err = client.GetFeatureVariableInteger(testFeatureKey, "unknown_variable", testUserContext)
+	assert.Equal(t, expectedErr, err)
+	_, err = client.GetFeatureVariableString(testFeatureKey, "unknown_variable", testUserContext)
+	assert.Equal(t, expectedErr, err)
+	mockDecisionService.AssertNotCalled(t, "GetFeatureDecision", mock.Anything, mock.Anything)
+}
+
+func TestUnknownExperimentKey(t *testing.T) {
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	mockConfig := new(MockProjectConfig)
+	mockConfig.On("GetExperimentByKey", "unknown_exp").Return(entities.Experiment{}, errors.New("experiment not found"))
+	mockConfigManager := new(MockProjectConfigManager)
+	mockConfigManager.On("GetConfig").Return(mockConfig, nil)
+	mockDecisionService := new(MockDecisionService)
+
+	client := OptimizelyClient{
+		ConfigManager:      mockConfigManager,
+		DecisionService:    mockDecisionService,
+		notificationCenter: notification.NewNotificationCenter(),
+	}
+	var unknownKeys []string
+	_, err := client.OnUnknownKey(func(kind, key string, userContext entities.UserContext) {
+		assert.Equal(t, testUserContext, userContext)
+		unknownKeys = append(unknownKeys, kind+":"+key)
+	})
+	assert.NoError(t, err)
+
+	expectedErr := &ErrEntityNotFound{Kind: EntityKindExperiment, Key: "unknown_exp"}
+	variationKey, err := client.GetVariation("unknown_exp", testUserContext)
+	assert.Equal(t, "", variationKey)
+	assert.Equal(t, expectedErr, err)
+	assert.EqualError(t, err, `experiment with key "unknown_exp" not found`)
+
+	variation, err := client.ActivateVariationObject("unknown_exp", testUserContext)
+	assert.Nil(t, variation)
+	assert.Equal(t, expectedErr, err)
+
+	assert.Equal(t, []string{"experiment:unknown_exp", "experiment:unknown_exp"}, unknownKeys)
+	mockDecisionService.AssertNotCalled(t, "GetExperimentDecision", mock.Anything, mock.Anything)
+}
+
+func TestUnknownFeatureKey(t *testing.T) {
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	mockConfig := new(MockProjectConfig)
+	mockConfig.On("GetFeatureByKey", "unknown_feature").Return(entities.Feature{}, errors.New("feature not found"))
+	mockConfigManager := new(MockProjectConfigManager)
+	mockConfigManager.On("GetConfig").Return(mockConfig, nil)
+	mockDecisionService := new(MockDecisionService)
+
+	client := OptimizelyClient{
+		ConfigManager:      mockConfigManager,
+		DecisionService:    mockDecisionService,
+		notificationCenter: notification.NewNotificationCenter(),
+	}
+	var unknownKeys []string
+	id, err := client.OnUnknownKey(func(kind, key string, userContext entities.UserContext) {
+		unknownKeys = append(unknownKeys, kind+":"+key)
+	})
+	assert.NoError(t, err)
+
+	expectedErr := &ErrEntityNotFound{Kind: EntityKindFeature, Key: "unknown_feature"}
+	enabled, err := client.IsFeatureEnabled("unknown_feature", testUserContext)
+	assert.False(t, enabled)
+	assert.Equal(t, expectedErr, err)
+	assert.EqualError(t, err, `feature with key "unknown_feature" not found`)
+
+	value, err := client.GetFeatureVariableString("unknown_feature", "var", testUserContext)
+	assert.Equal(t, "", value)
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, []string{"feature:unknown_feature", "feature:unknown_feature"}, unknownKeys)
+
+	assert.NoError(t, client.RemoveOnUnknownKey(id))
+	_, err = client.IsFeatureEnabled("unknown_feature", testUserContext)
+	assert.Equal(t, expectedErr, err)
+	assert.Len(t, unknownKeys, 2)
+	mockDecisionService.AssertNotCalled(t, "GetFeatureDecision", mock.Anything, mock.Anything)
+}
+
+func TestWarmUserProfiles(t *testing.T) {
+	userProfileService := new(MockUserProfileService)
+	userProfileService.On("BatchLookup", []string{"test_user_1", "test_user_2"}).Return(map[string]decision.UserProfile{})
+	experimentService := decision.NewCompositeExperimentService(decision.WithUserProfileService(userProfileService))
+	client := OptimizelyClient{
+		DecisionService: decision.NewCompositeService("", decision.WithCompositeExperimentService(experimentService)),
+	}
+
+	client.WarmUserProfiles([]string{"test_user_1", "test_user_2"})
+	userProfileService.AssertExpectations(t)
+
+	// the other decision services are left alone
+	mockDecisionService := new(MockDecisionService)
+	(&OptimizelyClient{DecisionService: mockDecisionService}).WarmUserProfiles([]string{"test_user_1"})
+	mockDecisionService.AssertExpectations(t)
+}
+
+func TestOnDecisionOfTypes(t *testing.T) {
+	notificationCenter := notification.NewNotificationCenter()
+	client := OptimizelyClient{
+		DecisionService: decision.NewCompositeService("", decision.WithNotificationCenter(notificationCenter)),
+	}
+	var featureNotes []notification.DecisionNotification
+	id, err := client.OnDecisionOfTypes(func(note notification.DecisionNotification) {
+		featureNotes = append(featureNotes, note)
+	}, notification.Feature)
 	assert.NoError(t, err)
+
+	notificationCenter.Send(notification.Decision, notification.DecisionNotification{Type: notification.FeatureVariable})
+	notificationCenter.Send(notification.Decision, notification.DecisionNotification{Type: notification.Feature})
+	assert.Equal(t, []notification.DecisionNotification{{Type: notification.Feature}}, featureNotes)
+
+	assert.NoError(t, client.RemoveOnDecision(id))
+	notificationCenter.Send(notification.Decision, notification.DecisionNotification{Type: notification.Feature})
+	assert.Len(t, featureNotes, 1)
+}
+
+func TestOnDecisionOfTypesUnfilteredService(t *testing.T) {
+	var handler func(notification.DecisionNotification)
+	mockDecisionService := new(MockDecisionService)
+	mockDecisionService.On("OnDecision", mock.Anything).Run(func(args mock.Arguments) {
+		handler = args.Get(0).(func(notification.DecisionNotification))
+	}).Return(1, nil)
+	client := OptimizelyClient{DecisionService: mockDecisionService}
+
+	var featureNotes []notification.DecisionNotification
+	id, err := client.OnDecisionOfTypes(func(note notification.DecisionNotification) {
+		featureNotes = append(featureNotes, note)
+	}, notification.Feature)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	handler(notification.DecisionNotification{Type: notification.FeatureVariable})
+	handler(notification.DecisionNotification{Type: notification.Feature})
+	assert.Equal(t, []notification.DecisionNotification{{Type: notification.Feature}}, featureNotes)
+
+	_, err = (&OptimizelyClient{}).OnDecisionOfTypes(func(notification.DecisionNotification) {})
+	assert.Error(t, err)
 }
 
 // Helper Methods
@@ -1670,9 +2390,9 @@ func (s *ClientTestSuiteAB) TestActivate() {
 	s.NoError(err1)
 	s.Equal(expectedVariation.Key, variationKey1)
 
-	// should not return error for experiment not found.
+	// an unknown experiment is told apart from a user not bucketed
 	variationKey2, err2 := testClient.Activate("test_exp_2", testUserContext)
-	s.NoError(err2)
+	s.Equal(&ErrEntityNotFound{Kind: EntityKindExperiment, Key: "test_exp_2"}, err2)
 	s.Equal("", variationKey2)
 
 	s.mockConfig.AssertExpectations(s.T())
@@ -1680,6 +2400,118 @@ func (s *ClientTestSuiteAB) TestActivate() {
 	s.mockEventProcessor.AssertExpectations(s.T())
 }
 
+func (s *ClientTestSuiteAB) TestActivateOptedOut() {
+	testUserContext := entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{entities.OptOutAttributeName: true}}
+	testExperiment := makeTestExperiment("test_exp_1")
+	s.mockConfig.On("GetExperimentByKey", "test_exp_1").Return(testExperiment, nil)
+
+	testDecisionContext := decision.ExperimentDecisionContext{
+		Experiment:    &testExperiment,
+		ProjectConfig: s.mockConfig,
+	}
+
+	expectedVariation := testExperiment.Variations["v2"]
+	expectedExperimentDecision := decision.ExperimentDecision{
+		Variation: &expectedVariation,
+	}
+	s.mockDecisionService.On("GetExperimentDecision", testDecisionContext, testUserContext).Return(expectedExperimentDecision, nil)
+
+	testClient := OptimizelyClient{
+		ConfigManager:   s.mockConfigManager,
+		DecisionService: s.mockDecisionService,
+		EventProcessor:  s.mockEventProcessor,
+	}
+
+	variationKey, err := testClient.Activate("test_exp_1", testUserContext)
+	s.NoError(err)
+	s.Equal(expectedVariation.Key, variationKey)
+	s.mockDecisionService.AssertExpectations(s.T())
+	s.mockEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+}
+
+func (s *ClientTestSuiteAB) TestActivatePausedExperiment() {
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	testExperiment := makeTestExperiment("test_exp_1")
+	testExperiment.Status = entities.ExperimentPaused
+	s.mockConfig.On("GetExperimentByKey", "test_exp_1").Return(testExperiment, nil)
+
+	testClient := OptimizelyClient{
+		ConfigManager:   s.mockConfigManager,
+		DecisionService: decision.NewCompositeService("test_paused_experiment"),
+		EventProcessor:  s.mockEventProcessor,
+	}
+
+	variationKey, err := testClient.Activate("test_exp_1", testUserContext)
+	s.NoError(err)
+	s.Equal("", variationKey)
+	s.mockEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+}
+
+func (s *ClientTestSuiteAB) TestActivateWithContext() {
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("request"), "abc")
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	testExperiment := makeTestExperiment("test_exp_1")
+	s.mockConfig.On("GetExperimentByKey", "test_exp_1").Return(testExperiment, nil)
+
+	testDecisionContext := decision.ExperimentDecisionContext{
+		Experiment:    &testExperiment,
+		ProjectConfig: s.mockConfig,
+	}
+
+	expectedVariation := testExperiment.Variations["v2"]
+	expectedExperimentDecision := decision.ExperimentDecision{
+		Variation: &expectedVariation,
+	}
+	contextDecisionService := new(ContextDecisionService)
+	contextDecisionService.On("GetExperimentDecisionWithContext", ctx, testDecisionContext, testUserContext).Return(expectedExperimentDecision, nil)
+	contextEventProcessor := new(ContextEventProcessor)
+	contextEventProcessor.On("ProcessEventWithContext", ctx, mock.AnythingOfType("event.UserEvent")).Return(true)
+
+	testClient := OptimizelyClient{
+		ConfigManager:   s.mockConfigManager,
+		DecisionService: contextDecisionService,
+		EventProcessor:  contextEventProcessor,
+	}
+
+	variationKey, err := testClient.ActivateWithContext(ctx, "test_exp_1", testUserContext)
+	s.NoError(err)
+	s.Equal(expectedVariation.Key, variationKey)
+
+	contextDecisionService.AssertExpectations(s.T())
+	contextEventProcessor.AssertExpectations(s.T())
+	contextEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.Anything)
+}
+
+func (s *ClientTestSuiteAB) TestGetVariationWithDefaultAttributes() {
+	testUserContext := entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"country": "NZ"}}
+	mergedUserContext := entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"country": "NZ", "platform": "ios"}}
+	testExperiment := makeTestExperiment("test_exp_1")
+	s.mockConfig.On("GetExperimentByKey", "test_exp_1").Return(testExperiment, nil)
+
+	testDecisionContext := decision.ExperimentDecisionContext{
+		Experiment:    &testExperiment,
+		ProjectConfig: s.mockConfig,
+	}
+
+	expectedVariation := testExperiment.Variations["v2"]
+	expectedExperimentDecision := decision.ExperimentDecision{
+		Variation: &expectedVariation,
+	}
+	s.mockDecisionService.On("GetExperimentDecision", testDecisionContext, mergedUserContext).Return(expectedExperimentDecision, nil)
+
+	testClient := OptimizelyClient{
+		ConfigManager:     s.mockConfigManager,
+		DecisionService:   s.mockDecisionService,
+		defaultAttributes: map[string]interface{}{"country": "US", "platform": "ios"},
+	}
+
+	variationKey, err := testClient.GetVariation("test_exp_1", testUserContext)
+	s.NoError(err)
+	s.Equal(expectedVariation.Key, variationKey)
+	s.mockDecisionService.AssertExpectations(s.T())
+}
+
 func (s *ClientTestSuiteAB) TestActivatePanics() {
 	// ensure that we recover if the SDK panics while getting variation
 	testUserContext := entities.UserContext{}
@@ -1738,6 +2570,104 @@ func (s *ClientTestSuiteAB) TestGetVariation() {
 	s.mockEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
 }
 
+func (s *ClientTestSuiteAB) TestGetVariationObject() {
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	testExperiment := makeTestExperiment("test_exp_1")
+	testVariation := testExperiment.Variations["v2"]
+	testVariation.Variables = map[string]entities.VariationVariable{"var_1": {ID: "var_1", Value: "blue"}}
+	testExperiment.Variations["v2"] = testVariation
+	s.mockConfig.On("GetExperimentByKey", "test_exp_1").Return(testExperiment, nil)
+
+	testDecisionContext := decision.ExperimentDecisionContext{
+		Experiment:    &testExperiment,
+		ProjectConfig: s.mockConfig,
+	}
+	s.mockDecisionService.On("GetExperimentDecision", testDecisionContext, testUserContext).Return(decision.ExperimentDecision{Variation: &testVariation}, nil)
+	s.mockEventProcessor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent")).Return(true).Once()
+
+	testClient := OptimizelyClient{
+		ConfigManager:   s.mockConfigManager,
+		DecisionService: s.mockDecisionService,
+		EventProcessor:  s.mockEventProcessor,
+	}
+
+	variation, err := testClient.GetVariationObject("test_exp_1", testUserContext)
+	s.NoError(err)
+	s.Equal(&testVariation, variation)
+	s.mockEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+
+	// the variations returned are copies, which don't alter the project config
+	variation.Variables["var_1"] = entities.VariationVariable{ID: "var_1", Value: "red"}
+	s.Equal("blue", testVariation.Variables["var_1"].Value)
+
+	variation, err = testClient.ActivateVariationObject("test_exp_1", testUserContext)
+	s.NoError(err)
+	s.Equal(&testVariation, variation)
+	s.mockEventProcessor.AssertExpectations(s.T())
+}
+
+func (s *ClientTestSuiteAB) TestGetVariationObjectNotBucketed() {
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	testExperiment := makeTestExperiment("test_exp_1")
+	s.mockConfig.On("GetExperimentByKey", "test_exp_1").Return(testExperiment, nil)
+
+	testDecisionContext := decision.ExperimentDecisionContext{
+		Experiment:    &testExperiment,
+		ProjectConfig: s.mockConfig,
+	}
+	s.mockDecisionService.On("GetExperimentDecision", testDecisionContext, testUserContext).Return(decision.ExperimentDecision{}, nil)
+
+	testClient := OptimizelyClient{
+		ConfigManager:   s.mockConfigManager,
+		DecisionService: s.mockDecisionService,
+		EventProcessor:  s.mockEventProcessor,
+	}
+
+	variation, err := testClient.GetVariationObject("test_exp_1", testUserContext)
+	s.NoError(err)
+	s.Nil(variation)
+	variation, err = testClient.ActivateVariationObject("test_exp_1", testUserContext)
+	s.NoError(err)
+	s.Nil(variation)
+	s.mockEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+}
+
+func (s *ClientTestSuiteAB) TestGetVariations() {
+	testExperiment := makeTestExperiment("test_exp_1")
+	expectedVariations := []entities.Variation{testExperiment.Variations["v1"], testExperiment.Variations["v2"]}
+	s.mockConfig.On("GetExperimentByKey", "test_exp_1").Return(testExperiment, nil)
+	s.mockConfig.On("GetVariations", "test_exp_1").Return(expectedVariations, nil)
+	s.mockConfig.On("GetVariations", "test_exp_2").Return([]entities.Variation(nil), errors.New("experiment not found"))
+
+	testClient := OptimizelyClient{
+		ConfigManager:   s.mockConfigManager,
+		DecisionService: s.mockDecisionService,
+	}
+
+	variations, err := testClient.GetVariations("test_exp_1")
+	s.NoError(err)
+	s.Equal(expectedVariations, variations)
+
+	variations, err = testClient.GetVariations("test_exp_2")
+	s.Nil(variations)
+	s.Equal(&ErrEntityNotFound{Kind: EntityKindExperiment, Key: "test_exp_2"}, err)
+	s.mockConfig.AssertNotCalled(s.T(), "GetExperimentByKey", mock.Anything)
+}
+
+func (s *ClientTestSuiteAB) TestGetVariationsUnsupportedConfig() {
+	// the embedded interface hides the GetVariations of the mock
+	projectConfig := struct{ config.ProjectConfig }{s.mockConfig}
+	testClient := OptimizelyClient{
+		ConfigManager:   &MockProjectConfigManager{projectConfig: projectConfig},
+		DecisionService: s.mockDecisionService,
+	}
+
+	variations, err := testClient.GetVariations("test_exp_1")
+	s.Nil(variations)
+	s.Error(err)
+	s.mockConfig.AssertNotCalled(s.T(), "GetVariations", mock.Anything)
+}
+
 func (s *ClientTestSuiteAB) TestGetVariationWithDecisionError() {
 	testUserContext := entities.UserContext{ID: "test_user_1"}
 	testExperiment := makeTestExperiment("test_exp_1")
@@ -1831,6 +2761,52 @@ func (s *ClientTestSuiteFM) TestIsFeatureEnabled() {
 	s.mockDecisionService.AssertExpectations(s.T())
 }
 
+func (s *ClientTestSuiteFM) TestIsFeatureEnabledWithContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+
+	testVariation := makeTestVariation("green", true)
+	testExperiment := makeTestExperimentWithVariations("number_1", []entities.Variation{testVariation})
+	testFeature := makeTestFeatureWithExperiment("feature_1", testExperiment)
+	s.mockConfig.On("GetFeatureByKey", testFeature.Key).Return(testFeature, nil)
+	s.mockConfigManager.On("GetConfig").Return(s.mockConfig, nil)
+
+	testDecisionContext := decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}
+
+	expectedFeatureDecision := decision.FeatureDecision{
+		Experiment: testExperiment,
+		Variation:  &testVariation,
+		Source:     decision.FeatureTest,
+	}
+
+	contextDecisionService := new(ContextDecisionService)
+	contextDecisionService.On("GetFeatureDecisionWithContext", ctx, testDecisionContext, testUserContext).Return(expectedFeatureDecision, nil)
+	contextEventProcessor := new(ContextEventProcessor)
+	contextEventProcessor.On("ProcessEventWithContext", ctx, mock.AnythingOfType("event.UserEvent")).Return(true)
+
+	client := OptimizelyClient{
+		ConfigManager:   s.mockConfigManager,
+		DecisionService: contextDecisionService,
+		EventProcessor:  contextEventProcessor,
+	}
+	result, err := client.IsFeatureEnabledWithContext(ctx, testFeature.Key, testUserContext)
+	s.NoError(err)
+	s.True(result)
+	contextDecisionService.AssertExpectations(s.T())
+	contextEventProcessor.AssertExpectations(s.T())
+
+	// no decision is made once the context is done, so that no decision goes without its impression event
+	cancel()
+	result, err = client.IsFeatureEnabledWithContext(ctx, testFeature.Key, testUserContext)
+	s.Equal(context.Canceled, err)
+	s.False(result)
+	contextDecisionService.AssertNumberOfCalls(s.T(), "GetFeatureDecisionWithContext", 1)
+	contextEventProcessor.AssertNumberOfCalls(s.T(), "ProcessEventWithContext", 1)
+}
+
 func (s *ClientTestSuiteFM) TestIsFeatureEnabledWithDecisionError() {
 	testUserContext := entities.UserContext{ID: "test_user_1"}
 
@@ -1896,7 +2872,7 @@ func (s *ClientTestSuiteFM) TestIsFeatureEnabledErrorCases() {
 		DecisionService: s.mockDecisionService,
 	}
 	result, err := client.IsFeatureEnabled(testFeatureKey, testUserContext)
-	s.NoError(err)
+	s.Equal(&ErrEntityNotFound{Kind: EntityKindFeature, Key: testFeatureKey}, err)
 	s.False(result)
 	s.mockConfigManager.AssertExpectations(s.T())
 	s.mockDecisionService.AssertNotCalled(s.T(), "GetDecision")
@@ -1964,6 +2940,81 @@ func (s *ClientTestSuiteFM) TestGetEnabledFeatures() {
 	s.mockDecisionService.AssertExpectations(s.T())
 }
 
+// rolledOutFeaturesDatafile has several features rolled out to everyone
+var rolledOutFeaturesDatafile = []byte(`{"version":"4","revision":"1","rollouts":[{"id":"r1","experiments":[{"id":"e1","key":"rule",` +
+	`"status":"Running","layerId":"r1","audienceIds":[],"variations":[{"id":"v1","key":"on","featureEnabled":true}],` +
+	`"trafficAllocation":[{"entityId":"v1","endOfRange":10000}],"forcedVariations":{}}]}],"featureFlags":[` +
+	`{"id":"1","key":"feature_c","rolloutId":"r1","experimentIds":[],"variables":[]},` +
+	`{"id":"2","key":"feature_a","rolloutId":"r1","experimentIds":[],"variables":[]},` +
+	`{"id":"3","key":"feature_d","rolloutId":"r1","experimentIds":[],"variables":[]},` +
+	`{"id":"4","key":"feature_b","rolloutId":"r1","experimentIds":[],"variables":[]}]}`)
+
+func TestGetEnabledFeaturesSorted(t *testing.T) {
+	client, err := (&OptimizelyFactory{Datafile: rolledOutFeaturesDatafile}).Client()
+	assert.NoError(t, err)
+
+	// the features are held in a map, the order must not depend on its iteration order
+	for i := 0; i < 10; i++ {
+		enabledFeatures, err := client.GetEnabledFeatures(entities.UserContext{ID: "test_user"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"feature_a", "feature_b", "feature_c", "feature_d"}, enabledFeatures)
+	}
+	client.Close()
+}
+
+func TestConcurrentDecisions(t *testing.T) {
+	client, err := (&OptimizelyFactory{Datafile: rolledOutFeaturesDatafile}).Client()
+	assert.NoError(t, err)
+
+	var decisions int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// the listeners come and go while the other goroutines decide
+			id, err := client.DecisionService.OnDecision(func(notification.DecisionNotification) {
+				atomic.AddInt32(&decisions, 1)
+			})
+			assert.NoError(t, err)
+			for j := 0; j < 100; j++ {
+				enabled, err := client.IsFeatureEnabled("feature_a", entities.UserContext{ID: fmt.Sprintf("user_%d_%d", i, j)})
+				assert.NoError(t, err)
+				assert.True(t, enabled)
+				client.GetOptimizelyConfig()
+			}
+			assert.NoError(t, client.DecisionService.RemoveOnDecision(id))
+		}(i)
+	}
+	wg.Wait()
+	assert.True(t, atomic.LoadInt32(&decisions) >= 800)
+	client.Close()
+}
+
+// BenchmarkIsFeatureEnabledParallel decides one feature from many goroutines sharing the client and its project config,
+// run it with the race detector to check the decision path: go test -race -run ^$ -bench IsFeatureEnabledParallel
+func BenchmarkIsFeatureEnabledParallel(b *testing.B) {
+	client, err := (&OptimizelyFactory{Datafile: rolledOutFeaturesDatafile}).Client()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer client.Close()
+	client.DecisionService.OnDecision(func(notification.DecisionNotification) {})
+
+	var goroutines int32
+	b.SetParallelism(8)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		userPrefix := fmt.Sprintf("user_%d_", atomic.AddInt32(&goroutines, 1))
+		for i := 0; pb.Next(); i++ {
+			if _, err := client.IsFeatureEnabled("feature_a", entities.UserContext{ID: userPrefix + strconv.Itoa(i%1000)}); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
 func (s *ClientTestSuiteFM) TestGetEnabledFeaturesErrorCases() {
 	testUserContext := entities.UserContext{ID: "test_user_1"}
 
@@ -2008,6 +3059,94 @@ func TestClose(t *testing.T) {
 	wg.Wait()
 }
 
+type blockingDispatcher struct {
+	release chan struct{}
+}
+
+func (b *blockingDispatcher) DispatchEvent(event event.LogEvent) (bool, error) {
+	<-b.release
+	return true, nil
+}
+
+func TestCloseWithTimeout(t *testing.T) {
+	dispatcher := &blockingDispatcher{release: make(chan struct{})}
+	defer close(dispatcher.release)
+	processor := event.NewBatchEventProcessor(event.WithEventDispatcher(dispatcher))
+	eg := utils.NewExecGroup(context.Background())
+	eg.Go(processor.Start)
+
+	client := OptimizelyClient{
+		ConfigManager:   ValidProjectConfigManager(),
+		DecisionService: new(MockDecisionService),
+		EventProcessor:  processor,
+		execGroup:       eg,
+	}
+
+	userContext := entities.UserContext{ID: "test_user"}
+	for _, key := range []string{"sample_conversion", "other_conversion"} {
+		processor.ProcessEvent(event.CreateConversionUserEvent(TestConfig{}, entities.Event{ID: key, Key: key}, userContext, nil))
+	}
+
+	start := time.Now()
+	assert.Equal(t, 2, client.CloseWithTimeout(50*time.Millisecond))
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestCloseWithTimeoutCompletes(t *testing.T) {
+	eg := utils.NewExecGroup(context.Background())
+	client := OptimizelyClient{
+		ConfigManager:   ValidProjectConfigManager(),
+		DecisionService: new(MockDecisionService),
+		EventProcessor:  &MockProcessor{},
+		execGroup:       eg,
+	}
+	assert.Equal(t, 0, client.CloseWithTimeout(time.Second))
+}
+
+func TestCloseOnSignal(t *testing.T) {
+	// the test process keeps receiving the signal, so that it is not killed by a signal sent before the helper listens
+	testSignals := make(chan os.Signal, 1)
+	signal.Notify(testSignals, os.Interrupt)
+	defer signal.Stop(testSignals)
+
+	client := &OptimizelyClient{
+		ConfigManager:   ValidProjectConfigManager(),
+		DecisionService: new(MockDecisionService),
+		EventProcessor:  &MockProcessor{},
+		execGroup:       utils.NewExecGroup(context.Background()),
+	}
+	done := make(chan int)
+	go func() {
+		done <- CloseOnSignal(context.Background(), client, time.Second, os.Interrupt)
+	}()
+
+	process, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case undispatched := <-done:
+			assert.Equal(t, 0, undispatched)
+			return
+		case <-ticker.C:
+			assert.NoError(t, process.Signal(os.Interrupt))
+		}
+	}
+}
+
+func TestCloseOnSignalContextDone(t *testing.T) {
+	client := &OptimizelyClient{
+		ConfigManager:   ValidProjectConfigManager(),
+		DecisionService: new(MockDecisionService),
+		EventProcessor:  &MockProcessor{},
+		execGroup:       utils.NewExecGroup(context.Background()),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, 0, CloseOnSignal(ctx, client, time.Second))
+}
+
 type ClientTestSuiteTrackEvent struct {
 	suite.Suite
 	mockProcessor       *MockProcessor
@@ -2039,6 +3178,8 @@ func (s *ClientTestSuiteTrackEvent) TestTrackWithNotification() {
 		s.Equal("sample_conversion", eventKey)
 		s.Equal(expectedUserContext, userContext)
 		s.Equal(*s.mockProcessor.Events[0].Conversion, conversionEvent)
+		s.Equal("15389410617", conversionEvent.EventContext.ProjectID)
+		s.Equal(s.mockProcessor.Events[0].EventContext.Revision, conversionEvent.EventContext.Revision)
 	}
 
 	id, err := s.client.OnTrack(onTrack)
@@ -0,0 +1,398 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package client has client definitions
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+
+	"github.com/optimizely/go-sdk/pkg/config"
+	"github.com/optimizely/go-sdk/pkg/decision"
+	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/event"
+)
+
+// DecideOption alters how a call to Decide is evaluated
+type DecideOption string
+
+const (
+	// DisableDecisionEvent prevents an impression event from being sent for the decision
+	DisableDecisionEvent DecideOption = "DISABLE_DECISION_EVENT"
+	// IncludeReasons includes the reasons the decision was made in the DecisionResult
+	IncludeReasons DecideOption = "INCLUDE_REASONS"
+	// FlushDecisionEvents flushes the event processor once the impression events of DecideForKeys or DecideAll are
+	// queued up, instead of waiting for the next batch or flush interval
+	FlushDecisionEvents DecideOption = "FLUSH_DECISION_EVENTS"
+)
+
+// decideOptions holds the DecideOption flags resolved for a single Decide call
+type decideOptions struct {
+	disableDecisionEvent bool
+	includeReasons       bool
+	flushDecisionEvents  bool
+}
+
+func newDecideOptions(defaultOptions, options []DecideOption) decideOptions {
+	resolved := decideOptions{}
+	for _, option := range append(append([]DecideOption{}, defaultOptions...), options...) {
+		switch option {
+		case DisableDecisionEvent:
+			resolved.disableDecisionEvent = true
+		case IncludeReasons:
+			resolved.includeReasons = true
+		case FlushDecisionEvents:
+			resolved.flushDecisionEvents = true
+		default:
+			logger.Warning(fmt.Sprintf(`Unknown decide option "%s".`, option))
+		}
+	}
+	return resolved
+}
+
+// DecisionResult holds everything about a decision made by Decide
+type DecisionResult struct {
+	// Key is the feature or experiment key the decision was made for
+	Key          string
+	VariationKey string
+	Enabled      bool
+	Variables    map[string]interface{}
+	// RuleKey is the key of the feature test, rollout rule or experiment the user was bucketed through
+	RuleKey     string
+	UserContext entities.UserContext
+	Reasons     []string
+}
+
+// SortedDecisionResults returns the decision results of DecideAll or DecideForKeys sorted by key, so that they can be
+// compared across calls
+func SortedDecisionResults(results map[string]DecisionResult) []DecisionResult {
+	sortedResults := make([]DecisionResult, 0, len(results))
+	for _, result := range results {
+		sortedResults = append(sortedResults, result)
+	}
+	sort.Slice(sortedResults, func(i, j int) bool {
+		return sortedResults[i].Key < sortedResults[j].Key
+	})
+	return sortedResults
+}
+
+// DecisionSnapshot is a compact envelope of decisions made on a server, to be embedded in a rendered page so that the
+// browser reads the decisions instead of making them again. The revision of the project config the decisions were made
+// with tells whether they are stale.
+type DecisionSnapshot struct {
+	Revision  string                      `json:"revision"`
+	Decisions map[string]SnapshotDecision `json:"decisions"`
+}
+
+// SnapshotDecision is the decision for a flag in a DecisionSnapshot
+type SnapshotDecision struct {
+	Enabled      bool                   `json:"enabled"`
+	VariationKey string                 `json:"variationKey,omitempty"`
+	Variables    map[string]interface{} `json:"variables,omitempty"`
+}
+
+// NewDecisionSnapshot returns the snapshot of the decision results of DecideAll or DecideForKeys, keyed by flag key
+func NewDecisionSnapshot(revision string, results map[string]DecisionResult) DecisionSnapshot {
+	snapshot := DecisionSnapshot{Revision: revision, Decisions: make(map[string]SnapshotDecision, len(results))}
+	for key, result := range results {
+		snapshotDecision := SnapshotDecision{Enabled: result.Enabled, VariationKey: result.VariationKey}
+		if len(result.Variables) > 0 {
+			snapshotDecision.Variables = result.Variables
+		}
+		snapshot.Decisions[key] = snapshotDecision
+	}
+	return snapshot
+}
+
+// SerializeDecisions returns the JSON of the snapshot of the decision results. The flags and variables are sorted by
+// key so that the same decisions always serialize the same, and <, > and & are escaped so that the JSON can be embedded
+// in a script element of an HTML page.
+func SerializeDecisions(revision string, results map[string]DecisionResult) ([]byte, error) {
+	return json.Marshal(NewDecisionSnapshot(revision, results))
+}
+
+// Decide returns the decision for the given feature or experiment key. As with IsFeatureEnabled and Activate, an
+// impression event is queued up when the user is bucketed into a feature test or an experiment, and decision
+// notifications are sent. The given options are combined with the default decide options of the client. An
+// ErrEntityNotFound is returned for a key which is neither a feature nor an experiment.
+func (o *OptimizelyClient) Decide(key string, userContext entities.UserContext, options ...DecideOption) (result DecisionResult, err error) {
+	return o.DecideWithContext(context.Background(), key, userContext, options...)
+}
+
+// DecideWithContext is like Decide, passing the given context to the decision service and the event processor.
+func (o *OptimizelyClient) DecideWithContext(ctx context.Context, key string, userContext entities.UserContext, options ...DecideOption) (result DecisionResult, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch t := r.(type) {
+			case error:
+				err = t
+			case string:
+				err = errors.New(t)
+			default:
+				err = errors.New("unexpected error")
+			}
+			errorMessage := fmt.Sprintf("Decide call, optimizely SDK is panicking with the error:")
+			logger.Error(errorMessage, err)
+			logger.Debug(string(debug.Stack()))
+		}
+	}()
+
+	userContext = o.withDefaultAttributes(userContext)
+
+	projectConfig, err := o.getProjectConfig()
+	if err != nil {
+		logger.Error("Error retrieving ProjectConfig", err)
+		return newDecisionResult(key, userContext), err
+	}
+
+	result, impressionEvent, err := o.decide(ctx, projectConfig, key, userContext, newDecideOptions(o.defaultDecideOptions, options))
+	if impressionEvent != nil {
+		o.processEvent(ctx, *impressionEvent)
+	}
+	return result, err
+}
+
+// DecideForKeys returns the decisions for the given feature or experiment keys, each key being evaluated once. The
+// impression events for the decisions are queued up together once all the keys have been evaluated, so that they
+// are batched together. They are flushed right away with the FlushDecisionEvents option.
+func (o *OptimizelyClient) DecideForKeys(keys []string, userContext entities.UserContext, options ...DecideOption) (results map[string]DecisionResult, err error) {
+	return o.DecideForKeysWithContext(context.Background(), keys, userContext, options...)
+}
+
+// DecideForKeysWithContext is like DecideForKeys, passing the given context to the decision service and the event
+// processor.
+func (o *OptimizelyClient) DecideForKeysWithContext(ctx context.Context, keys []string, userContext entities.UserContext, options ...DecideOption) (results map[string]DecisionResult, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch t := r.(type) {
+			case error:
+				err = t
+			case string:
+				err = errors.New(t)
+			default:
+				err = errors.New("unexpected error")
+			}
+			errorMessage := fmt.Sprintf("DecideForKeys call, optimizely SDK is panicking with the error:")
+			logger.Error(errorMessage, err)
+			logger.Debug(string(debug.Stack()))
+		}
+	}()
+
+	userContext = o.withDefaultAttributes(userContext)
+
+	results = map[string]DecisionResult{}
+	projectConfig, err := o.getProjectConfig()
+	if err != nil {
+		logger.Error("Error retrieving ProjectConfig", err)
+		return results, err
+	}
+
+	return o.decideForKeys(ctx, projectConfig, keys, userContext, newDecideOptions(o.defaultDecideOptions, options)), nil
+}
+
+// DecideAll returns the decisions for all the features in the project. The features are evaluated in the order of their
+// keys, impression events are batched as for DecideForKeys.
+func (o *OptimizelyClient) DecideAll(userContext entities.UserContext, options ...DecideOption) (results map[string]DecisionResult, err error) {
+	return o.DecideAllWithContext(context.Background(), userContext, options...)
+}
+
+// DecideAllWithContext is like DecideAll, passing the given context to the decision service and the event processor.
+func (o *OptimizelyClient) DecideAllWithContext(ctx context.Context, userContext entities.UserContext, options ...DecideOption) (results map[string]DecisionResult, err error) {
+	_, results, err = o.decideAll(ctx, userContext, options)
+	return results, err
+}
+
+// DecideAllSnapshot returns the decisions for all the features in the project as a DecisionSnapshot, along with the
+// revision of the project config they were made with, see DecideAll
+func (o *OptimizelyClient) DecideAllSnapshot(userContext entities.UserContext, options ...DecideOption) (DecisionSnapshot, error) {
+	projectConfig, results, err := o.decideAll(context.Background(), userContext, options)
+	if err != nil {
+		return DecisionSnapshot{}, err
+	}
+	return NewDecisionSnapshot(projectConfig.GetRevision(), results), nil
+}
+
+// decideAll returns the decisions for all the features in the project along with the project config they were made with
+func (o *OptimizelyClient) decideAll(ctx context.Context, userContext entities.UserContext, options []DecideOption) (projectConfig config.ProjectConfig, results map[string]DecisionResult, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch t := r.(type) {
+			case error:
+				err = t
+			case string:
+				err = errors.New(t)
+			default:
+				err = errors.New("unexpected error")
+			}
+			errorMessage := fmt.Sprintf("DecideAll call, optimizely SDK is panicking with the error:")
+			logger.Error(errorMessage, err)
+			logger.Debug(string(debug.Stack()))
+		}
+	}()
+
+	userContext = o.withDefaultAttributes(userContext)
+
+	results = map[string]DecisionResult{}
+	projectConfig, err = o.getProjectConfig()
+	if err != nil {
+		logger.Error("Error retrieving ProjectConfig", err)
+		return nil, results, err
+	}
+
+	var keys []string
+	for _, feature := range projectConfig.GetFeatureList() {
+		keys = append(keys, feature.Key)
+	}
+	sort.Strings(keys)
+
+	return projectConfig, o.decideForKeys(ctx, projectConfig, keys, userContext, newDecideOptions(o.defaultDecideOptions, options)), nil
+}
+
+func (o *OptimizelyClient) decideForKeys(ctx context.Context, projectConfig config.ProjectConfig, keys []string, userContext entities.UserContext, options decideOptions) map[string]DecisionResult {
+	results := map[string]DecisionResult{}
+	var impressionEvents []event.UserEvent
+
+	for _, key := range keys {
+		if _, ok := results[key]; ok {
+			continue
+		}
+
+		result, impressionEvent, err := o.decide(ctx, projectConfig, key, userContext, options)
+		if err != nil {
+			logger.Warning(fmt.Sprintf(`Received error while making a decision for key "%s": %s`, key, err))
+		}
+		if impressionEvent != nil {
+			impressionEvents = append(impressionEvents, *impressionEvent)
+		}
+		results[key] = result
+	}
+
+	for _, impressionEvent := range impressionEvents {
+		o.processEvent(ctx, impressionEvent)
+	}
+	if options.flushDecisionEvents && len(impressionEvents) > 0 {
+		if flushableProcessor, ok := o.EventProcessor.(event.FlushableProcessor); ok {
+			flushableProcessor.Flush()
+		}
+	}
+
+	return results
+}
+
+func newDecisionResult(key string, userContext entities.UserContext) DecisionResult {
+	return DecisionResult{
+		Key:         key,
+		Variables:   map[string]interface{}{},
+		UserContext: userContext,
+	}
+}
+
+// decide returns the decision for the given key along with the impression event to send for it, if any
+func (o *OptimizelyClient) decide(ctx context.Context, projectConfig config.ProjectConfig, key string, userContext entities.UserContext, options decideOptions) (result DecisionResult, impressionEvent *event.UserEvent, err error) {
+	result = newDecisionResult(key, userContext)
+
+	// the bucketing is only explained when the reasons are included, it is not computed otherwise
+	if options.includeReasons {
+		ctx = decision.WithBucketingTrace(ctx)
+	}
+
+	if _, e := projectConfig.GetFeatureByKey(key); e == nil {
+		result, impressionEvent, err = o.decideFeature(ctx, projectConfig, result)
+	} else if _, e := projectConfig.GetExperimentByKey(key); e == nil {
+		result, impressionEvent, err = o.decideExperiment(ctx, projectConfig, result)
+	} else {
+		reason := fmt.Sprintf(`No feature or experiment found for key "%s".`, key)
+		logger.Warning(reason)
+		result.Reasons = append(result.Reasons, reason)
+		err = o.entityNotFound(EntityKindFeature, key, userContext)
+	}
+
+	if !options.includeReasons {
+		result.Reasons = nil
+	}
+	if options.disableDecisionEvent {
+		impressionEvent = nil
+	}
+	return result, impressionEvent, err
+}
+
+func (o *OptimizelyClient) decideFeature(ctx context.Context, projectConfig config.ProjectConfig, result DecisionResult) (DecisionResult, *event.UserEvent, error) {
+	decisionContext, featureDecision, err := o.getFeatureDecisionWithConfig(ctx, projectConfig, result.Key, "", result.UserContext)
+	if err != nil {
+		logger.Error("received an error while computing feature decision", err)
+		return result, nil, err
+	}
+
+	if featureDecision.Reason != "" {
+		result.Reasons = append(result.Reasons, string(featureDecision.Reason))
+	}
+	result.Reasons = append(result.Reasons, featureDecision.BucketingTrace...)
+
+	if featureDecision.Variation != nil {
+		result.VariationKey = featureDecision.Variation.Key
+		result.Enabled = featureDecision.Variation.FeatureEnabled
+		result.RuleKey = featureDecision.Experiment.Key
+	}
+
+	variableMap, err := getFeatureVariableMap(*decisionContext.Feature, featureDecision)
+	if err != nil {
+		result.Reasons = append(result.Reasons, err.Error())
+	}
+	result.Variables = variableMap
+
+	if featureDecision.Source == decision.FeatureTest && featureDecision.Variation != nil && !isOptedOut(result.UserContext) {
+		// impression events are only sent for feature tests
+		impressionEvent := o.createImpressionUserEvent(decisionContext.ProjectConfig, featureDecision.Experiment, *featureDecision.Variation, result.UserContext)
+		return result, &impressionEvent, nil
+	}
+
+	return result, nil, nil
+}
+
+func (o *OptimizelyClient) decideExperiment(ctx context.Context, projectConfig config.ProjectConfig, result DecisionResult) (DecisionResult, *event.UserEvent, error) {
+	decisionContext, experimentDecision, err := o.getExperimentDecisionWithConfig(ctx, projectConfig, result.Key, result.UserContext)
+	if err != nil {
+		logger.Error("received an error while computing experiment decision", err)
+		return result, nil, err
+	}
+
+	if experimentDecision.Reason != "" {
+		result.Reasons = append(result.Reasons, string(experimentDecision.Reason))
+	}
+	result.Reasons = append(result.Reasons, experimentDecision.BucketingTrace...)
+
+	if experimentDecision.Variation == nil || decisionContext.Experiment == nil {
+		return result, nil, nil
+	}
+
+	result.VariationKey = experimentDecision.Variation.Key
+	result.Enabled = true
+	result.RuleKey = decisionContext.Experiment.Key
+
+	if isOptedOut(result.UserContext) {
+		return result, nil, nil
+	}
+	impressionEvent := o.createImpressionUserEvent(decisionContext.ProjectConfig, *decisionContext.Experiment, *experimentDecision.Variation, result.UserContext)
+	return result, &impressionEvent, nil
+}
@@ -0,0 +1,580 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/optimizely/go-sdk/pkg/config/datafileprojectconfig"
+	"github.com/optimizely/go-sdk/pkg/decision"
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
+	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/notification"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientTestSuiteDecide struct {
+	suite.Suite
+	mockConfig          *MockProjectConfig
+	mockConfigManager   *MockProjectConfigManager
+	mockDecisionService *MockDecisionService
+	mockEventProcessor  *MockEventProcessor
+	testUserContext     entities.UserContext
+	testClient          OptimizelyClient
+}
+
+func (s *ClientTestSuiteDecide) SetupTest() {
+	s.mockConfig = new(MockProjectConfig)
+	s.mockConfigManager = new(MockProjectConfigManager)
+	s.mockConfigManager.On("GetConfig").Return(s.mockConfig, nil)
+	s.mockDecisionService = new(MockDecisionService)
+	s.mockEventProcessor = new(MockEventProcessor)
+	s.testUserContext = entities.UserContext{ID: "test_user_1"}
+	s.testClient = OptimizelyClient{
+		ConfigManager:   s.mockConfigManager,
+		DecisionService: s.mockDecisionService,
+		EventProcessor:  s.mockEventProcessor,
+	}
+}
+
+func (s *ClientTestSuiteDecide) makeTestFeature(source decision.Source, featureEnabled bool) (entities.Feature, decision.FeatureDecision) {
+	testVariable := entities.Variable{DefaultValue: "1", ID: "1", Key: "int_variable", Type: entities.Integer}
+	testVariation := makeTestVariation("v1", featureEnabled)
+	testVariation.Variables = map[string]entities.VariationVariable{"1": {ID: "1", Value: "42"}}
+	testExperiment := makeTestExperimentWithVariations("test_rule", []entities.Variation{testVariation})
+	testFeature := makeTestFeatureWithExperiment("test_feature", testExperiment)
+	testFeature.VariableMap = map[string]entities.Variable{"int_variable": testVariable}
+
+	featureDecision := decision.FeatureDecision{
+		Decision:   decision.Decision{Reason: reasons.BucketedIntoFeatureTest},
+		Source:     source,
+		Experiment: testExperiment,
+		Variation:  &testVariation,
+	}
+	return testFeature, featureDecision
+}
+
+func (s *ClientTestSuiteDecide) TestDecideFeatureTest() {
+	testFeature, featureDecision := s.makeTestFeature(decision.FeatureTest, true)
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	testDecisionContext := decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}
+	s.mockDecisionService.On("GetFeatureDecision", testDecisionContext, s.testUserContext).Return(featureDecision, nil)
+	s.mockEventProcessor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+
+	result, err := s.testClient.Decide("test_feature", s.testUserContext, IncludeReasons)
+	s.NoError(err)
+	s.Equal(DecisionResult{
+		Key:          "test_feature",
+		VariationKey: "v1",
+		Enabled:      true,
+		Variables:    map[string]interface{}{"int_variable": 42},
+		RuleKey:      "test_rule",
+		UserContext:  s.testUserContext,
+		Reasons:      []string{string(reasons.BucketedIntoFeatureTest)},
+	}, result)
+	s.mockDecisionService.AssertExpectations(s.T())
+	s.mockEventProcessor.AssertExpectations(s.T())
+}
+
+func (s *ClientTestSuiteDecide) TestDecideWithContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	testFeature, featureDecision := s.makeTestFeature(decision.FeatureTest, true)
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	testDecisionContext := decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}
+	contextDecisionService := new(ContextDecisionService)
+	contextDecisionService.On("GetFeatureDecisionWithContext", ctx, testDecisionContext, s.testUserContext).Return(featureDecision, nil)
+	contextEventProcessor := new(ContextEventProcessor)
+	contextEventProcessor.On("ProcessEventWithContext", ctx, mock.AnythingOfType("event.UserEvent")).Return(true)
+	s.testClient.DecisionService = contextDecisionService
+	s.testClient.EventProcessor = contextEventProcessor
+
+	result, err := s.testClient.DecideWithContext(ctx, "test_feature", s.testUserContext)
+	s.NoError(err)
+	s.True(result.Enabled)
+	results, err := s.testClient.DecideForKeysWithContext(ctx, []string{"test_feature"}, s.testUserContext)
+	s.NoError(err)
+	s.True(results["test_feature"].Enabled)
+	contextDecisionService.AssertNumberOfCalls(s.T(), "GetFeatureDecisionWithContext", 2)
+	contextEventProcessor.AssertNumberOfCalls(s.T(), "ProcessEventWithContext", 2)
+
+	// no decision is made once the context is done, so that no decision goes without its impression event
+	cancel()
+	_, err = s.testClient.DecideWithContext(ctx, "test_feature", s.testUserContext)
+	s.Equal(context.Canceled, err)
+	contextDecisionService.AssertNumberOfCalls(s.T(), "GetFeatureDecisionWithContext", 2)
+	contextEventProcessor.AssertNumberOfCalls(s.T(), "ProcessEventWithContext", 2)
+}
+
+func (s *ClientTestSuiteDecide) TestDecideFeatureRollout() {
+	testFeature, featureDecision := s.makeTestFeature(decision.Rollout, false)
+	featureDecision.Reason = reasons.BucketedIntoRollout
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	testDecisionContext := decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}
+	s.mockDecisionService.On("GetFeatureDecision", testDecisionContext, s.testUserContext).Return(featureDecision, nil)
+
+	result, err := s.testClient.Decide("test_feature", s.testUserContext, IncludeReasons)
+	s.NoError(err)
+	s.Equal(DecisionResult{
+		Key:          "test_feature",
+		VariationKey: "v1",
+		Enabled:      false,
+		Variables:    map[string]interface{}{"int_variable": 1},
+		RuleKey:      "test_rule",
+		UserContext:  s.testUserContext,
+		Reasons:      []string{string(reasons.BucketedIntoRollout)},
+	}, result)
+	s.mockDecisionService.AssertExpectations(s.T())
+	s.mockEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+}
+
+func (s *ClientTestSuiteDecide) TestDecideExperiment() {
+	testExperiment := makeTestExperiment("test_exp_1")
+	s.mockConfig.On("GetFeatureByKey", "test_exp_1").Return(entities.Feature{}, errors.New("feature not found"))
+	s.mockConfig.On("GetExperimentByKey", "test_exp_1").Return(testExperiment, nil)
+	testDecisionContext := decision.ExperimentDecisionContext{
+		Experiment:    &testExperiment,
+		ProjectConfig: s.mockConfig,
+	}
+	expectedVariation := testExperiment.Variations["v2"]
+	experimentDecision := decision.ExperimentDecision{
+		Decision:  decision.Decision{Reason: reasons.BucketedIntoVariation},
+		Variation: &expectedVariation,
+	}
+	s.mockDecisionService.On("GetExperimentDecision", testDecisionContext, s.testUserContext).Return(experimentDecision, nil)
+	s.mockEventProcessor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+
+	result, err := s.testClient.Decide("test_exp_1", s.testUserContext, IncludeReasons)
+	s.NoError(err)
+	s.Equal(DecisionResult{
+		Key:          "test_exp_1",
+		VariationKey: "v2",
+		Enabled:      true,
+		Variables:    map[string]interface{}{},
+		RuleKey:      "test_exp_1",
+		UserContext:  s.testUserContext,
+		Reasons:      []string{string(reasons.BucketedIntoVariation)},
+	}, result)
+	s.mockDecisionService.AssertExpectations(s.T())
+	s.mockEventProcessor.AssertExpectations(s.T())
+}
+
+func (s *ClientTestSuiteDecide) TestDecideUnknownKey() {
+	s.mockConfig.On("GetFeatureByKey", "unknown").Return(entities.Feature{}, errors.New("feature not found"))
+	s.mockConfig.On("GetExperimentByKey", "unknown").Return(entities.Experiment{}, errors.New("experiment not found"))
+
+	s.testClient.notificationCenter = notification.NewNotificationCenter()
+	var unknownKeys []string
+	_, err := s.testClient.OnUnknownKey(func(kind, key string, userContext entities.UserContext) {
+		s.Equal(s.testUserContext, userContext)
+		unknownKeys = append(unknownKeys, kind+":"+key)
+	})
+	s.NoError(err)
+
+	result, err := s.testClient.Decide("unknown", s.testUserContext, IncludeReasons)
+	s.Equal(&ErrEntityNotFound{Kind: EntityKindFeature, Key: "unknown"}, err)
+	s.False(result.Enabled)
+	s.Equal("", result.VariationKey)
+	s.Equal([]string{`No feature or experiment found for key "unknown".`}, result.Reasons)
+	s.Equal([]string{"feature:unknown"}, unknownKeys)
+
+	// the other keys are still decided
+	testFeature, featureDecision := s.makeTestFeature(decision.Rollout, true)
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	s.mockDecisionService.On("GetFeatureDecision", decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}, s.testUserContext).Return(featureDecision, nil)
+	results, err := s.testClient.DecideForKeys([]string{"unknown", "test_feature"}, s.testUserContext)
+	s.NoError(err)
+	s.False(results["unknown"].Enabled)
+	s.True(results["test_feature"].Enabled)
+	s.Equal([]string{"feature:unknown", "feature:unknown"}, unknownKeys)
+	s.mockDecisionService.AssertNotCalled(s.T(), "GetExperimentDecision", mock.Anything, mock.Anything)
+}
+
+func (s *ClientTestSuiteDecide) TestDecideWithoutReasons() {
+	s.mockConfig.On("GetFeatureByKey", "unknown").Return(entities.Feature{}, errors.New("feature not found"))
+	s.mockConfig.On("GetExperimentByKey", "unknown").Return(entities.Experiment{}, errors.New("experiment not found"))
+
+	result, err := s.testClient.Decide("unknown", s.testUserContext)
+	s.Equal(&ErrEntityNotFound{Kind: EntityKindFeature, Key: "unknown"}, err)
+	s.Nil(result.Reasons)
+}
+
+func (s *ClientTestSuiteDecide) TestDecideFeatureTestDisableDecisionEvent() {
+	testFeature, featureDecision := s.makeTestFeature(decision.FeatureTest, true)
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	testDecisionContext := decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}
+	s.mockDecisionService.On("GetFeatureDecision", testDecisionContext, s.testUserContext).Return(featureDecision, nil)
+
+	result, err := s.testClient.Decide("test_feature", s.testUserContext, DisableDecisionEvent)
+	s.NoError(err)
+	s.True(result.Enabled)
+	s.Equal("v1", result.VariationKey)
+	s.mockEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+}
+
+func (s *ClientTestSuiteDecide) TestDecideFeatureTestOptedOut() {
+	testFeature, featureDecision := s.makeTestFeature(decision.FeatureTest, true)
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	testDecisionContext := decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}
+	optedOutUserContext := entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{entities.OptOutAttributeName: true}}
+	s.mockDecisionService.On("GetFeatureDecision", testDecisionContext, optedOutUserContext).Return(featureDecision, nil)
+
+	result, err := s.testClient.Decide("test_feature", optedOutUserContext)
+	s.NoError(err)
+	s.True(result.Enabled)
+	s.Equal("v1", result.VariationKey)
+	s.mockDecisionService.AssertExpectations(s.T())
+	s.mockEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+}
+
+func (s *ClientTestSuiteDecide) TestDecideExperimentWithDefaultDecideOptions() {
+	testExperiment := makeTestExperiment("test_exp_1")
+	s.mockConfig.On("GetFeatureByKey", "test_exp_1").Return(entities.Feature{}, errors.New("feature not found"))
+	s.mockConfig.On("GetExperimentByKey", "test_exp_1").Return(testExperiment, nil)
+	testDecisionContext := decision.ExperimentDecisionContext{
+		Experiment:    &testExperiment,
+		ProjectConfig: s.mockConfig,
+	}
+	expectedVariation := testExperiment.Variations["v2"]
+	experimentDecision := decision.ExperimentDecision{
+		Decision:  decision.Decision{Reason: reasons.BucketedIntoVariation},
+		Variation: &expectedVariation,
+	}
+	s.mockDecisionService.On("GetExperimentDecision", testDecisionContext, s.testUserContext).Return(experimentDecision, nil)
+
+	// client level options compose with the ones passed to the call
+	s.testClient.defaultDecideOptions = []DecideOption{DisableDecisionEvent}
+	result, err := s.testClient.Decide("test_exp_1", s.testUserContext, IncludeReasons)
+	s.NoError(err)
+	s.Equal("v2", result.VariationKey)
+	s.Equal([]string{string(reasons.BucketedIntoVariation)}, result.Reasons)
+	s.mockEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+}
+
+func (s *ClientTestSuiteDecide) TestNewDecideOptions() {
+	s.Equal(decideOptions{}, newDecideOptions(nil, nil))
+	s.Equal(decideOptions{includeReasons: true}, newDecideOptions(nil, []DecideOption{IncludeReasons}))
+	s.Equal(decideOptions{disableDecisionEvent: true, includeReasons: true},
+		newDecideOptions([]DecideOption{DisableDecisionEvent}, []DecideOption{IncludeReasons, "unknown"}))
+}
+
+func (s *ClientTestSuiteDecide) TestDecideInvalidConfig() {
+	mockConfigManager := new(MockProjectConfigManager)
+	expectedError := errors.New("no project config available")
+	mockConfigManager.On("GetConfig").Return(s.mockConfig, expectedError)
+	s.testClient.ConfigManager = mockConfigManager
+
+	result, err := s.testClient.Decide("test_feature", s.testUserContext)
+	s.Equal(expectedError, err)
+	s.False(result.Enabled)
+}
+
+func (s *ClientTestSuiteDecide) TestDecidePanics() {
+	s.testClient.ConfigManager = new(PanickingConfigManager)
+
+	result, err := s.testClient.Decide("test_feature", s.testUserContext)
+	s.EqualError(err, "I'm panicking")
+	s.False(result.Enabled)
+}
+
+func (s *ClientTestSuiteDecide) TestDecideForKeys() {
+	testFeature, featureDecision := s.makeTestFeature(decision.FeatureTest, true)
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	s.mockDecisionService.On("GetFeatureDecision", decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}, s.testUserContext).Return(featureDecision, nil).Once()
+
+	testExperiment := makeTestExperiment("test_exp_1")
+	s.mockConfig.On("GetFeatureByKey", "test_exp_1").Return(entities.Feature{}, errors.New("feature not found"))
+	s.mockConfig.On("GetExperimentByKey", "test_exp_1").Return(testExperiment, nil)
+	expectedVariation := testExperiment.Variations["v2"]
+	s.mockDecisionService.On("GetExperimentDecision", decision.ExperimentDecisionContext{
+		Experiment:    &testExperiment,
+		ProjectConfig: s.mockConfig,
+	}, s.testUserContext).Return(decision.ExperimentDecision{Variation: &expectedVariation}, nil).Once()
+
+	flushableProcessor := new(FlushableEventProcessor)
+	flushableProcessor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent")).Twice()
+	s.testClient.EventProcessor = flushableProcessor
+
+	results, err := s.testClient.DecideForKeys([]string{"test_feature", "test_exp_1", "test_feature"}, s.testUserContext)
+	s.NoError(err)
+	s.Len(results, 2)
+	s.True(results["test_feature"].Enabled)
+	s.Equal("v1", results["test_feature"].VariationKey)
+	s.Equal("v2", results["test_exp_1"].VariationKey)
+	s.mockDecisionService.AssertExpectations(s.T())
+	flushableProcessor.AssertExpectations(s.T())
+	// the events are left to the processor batching
+	flushableProcessor.AssertNotCalled(s.T(), "Flush")
+}
+
+func (s *ClientTestSuiteDecide) TestDecideForKeysWithOneConfig() {
+	testFeature, featureDecision := s.makeTestFeature(decision.Rollout, true)
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	s.mockConfig.On("GetFeatureByKey", "test_feature_2").Return(testFeature, nil)
+	s.mockDecisionService.On("GetFeatureDecision", decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}, s.testUserContext).Return(featureDecision, nil).Twice()
+
+	// the config is updated while the keys are evaluated, they are all evaluated with the first one
+	mockConfigManager := new(MockProjectConfigManager)
+	mockConfigManager.On("GetConfig").Return(s.mockConfig, nil).Once()
+	mockConfigManager.On("GetConfig").Return(new(MockProjectConfig), nil)
+	s.testClient.ConfigManager = mockConfigManager
+
+	results, err := s.testClient.DecideForKeys([]string{"test_feature", "test_feature_2"}, s.testUserContext)
+	s.NoError(err)
+	s.True(results["test_feature"].Enabled)
+	s.True(results["test_feature_2"].Enabled)
+	s.mockDecisionService.AssertExpectations(s.T())
+	mockConfigManager.AssertNumberOfCalls(s.T(), "GetConfig", 1)
+}
+
+func (s *ClientTestSuiteDecide) TestDecideForKeysFlushDecisionEvents() {
+	testFeature, featureDecision := s.makeTestFeature(decision.FeatureTest, true)
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	s.mockDecisionService.On("GetFeatureDecision", decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}, s.testUserContext).Return(featureDecision, nil)
+
+	flushableProcessor := new(FlushableEventProcessor)
+	flushableProcessor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent")).Once()
+	flushableProcessor.On("Flush").Once()
+	s.testClient.EventProcessor = flushableProcessor
+
+	results, err := s.testClient.DecideForKeys([]string{"test_feature"}, s.testUserContext, FlushDecisionEvents)
+	s.NoError(err)
+	s.True(results["test_feature"].Enabled)
+	flushableProcessor.AssertExpectations(s.T())
+}
+
+func (s *ClientTestSuiteDecide) TestDecideForKeysDisableDecisionEvent() {
+	testFeature, featureDecision := s.makeTestFeature(decision.FeatureTest, true)
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	s.mockDecisionService.On("GetFeatureDecision", decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}, s.testUserContext).Return(featureDecision, nil)
+
+	flushableProcessor := new(FlushableEventProcessor)
+	s.testClient.EventProcessor = flushableProcessor
+
+	results, err := s.testClient.DecideForKeys([]string{"test_feature"}, s.testUserContext, DisableDecisionEvent)
+	s.NoError(err)
+	s.True(results["test_feature"].Enabled)
+	flushableProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+	flushableProcessor.AssertNotCalled(s.T(), "Flush")
+}
+
+func (s *ClientTestSuiteDecide) TestDecideAll() {
+	testFeature, featureDecision := s.makeTestFeature(decision.Rollout, true)
+	s.mockConfig.On("GetFeatureList").Return([]entities.Feature{testFeature})
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	s.mockDecisionService.On("GetFeatureDecision", decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}, s.testUserContext).Return(featureDecision, nil)
+
+	results, err := s.testClient.DecideAll(s.testUserContext)
+	s.NoError(err)
+	s.Equal(map[string]DecisionResult{
+		"test_feature": {
+			Key:          "test_feature",
+			VariationKey: "v1",
+			Enabled:      true,
+			Variables:    map[string]interface{}{"int_variable": 42},
+			RuleKey:      "test_rule",
+			UserContext:  s.testUserContext,
+		},
+	}, results)
+	s.mockEventProcessor.AssertNotCalled(s.T(), "ProcessEvent", mock.AnythingOfType("event.UserEvent"))
+}
+
+func (s *ClientTestSuiteDecide) TestDecideAllWithContext() {
+	ctx := context.Background()
+	testFeature, featureDecision := s.makeTestFeature(decision.FeatureTest, true)
+	s.mockConfig.On("GetFeatureList").Return([]entities.Feature{testFeature})
+	s.mockConfig.On("GetFeatureByKey", "test_feature").Return(testFeature, nil)
+	contextDecisionService := new(ContextDecisionService)
+	contextDecisionService.On("GetFeatureDecisionWithContext", ctx, decision.FeatureDecisionContext{
+		Feature:       &testFeature,
+		ProjectConfig: s.mockConfig,
+	}, s.testUserContext).Return(featureDecision, nil)
+	contextEventProcessor := new(ContextEventProcessor)
+	contextEventProcessor.On("ProcessEventWithContext", ctx, mock.AnythingOfType("event.UserEvent")).Return(true)
+	s.testClient.DecisionService = contextDecisionService
+	s.testClient.EventProcessor = contextEventProcessor
+
+	results, err := s.testClient.DecideAllWithContext(ctx, s.testUserContext)
+	s.NoError(err)
+	s.True(results["test_feature"].Enabled)
+	contextDecisionService.AssertExpectations(s.T())
+	contextEventProcessor.AssertExpectations(s.T())
+}
+
+func TestSortedDecisionResults(t *testing.T) {
+	client, err := (&OptimizelyFactory{Datafile: rolledOutFeaturesDatafile}).Client()
+	assert.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		results, err := client.DecideAll(entities.UserContext{ID: "test_user"})
+		assert.NoError(t, err)
+		var keys []string
+		for _, result := range SortedDecisionResults(results) {
+			keys = append(keys, result.Key)
+			assert.True(t, result.Enabled)
+		}
+		assert.Equal(t, []string{"feature_a", "feature_b", "feature_c", "feature_d"}, keys)
+	}
+	assert.Empty(t, SortedDecisionResults(nil))
+	client.Close()
+}
+
+func TestSerializeDecisions(t *testing.T) {
+	results := map[string]DecisionResult{
+		"feature_b": {Key: "feature_b", Variables: map[string]interface{}{}},
+		"feature_a": {Key: "feature_a", Enabled: true, VariationKey: "on", RuleKey: "rule",
+			Variables: map[string]interface{}{"title": "<b>Hello</b>", "count": 2}},
+	}
+
+	serialized, err := SerializeDecisions("42", results)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"revision":"42","decisions":{"feature_a":{"enabled":true,"variationKey":"on",`+
+		`"variables":{"count":2,"title":"\u003cb\u003eHello\u003c/b\u003e"}},"feature_b":{"enabled":false}}}`, string(serialized))
+
+	var snapshot DecisionSnapshot
+	assert.NoError(t, json.Unmarshal(serialized, &snapshot))
+	assert.Equal(t, "42", snapshot.Revision)
+	assert.Equal(t, SnapshotDecision{Enabled: true, VariationKey: "on", Variables: map[string]interface{}{"title": "<b>Hello</b>", "count": 2.0}},
+		snapshot.Decisions["feature_a"])
+}
+
+func TestDecideAllSnapshot(t *testing.T) {
+	client, err := (&OptimizelyFactory{Datafile: rolledOutFeaturesDatafile}).Client()
+	assert.NoError(t, err)
+
+	snapshot, err := client.DecideAllSnapshot(entities.UserContext{ID: "test_user"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", snapshot.Revision)
+	assert.Equal(t, map[string]SnapshotDecision{
+		"feature_a": {Enabled: true, VariationKey: "on"},
+		"feature_b": {Enabled: true, VariationKey: "on"},
+		"feature_c": {Enabled: true, VariationKey: "on"},
+		"feature_d": {Enabled: true, VariationKey: "on"},
+	}, snapshot.Decisions)
+	client.Close()
+
+	mockConfigManager := new(MockProjectConfigManager)
+	expectedError := errors.New("no project config available")
+	mockConfigManager.On("GetConfig").Return(new(MockProjectConfig), expectedError)
+	_, err = (&OptimizelyClient{ConfigManager: mockConfigManager}).DecideAllSnapshot(entities.UserContext{ID: "test_user"})
+	assert.Equal(t, expectedError, err)
+}
+
+func TestDecideAllSnapshotWithUpdatedConfig(t *testing.T) {
+	projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig(rolledOutFeaturesDatafile)
+	assert.NoError(t, err)
+	updatedDatafile := bytes.Replace(rolledOutFeaturesDatafile, []byte(`"revision":"1"`), []byte(`"revision":"2"`), 1)
+	updatedDatafile = bytes.Replace(updatedDatafile, []byte(`"featureEnabled":true`), []byte(`"featureEnabled":false`), 1)
+	updatedProjectConfig, err := datafileprojectconfig.NewDatafileProjectConfig(updatedDatafile)
+	assert.NoError(t, err)
+
+	// the config is updated while the flags are decided, the decisions are made with the one of the revision
+	mockConfigManager := new(MockProjectConfigManager)
+	mockConfigManager.On("GetConfig").Return(projectConfig, nil).Once()
+	mockConfigManager.On("GetConfig").Return(updatedProjectConfig, nil)
+	client, err := (&OptimizelyFactory{}).Client(WithConfigManager(mockConfigManager), WithoutEventProcessing())
+	assert.NoError(t, err)
+
+	snapshot, err := client.DecideAllSnapshot(entities.UserContext{ID: "test_user"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", snapshot.Revision)
+	for key, snapshotDecision := range snapshot.Decisions {
+		assert.True(t, snapshotDecision.Enabled, key)
+	}
+	assert.Len(t, snapshot.Decisions, 4)
+	client.Close()
+}
+
+func TestDecideBucketingTrace(t *testing.T) {
+	client, err := (&OptimizelyFactory{Datafile: rolledOutFeaturesDatafile}).Client()
+	assert.NoError(t, err)
+	userContext := entities.UserContext{ID: "test_user"}
+
+	result, err := client.Decide("feature_a", userContext, IncludeReasons)
+	assert.NoError(t, err)
+	if assert.Len(t, result.Reasons, 3) {
+		assert.Equal(t, string(reasons.BucketedIntoRollout), result.Reasons[0])
+		assert.Regexp(t, `^bucket \d+ fell in \[0,10000\) of experiment "rule" → variation "on"$`, result.Reasons[1])
+		assert.Equal(t, `Rule 0 of feature rollout with key "feature_a": Bucketed into feature rollout.`, result.Reasons[2])
+	}
+
+	result, err = client.Decide("feature_a", userContext)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Reasons)
+	client.Close()
+}
+
+func (s *ClientTestSuiteDecide) TestDecideAllInvalidConfig() {
+	mockConfigManager := new(MockProjectConfigManager)
+	expectedError := errors.New("no project config available")
+	mockConfigManager.On("GetConfig").Return(s.mockConfig, expectedError)
+	s.testClient.ConfigManager = mockConfigManager
+
+	results, err := s.testClient.DecideAll(s.testUserContext)
+	s.Equal(expectedError, err)
+	s.Empty(results)
+}
+
+func (s *ClientTestSuiteDecide) TestDecideForKeysPanics() {
+	s.testClient.ConfigManager = new(PanickingConfigManager)
+
+	_, err := s.testClient.DecideForKeys([]string{"test_feature"}, s.testUserContext)
+	s.EqualError(err, "I'm panicking")
+}
+
+func TestClientTestSuiteDecide(t *testing.T) {
+	suite.Run(t, new(ClientTestSuiteDecide))
+}
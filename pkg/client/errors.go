@@ -0,0 +1,40 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package client //
+package client
+
+import "fmt"
+
+const (
+	// EntityKindExperiment is the kind of the entity not found for an unknown experiment key
+	EntityKindExperiment = "experiment"
+	// EntityKindFeature is the kind of the entity not found for an unknown feature key
+	EntityKindFeature = "feature"
+	// EntityKindVariable is the kind of the entity not found for an unknown feature variable key
+	EntityKindVariable = "variable"
+)
+
+// ErrEntityNotFound is returned when an experiment, a feature or a feature variable is requested by a key which is not
+// in the project config, which tells the unknown keys apart from the users which are not bucketed
+type ErrEntityNotFound struct {
+	Kind string
+	Key  string
+}
+
+func (e *ErrEntityNotFound) Error() string {
+	return fmt.Sprintf(`%s with key "%s" not found`, e.Kind, e.Key)
+}
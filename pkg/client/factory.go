@@ -20,12 +20,15 @@ package client
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/optimizely/go-sdk/pkg/config"
 	"github.com/optimizely/go-sdk/pkg/decision"
+	"github.com/optimizely/go-sdk/pkg/decision/evaluator"
 	"github.com/optimizely/go-sdk/pkg/event"
 	"github.com/optimizely/go-sdk/pkg/metrics"
+	"github.com/optimizely/go-sdk/pkg/notification"
 	"github.com/optimizely/go-sdk/pkg/registry"
 	"github.com/optimizely/go-sdk/pkg/utils"
 )
@@ -42,7 +45,32 @@ type OptimizelyFactory struct {
 	eventProcessor     event.Processor
 	userProfileService decision.UserProfileService
 	overrideStore      decision.ExperimentOverrideStore
+	featureOverrides   decision.FeatureOverrideStore
+	holdoutPercentage  float64
+	decisionCacheSize  int
+	audienceMetrics    bool
+	lenientAttributes  bool
 	metricsRegistry    metrics.Registry
+	notificationCenter notification.Center
+
+	datafileURLTemplate  string
+	region               string
+	userAgentSuffix      string
+	defaultDecideOptions []DecideOption
+	defaultAttributes    map[string]interface{}
+	defaultEventTags     map[string]interface{}
+	allowedEventKeys     map[string]bool
+	attributeConversions bool
+	clock                utils.Clock
+	datafileTimeout      time.Duration
+	datafileCache        config.DatafileCache
+	datafileCacheTTL     time.Duration
+
+	// batchProcessorOptions holds the settings of the default event processor set with WithBatchEventProcessor
+	batchProcessorOptions []event.BPOptionConfig
+
+	// optionErrors holds the errors of the options which could not be applied
+	optionErrors []error
 }
 
 // OptionFunc is used to provide custom client configuration to the OptimizelyFactory.
@@ -59,6 +87,15 @@ func (f OptimizelyFactory) Client(clientOptions ...OptionFunc) (*OptimizelyClien
 		return nil, errors.New("unable to instantiate client: no project config manager, SDK key, or a Datafile provided")
 	}
 
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	f.warnCustomNotificationCenters()
+	return f.client()
+}
+
+// client instantiates a new OptimizelyClient once the options of the factory have been validated
+func (f OptimizelyFactory) client() (*OptimizelyClient, error) {
 	var metricsRegistry metrics.Registry
 	if f.metricsRegistry != nil {
 		metricsRegistry = f.metricsRegistry
@@ -73,16 +110,39 @@ func (f OptimizelyFactory) Client(clientOptions ...OptionFunc) (*OptimizelyClien
 		ctx = context.Background()
 	}
 
+	// every client owns a notification center unless one is provided, so that the listeners of clients sharing an
+	// SDK key do not receive each other's notifications
+	notificationCenter := f.notificationCenter
+	if notificationCenter == nil {
+		notificationCenter = notification.NewNotificationCenter()
+	}
+
 	eg := utils.NewExecGroup(ctx)
-	appClient := &OptimizelyClient{execGroup: eg, notificationCenter: registry.GetNotificationCenter(f.SDKKey)}
+	appClient := &OptimizelyClient{
+		execGroup:            eg,
+		notificationCenter:   notificationCenter,
+		defaultDecideOptions: f.defaultDecideOptions,
+		defaultAttributes:    f.defaultAttributes,
+		defaultEventTags:     f.defaultEventTags,
+		allowedEventKeys:     f.allowedEventKeys,
+		clock:                f.clock,
+	}
+	if f.attributeConversions {
+		appClient.userProfileService = f.userProfileService
+	}
 
 	if f.configManager != nil {
 		appClient.ConfigManager = f.configManager
 	} else {
-		appClient.ConfigManager = config.NewPollingProjectConfigManager(
-			f.SDKKey,
-			config.WithInitialDatafile(f.Datafile),
-		)
+		pollingConfigManagerOptions := []config.OptionFunc{config.WithInitialDatafile(f.Datafile),
+			config.WithDatafileURLTemplate(f.getDatafileURLTemplate()), config.WithNotificationCenter(notificationCenter)}
+		if requester := f.getDatafileRequester(); requester != nil {
+			pollingConfigManagerOptions = append(pollingConfigManagerOptions, config.WithRequester(requester))
+		}
+		if f.datafileCache != nil {
+			pollingConfigManagerOptions = append(pollingConfigManagerOptions, config.WithDatafileCache(f.datafileCache, f.datafileCacheTTL))
+		}
+		appClient.ConfigManager = config.NewPollingProjectConfigManager(f.SDKKey, pollingConfigManagerOptions...)
 	}
 
 	if f.eventProcessor != nil {
@@ -90,11 +150,15 @@ func (f OptimizelyFactory) Client(clientOptions ...OptionFunc) (*OptimizelyClien
 	} else {
 		var eventProcessorOptions = []event.BPOptionConfig{
 			event.WithSDKKey(f.SDKKey),
+			event.WithRegion(f.region),
+			event.WithEventHTTPRequester(f.getRequester()),
+			event.WithNotificationCenter(notificationCenter),
 		}
 		if f.eventDispatcher != nil {
 			eventProcessorOptions = append(eventProcessorOptions, event.WithEventDispatcher(f.eventDispatcher))
 		}
 		eventProcessorOptions = append(eventProcessorOptions, event.WithEventDispatcherMetrics(metricsRegistry))
+		eventProcessorOptions = append(eventProcessorOptions, f.batchProcessorOptions...)
 		appClient.EventProcessor = event.NewBatchEventProcessor(eventProcessorOptions...)
 	}
 
@@ -108,18 +172,44 @@ func (f OptimizelyFactory) Client(clientOptions ...OptionFunc) (*OptimizelyClien
 		if f.overrideStore != nil {
 			experimentServiceOptions = append(experimentServiceOptions, decision.WithOverrideStore(f.overrideStore))
 		}
+		var audienceTreeEvaluator evaluator.TreeEvaluator
+		var treeEvaluatorOptions []evaluator.TreeEvaluatorOptionFunc
+		if f.audienceMetrics {
+			treeEvaluatorOptions = append(treeEvaluatorOptions, evaluator.WithEvaluationMetrics(metricsRegistry))
+		}
+		if f.lenientAttributes {
+			treeEvaluatorOptions = append(treeEvaluatorOptions, evaluator.WithLenientTypeMatching())
+		}
+		if len(treeEvaluatorOptions) > 0 {
+			audienceTreeEvaluator = evaluator.NewMixedTreeEvaluator(treeEvaluatorOptions...)
+			experimentServiceOptions = append(experimentServiceOptions, decision.WithAudienceTreeEvaluator(audienceTreeEvaluator))
+		}
 		compositeExperimentService := decision.NewCompositeExperimentService(experimentServiceOptions...)
-		compositeService := decision.NewCompositeService(f.SDKKey, decision.WithCompositeExperimentService(compositeExperimentService))
+		compositeServiceOptions := []decision.CSOptionFunc{decision.WithCompositeExperimentService(compositeExperimentService),
+			decision.WithNotificationCenter(notificationCenter)}
+		if audienceTreeEvaluator != nil {
+			compositeServiceOptions = append(compositeServiceOptions, decision.WithRolloutAudienceTreeEvaluator(audienceTreeEvaluator))
+		}
+		if f.featureOverrides != nil {
+			compositeServiceOptions = append(compositeServiceOptions, decision.WithFeatureOverrideStore(f.featureOverrides))
+		}
+		if f.holdoutPercentage != 0 {
+			compositeServiceOptions = append(compositeServiceOptions, decision.WithHoldout(f.holdoutPercentage))
+		}
+		if f.decisionCacheSize > 0 {
+			compositeServiceOptions = append(compositeServiceOptions, decision.WithDecisionCache(f.decisionCacheSize))
+		}
+		compositeService := decision.NewCompositeService(f.SDKKey, compositeServiceOptions...)
 		appClient.DecisionService = compositeService
 	}
 
 	// Initialize the default services with the execution context
-	if pollingConfigManager, ok := appClient.ConfigManager.(*config.PollingProjectConfigManager); ok {
-		eg.Go(pollingConfigManager.Start)
+	if startableConfigManager, ok := appClient.ConfigManager.(config.StartableProjectConfigManager); ok {
+		eg.Go(startableConfigManager.Start)
 	}
 
-	if batchProcessor, ok := appClient.EventProcessor.(*event.BatchEventProcessor); ok {
-		eg.Go(batchProcessor.Start)
+	if startableProcessor, ok := appClient.EventProcessor.(event.StartableProcessor); ok {
+		eg.Go(startableProcessor.Start)
 	}
 
 	return appClient, nil
@@ -133,6 +223,15 @@ func WithPollingConfigManager(pollingInterval time.Duration, initDataFile []byte
 	}
 }
 
+// WithDatafileCache sets the cache the datafiles fetched for the SDK key are saved to, and started from when the datafile
+// cached is not older than the TTL, or when the datafile can't be fetched, see config.WithDatafileCache
+func WithDatafileCache(cache config.DatafileCache, ttl time.Duration) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.datafileCache = cache
+		f.datafileCacheTTL = ttl
+	}
+}
+
 // WithConfigManager sets polling config manager on a client.
 func WithConfigManager(configManager config.ProjectConfigManager) OptionFunc {
 	return func(f *OptimizelyFactory) {
@@ -140,6 +239,15 @@ func WithConfigManager(configManager config.ProjectConfigManager) OptionFunc {
 	}
 }
 
+// WithNotificationCenter sets the notification center of the client and of the services created by the factory. By
+// default every client owns a new notification center, registry.GetNotificationCenter(sdkKey) can be passed to share the
+// one registered for the SDK key, which the services created outside of the factory send their notifications to.
+func WithNotificationCenter(notificationCenter notification.Center) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.notificationCenter = notificationCenter
+	}
+}
+
 // WithDecisionService sets decision service on a client.
 func WithDecisionService(decisionService decision.Service) OptionFunc {
 	return func(f *OptimizelyFactory) {
@@ -154,6 +262,27 @@ func WithUserProfileService(userProfileService decision.UserProfileService) Opti
 	}
 }
 
+// WithFeatureOverrides sets the store of attribute based feature overrides on the decision service.
+func WithFeatureOverrides(featureOverrides decision.FeatureOverrideStore) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.featureOverrides = featureOverrides
+	}
+}
+
+// WithHoldout holds the given percentage (0 to 100) of users out of all experiments on the decision service.
+func WithHoldout(percentage float64) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.holdoutPercentage = percentage
+	}
+}
+
+// WithDecisionCache caches up to size feature decisions on the decision service, per user and config revision.
+func WithDecisionCache(size int) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.decisionCacheSize = size
+	}
+}
+
 // WithExperimentOverrides sets the experiment override store on the decision service.
 func WithExperimentOverrides(overrideStore decision.ExperimentOverrideStore) OptionFunc {
 	return func(f *OptimizelyFactory) {
@@ -164,8 +293,14 @@ func WithExperimentOverrides(overrideStore decision.ExperimentOverrideStore) Opt
 // WithBatchEventProcessor sets event processor on a client.
 func WithBatchEventProcessor(batchSize, queueSize int, flushInterval time.Duration) OptionFunc {
 	return func(f *OptimizelyFactory) {
-		f.eventProcessor = event.NewBatchEventProcessor(event.WithBatchSize(batchSize),
-			event.WithQueueSize(queueSize), event.WithFlushInterval(flushInterval))
+		if batchSize < 0 || queueSize < 0 || flushInterval < 0 {
+			f.optionErrors = append(f.optionErrors, fmt.Errorf("invalid batch event processor settings: batch size %d, "+
+				"queue size %d and flush interval %s cannot be negative", batchSize, queueSize, flushInterval))
+		}
+		// the processor is created with the client, along with the other event options such as the region
+		f.eventProcessor = nil
+		f.batchProcessorOptions = []event.BPOptionConfig{event.WithBatchSize(batchSize),
+			event.WithQueueSize(queueSize), event.WithFlushInterval(flushInterval)}
 	}
 }
 
@@ -173,6 +308,16 @@ func WithBatchEventProcessor(batchSize, queueSize int, flushInterval time.Durati
 func WithEventProcessor(eventProcessor event.Processor) OptionFunc {
 	return func(f *OptimizelyFactory) {
 		f.eventProcessor = eventProcessor
+		f.batchProcessorOptions = nil
+	}
+}
+
+// WithoutEventProcessing sets a processor discarding the impression and conversion events on a client, which then
+// runs no event goroutine and makes no event request. Decisions are still returned and notifications still sent.
+func WithoutEventProcessing() OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.eventProcessor = event.NewNoopProcessor()
+		f.batchProcessorOptions = nil
 	}
 }
 
@@ -183,6 +328,32 @@ func WithEventDispatcher(eventDispatcher event.Dispatcher) OptionFunc {
 	}
 }
 
+// WithDatafileURLTemplate sets the template, holding a %s placeholder for the SDK key, used to build the URL the
+// datafile is fetched from. It allows fetching the datafile through a proxy or from a self-hosted location.
+func WithDatafileURLTemplate(datafileURLTemplate string) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.datafileURLTemplate = datafileURLTemplate
+	}
+}
+
+// WithRegion sets the data residency region (US or EU) of the client, it selects the default event endpoint and
+// datafile host for all projects on the client. Projects which specify a region in their datafile keep dispatching
+// their events to it. An unknown region fails the creation of the client, as does a region along with a custom config
+// manager or event processor, which it would not be applied to.
+func WithRegion(region string) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.region = region
+	}
+}
+
+// WithUserAgentSuffix appends the given suffix, e.g. the name and version of the app, to the User-Agent header
+// identifying the SDK in the datafile and event requests of the client.
+func WithUserAgentSuffix(suffix string) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.userAgentSuffix = suffix
+	}
+}
+
 // WithContext allows user to pass in their own context to override the default one in the client.
 func WithContext(ctx context.Context) OptionFunc {
 	return func(f *OptimizelyFactory) {
@@ -197,13 +368,109 @@ func WithMetricsRegistry(metricsRegistry metrics.Registry) OptionFunc {
 	}
 }
 
+// WithDefaultDecideOptions sets the options applied to every Decide call on the client, in addition to the
+// options passed to the call itself.
+func WithDefaultDecideOptions(options ...DecideOption) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.defaultDecideOptions = options
+	}
+}
+
+// WithAudienceEvaluationMetrics counts the audience conditions evaluated by the decision service, and the condition
+// trees it short-circuited, with the counters of the metrics registry of the client. It is off by default.
+func WithAudienceEvaluationMetrics() OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.audienceMetrics = true
+	}
+}
+
+// WithLenientAttributeTypes coerces string attributes to the number or bool type of the audience conditions they are
+// evaluated against, for attributes such as query parameters. Attributes are matched strictly by default.
+func WithLenientAttributeTypes() OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.lenientAttributes = true
+	}
+}
+
+// WithConversionAttribution attributes the conversions tracked by the client to the experiments saved in the profile of
+// the user by the user profile service, which is looked up on every call to Track. It is off by default.
+func WithConversionAttribution() OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.attributeConversions = true
+	}
+}
+
+// WithDefaultAttributes sets the attributes merged into the attributes of every user context passed to the client,
+// the attributes of the user context take precedence.
+func WithDefaultAttributes(attributes map[string]interface{}) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.defaultAttributes = copyValues(attributes)
+	}
+}
+
+// WithDefaultEventTags sets the event tags merged into the event tags of every tracked event, the tags passed to Track
+// take precedence.
+func WithDefaultEventTags(eventTags map[string]interface{}) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.defaultEventTags = copyValues(eventTags)
+	}
+}
+
+// WithAllowedEventKeys sets the keys of the events a client tracks, Track calls for the other events are dropped before
+// any conversion event is created. All the events are tracked by default.
+func WithAllowedEventKeys(eventKeys ...string) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.allowedEventKeys = make(map[string]bool, len(eventKeys))
+		for _, eventKey := range eventKeys {
+			f.allowedEventKeys[eventKey] = true
+		}
+	}
+}
+
+// WithClock sets the clock the timestamps of the impression and conversion events are taken from, e.g. to create events
+// at a fixed time in tests. The system clock is used by default.
+func WithClock(clock utils.Clock) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		f.clock = clock
+	}
+}
+
+// WithDatafileRequestTimeout sets the timeout of each datafile request, separately from the event requests, so that an
+// unresponsive CDN can't hold the client creation for longer. The requests time out after 5 seconds by default.
+func WithDatafileRequestTimeout(timeout time.Duration) OptionFunc {
+	return func(f *OptimizelyFactory) {
+		if timeout <= 0 {
+			f.optionErrors = append(f.optionErrors, fmt.Errorf("datafile request timeout %s must be positive", timeout))
+		}
+		f.datafileTimeout = timeout
+	}
+}
+
 // StaticClient returns a client initialized with a static project config.
-func (f OptimizelyFactory) StaticClient() (*OptimizelyClient, error) {
+func (f OptimizelyFactory) StaticClient(clientOptions ...OptionFunc) (*OptimizelyClient, error) {
+	for _, opt := range clientOptions {
+		opt(&f)
+	}
+
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	f.warnCustomNotificationCenters()
+	if f.SDKKey != "" && f.Datafile != nil {
+		return nil, errors.New("unable to instantiate client: a static client is created either from an SDK key or from a Datafile, not both")
+	}
+	if f.datafileCache != nil {
+		return nil, errors.New("unable to instantiate client: a datafile cache cannot be used with a static client")
+	}
+
 	var configManager config.ProjectConfigManager
 
 	if f.SDKKey != "" {
-		staticConfigManager, err := config.NewStaticProjectConfigManagerFromURL(f.SDKKey)
-
+		var staticOptions []config.StaticOptionFunc
+		if requester := f.getDatafileRequester(); requester != nil {
+			staticOptions = append(staticOptions, config.WithFetchRequester(requester))
+		}
+		staticConfigManager, err := config.NewStaticProjectConfigManagerFromURLTemplate(f.SDKKey, f.getDatafileURLTemplate(), staticOptions...)
 		if err != nil {
 			return nil, err
 		}
@@ -220,10 +487,126 @@ func (f OptimizelyFactory) StaticClient() (*OptimizelyClient, error) {
 		configManager = staticConfigManager
 	}
 
-	optlyClient, e := f.Client(
-		WithConfigManager(configManager),
-		WithBatchEventProcessor(event.DefaultBatchSize, event.DefaultEventQueueSize, event.DefaultEventFlushInterval),
-	)
+	if configManager == nil {
+		return nil, errors.New("unable to instantiate client: no project config manager, SDK key, or a Datafile provided")
+	}
+	f.configManager = configManager
+	if f.eventProcessor == nil && f.eventDispatcher == nil {
+		// the processor sends its notifications to the center of the client, which is then created up front
+		if f.notificationCenter == nil {
+			f.notificationCenter = notification.NewNotificationCenter()
+		}
+		eventProcessorOptions := append([]event.BPOptionConfig{event.WithBatchSize(event.DefaultBatchSize),
+			event.WithQueueSize(event.DefaultEventQueueSize), event.WithFlushInterval(event.DefaultEventFlushInterval),
+			event.WithRegion(f.region), event.WithEventHTTPRequester(f.getRequester()),
+			event.WithNotificationCenter(f.notificationCenter)}, f.batchProcessorOptions...)
+		f.eventProcessor = event.NewBatchEventProcessor(eventProcessorOptions...)
+		// the region has been applied to the event processor
+		f.region = ""
+	}
+
+	// the options have been validated above, the static config manager takes the place of the datafile URL template
+	return f.client()
+}
 
-	return optlyClient, e
+// validate returns a descriptive error for options holding invalid values or conflicting with each other, conflicting
+// options would otherwise be silently ignored
+func (f OptimizelyFactory) validate() error {
+	var problem error
+	switch {
+	case len(f.optionErrors) > 0:
+		problem = f.optionErrors[0]
+	case f.region != "" && event.ValidateRegion(f.region) != nil:
+		problem = event.ValidateRegion(f.region)
+	case f.region != "" && f.configManager != nil:
+		problem = errors.New("a region cannot be used with a custom config manager")
+	case f.region != "" && f.hasCustomEventProcessor():
+		problem = errors.New("a region cannot be used with a custom event processor")
+	case f.configManager != nil && f.datafileURLTemplate != "":
+		problem = errors.New("a datafile URL template cannot be used with a custom config manager")
+	case f.configManager != nil && f.datafileCache != nil:
+		problem = errors.New("a datafile cache cannot be used with a custom config manager")
+	case f.configManager != nil && f.datafileTimeout != 0:
+		problem = errors.New("a datafile request timeout cannot be used with a custom config manager")
+	case f.configManager == nil && f.SDKKey == "" && f.datafileURLTemplate != "":
+		problem = errors.New("a datafile URL template requires an SDK key")
+	case f.eventProcessor != nil && f.eventDispatcher != nil:
+		problem = errors.New("an event dispatcher cannot be used with a custom event processor")
+	// the user profile service is also used by the client itself to attribute the conversions
+	case f.decisionService != nil && ((f.userProfileService != nil && !f.attributeConversions) || f.overrideStore != nil ||
+		f.featureOverrides != nil || f.holdoutPercentage != 0 || f.decisionCacheSize != 0 || f.audienceMetrics || f.lenientAttributes):
+		problem = errors.New("decision service options cannot be used with a custom decision service")
+	case f.holdoutPercentage < 0 || f.holdoutPercentage > 100:
+		problem = fmt.Errorf("holdout percentage %v is not between 0 and 100", f.holdoutPercentage)
+	case f.decisionCacheSize < 0:
+		problem = fmt.Errorf("decision cache size %d cannot be negative", f.decisionCacheSize)
+	}
+
+	if problem != nil {
+		return fmt.Errorf("unable to instantiate client: %s", problem)
+	}
+	return nil
+}
+
+// warnCustomNotificationCenters warns about the custom config manager and event processor, which send their
+// notifications to the center they were created with rather than to the one of the client. Their handlers are still
+// registered through the client, the handlers added to the center of the client directly are not notified.
+func (f OptimizelyFactory) warnCustomNotificationCenters() {
+	// the services created outside of the factory default to the center registered for the SDK key
+	if f.notificationCenter != nil && f.notificationCenter == registry.GetNotificationCenter(f.SDKKey) {
+		return
+	}
+	if f.configManager != nil {
+		logger.Warning("The custom config manager sends its notifications to the notification center it was created with, not to the one of the client.")
+	}
+	if f.hasCustomEventProcessor() {
+		logger.Warning("The custom event processor sends its notifications to the notification center it was created with, not to the one of the client.")
+	}
+}
+
+// hasCustomEventProcessor returns whether an event processor dispatching the events was passed to the factory
+func (f OptimizelyFactory) hasCustomEventProcessor() bool {
+	_, noop := f.eventProcessor.(*event.NoopProcessor)
+	return f.eventProcessor != nil && !noop
+}
+
+// getRequester returns the requester of the datafile and event requests of the client, nil for the defaults
+func (f OptimizelyFactory) getRequester() *utils.HTTPRequester {
+	if f.userAgentSuffix == "" {
+		return nil
+	}
+	return utils.NewHTTPRequester(utils.UserAgentSuffix(f.userAgentSuffix))
+}
+
+// getDatafileRequester returns the requester of the datafile requests of the client, nil for the default
+func (f OptimizelyFactory) getDatafileRequester() *utils.HTTPRequester {
+	if f.datafileTimeout == 0 {
+		return f.getRequester()
+	}
+	requesterOptions := []func(*utils.HTTPRequester){utils.Timeout(f.datafileTimeout)}
+	if f.userAgentSuffix != "" {
+		requesterOptions = append(requesterOptions, utils.UserAgentSuffix(f.userAgentSuffix))
+	}
+	return utils.NewHTTPRequester(requesterOptions...)
+}
+
+// getDatafileURLTemplate returns the datafile URL template set on the factory, or the one of its region
+func (f OptimizelyFactory) getDatafileURLTemplate() string {
+	if f.datafileURLTemplate != "" {
+		return f.datafileURLTemplate
+	}
+	return config.GetDatafileURLTemplate(f.region)
+}
+
+// copyValues returns a copy of the given attributes or tags, so that the caller changing them later does not affect
+// the client
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
 }
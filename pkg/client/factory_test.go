@@ -17,9 +17,12 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -27,8 +30,12 @@ import (
 	"github.com/optimizely/go-sdk/pkg/config"
 	"github.com/optimizely/go-sdk/pkg/config/datafileprojectconfig"
 	"github.com/optimizely/go-sdk/pkg/decision"
+	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/event"
+	"github.com/optimizely/go-sdk/pkg/logging"
 	"github.com/optimizely/go-sdk/pkg/metrics"
+	"github.com/optimizely/go-sdk/pkg/notification"
+	"github.com/optimizely/go-sdk/pkg/registry"
 	"github.com/optimizely/go-sdk/pkg/utils"
 
 	"github.com/stretchr/testify/assert"
@@ -114,6 +121,33 @@ func TestClientWithDecisionServiceAndEventProcessorInOptions(t *testing.T) {
 	assert.Equal(t, processor, optimizelyClient.EventProcessor)
 }
 
+func TestClientWithCustomEventProcessorIsStarted(t *testing.T) {
+	factory := OptimizelyFactory{}
+	configManager := config.NewStaticProjectConfigManager(datafileprojectconfig.DatafileProjectConfig{})
+	processor := &StartableEventProcessor{started: make(chan bool, 1)}
+	assert.Implements(t, (*event.EventProcessor)(nil), processor)
+
+	optimizelyClient, err := factory.Client(WithConfigManager(configManager), WithEventProcessor(processor))
+	assert.NoError(t, err)
+	assert.Equal(t, processor, optimizelyClient.EventProcessor)
+
+	select {
+	case <-processor.started:
+	case <-time.After(time.Second):
+		assert.Fail(t, "custom event processor was not started")
+	}
+	optimizelyClient.Close()
+}
+
+func TestClientWithDefaultDecideOptions(t *testing.T) {
+	factory := OptimizelyFactory{}
+	configManager := config.NewStaticProjectConfigManager(datafileprojectconfig.DatafileProjectConfig{})
+
+	optimizelyClient, err := factory.Client(WithConfigManager(configManager), WithDefaultDecideOptions(IncludeReasons))
+	assert.NoError(t, err)
+	assert.Equal(t, []DecideOption{IncludeReasons}, optimizelyClient.defaultDecideOptions)
+}
+
 func TestClientWithCustomCtx(t *testing.T) {
 	factory := OptimizelyFactory{}
 	ctx, cancel := context.WithCancel(context.Background())
@@ -149,6 +183,75 @@ func TestStaticClient(t *testing.T) {
 	assert.Nil(t, optlyClient)
 }
 
+func TestClientWithDatafileURLTemplate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/datafiles/test_sdk_key.json" {
+			w.Write([]byte(`{"revision":"42","version":"4"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	factory := OptimizelyFactory{SDKKey: "test_sdk_key"}
+	optlyClient, err := factory.Client(WithDatafileURLTemplate(ts.URL + "/datafiles/%s.json"))
+	assert.NoError(t, err)
+	parsedConfig, err := optlyClient.ConfigManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", parsedConfig.GetRevision())
+	optlyClient.Close()
+
+	optlyClient, err = factory.StaticClient(WithDatafileURLTemplate(ts.URL + "/datafiles/%s.json"))
+	assert.NoError(t, err)
+	parsedConfig, err = optlyClient.ConfigManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", parsedConfig.GetRevision())
+}
+
+func TestClientWithUserAgentSuffix(t *testing.T) {
+	userAgents := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case userAgents <- r.UserAgent():
+		default:
+		}
+		w.Write([]byte(`{"revision":"42","version":"4"}`))
+	}))
+	defer ts.Close()
+
+	factory := OptimizelyFactory{SDKKey: "test_sdk_key"}
+	optlyClient, err := factory.Client(WithDatafileURLTemplate(ts.URL+"/datafiles/%s.json"), WithUserAgentSuffix("app/2.0"))
+	assert.NoError(t, err)
+	assert.Equal(t, utils.UserAgent+" app/2.0", <-userAgents)
+	optlyClient.Close()
+}
+
+func TestClientWithDatafileRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	factory := OptimizelyFactory{SDKKey: "test_sdk_key"}
+	start := time.Now()
+	optlyClient, err := factory.Client(WithDatafileURLTemplate(ts.URL+"/datafiles/%s.json"), WithDatafileRequestTimeout(50*time.Millisecond))
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+	_, err = optlyClient.ConfigManager.GetConfig()
+	assert.Error(t, err)
+	optlyClient.Close()
+
+	_, err = factory.StaticClient(WithDatafileURLTemplate(ts.URL+"/datafiles/%s.json"), WithDatafileRequestTimeout(50*time.Millisecond))
+	assert.Error(t, err)
+	// the default startup retries and the requester delay after each attempt add up to about 3s
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	_, err = factory.Client(WithDatafileRequestTimeout(-time.Second))
+	assert.EqualError(t, err, "unable to instantiate client: datafile request timeout -1s must be positive")
+}
+
 func TestClientWithCustomDecisionServiceOptions(t *testing.T) {
 	factory := OptimizelyFactory{SDKKey: "1212"}
 
@@ -162,6 +265,230 @@ func TestClientWithCustomDecisionServiceOptions(t *testing.T) {
 	assert.NotNil(t, optimizelyClient.DecisionService)
 }
 
+func TestClientWithFeatureOverrides(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","featureFlags":[{"id":"1","key":"feature_key","rolloutId":"","experimentIds":[],"variables":[]}]}`)
+	factory := OptimizelyFactory{Datafile: datafile}
+
+	featureOverrides := decision.NewMapFeatureOverridesStore()
+	featureOverrides.SetOverride("feature_key", decision.FeatureAttributeOverride{AttributeName: "beta", AttributeValue: true, FeatureEnabled: true})
+	optimizelyClient, err := factory.Client(WithFeatureOverrides(featureOverrides))
+	assert.NoError(t, err)
+
+	enabled, err := optimizelyClient.IsFeatureEnabled("feature_key", entities.UserContext{ID: "test_user", Attributes: map[string]interface{}{"beta": true}})
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = optimizelyClient.IsFeatureEnabled("feature_key", entities.UserContext{ID: "test_user"})
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+	optimizelyClient.Close()
+}
+
+func TestClientAttributesConversionToExperiments(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","events":[{"id":"31","key":"event_key","experimentIds":["11","12"]}],"experiments":[` +
+		`{"id":"11","key":"exp_1","status":"Running","layerId":"1","audienceIds":[],"variations":[{"id":"21","key":"var_1"}],"trafficAllocation":[{"entityId":"21","endOfRange":10000}],"forcedVariations":{}},` +
+		`{"id":"12","key":"exp_2","status":"Running","layerId":"2","audienceIds":[],"variations":[{"id":"22","key":"var_2"}],"trafficAllocation":[{"entityId":"22","endOfRange":10000}],"forcedVariations":{}}]}`)
+	userContext := entities.UserContext{ID: "test_user"}
+	processor := new(MockProcessor)
+	processor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent")).Return(true)
+
+	factory := OptimizelyFactory{Datafile: datafile}
+	optimizelyClient, err := factory.Client(WithUserProfileService(decision.NewInMemoryUserProfileService()), WithConversionAttribution(),
+		WithEventProcessor(processor))
+	assert.NoError(t, err)
+	for _, experimentKey := range []string{"exp_1", "exp_2"} {
+		_, err = optimizelyClient.GetVariation(experimentKey, userContext)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, optimizelyClient.Track("event_key", userContext, nil))
+	if assert.Len(t, processor.Events, 1) {
+		expected := []event.Decision{
+			{CampaignID: "1", ExperimentID: "11", VariationID: "21"},
+			{CampaignID: "2", ExperimentID: "12", VariationID: "22"},
+		}
+		assert.Equal(t, expected, processor.Events[0].Conversion.Decisions)
+	}
+	optimizelyClient.Close()
+
+	// the user profile is not looked up on track by default
+	userProfileService := new(MockUserProfileService)
+	userProfileService.On("Lookup", mock.Anything).Return(decision.UserProfile{ID: "test_user"})
+	userProfileService.On("Save", mock.Anything)
+	processor = new(MockProcessor)
+	processor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent")).Return(true)
+	optimizelyClient, err = factory.Client(WithUserProfileService(userProfileService), WithEventProcessor(processor))
+	assert.NoError(t, err)
+	assert.NoError(t, optimizelyClient.Track("event_key", userContext, nil))
+	if assert.Len(t, processor.Events, 1) {
+		assert.Empty(t, processor.Events[0].Conversion.Decisions)
+	}
+	userProfileService.AssertNotCalled(t, "Lookup", mock.Anything)
+	optimizelyClient.Close()
+}
+
+func TestClientNotificationCenter(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","events":[{"id":"31","key":"event_key","experimentIds":[]}]}`)
+	factory := OptimizelyFactory{SDKKey: "shared_sdk_key", Datafile: datafile}
+	processor := new(MockProcessor)
+	processor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent")).Return(true)
+
+	// clients sharing an SDK key do not receive each other's notifications
+	client1, err := factory.Client(WithEventProcessor(processor))
+	assert.NoError(t, err)
+	client2, err := factory.Client(WithEventProcessor(processor))
+	assert.NoError(t, err)
+	var client1Calls, client2Calls int
+	_, err = client1.OnTrack(func(string, entities.UserContext, map[string]interface{}, event.ConversionEvent) { client1Calls++ })
+	assert.NoError(t, err)
+	_, err = client2.OnTrack(func(string, entities.UserContext, map[string]interface{}, event.ConversionEvent) { client2Calls++ })
+	assert.NoError(t, err)
+
+	assert.NoError(t, client2.Track("event_key", entities.UserContext{ID: "test_user"}, nil))
+	assert.Zero(t, client1Calls)
+	assert.Equal(t, 1, client2Calls)
+
+	// a notification center can be shared on purpose
+	notificationCenter := notification.NewNotificationCenter()
+	client3, err := factory.Client(WithEventProcessor(processor), WithNotificationCenter(notificationCenter))
+	assert.NoError(t, err)
+	var sharedCalls int
+	_, err = notificationCenter.AddHandler(notification.Track, func(interface{}) { sharedCalls++ })
+	assert.NoError(t, err)
+	assert.NoError(t, client3.Track("event_key", entities.UserContext{ID: "test_user"}, nil))
+	assert.Equal(t, 1, sharedCalls)
+
+	client1.Close()
+	client2.Close()
+	client3.Close()
+}
+
+func TestStaticClientNotificationCenter(t *testing.T) {
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: []byte(`{"version":"4","revision":"1"}`)}).StaticClient()
+	assert.NoError(t, err)
+
+	// the default processor sends its notifications to the center of the client
+	processor, ok := optimizelyClient.EventProcessor.(*event.BatchEventProcessor)
+	assert.True(t, ok)
+	var logEvents []event.LogEvent
+	_, err = processor.OnEventDispatch(func(logEvent event.LogEvent) { logEvents = append(logEvents, logEvent) })
+	assert.NoError(t, err)
+	assert.NoError(t, optimizelyClient.notificationCenter.Send(notification.LogEvent, event.LogEvent{}))
+	assert.Len(t, logEvents, 1)
+	optimizelyClient.Close()
+}
+
+func TestClientWithCustomComponentsNotificationCenter(t *testing.T) {
+	out := &bytes.Buffer{}
+	logging.SetLogger(logging.NewFilteredLevelLogConsumer(logging.LogLevelWarning, out))
+	defer logging.SetLogger(logging.NewFilteredLevelLogConsumer(logging.LogLevelInfo, os.Stdout))
+
+	datafile := []byte(`{"version":"4","revision":"1"}`)
+	configManager := config.NewStaticProjectConfigManager(datafileprojectconfig.DatafileProjectConfig{})
+	processor := new(MockProcessor)
+
+	// the components created by the factory send their notifications to the center of the client
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: datafile}).Client(WithoutEventProcessing())
+	assert.NoError(t, err)
+	optimizelyClient.Close()
+	assert.Empty(t, out.String())
+
+	optimizelyClient, err = (&OptimizelyFactory{}).Client(WithConfigManager(configManager), WithEventProcessor(processor))
+	assert.NoError(t, err)
+	optimizelyClient.Close()
+	assert.Contains(t, out.String(), "The custom config manager sends its notifications")
+	assert.Contains(t, out.String(), "The custom event processor sends its notifications")
+
+	out.Reset()
+	optimizelyClient, err = (&OptimizelyFactory{Datafile: datafile}).StaticClient(WithEventProcessor(processor))
+	assert.NoError(t, err)
+	optimizelyClient.Close()
+	assert.Contains(t, out.String(), "The custom event processor sends its notifications")
+
+	// nothing is reported when the client shares the center the custom components default to
+	out.Reset()
+	optimizelyClient, err = (&OptimizelyFactory{SDKKey: "custom_components_sdk_key"}).Client(WithConfigManager(configManager),
+		WithEventProcessor(processor), WithNotificationCenter(registry.GetNotificationCenter("custom_components_sdk_key")))
+	assert.NoError(t, err)
+	optimizelyClient.Close()
+	assert.Empty(t, out.String())
+}
+
+func TestClientWithHoldout(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","experiments":[{"id":"11","key":"exp_key","status":"Running","layerId":"1","audienceIds":[],"variations":[{"id":"21","key":"variation_key"}],"trafficAllocation":[{"entityId":"21","endOfRange":10000}],"forcedVariations":{}}]}`)
+	userContext := entities.UserContext{ID: "test_user"}
+
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: datafile}).Client()
+	assert.NoError(t, err)
+	variation, err := optimizelyClient.GetVariation("exp_key", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "variation_key", variation)
+	optimizelyClient.Close()
+
+	optimizelyClient, err = (&OptimizelyFactory{Datafile: datafile}).Client(WithHoldout(100))
+	assert.NoError(t, err)
+	variation, err = optimizelyClient.GetVariation("exp_key", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "", variation)
+	optimizelyClient.Close()
+}
+
+func TestClientWithDecisionCache(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","experiments":[{"id":"11","key":"exp_key","status":"Running","layerId":"1","audienceIds":[],"variations":[{"id":"21","key":"variation_key","featureEnabled":true}],"trafficAllocation":[{"entityId":"21","endOfRange":10000}],"forcedVariations":{}}],"featureFlags":[{"id":"1","key":"feature_key","rolloutId":"","experimentIds":["11"],"variables":[]}]}`)
+	userContext := entities.UserContext{ID: "test_user"}
+	mockProcessor := new(MockProcessor)
+	mockProcessor.On("ProcessEvent", mock.AnythingOfType("event.UserEvent")).Return(true)
+
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: datafile}).Client(WithDecisionCache(10), WithEventProcessor(mockProcessor))
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		enabled, err := optimizelyClient.IsFeatureEnabled("feature_key", userContext)
+		assert.NoError(t, err)
+		assert.True(t, enabled)
+	}
+	// an impression is queued for the cached decision too
+	assert.Len(t, mockProcessor.Events, 2)
+	optimizelyClient.Close()
+}
+
+func TestClientWithDefaultAttributes(t *testing.T) {
+	factory := OptimizelyFactory{SDKKey: "1212"}
+
+	defaultAttributes := map[string]interface{}{"platform": "ios"}
+	optimizelyClient, err := factory.Client(WithDefaultAttributes(defaultAttributes))
+	assert.NoError(t, err)
+	assert.Equal(t, defaultAttributes, optimizelyClient.defaultAttributes)
+
+	// the client keeps its own copy of the attributes
+	defaultAttributes["platform"] = "android"
+	assert.Equal(t, map[string]interface{}{"platform": "ios"}, optimizelyClient.defaultAttributes)
+}
+
+func TestClientWithDefaultEventTags(t *testing.T) {
+	factory := OptimizelyFactory{SDKKey: "1212"}
+
+	defaultEventTags := map[string]interface{}{"environment": "production"}
+	optimizelyClient, err := factory.Client(WithDefaultEventTags(defaultEventTags))
+	assert.NoError(t, err)
+	assert.Equal(t, defaultEventTags, optimizelyClient.defaultEventTags)
+
+	// the client keeps its own copy of the event tags
+	defaultEventTags["environment"] = "staging"
+	assert.Equal(t, map[string]interface{}{"environment": "production"}, optimizelyClient.defaultEventTags)
+}
+
+func TestClientWithAllowedEventKeys(t *testing.T) {
+	factory := OptimizelyFactory{SDKKey: "1212"}
+
+	optimizelyClient, err := factory.Client(WithAllowedEventKeys("purchase", "signup"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"purchase": true, "signup": true}, optimizelyClient.allowedEventKeys)
+
+	optimizelyClient, err = factory.Client()
+	assert.NoError(t, err)
+	assert.Nil(t, optimizelyClient.allowedEventKeys)
+}
+
 func TestClientWithEventDispatcher(t *testing.T) {
 	factory := OptimizelyFactory{SDKKey: "1212"}
 
@@ -173,6 +500,228 @@ func TestClientWithEventDispatcher(t *testing.T) {
 	assert.Equal(t, dispatcher, mockEventDispatcher)
 }
 
+func TestClientWithRegion(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","projectId":"11","accountId":"12","events":[{"id":"31","key":"event_key","experimentIds":[]}]}`)
+
+	mockEventDispatcher := new(MockDispatcher)
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: datafile}).Client(WithRegion(event.EURegion), WithEventDispatcher(mockEventDispatcher))
+	assert.NoError(t, err)
+	assert.NoError(t, optimizelyClient.Track("event_key", entities.UserContext{ID: "test_user"}, nil))
+	optimizelyClient.Close()
+
+	if assert.Len(t, mockEventDispatcher.Events, 1) {
+		assert.Equal(t, "https://eu.logx.optimizely.com/v1/events", mockEventDispatcher.Events[0].EndPoint)
+	}
+
+	_, err = (&OptimizelyFactory{Datafile: datafile}).Client(WithRegion("APAC"))
+	assert.EqualError(t, err, `unable to instantiate client: unknown region "APAC"`)
+
+	_, err = (&OptimizelyFactory{Datafile: datafile}).StaticClient(WithRegion("APAC"))
+	assert.EqualError(t, err, `unable to instantiate client: unknown region "APAC"`)
+}
+
+func TestClientWithRegionAndBatchEventProcessor(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","projectId":"11","accountId":"12","events":[{"id":"31","key":"event_key","experimentIds":[]}]}`)
+
+	mockEventDispatcher := new(MockDispatcher)
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: datafile}).Client(WithRegion(event.EURegion),
+		WithBatchEventProcessor(5, 50, time.Minute), WithEventDispatcher(mockEventDispatcher))
+	assert.NoError(t, err)
+	batchProcessor, ok := optimizelyClient.EventProcessor.(*event.BatchEventProcessor)
+	if assert.True(t, ok) {
+		assert.Equal(t, 5, batchProcessor.BatchSize)
+		assert.Equal(t, 50, batchProcessor.MaxQueueSize)
+		assert.Equal(t, time.Minute, batchProcessor.FlushInterval)
+	}
+	assert.NoError(t, optimizelyClient.Track("event_key", entities.UserContext{ID: "test_user"}, nil))
+	optimizelyClient.Close()
+
+	if assert.Len(t, mockEventDispatcher.Events, 1) {
+		assert.Equal(t, "https://eu.logx.optimizely.com/v1/events", mockEventDispatcher.Events[0].EndPoint)
+	}
+
+	// the last event processor option applies
+	processor := new(MockProcessor)
+	optimizelyClient, err = (&OptimizelyFactory{Datafile: datafile}).Client(WithBatchEventProcessor(5, 50, time.Minute), WithEventProcessor(processor))
+	assert.NoError(t, err)
+	assert.Equal(t, processor, optimizelyClient.EventProcessor)
+	optimizelyClient, err = (&OptimizelyFactory{Datafile: datafile}).Client(WithEventProcessor(processor), WithBatchEventProcessor(5, 50, time.Minute))
+	assert.NoError(t, err)
+	assert.IsType(t, new(event.BatchEventProcessor), optimizelyClient.EventProcessor)
+	optimizelyClient.Close()
+}
+
+func TestStaticClientWithRegionAndBatchEventProcessor(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","projectId":"11","accountId":"12","events":[{"id":"31","key":"event_key","experimentIds":[]}]}`)
+
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: datafile}).StaticClient(WithRegion(event.EURegion), WithBatchEventProcessor(5, 50, time.Minute))
+	assert.NoError(t, err)
+	batchProcessor, ok := optimizelyClient.EventProcessor.(*event.BatchEventProcessor)
+	if assert.True(t, ok) {
+		assert.Equal(t, 5, batchProcessor.BatchSize)
+		assert.Equal(t, time.Minute, batchProcessor.FlushInterval)
+	}
+	optimizelyClient.Close()
+}
+
+func TestClientWithoutEventProcessing(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","projectId":"11","accountId":"12","events":[{"id":"31","key":"event_key","experimentIds":[]}]}`)
+
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: datafile}).Client(WithoutEventProcessing())
+	assert.NoError(t, err)
+	assert.IsType(t, new(event.NoopProcessor), optimizelyClient.EventProcessor)
+
+	// the events are discarded, their notifications are still sent
+	var tracked []string
+	_, err = optimizelyClient.notificationCenter.AddHandler(notification.Track, func(payload interface{}) {
+		tracked = append(tracked, payload.(notification.TrackNotification).EventKey)
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, optimizelyClient.Track("event_key", entities.UserContext{ID: "test_user"}, nil))
+	assert.Equal(t, []string{"event_key"}, tracked)
+	optimizelyClient.Close()
+
+	_, err = (&OptimizelyFactory{Datafile: datafile}).Client(WithoutEventProcessing(), WithEventDispatcher(new(MockDispatcher)))
+	assert.EqualError(t, err, "unable to instantiate client: an event dispatcher cannot be used with a custom event processor")
+}
+
+func TestClientWithConflictingOptions(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1"}`)
+	scenarios := []struct {
+		name    string
+		factory OptimizelyFactory
+		options []OptionFunc
+		err     string
+	}{
+		{"negative flush interval", OptimizelyFactory{Datafile: datafile},
+			[]OptionFunc{WithBatchEventProcessor(10, 100, -time.Second)},
+			"unable to instantiate client: invalid batch event processor settings: batch size 10, queue size 100 and flush interval -1s cannot be negative"},
+		{"template with a config manager", OptimizelyFactory{SDKKey: "1212"},
+			[]OptionFunc{WithConfigManager(ValidProjectConfigManager()), WithDatafileURLTemplate("https://localhost/%s.json")},
+			"unable to instantiate client: a datafile URL template cannot be used with a custom config manager"},
+		{"datafile cache with a config manager", OptimizelyFactory{SDKKey: "1212"},
+			[]OptionFunc{WithConfigManager(ValidProjectConfigManager()), WithDatafileCache(config.NewFileDatafileCache("."), time.Hour)},
+			"unable to instantiate client: a datafile cache cannot be used with a custom config manager"},
+		{"datafile request timeout with a config manager", OptimizelyFactory{SDKKey: "1212"},
+			[]OptionFunc{WithConfigManager(ValidProjectConfigManager()), WithDatafileRequestTimeout(time.Second)},
+			"unable to instantiate client: a datafile request timeout cannot be used with a custom config manager"},
+		{"region with a config manager", OptimizelyFactory{SDKKey: "1212"},
+			[]OptionFunc{WithConfigManager(ValidProjectConfigManager()), WithRegion(event.EURegion)},
+			"unable to instantiate client: a region cannot be used with a custom config manager"},
+		{"region with an event processor", OptimizelyFactory{Datafile: datafile},
+			[]OptionFunc{WithEventProcessor(new(MockProcessor)), WithRegion(event.EURegion)},
+			"unable to instantiate client: a region cannot be used with a custom event processor"},
+		{"template without an SDK key", OptimizelyFactory{Datafile: datafile},
+			[]OptionFunc{WithDatafileURLTemplate("https://localhost/%s.json")},
+			"unable to instantiate client: a datafile URL template requires an SDK key"},
+		{"dispatcher with an event processor", OptimizelyFactory{Datafile: datafile},
+			[]OptionFunc{WithEventProcessor(new(MockProcessor)), WithEventDispatcher(new(MockDispatcher))},
+			"unable to instantiate client: an event dispatcher cannot be used with a custom event processor"},
+		{"holdout with a decision service", OptimizelyFactory{Datafile: datafile},
+			[]OptionFunc{WithDecisionService(new(MockDecisionService)), WithHoldout(10)},
+			"unable to instantiate client: decision service options cannot be used with a custom decision service"},
+		{"holdout out of range", OptimizelyFactory{Datafile: datafile},
+			[]OptionFunc{WithHoldout(150)},
+			"unable to instantiate client: holdout percentage 150 is not between 0 and 100"},
+		{"negative decision cache", OptimizelyFactory{Datafile: datafile},
+			[]OptionFunc{WithDecisionCache(-1)},
+			"unable to instantiate client: decision cache size -1 cannot be negative"},
+	}
+
+	for _, scenario := range scenarios {
+		optimizelyClient, err := scenario.factory.Client(scenario.options...)
+		assert.EqualError(t, err, scenario.err, scenario.name)
+		assert.Nil(t, optimizelyClient, scenario.name)
+	}
+
+	_, err := (&OptimizelyFactory{SDKKey: "1212", Datafile: datafile}).StaticClient()
+	assert.EqualError(t, err, "unable to instantiate client: a static client is created either from an SDK key or from a Datafile, not both")
+	_, err = (&OptimizelyFactory{Datafile: datafile}).StaticClient(WithDatafileCache(config.NewFileDatafileCache("."), time.Hour))
+	assert.EqualError(t, err, "unable to instantiate client: a datafile cache cannot be used with a static client")
+	_, err = (&OptimizelyFactory{Datafile: datafile}).StaticClient(WithRegion(event.EURegion), WithEventProcessor(new(MockProcessor)))
+	assert.EqualError(t, err, "unable to instantiate client: a region cannot be used with a custom event processor")
+
+	// the events of the clients without event processing are not dispatched to any region
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: datafile}).Client(WithRegion(event.EURegion), WithoutEventProcessing())
+	if assert.NoError(t, err) {
+		optimizelyClient.Close()
+	}
+
+	// the user profile service attributing the conversions is used by the client along with the custom decision service
+	optimizelyClient, err = (&OptimizelyFactory{Datafile: datafile}).Client(WithDecisionService(new(MockDecisionService)),
+		WithUserProfileService(new(MockUserProfileService)), WithConversionAttribution(), WithEventProcessor(new(MockProcessor)))
+	if assert.NoError(t, err) {
+		optimizelyClient.Close()
+	}
+}
+
+type countingMetricsRegistry struct {
+	metrics.NoopRegistry
+	counts map[string]float64
+}
+
+type countingCounter struct {
+	key      string
+	registry *countingMetricsRegistry
+}
+
+func (c countingCounter) Add(delta float64) {
+	c.registry.counts[c.key] += delta
+}
+
+func (r *countingMetricsRegistry) GetCounter(key string) metrics.Counter {
+	return countingCounter{key: key, registry: r}
+}
+
+func TestClientWithAudienceEvaluationMetrics(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","audiences":[{"id":"41","name":"us","conditions":"[\"and\",{\"type\":\"custom_attribute\",\"name\":\"country\",\"match\":\"exact\",\"value\":\"us\"}]"}],"experiments":[{"id":"11","key":"exp_key","status":"Running","layerId":"1","audienceIds":["41"],"variations":[{"id":"21","key":"variation_key"}],"trafficAllocation":[{"entityId":"21","endOfRange":10000}],"forcedVariations":{}}]}`)
+	userContext := entities.UserContext{ID: "test_user", Attributes: map[string]interface{}{"country": "us"}}
+
+	registry := &countingMetricsRegistry{counts: map[string]float64{}}
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: datafile}).Client(WithMetricsRegistry(registry),
+		WithAudienceEvaluationMetrics(), WithEventProcessor(new(MockProcessor)))
+	assert.NoError(t, err)
+	variation, err := optimizelyClient.GetVariation("exp_key", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "variation_key", variation)
+	assert.Equal(t, float64(1), registry.counts[metrics.AudienceConditionEvaluations])
+	optimizelyClient.Close()
+
+	// the evaluations are not counted by default
+	registry = &countingMetricsRegistry{counts: map[string]float64{}}
+	optimizelyClient, err = (&OptimizelyFactory{Datafile: datafile}).Client(WithMetricsRegistry(registry),
+		WithEventProcessor(new(MockProcessor)))
+	assert.NoError(t, err)
+	optimizelyClient.GetVariation("exp_key", userContext)
+	assert.Zero(t, registry.counts[metrics.AudienceConditionEvaluations])
+	optimizelyClient.Close()
+}
+
+func TestClientWithLenientAttributeTypes(t *testing.T) {
+	datafile := []byte(`{"version":"4","revision":"1","audiences":[{"id":"41","name":"adults","conditions":"[\"and\",{\"type\":\"custom_attribute\",\"name\":\"age\",\"match\":\"gt\",\"value\":18}]"}],"experiments":[{"id":"11","key":"exp_key","status":"Running","layerId":"1","audienceIds":["41"],"variations":[{"id":"21","key":"variation_key"}],"trafficAllocation":[{"entityId":"21","endOfRange":10000}],"forcedVariations":{}}]}`)
+	userContext := entities.UserContext{ID: "test_user", Attributes: map[string]interface{}{"age": "25"}}
+
+	optimizelyClient, err := (&OptimizelyFactory{Datafile: datafile}).Client(WithLenientAttributeTypes(),
+		WithEventProcessor(new(MockProcessor)))
+	assert.NoError(t, err)
+	variation, err := optimizelyClient.GetVariation("exp_key", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "variation_key", variation)
+	optimizelyClient.Close()
+
+	// attribute types are matched strictly by default
+	optimizelyClient, err = (&OptimizelyFactory{Datafile: datafile}).Client(WithEventProcessor(new(MockProcessor)))
+	assert.NoError(t, err)
+	variation, err = optimizelyClient.GetVariation("exp_key", userContext)
+	assert.NoError(t, err)
+	assert.Empty(t, variation)
+	optimizelyClient.Close()
+
+	_, err = (&OptimizelyFactory{Datafile: datafile}).Client(WithLenientAttributeTypes(),
+		WithDecisionService(new(MockDecisionService)))
+	assert.Error(t, err)
+}
+
 func TestClientMetrics(t *testing.T) {
 	factory := OptimizelyFactory{SDKKey: "1212"}
 
@@ -18,6 +18,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/optimizely/go-sdk/pkg/config"
@@ -47,6 +48,11 @@ func (c *MockProjectConfig) GetExperimentByKey(experimentKey string) (entities.E
 	return args.Get(0).(entities.Experiment), args.Error(1)
 }
 
+func (c *MockProjectConfig) GetVariations(experimentKey string) ([]entities.Variation, error) {
+	args := c.Called(experimentKey)
+	return args.Get(0).([]entities.Variation), args.Error(1)
+}
+
 func (c *MockProjectConfig) GetFeatureByKey(featureKey string) (entities.Feature, error) {
 	args := c.Called(featureKey)
 	return args.Get(0).(entities.Feature), args.Error(1)
@@ -122,6 +128,11 @@ func (m *MockDecisionService) GetExperimentDecision(decisionContext decision.Exp
 	return args.Get(0).(decision.ExperimentDecision), args.Error(1)
 }
 
+func (m *MockDecisionService) OnDecision(callback func(notification.DecisionNotification)) (int, error) {
+	args := m.Called(callback)
+	return args.Int(0), args.Error(1)
+}
+
 type MockEventProcessor struct {
 	event.Processor
 	mock.Mock
@@ -132,6 +143,50 @@ func (m *MockEventProcessor) ProcessEvent(event event.UserEvent) bool {
 	return false
 }
 
+type ContextDecisionService struct {
+	MockDecisionService
+}
+
+func (m *ContextDecisionService) GetFeatureDecisionWithContext(ctx context.Context, decisionContext decision.FeatureDecisionContext, userContext entities.UserContext) (decision.FeatureDecision, error) {
+	args := m.Called(ctx, decisionContext, userContext)
+	return args.Get(0).(decision.FeatureDecision), args.Error(1)
+}
+
+func (m *ContextDecisionService) GetExperimentDecisionWithContext(ctx context.Context, decisionContext decision.ExperimentDecisionContext, userContext entities.UserContext) (decision.ExperimentDecision, error) {
+	args := m.Called(ctx, decisionContext, userContext)
+	return args.Get(0).(decision.ExperimentDecision), args.Error(1)
+}
+
+type ContextEventProcessor struct {
+	MockEventProcessor
+}
+
+func (m *ContextEventProcessor) ProcessEventWithContext(ctx context.Context, event event.UserEvent) bool {
+	return m.Called(ctx, event).Bool(0)
+}
+
+type StartableEventProcessor struct {
+	MockEventProcessor
+	started chan bool
+}
+
+func (m *StartableEventProcessor) Start(ctx context.Context) {
+	m.started <- true
+	<-ctx.Done()
+}
+
+func (m *StartableEventProcessor) EventsCount() int {
+	return 0
+}
+
+type FlushableEventProcessor struct {
+	MockEventProcessor
+}
+
+func (m *FlushableEventProcessor) Flush() {
+	m.Called()
+}
+
 type PanickingConfigManager struct {
 	config.ProjectConfigManager
 }
@@ -164,6 +219,11 @@ type MockUserProfileService struct {
 	mock.Mock
 }
 
+func (m *MockUserProfileService) BatchLookup(userIDs []string) map[string]decision.UserProfile {
+	args := m.Called(userIDs)
+	return args.Get(0).(map[string]decision.UserProfile)
+}
+
 // Helper methods for creating test entities
 func makeTestExperiment(experimentKey string) entities.Experiment {
 	return entities.Experiment{
@@ -0,0 +1,99 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DatafileCache stores the datafiles fetched by the polling config manager so that a new manager can start from the
+// datafile fetched last, without a request or during a CDN outage
+type DatafileCache interface {
+	// Lookup returns the datafile stored for the SDK key and the time it was stored at, false when there is none
+	Lookup(sdkKey string) (datafile []byte, storedAt time.Time, ok bool)
+	// Save stores the datafile for the SDK key, replacing the one stored before
+	Save(sdkKey string, datafile []byte) error
+}
+
+// FileDatafileCache is a DatafileCache storing the datafile of each SDK key in a file of a directory, named after the
+// SDK key, the modification time of the file being the time the datafile was stored at
+type FileDatafileCache struct {
+	dir string
+}
+
+// NewFileDatafileCache returns a FileDatafileCache storing the datafiles in the given directory, which is created on
+// the first Save if it does not exist
+func NewFileDatafileCache(dir string) *FileDatafileCache {
+	return &FileDatafileCache{dir: dir}
+}
+
+// Lookup returns the datafile stored for the SDK key and the time it was stored at, false when there is none
+func (c *FileDatafileCache) Lookup(sdkKey string) (datafile []byte, storedAt time.Time, ok bool) {
+	path, err := c.path(sdkKey)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	if datafile, err = ioutil.ReadFile(path); err != nil || len(datafile) == 0 {
+		return nil, time.Time{}, false
+	}
+	return datafile, info.ModTime(), true
+}
+
+// Save stores the datafile for the SDK key, the file is written to a temporary file first and then renamed so that a
+// concurrent Lookup never reads a partially written datafile
+func (c *FileDatafileCache) Save(sdkKey string, datafile []byte) error {
+	path, err := c.path(sdkKey)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(c.dir, filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(datafile)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+	return err
+}
+
+// path returns the path of the file of the SDK key, SDK keys which can't be used as a file name are rejected
+func (c *FileDatafileCache) path(sdkKey string) (string, error) {
+	if sdkKey == "" || sdkKey == "." || sdkKey == ".." || strings.ContainsAny(sdkKey, `/\`) {
+		return "", fmt.Errorf(`invalid SDK key "%s" for the datafile cache`, sdkKey)
+	}
+	return filepath.Join(c.dir, sdkKey+".json"), nil
+}
@@ -0,0 +1,68 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileDatafileCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datafile_cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cache := NewFileDatafileCache(filepath.Join(dir, "datafiles"))
+	_, _, ok := cache.Lookup("sdk_key")
+	assert.False(t, ok)
+
+	before := time.Now().Add(-time.Second)
+	assert.NoError(t, cache.Save("sdk_key", []byte(`{"revision":"42"}`)))
+	datafile, storedAt, ok := cache.Lookup("sdk_key")
+	assert.True(t, ok)
+	assert.Equal(t, []byte(`{"revision":"42"}`), datafile)
+	assert.True(t, storedAt.After(before))
+
+	assert.NoError(t, cache.Save("sdk_key", []byte(`{"revision":"43"}`)))
+	datafile, _, _ = cache.Lookup("sdk_key")
+	assert.Equal(t, []byte(`{"revision":"43"}`), datafile)
+
+	// the datafiles are keyed by SDK key and no temporary file is left behind
+	_, _, ok = cache.Lookup("other_sdk_key")
+	assert.False(t, ok)
+	files, err := ioutil.ReadDir(filepath.Join(dir, "datafiles"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestFileDatafileCacheInvalidSDKKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datafile_cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cache := NewFileDatafileCache(dir)
+	for _, sdkKey := range []string{"", "..", "../sdk_key", `dir\sdk_key`} {
+		assert.Error(t, cache.Save(sdkKey, []byte(`{}`)), sdkKey)
+		_, _, ok := cache.Lookup(sdkKey)
+		assert.False(t, ok, sdkKey)
+	}
+}
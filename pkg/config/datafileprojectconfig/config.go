@@ -20,6 +20,7 @@ package datafileprojectconfig
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/optimizely/go-sdk/pkg/config/datafileprojectconfig/mappers"
 	"github.com/optimizely/go-sdk/pkg/entities"
@@ -44,10 +45,14 @@ type DatafileProjectConfig struct {
 	attributeKeyToIDMap  map[string]string
 	experimentMap        map[string]entities.Experiment
 	featureMap           map[string]entities.Feature
+	featureExperimentMap map[string][]string // feature key to the keys of its experiments
+	experimentFeatureMap map[string]string   // experiment key to the key of its feature
+	experimentVariations map[string][]string // experiment key to the IDs of its variations
 	groupMap             map[string]entities.Group
 	rolloutMap           map[string]entities.Rollout
 	anonymizeIP          bool
 	botFiltering         bool
+	region               string
 }
 
 // GetProjectID returns projectID
@@ -60,6 +65,11 @@ func (c DatafileProjectConfig) GetRevision() string {
 	return c.revision
 }
 
+// GetRegion returns the region events for the project are dispatched to
+func (c DatafileProjectConfig) GetRegion() string {
+	return c.region
+}
+
 // GetAccountID returns accountID
 func (c DatafileProjectConfig) GetAccountID() string {
 	return c.accountID
@@ -98,6 +108,48 @@ func (c DatafileProjectConfig) GetFeatureByKey(featureKey string) (entities.Feat
 	return entities.Feature{}, fmt.Errorf(`feature with key "%s" not found`, featureKey)
 }
 
+// GetFeatureExperimentKeys returns the keys of the experiments of the feature with the given key, in the datafile order
+func (c DatafileProjectConfig) GetFeatureExperimentKeys(featureKey string) ([]string, error) {
+	if experimentKeys, ok := c.featureExperimentMap[featureKey]; ok {
+		return append([]string{}, experimentKeys...), nil
+	}
+
+	return nil, fmt.Errorf(`feature with key "%s" not found`, featureKey)
+}
+
+// GetExperimentFeatureKey returns the key of the feature the experiment with the given key is a feature test of
+func (c DatafileProjectConfig) GetExperimentFeatureKey(experimentKey string) (string, error) {
+	if featureKey, ok := c.experimentFeatureMap[experimentKey]; ok {
+		return featureKey, nil
+	}
+
+	return "", fmt.Errorf(`experiment with key "%s" is not a feature test`, experimentKey)
+}
+
+// GetVariations returns all the variations of the experiment with the given key in the datafile order, with the values
+// of the feature variables they override. The variations are copies, changing them does not affect the config.
+func (c DatafileProjectConfig) GetVariations(experimentKey string) ([]entities.Variation, error) {
+	experiment, err := c.GetExperimentByKey(experimentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	variations := make([]entities.Variation, 0, len(experiment.Variations))
+	for _, variationID := range c.experimentVariations[experimentKey] {
+		variation, ok := experiment.Variations[variationID]
+		if !ok {
+			continue
+		}
+		variables := make(map[string]entities.VariationVariable, len(variation.Variables))
+		for id, variable := range variation.Variables {
+			variables[id] = variable
+		}
+		variation.Variables = variables
+		variations = append(variations, variation)
+	}
+	return variations, nil
+}
+
 // GetVariableByKey returns the featureVariable with the given key
 func (c DatafileProjectConfig) GetVariableByKey(featureKey, variableKey string) (entities.Variable, error) {
 
@@ -154,6 +206,18 @@ func (c DatafileProjectConfig) GetAudienceMap() map[string]entities.Audience {
 	return c.audienceMap
 }
 
+// GetAudiences returns all the audiences with their condition trees, sorted by ID
+func (c DatafileProjectConfig) GetAudiences() []entities.Audience {
+	audiences := make([]entities.Audience, 0, len(c.audienceMap))
+	for _, audience := range c.audienceMap {
+		audiences = append(audiences, audience)
+	}
+	sort.Slice(audiences, func(i, j int) bool {
+		return audiences[i].ID < audiences[j].ID
+	})
+	return audiences
+}
+
 // GetExperimentByKey returns the experiment with the given key
 func (c DatafileProjectConfig) GetExperimentByKey(experimentKey string) (entities.Experiment, error) {
 	if experimentID, ok := c.experimentKeyToIDMap[experimentKey]; ok {
@@ -165,6 +229,22 @@ func (c DatafileProjectConfig) GetExperimentByKey(experimentKey string) (entitie
 	return entities.Experiment{}, fmt.Errorf(`experiment with key "%s" not found`, experimentKey)
 }
 
+// GetVariationByKey returns the variation with the given key of the experiment with the given key
+func (c DatafileProjectConfig) GetVariationByKey(experimentKey, variationKey string) (entities.Variation, error) {
+	experiment, err := c.GetExperimentByKey(experimentKey)
+	if err != nil {
+		return entities.Variation{}, err
+	}
+
+	for _, variation := range experiment.Variations {
+		if variation.Key == variationKey {
+			return variation, nil
+		}
+	}
+
+	return entities.Variation{}, fmt.Errorf(`variation with key "%s" not found in experiment "%s"`, variationKey, experimentKey)
+}
+
 // GetGroupByID returns the group with the given ID
 func (c DatafileProjectConfig) GetGroupByID(groupID string) (entities.Group, error) {
 	if group, ok := c.groupMap[groupID]; ok {
@@ -197,6 +277,7 @@ func NewDatafileProjectConfig(jsonDatafile []byte) (*DatafileProjectConfig, erro
 	eventMap := mappers.MapEvents(datafile.Events)
 	mergedAudiences := append(datafile.TypedAudiences, datafile.Audiences...)
 	featureMap := mappers.MapFeatures(datafile.FeatureFlags, rolloutMap, experimentMap)
+	featureExperimentMap, experimentFeatureMap := mappers.MapFeatureExperiments(datafile.FeatureFlags, experimentMap)
 	config := &DatafileProjectConfig{
 		accountID:            datafile.AccountID,
 		anonymizeIP:          datafile.AnonymizeIP,
@@ -204,11 +285,15 @@ func NewDatafileProjectConfig(jsonDatafile []byte) (*DatafileProjectConfig, erro
 		audienceMap:          mappers.MapAudiences(mergedAudiences),
 		attributeMap:         attributeMap,
 		botFiltering:         datafile.BotFiltering,
+		region:               datafile.Region,
 		experimentKeyToIDMap: experimentKeyMap,
 		experimentMap:        experimentMap,
 		groupMap:             groupMap,
 		eventMap:             eventMap,
 		featureMap:           featureMap,
+		featureExperimentMap: featureExperimentMap,
+		experimentFeatureMap: experimentFeatureMap,
+		experimentVariations: mappers.MapExperimentVariations(allExperiments),
 		projectID:            datafile.ProjectID,
 		revision:             datafile.Revision,
 		rolloutMap:           rolloutMap,
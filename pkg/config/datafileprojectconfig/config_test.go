@@ -106,6 +106,15 @@ func TestGetBotFiltering(t *testing.T) {
 	assert.Equal(t, botFiltering, config.GetBotFiltering())
 }
 
+func TestGetRegion(t *testing.T) {
+	region := "EU"
+	config := &DatafileProjectConfig{
+		region: region,
+	}
+
+	assert.Equal(t, region, config.GetRegion())
+}
+
 func TestGetEventByKey(t *testing.T) {
 	key := "key"
 	event := entities.Event{
@@ -154,6 +163,59 @@ func TestGetFeatureByKeyWithError(t *testing.T) {
 	}
 }
 
+func TestGetFeatureExperimentKeys(t *testing.T) {
+	config := &DatafileProjectConfig{
+		featureExperimentMap: map[string][]string{"feature": {"experiment_1", "experiment_2"}},
+	}
+
+	actual, err := config.GetFeatureExperimentKeys("feature")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"experiment_1", "experiment_2"}, actual)
+
+	_, err = config.GetFeatureExperimentKeys("missing_feature")
+	assert.Equal(t, fmt.Errorf(`feature with key "missing_feature" not found`), err)
+}
+
+func TestGetExperimentFeatureKey(t *testing.T) {
+	config := &DatafileProjectConfig{
+		experimentFeatureMap: map[string]string{"experiment_1": "feature"},
+	}
+
+	actual, err := config.GetExperimentFeatureKey("experiment_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "feature", actual)
+
+	_, err = config.GetExperimentFeatureKey("experiment_3")
+	assert.Equal(t, fmt.Errorf(`experiment with key "experiment_3" is not a feature test`), err)
+}
+
+func TestGetVariations(t *testing.T) {
+	variation1 := entities.Variation{ID: "2", Key: "variation_1",
+		Variables: map[string]entities.VariationVariable{"var": {ID: "var", Value: "1"}}}
+	variation2 := entities.Variation{ID: "1", Key: "variation_2", Variables: map[string]entities.VariationVariable{}}
+	experiment := entities.Experiment{
+		ID:         "3",
+		Key:        "experiment_1",
+		Variations: map[string]entities.Variation{"1": variation2, "2": variation1},
+	}
+	config := &DatafileProjectConfig{
+		experimentKeyToIDMap: map[string]string{"experiment_1": "3"},
+		experimentMap:        map[string]entities.Experiment{"3": experiment},
+		experimentVariations: map[string][]string{"experiment_1": {"2", "1"}},
+	}
+
+	actual, err := config.GetVariations("experiment_1")
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.Variation{variation1, variation2}, actual)
+
+	// the variations are copies
+	actual[0].Variables["var"] = entities.VariationVariable{ID: "var", Value: "2"}
+	assert.Equal(t, "1", config.experimentMap["3"].Variations["2"].Variables["var"].Value)
+
+	_, err = config.GetVariations("experiment_2")
+	assert.Equal(t, fmt.Errorf(`experiment with key "experiment_2" not found`), err)
+}
+
 func TestGetVariableByKey(t *testing.T) {
 	featureKey := "featureKey"
 	variableKey := "variable"
@@ -329,6 +391,22 @@ func TestGetAudienceMap(t *testing.T) {
 	assert.Equal(t, audienceMap, config.GetAudienceMap())
 }
 
+func TestGetAudiences(t *testing.T) {
+	conditionTree := &entities.TreeNode{Operator: "or", Nodes: []*entities.TreeNode{{Item: entities.Condition{Name: "country"}}}}
+	config := &DatafileProjectConfig{
+		audienceMap: map[string]entities.Audience{
+			"2": {ID: "2", Name: "second"},
+			"1": {ID: "1", Name: "first", ConditionTree: conditionTree},
+		},
+	}
+
+	assert.Equal(t, []entities.Audience{
+		{ID: "1", Name: "first", ConditionTree: conditionTree},
+		{ID: "2", Name: "second"},
+	}, config.GetAudiences())
+	assert.Empty(t, (&DatafileProjectConfig{}).GetAudiences())
+}
+
 func TestGetExperimentByKey(t *testing.T) {
 	id := "id"
 	key := "key"
@@ -375,6 +453,33 @@ func TestGetExperimentByKeyMissingIDError(t *testing.T) {
 	}
 }
 
+func TestGetVariationByKey(t *testing.T) {
+	variation := entities.Variation{ID: "variationID", Key: "variationKey"}
+	experiment := entities.Experiment{
+		Key:        "experimentKey",
+		Variations: map[string]entities.Variation{variation.ID: variation},
+	}
+
+	config := &DatafileProjectConfig{
+		experimentKeyToIDMap: map[string]string{experiment.Key: "experimentID"},
+		experimentMap:        map[string]entities.Experiment{"experimentID": experiment},
+	}
+
+	actual, err := config.GetVariationByKey("experimentKey", "variationKey")
+	assert.Nil(t, err)
+	assert.Equal(t, variation, actual)
+
+	_, err = config.GetVariationByKey("experimentKey", "missing")
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf(`variation with key "missing" not found in experiment "experimentKey"`), err)
+	}
+
+	_, err = config.GetVariationByKey("missing", "variationKey")
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Errorf(`experiment with key "missing" not found`), err)
+	}
+}
+
 func TestGetGroupByID(t *testing.T) {
 	id := "id"
 	group := entities.Group{
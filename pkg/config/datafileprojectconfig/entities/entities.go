@@ -116,6 +116,7 @@ type Datafile struct {
 	Variables      []string      `json:"variables"`
 	AccountID      string        `json:"accountId"`
 	ProjectID      string        `json:"projectId"`
+	Region         string        `json:"region"`
 	Revision       string        `json:"revision"`
 	Version        string        `json:"version"`
 	AnonymizeIP    bool          `json:"anonymizeIP"`
@@ -18,10 +18,15 @@
 package mappers
 
 import (
+	"fmt"
+
 	datafileEntities "github.com/optimizely/go-sdk/pkg/config/datafileprojectconfig/entities"
 	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/logging"
 )
 
+var logger = logging.GetLogger("DatafileMappers")
+
 // MapExperiments maps the raw experiments entities from the datafile to SDK Experiment entities and also returns a map of experiment key to experiment ID
 func MapExperiments(rawExperiments []datafileEntities.Experiment, experimentGroupMap map[string]string) (experimentMap map[string]entities.Experiment, experimentKeyMap map[string]string) {
 
@@ -38,6 +43,19 @@ func MapExperiments(rawExperiments []datafileEntities.Experiment, experimentGrou
 	return experimentMap, experimentKeyMap
 }
 
+// MapExperimentVariations maps the keys of the raw experiments to the IDs of their variations in the datafile order
+func MapExperimentVariations(rawExperiments []datafileEntities.Experiment) map[string][]string {
+	experimentVariationMap := make(map[string][]string)
+	for _, rawExperiment := range rawExperiments {
+		variationIDs := make([]string, 0, len(rawExperiment.Variations))
+		for _, rawVariation := range rawExperiment.Variations {
+			variationIDs = append(variationIDs, rawVariation.ID)
+		}
+		experimentVariationMap[rawExperiment.Key] = variationIDs
+	}
+	return experimentVariationMap
+}
+
 // Maps the raw variation entity from the datafile to an SDK Variation entity
 func mapVariation(rawVariation datafileEntities.Variation) entities.Variation {
 
@@ -90,6 +108,7 @@ func mapExperiment(rawExperiment datafileEntities.Experiment) entities.Experimen
 		AudienceConditionTree: audienceConditionTree,
 		Whitelist:             rawExperiment.ForcedVariations,
 		IsFeatureExperiment:   false,
+		Status:                entities.ExperimentStatus(rawExperiment.Status),
 	}
 
 	for _, variation := range rawExperiment.Variations {
@@ -99,6 +118,12 @@ func mapExperiment(rawExperiment datafileEntities.Experiment) entities.Experimen
 
 	for i, allocation := range rawExperiment.TrafficAllocation {
 		experiment.TrafficAllocation[i] = entities.Range(allocation)
+		if _, ok := experiment.Variations[allocation.EntityID]; !ok && allocation.EntityID != "" {
+			// the range is kept without its entity so that the ranges of the other variations are unchanged
+			logger.Warning(fmt.Sprintf(`The traffic allocation of experiment "%s" references the undefined variation "%s", no user is bucketed into its range`,
+				rawExperiment.Key, allocation.EntityID))
+			experiment.TrafficAllocation[i].EntityID = ""
+		}
 	}
 
 	return experiment
@@ -30,6 +30,7 @@ func TestMapExperiments(t *testing.T) {
 		"audienceIds": ["31111"],
 		"id": "11111",
 		"key": "test_experiment_11111",
+		"status": "Paused",
 		"variations": [
 			{
 				"id": "21111",
@@ -74,6 +75,7 @@ func TestMapExperiments(t *testing.T) {
 			ID:          "11111",
 			GroupID:     "15",
 			Key:         "test_experiment_11111",
+			Status:      entities.ExperimentPaused,
 			Variations: map[string]entities.Variation{
 				"21111": {
 					ID:             "21111",
@@ -162,6 +164,47 @@ func TestMapExperimentsWithStringAudienceCondition(t *testing.T) {
 	assert.Equal(t, expectedExperimentKeyMap, experimentKeyMap)
 }
 
+func TestMapExperimentsWithUndefinedTrafficAllocationVariation(t *testing.T) {
+
+	rawExperiment := datafileEntities.Experiment{
+		ID:  "11111",
+		Key: "test_experiment_11111",
+		Variations: []datafileEntities.Variation{
+			{ID: "21111", Key: "variation_1"},
+		},
+		TrafficAllocation: []datafileEntities.TrafficAllocation{
+			{EntityID: "21111", EndOfRange: 5000},
+			{EntityID: "21112", EndOfRange: 10000},
+		},
+	}
+
+	experiments, _ := MapExperiments([]datafileEntities.Experiment{rawExperiment}, map[string]string{})
+	expectedTrafficAllocation := []entities.Range{
+		{EntityID: "21111", EndOfRange: 5000},
+		{EntityID: "", EndOfRange: 10000},
+	}
+	assert.Equal(t, expectedTrafficAllocation, experiments["11111"].TrafficAllocation)
+}
+
+func TestMapExperimentVariations(t *testing.T) {
+	rawExperiments := []datafileEntities.Experiment{
+		{
+			Key: "test_experiment_11111",
+			Variations: []datafileEntities.Variation{
+				{ID: "21112", Key: "variation_2"},
+				{ID: "21111", Key: "variation_1"},
+			},
+		},
+		{Key: "test_experiment_11112"},
+	}
+
+	expected := map[string][]string{
+		"test_experiment_11111": {"21112", "21111"},
+		"test_experiment_11112": {},
+	}
+	assert.Equal(t, expected, MapExperimentVariations(rawExperiments))
+}
+
 func TestMergeExperiments(t *testing.T) {
 
 	rawExperiment := datafileEntities.Experiment{
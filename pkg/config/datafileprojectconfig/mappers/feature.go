@@ -59,3 +59,25 @@ func MapFeatures(featureFlags []datafileEntities.FeatureFlag, rolloutMap map[str
 	}
 	return featureMap
 }
+
+// MapFeatureExperiments maps the keys of the raw datafile feature flags to the keys of their experiments in the datafile
+// order, and the keys of the experiments to the key of the feature flag they belong to, the first one listing them
+func MapFeatureExperiments(featureFlags []datafileEntities.FeatureFlag, experimentMap map[string]entities.Experiment,
+) (featureExperimentKeysMap map[string][]string, experimentFeatureKeyMap map[string]string) {
+
+	featureExperimentKeysMap = make(map[string][]string)
+	experimentFeatureKeyMap = make(map[string]string)
+	for _, featureFlag := range featureFlags {
+		experimentKeys := []string{}
+		for _, experimentID := range featureFlag.ExperimentIDs {
+			if experiment, ok := experimentMap[experimentID]; ok {
+				experimentKeys = append(experimentKeys, experiment.Key)
+				if _, ok := experimentFeatureKeyMap[experiment.Key]; !ok {
+					experimentFeatureKeyMap[experiment.Key] = featureFlag.Key
+				}
+			}
+		}
+		featureExperimentKeysMap[featureFlag.Key] = experimentKeys
+	}
+	return featureExperimentKeysMap, experimentFeatureKeyMap
+}
@@ -76,3 +76,28 @@ func TestMapFeatures(t *testing.T) {
 	assert.Equal(t, expectedFeatureMap, featureMap)
 	assert.Equal(t, expectedExperimentMap, experimentMap)
 }
+
+func TestMapFeatureExperiments(t *testing.T) {
+	featureFlags := []datafileEntities.FeatureFlag{
+		{Key: "feature_1", ExperimentIDs: []string{"11112", "11111", "missing"}},
+		{Key: "feature_2", ExperimentIDs: []string{"11111"}},
+		{Key: "feature_3", ExperimentIDs: []string{}},
+	}
+	experimentMap := map[string]entities.Experiment{
+		"11111": {ID: "11111", Key: "experiment_1"},
+		"11112": {ID: "11112", Key: "experiment_2"},
+		"11113": {ID: "11113", Key: "experiment_3"},
+	}
+
+	featureExperimentKeysMap, experimentFeatureKeyMap := MapFeatureExperiments(featureFlags, experimentMap)
+	assert.Equal(t, map[string][]string{
+		"feature_1": {"experiment_2", "experiment_1"},
+		"feature_2": {"experiment_1"},
+		"feature_3": {},
+	}, featureExperimentKeysMap)
+	// an experiment of several features belongs to the first one
+	assert.Equal(t, map[string]string{
+		"experiment_1": "feature_1",
+		"experiment_2": "feature_1",
+	}, experimentFeatureKeyMap)
+}
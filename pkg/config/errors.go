@@ -0,0 +1,60 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import "fmt"
+
+// ErrDatafileFetch is returned when the datafile could not be requested, e.g. on a network failure
+type ErrDatafileFetch struct {
+	URL string
+	Err error
+}
+
+func (e *ErrDatafileFetch) Error() string {
+	return fmt.Sprintf(`unable to fetch datafile from "%s": %s`, e.URL, e.Err)
+}
+
+// Unwrap returns the underlying request error
+func (e *ErrDatafileFetch) Unwrap() error {
+	return e.Err
+}
+
+// ErrDatafileStatus is returned when the datafile request was answered with an error status code
+type ErrDatafileStatus struct {
+	URL  string
+	Code int
+}
+
+func (e *ErrDatafileStatus) Error() string {
+	return fmt.Sprintf(`unable to fetch datafile from "%s", status code: %d`, e.URL, e.Code)
+}
+
+// ErrDatafileParse is returned when the fetched datafile is not a valid datafile
+type ErrDatafileParse struct {
+	URL string
+	Err error
+}
+
+func (e *ErrDatafileParse) Error() string {
+	return fmt.Sprintf(`unable to parse datafile from "%s": %s`, e.URL, e.Err)
+}
+
+// Unwrap returns the underlying parse error
+func (e *ErrDatafileParse) Unwrap() error {
+	return e.Err
+}
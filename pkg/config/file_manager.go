@@ -0,0 +1,225 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/optimizely/go-sdk/pkg/config/datafileprojectconfig"
+	"github.com/optimizely/go-sdk/pkg/logging"
+	"github.com/optimizely/go-sdk/pkg/notification"
+	"github.com/optimizely/go-sdk/pkg/utils"
+)
+
+// DefaultFileCheckInterval is the interval the datafile of the file config manager is checked for changes at
+const DefaultFileCheckInterval = time.Second
+
+// DefaultFileDebounce is how long the datafile has to stay unchanged before it is reloaded
+const DefaultFileDebounce = 200 * time.Millisecond
+
+var fmLogger = logging.GetLogger("FileConfigManager")
+
+// FileProjectConfigManager maintains the project config of a datafile on disk, which it reloads when the file changes,
+// e.g. for local development with live datafile edits. The file is checked by modification time and size, and reloaded
+// once they stayed the same for the debounce period so that a file being written is not read halfway.
+type FileProjectConfigManager struct {
+	path               string
+	checkInterval      time.Duration
+	debounce           time.Duration
+	clock              utils.Clock
+	notificationCenter notification.Center
+
+	loadedState  fileState // the state of the file the current config was loaded from
+	pendingState fileState // the state of the changed file, reloaded once unchanged for the debounce period
+	pendingSince time.Time
+
+	configLock       sync.RWMutex
+	projectConfig    ProjectConfig
+	optimizelyConfig *OptimizelyConfig
+}
+
+// fileState identifies a version of the datafile
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// FileOptionFunc is used to provide custom configuration to the FileProjectConfigManager.
+type FileOptionFunc func(*FileProjectConfigManager)
+
+// WithFileCheckInterval is an optional function, sets the interval the datafile is checked for changes at
+func WithFileCheckInterval(interval time.Duration) FileOptionFunc {
+	return func(m *FileProjectConfigManager) {
+		m.checkInterval = interval
+	}
+}
+
+// WithFileDebounce is an optional function, sets how long the datafile has to stay unchanged before it is reloaded
+func WithFileDebounce(debounce time.Duration) FileOptionFunc {
+	return func(m *FileProjectConfigManager) {
+		m.debounce = debounce
+	}
+}
+
+// WithFileClock is an optional function, sets a passed clock used to schedule the checks
+func WithFileClock(clock utils.Clock) FileOptionFunc {
+	return func(m *FileProjectConfigManager) {
+		m.clock = clock
+	}
+}
+
+// NewFileProjectConfigManager returns a config manager for the datafile at the given path, which is loaded at once. The
+// file is watched for changes once the manager is started.
+func NewFileProjectConfigManager(path string, options ...FileOptionFunc) (*FileProjectConfigManager, error) {
+	fileManager := &FileProjectConfigManager{
+		path:               path,
+		checkInterval:      DefaultFileCheckInterval,
+		debounce:           DefaultFileDebounce,
+		clock:              utils.DefaultClock{},
+		notificationCenter: notification.NewNotificationCenter(),
+	}
+	for _, opt := range options {
+		opt(fileManager)
+	}
+
+	state, err := fileManager.stat()
+	if err != nil {
+		return nil, err
+	}
+	projectConfig, err := fileManager.load()
+	if err != nil {
+		return nil, err
+	}
+	fileManager.projectConfig = projectConfig
+	fileManager.loadedState = state
+	return fileManager, nil
+}
+
+// Start checks the datafile for changes at the check interval until the context is done
+func (m *FileProjectConfigManager) Start(ctx context.Context) {
+	fmLogger.Debug(fmt.Sprintf("Watching the datafile %s", m.path))
+	t := m.clock.NewTicker(m.checkInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C():
+			m.checkFile()
+		case <-ctx.Done():
+			fmLogger.Debug(fmt.Sprintf("Stopped watching the datafile %s", m.path))
+			return
+		}
+	}
+}
+
+// checkFile reloads the datafile when it changed and stayed unchanged since for the debounce period
+func (m *FileProjectConfigManager) checkFile() {
+	state, err := m.stat()
+	if err != nil {
+		fmLogger.Warning(fmt.Sprintf("Unable to check the datafile %s: %s", m.path, err))
+		return
+	}
+	if state == m.loadedState {
+		m.pendingState = fileState{}
+		return
+	}
+	now := m.clock.Now()
+	if state != m.pendingState {
+		m.pendingState = state
+		m.pendingSince = now
+	}
+	if now.Sub(m.pendingSince) < m.debounce {
+		return
+	}
+
+	projectConfig, err := m.load()
+	if err != nil {
+		// the invalid datafile is not loaded again until it changes
+		fmLogger.Warning(fmt.Sprintf("Unable to reload the datafile %s: %s", m.path, err))
+		m.loadedState = state
+		return
+	}
+	m.loadedState = state
+
+	m.configLock.Lock()
+	previousRevision := m.projectConfig.GetRevision()
+	m.projectConfig = projectConfig
+	m.optimizelyConfig = nil
+	m.configLock.Unlock()
+
+	fmLogger.Info(fmt.Sprintf("Datafile %s reloaded with revision: %s. Old revision: %s", m.path, projectConfig.GetRevision(), previousRevision))
+	projectConfigUpdateNotification := notification.ProjectConfigUpdateNotification{
+		Type:     notification.ProjectConfigUpdate,
+		Revision: projectConfig.GetRevision(),
+	}
+	if err = m.notificationCenter.Send(notification.ProjectConfigUpdate, projectConfigUpdateNotification); err != nil {
+		fmLogger.Warning("Problem with sending notification")
+	}
+}
+
+func (m *FileProjectConfigManager) stat() (fileState, error) {
+	info, err := os.Stat(m.path)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+func (m *FileProjectConfigManager) load() (ProjectConfig, error) {
+	datafile, err := ioutil.ReadFile(m.path)
+	if err != nil {
+		return nil, err
+	}
+	return datafileprojectconfig.NewDatafileProjectConfig(datafile)
+}
+
+// GetConfig returns the project config
+func (m *FileProjectConfigManager) GetConfig() (ProjectConfig, error) {
+	m.configLock.RLock()
+	defer m.configLock.RUnlock()
+	return m.projectConfig, nil
+}
+
+// GetOptimizelyConfig returns the optimizely project config
+func (m *FileProjectConfigManager) GetOptimizelyConfig() *OptimizelyConfig {
+	m.configLock.Lock()
+	defer m.configLock.Unlock()
+	if m.optimizelyConfig == nil {
+		m.optimizelyConfig = NewOptimizelyConfig(m.projectConfig)
+	}
+	return m.optimizelyConfig
+}
+
+// OnProjectConfigUpdate registers a handler for the ProjectConfigUpdate notifications sent when the datafile is reloaded
+func (m *FileProjectConfigManager) OnProjectConfigUpdate(callback func(notification.ProjectConfigUpdateNotification)) (int, error) {
+	handler := func(payload interface{}) {
+		if projectConfigUpdateNotification, ok := payload.(notification.ProjectConfigUpdateNotification); ok {
+			callback(projectConfigUpdateNotification)
+		}
+	}
+	return m.notificationCenter.AddHandler(notification.ProjectConfigUpdate, handler)
+}
+
+// RemoveOnProjectConfigUpdate removes handler for ProjectConfigUpdate notification with given id
+func (m *FileProjectConfigManager) RemoveOnProjectConfigUpdate(id int) error {
+	return m.notificationCenter.RemoveHandler(id, notification.ProjectConfigUpdate)
+}
@@ -0,0 +1,171 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/optimizely/go-sdk/pkg/notification"
+	"github.com/optimizely/go-sdk/pkg/utils/utilstest"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeDatafile(t *testing.T, path, datafile string, modTime time.Time) {
+	assert.NoError(t, ioutil.WriteFile(path, []byte(datafile), 0600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestFileProjectConfigManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "datafile.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeDatafile(t, path, `{"revision":"42","version":"4"}`, modTime)
+
+	clock := utilstest.NewClock()
+	clock.Set(time.Now())
+	configManager, err := NewFileProjectConfigManager(path, WithFileClock(clock), WithFileDebounce(time.Second))
+	assert.NoError(t, err)
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+	assert.Equal(t, "42", configManager.GetOptimizelyConfig().Revision)
+
+	var revisions []string
+	_, err = configManager.OnProjectConfigUpdate(func(n notification.ProjectConfigUpdateNotification) {
+		revisions = append(revisions, n.Revision)
+	})
+	assert.NoError(t, err)
+
+	// unchanged
+	configManager.checkFile()
+	assert.Empty(t, revisions)
+
+	// changed twice in a row, reloaded only once unchanged for the debounce period
+	writeDatafile(t, path, `{"revision":"43","version":"4"}`, modTime.Add(time.Minute))
+	configManager.checkFile()
+	clock.Advance(500 * time.Millisecond)
+	writeDatafile(t, path, `{"revision":"440","version":"4"}`, modTime.Add(2*time.Minute))
+	configManager.checkFile()
+	clock.Advance(500 * time.Millisecond)
+	configManager.checkFile()
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+	assert.Empty(t, revisions)
+
+	clock.Advance(500 * time.Millisecond)
+	configManager.checkFile()
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "440", actual.GetRevision())
+	assert.Equal(t, "440", configManager.GetOptimizelyConfig().Revision)
+	assert.Equal(t, []string{"440"}, revisions)
+
+	configManager.checkFile()
+	assert.Equal(t, []string{"440"}, revisions)
+}
+
+func TestFileProjectConfigManagerInvalidChange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "datafile.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeDatafile(t, path, `{"revision":"42","version":"4"}`, modTime)
+
+	configManager, err := NewFileProjectConfigManager(path, WithFileDebounce(0))
+	assert.NoError(t, err)
+
+	writeDatafile(t, path, `{"revision":`, modTime.Add(time.Minute))
+	configManager.checkFile()
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+
+	assert.NoError(t, os.Remove(path))
+	configManager.checkFile()
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+
+	writeDatafile(t, path, `{"revision":"43","version":"4"}`, modTime.Add(2*time.Minute))
+	configManager.checkFile()
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+}
+
+func TestFileProjectConfigManagerStart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "datafile.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeDatafile(t, path, `{"revision":"42","version":"4"}`, modTime)
+
+	clock := utilstest.NewClock()
+	configManager, err := NewFileProjectConfigManager(path, WithFileClock(clock), WithFileDebounce(0))
+	assert.NoError(t, err)
+	var _ StartableProjectConfigManager = configManager
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		configManager.Start(ctx)
+		close(done)
+	}()
+
+	writeDatafile(t, path, `{"revision":"43","version":"4"}`, modTime.Add(time.Minute))
+	clock.Tick()
+	clock.Tick() // the first tick is handled once the second one is received
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+
+	cancel()
+	<-done
+}
+
+func TestNewFileProjectConfigManagerError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = NewFileProjectConfigManager(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "invalid.json")
+	writeDatafile(t, path, `{"revision":`, time.Now())
+	_, err = NewFileProjectConfigManager(path)
+	assert.Error(t, err)
+}
+
+func TestNewStaticProjectConfigManagerFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "datafile.json")
+	writeDatafile(t, path, `{"revision":"42","version":"4"}`, time.Now())
+
+	configManager, err := NewStaticProjectConfigManagerFromFile(path)
+	assert.NoError(t, err)
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+
+	_, err = NewStaticProjectConfigManagerFromFile(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
@@ -18,6 +18,8 @@
 package config
 
 import (
+	"context"
+
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/notification"
 )
@@ -42,6 +44,42 @@ type ProjectConfig interface {
 	GetRevision() string
 }
 
+// AudiencesProjectConfig is a ProjectConfig which also lists all the audiences of the project, such as the
+// DatafileProjectConfig
+type AudiencesProjectConfig interface {
+	ProjectConfig
+	GetAudiences() []entities.Audience
+}
+
+// FeatureExperimentsProjectConfig is a ProjectConfig which also looks up the experiments of a feature and the feature
+// of an experiment, such as the DatafileProjectConfig
+type FeatureExperimentsProjectConfig interface {
+	ProjectConfig
+	GetFeatureExperimentKeys(featureKey string) ([]string, error)
+	GetExperimentFeatureKey(experimentKey string) (string, error)
+}
+
+// RegionProjectConfig is a ProjectConfig which also provides the region the events of the project are dispatched to,
+// such as the DatafileProjectConfig
+type RegionProjectConfig interface {
+	ProjectConfig
+	GetRegion() string
+}
+
+// VariationByKeyProjectConfig is a ProjectConfig which also looks up the variations of an experiment by key, such as
+// the DatafileProjectConfig
+type VariationByKeyProjectConfig interface {
+	ProjectConfig
+	GetVariationByKey(experimentKey string, variationKey string) (entities.Variation, error)
+}
+
+// VariationsProjectConfig is a ProjectConfig which also lists all the variations of an experiment, such as the
+// DatafileProjectConfig
+type VariationsProjectConfig interface {
+	ProjectConfig
+	GetVariations(experimentKey string) ([]entities.Variation, error)
+}
+
 // ProjectConfigManager maintains an instance of the ProjectConfig
 type ProjectConfigManager interface {
 	GetConfig() (ProjectConfig, error)
@@ -49,3 +87,10 @@ type ProjectConfigManager interface {
 	RemoveOnProjectConfigUpdate(id int) error
 	OnProjectConfigUpdate(callback func(notification.ProjectConfigUpdateNotification)) (int, error)
 }
+
+// StartableProjectConfigManager is a ProjectConfigManager which updates the config in the background, such as the
+// PollingProjectConfigManager. It is started by the client factory within the client execution context.
+type StartableProjectConfigManager interface {
+	ProjectConfigManager
+	Start(ctx context.Context)
+}
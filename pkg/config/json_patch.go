@@ -0,0 +1,275 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonPatchOperation is an operation of a JSON patch as defined by RFC 6902
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch returns the JSON document with the operations of the JSON patch applied in order, the document is left
+// untouched if any operation fails
+func applyJSONPatch(document, patch []byte) ([]byte, error) {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("invalid JSON patch: %v", err)
+	}
+
+	root, err := decodeJSON(document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON document: %v", err)
+	}
+
+	for i, operation := range operations {
+		if root, err = applyJSONPatchOperation(root, operation); err != nil {
+			return nil, fmt.Errorf(`unable to apply JSON patch operation %d "%s" at "%s": %v`, i, operation.Op, operation.Path, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+func applyJSONPatchOperation(root interface{}, operation jsonPatchOperation) (interface{}, error) {
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+		value, err := decodeJSON(operation.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch operation.Op {
+		case "add":
+			return addJSONValue(root, operation.Path, value)
+		case "replace":
+			if root, _, err = removeJSONValue(root, operation.Path); err != nil {
+				return nil, err
+			}
+			return addJSONValue(root, operation.Path, value)
+		default:
+			current, err := getJSONValue(root, operation.Path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("value does not match")
+			}
+			return root, nil
+		}
+	case "remove":
+		root, _, err := removeJSONValue(root, operation.Path)
+		return root, err
+	case "move":
+		if strings.HasPrefix(operation.Path, operation.From+"/") {
+			return nil, fmt.Errorf("cannot move a value into one of its children")
+		}
+		root, value, err := removeJSONValue(root, operation.From)
+		if err != nil {
+			return nil, err
+		}
+		return addJSONValue(root, operation.Path, value)
+	case "copy":
+		value, err := getJSONValue(root, operation.From)
+		if err != nil {
+			return nil, err
+		}
+		// the copied value must not share its maps and slices with the original one
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = decodeJSON(encoded); err != nil {
+			return nil, err
+		}
+		return addJSONValue(root, operation.Path, value)
+	}
+	return nil, fmt.Errorf("unknown operation")
+}
+
+// decodeJSON decodes the JSON value keeping its numbers as they are
+func decodeJSON(data []byte) (value interface{}, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&value)
+	return value, err
+}
+
+// parseJSONPointer returns the reference tokens of the JSON pointer as defined by RFC 6901
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf(`invalid JSON pointer "%s"`, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// arrayIndex returns the index of the array an element is referenced at, the length of the array is allowed when
+// adding an element
+func arrayIndex(token string, length int, adding bool) (int, error) {
+	if adding && token == "-" {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > length || (index == length && !adding) || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf(`invalid array index "%s"`, token)
+	}
+	return index, nil
+}
+
+func getJSONValue(root interface{}, pointer string) (interface{}, error) {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	value := root
+	for _, token := range tokens {
+		switch container := value.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if value, ok = container[token]; !ok {
+				return nil, fmt.Errorf(`member "%s" not found`, token)
+			}
+		case []interface{}:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			value = container[index]
+		default:
+			return nil, fmt.Errorf(`cannot reference "%s" in a scalar value`, token)
+		}
+	}
+	return value, nil
+}
+
+// updateJSONValue returns the value with the parent of the child referenced by the tokens replaced by the result of the
+// update function, which is given the parent and the last token. The value extracted by the function is also returned.
+func updateJSONValue(value interface{}, tokens []string,
+	update func(parent interface{}, token string) (interface{}, interface{}, error)) (interface{}, interface{}, error) {
+	if len(tokens) == 1 {
+		return update(value, tokens[0])
+	}
+
+	token := tokens[0]
+	switch container := value.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		if !ok {
+			return nil, nil, fmt.Errorf(`member "%s" not found`, token)
+		}
+		updated, extracted, err := updateJSONValue(child, tokens[1:], update)
+		if err != nil {
+			return nil, nil, err
+		}
+		container[token] = updated
+		return container, extracted, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(container), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		updated, extracted, err := updateJSONValue(container[index], tokens[1:], update)
+		if err != nil {
+			return nil, nil, err
+		}
+		container[index] = updated
+		return container, extracted, nil
+	}
+	return nil, nil, fmt.Errorf(`cannot reference "%s" in a scalar value`, token)
+}
+
+// addJSONValue returns the root with the value added at the pointer, an empty pointer replaces the root
+func addJSONValue(root interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	root, _, err = updateJSONValue(root, tokens, func(parent interface{}, token string) (interface{}, interface{}, error) {
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			container[token] = value
+			return container, nil, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(container), true)
+			if err != nil {
+				return nil, nil, err
+			}
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil, nil
+		}
+		return nil, nil, fmt.Errorf(`cannot add "%s" to a scalar value`, token)
+	})
+	return root, err
+}
+
+// removeJSONValue returns the root without the value at the pointer along with the removed value
+func removeJSONValue(root interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, root, nil
+	}
+
+	return updateJSONValue(root, tokens, func(parent interface{}, token string) (interface{}, interface{}, error) {
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, nil, fmt.Errorf(`member "%s" not found`, token)
+			}
+			delete(container, token)
+			return container, value, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, nil, err
+			}
+			value := container[index]
+			return append(container[:index], container[index+1:]...), value, nil
+		}
+		return nil, nil, fmt.Errorf(`cannot remove "%s" from a scalar value`, token)
+	})
+}
@@ -0,0 +1,82 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	document := `{"revision":"1","experiments":[{"id":"1"},{"id":"2"}],"events":{"a":1},"key~/":true}`
+
+	scenarios := []struct {
+		name     string
+		patch    string
+		expected string
+	}{
+		{"add member", `[{"op":"add","path":"/botFiltering","value":true}]`,
+			`{"revision":"1","experiments":[{"id":"1"},{"id":"2"}],"events":{"a":1},"key~/":true,"botFiltering":true}`},
+		{"add element", `[{"op":"add","path":"/experiments/1","value":{"id":"3"}}]`,
+			`{"revision":"1","experiments":[{"id":"1"},{"id":"3"},{"id":"2"}],"events":{"a":1},"key~/":true}`},
+		{"append element", `[{"op":"add","path":"/experiments/-","value":{"id":"3"}}]`,
+			`{"revision":"1","experiments":[{"id":"1"},{"id":"2"},{"id":"3"}],"events":{"a":1},"key~/":true}`},
+		{"remove element", `[{"op":"remove","path":"/experiments/0"}]`,
+			`{"revision":"1","experiments":[{"id":"2"}],"events":{"a":1},"key~/":true}`},
+		{"replace with escaped pointer", `[{"op":"replace","path":"/key~0~1","value":false}]`,
+			`{"revision":"1","experiments":[{"id":"1"},{"id":"2"}],"events":{"a":1},"key~/":false}`},
+		{"move", `[{"op":"move","from":"/events/a","path":"/events/b"}]`,
+			`{"revision":"1","experiments":[{"id":"1"},{"id":"2"}],"events":{"b":1},"key~/":true}`},
+		{"copy", `[{"op":"copy","from":"/experiments/0","path":"/experiments/-"},{"op":"replace","path":"/experiments/2/id","value":"3"}]`,
+			`{"revision":"1","experiments":[{"id":"1"},{"id":"2"},{"id":"3"}],"events":{"a":1},"key~/":true}`},
+		{"test and replace", `[{"op":"test","path":"/revision","value":"1"},{"op":"replace","path":"/revision","value":"2"}]`,
+			`{"revision":"2","experiments":[{"id":"1"},{"id":"2"}],"events":{"a":1},"key~/":true}`},
+	}
+
+	for _, scenario := range scenarios {
+		actual, err := applyJSONPatch([]byte(document), []byte(scenario.patch))
+		if assert.NoError(t, err, scenario.name) {
+			assert.JSONEq(t, scenario.expected, string(actual), scenario.name)
+		}
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	document := []byte(`{"revision":"1","experiments":[{"id":"1"}]}`)
+
+	patches := []string{
+		`{"op":"add"}`,
+		`[{"op":"unknown","path":"/revision"}]`,
+		`[{"op":"add","path":"/revision"}]`,
+		`[{"op":"remove","path":"/missing"}]`,
+		`[{"op":"replace","path":"/experiments/1","value":{}}]`,
+		`[{"op":"add","path":"/experiments/01","value":{}}]`,
+		`[{"op":"add","path":"revision","value":"2"}]`,
+		`[{"op":"add","path":"/revision/key","value":"2"}]`,
+		`[{"op":"test","path":"/revision","value":"2"}]`,
+		`[{"op":"move","from":"/experiments","path":"/experiments/0/children"}]`,
+	}
+
+	for _, patch := range patches {
+		_, err := applyJSONPatch(document, []byte(patch))
+		assert.Error(t, err, patch)
+	}
+
+	_, err := applyJSONPatch([]byte("invalid"), []byte("[]"))
+	assert.Error(t, err)
+}
@@ -17,19 +17,33 @@
 // Package config //
 package config
 
-import "github.com/optimizely/go-sdk/pkg/entities"
+import (
+	"encoding/json"
 
-// OptimizelyConfig is a snapshot of the experiments and features in the project config
+	"github.com/optimizely/go-sdk/pkg/entities"
+)
+
+// OptimizelyConfig is a snapshot of the experiments and features in the project config. Its JSON field names are stable
+// so a snapshot marshaled by one process can be unmarshaled and compared by another.
 type OptimizelyConfig struct {
 	Revision       string                          `json:"revision"`
 	ExperimentsMap map[string]OptimizelyExperiment `json:"experimentsMap"`
 	FeaturesMap    map[string]OptimizelyFeature    `json:"featuresMap"`
+	AudiencesMap   map[string]OptimizelyAudience   `json:"audiencesMap"`
+}
+
+// OptimizelyAudience has audience info, its conditions are serialized in the JSON format of the datafile
+type OptimizelyAudience struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Conditions string `json:"conditions"`
 }
 
-// OptimizelyExperiment has experiment info
+// OptimizelyExperiment has experiment info, the feature key is set for the feature tests only
 type OptimizelyExperiment struct {
 	ID            string                         `json:"id"`
 	Key           string                         `json:"key"`
+	FeatureKey    string                         `json:"featureKey,omitempty"`
 	VariationsMap map[string]OptimizelyVariation `json:"variationsMap"`
 }
 
@@ -142,6 +156,37 @@ func getFeatureMap(features []entities.Feature, experimentsMap map[string]Optimi
 	return optlyFeatureMap
 }
 
+func getAudienceMap(audiences []entities.Audience) (optlyAudienceMap map[string]OptimizelyAudience) {
+	optlyAudienceMap = map[string]OptimizelyAudience{}
+	for _, audience := range audiences {
+		optlyAudience := OptimizelyAudience{ID: audience.ID, Name: audience.Name}
+		if audience.ConditionTree != nil {
+			if conditions, err := json.Marshal(serializeConditionTree(audience.ConditionTree)); err == nil {
+				optlyAudience.Conditions = string(conditions)
+			}
+		}
+		optlyAudienceMap[audience.ID] = optlyAudience
+	}
+	return optlyAudienceMap
+}
+
+// serializeConditionTree returns the condition tree in the nested array format of the datafile, with the operator of
+// each node ahead of its children
+func serializeConditionTree(node *entities.TreeNode) interface{} {
+	if node.Item != nil {
+		return node.Item
+	}
+
+	serializedNode := []interface{}{}
+	if node.Operator != "" {
+		serializedNode = append(serializedNode, node.Operator)
+	}
+	for _, child := range node.Nodes {
+		serializedNode = append(serializedNode, serializeConditionTree(child))
+	}
+	return serializedNode
+}
+
 // NewOptimizelyConfig constructs OptimizelyConfig object
 func NewOptimizelyConfig(projConfig ProjectConfig) *OptimizelyConfig {
 
@@ -157,7 +202,20 @@ func NewOptimizelyConfig(projConfig ProjectConfig) *OptimizelyConfig {
 	variableByIDMap := getVariableByIDMap(featuresList)
 
 	optimizelyConfig.ExperimentsMap = getExperimentMap(featuresList, experimentsList, variableByIDMap)
+	if featureExperimentsConfig, ok := projConfig.(FeatureExperimentsProjectConfig); ok {
+		for key, experiment := range optimizelyConfig.ExperimentsMap {
+			if featureKey, err := featureExperimentsConfig.GetExperimentFeatureKey(key); err == nil {
+				experiment.FeatureKey = featureKey
+				optimizelyConfig.ExperimentsMap[key] = experiment
+			}
+		}
+	}
 	optimizelyConfig.FeaturesMap = getFeatureMap(featuresList, optimizelyConfig.ExperimentsMap)
+	var audiences []entities.Audience
+	if audiencesConfig, ok := projConfig.(AudiencesProjectConfig); ok {
+		audiences = audiencesConfig.GetAudiences()
+	}
+	optimizelyConfig.AudiencesMap = getAudienceMap(audiences)
 	optimizelyConfig.Revision = revision
 
 	return optimizelyConfig
@@ -0,0 +1,71 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package config //
+package config
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/optimizely/go-sdk/pkg/notification"
+)
+
+// NewOptimizelyConfigDiff compares the previous and current OptimizelyConfig, a nil config is treated as empty
+func NewOptimizelyConfigDiff(previous, current *OptimizelyConfig) notification.ConfigDiff {
+	if previous == nil {
+		previous = &OptimizelyConfig{}
+	}
+	if current == nil {
+		current = &OptimizelyConfig{}
+	}
+
+	diff := notification.ConfigDiff{}
+
+	for key, experiment := range current.ExperimentsMap {
+		if previousExperiment, ok := previous.ExperimentsMap[key]; !ok {
+			diff.AddedExperiments = append(diff.AddedExperiments, key)
+		} else if !reflect.DeepEqual(previousExperiment, experiment) {
+			diff.ModifiedExperiments = append(diff.ModifiedExperiments, key)
+		}
+	}
+	for key := range previous.ExperimentsMap {
+		if _, ok := current.ExperimentsMap[key]; !ok {
+			diff.RemovedExperiments = append(diff.RemovedExperiments, key)
+		}
+	}
+
+	for key, feature := range current.FeaturesMap {
+		if previousFeature, ok := previous.FeaturesMap[key]; !ok {
+			diff.AddedFeatures = append(diff.AddedFeatures, key)
+		} else if !reflect.DeepEqual(previousFeature, feature) {
+			diff.ModifiedFeatures = append(diff.ModifiedFeatures, key)
+		}
+	}
+	for key := range previous.FeaturesMap {
+		if _, ok := current.FeaturesMap[key]; !ok {
+			diff.RemovedFeatures = append(diff.RemovedFeatures, key)
+		}
+	}
+
+	// map iteration order is random, sort to keep the diff stable
+	for _, keys := range [][]string{diff.AddedExperiments, diff.RemovedExperiments, diff.ModifiedExperiments,
+		diff.AddedFeatures, diff.RemovedFeatures, diff.ModifiedFeatures} {
+		sort.Strings(keys)
+	}
+
+	return diff
+}
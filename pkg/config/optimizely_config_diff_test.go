@@ -0,0 +1,92 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/optimizely/go-sdk/pkg/notification"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOptimizelyConfigDiff(t *testing.T) {
+	previous := &OptimizelyConfig{
+		Revision: "1",
+		ExperimentsMap: map[string]OptimizelyExperiment{
+			"exp_unchanged": {ID: "1", Key: "exp_unchanged"},
+			"exp_modified":  {ID: "2", Key: "exp_modified"},
+			"exp_removed":   {ID: "3", Key: "exp_removed"},
+		},
+		FeaturesMap: map[string]OptimizelyFeature{
+			"feat_modified": {ID: "10", Key: "feat_modified", VariablesMap: map[string]OptimizelyVariable{
+				"var": {ID: "100", Key: "var", Type: "string", Value: "before"},
+			}},
+			"feat_removed": {ID: "11", Key: "feat_removed"},
+		},
+	}
+	current := &OptimizelyConfig{
+		Revision: "2",
+		ExperimentsMap: map[string]OptimizelyExperiment{
+			"exp_unchanged": {ID: "1", Key: "exp_unchanged"},
+			"exp_modified": {ID: "2", Key: "exp_modified", VariationsMap: map[string]OptimizelyVariation{
+				"var_a": {ID: "20", Key: "var_a"},
+			}},
+			"exp_added_b": {ID: "5", Key: "exp_added_b"},
+			"exp_added_a": {ID: "4", Key: "exp_added_a"},
+		},
+		FeaturesMap: map[string]OptimizelyFeature{
+			"feat_modified": {ID: "10", Key: "feat_modified", VariablesMap: map[string]OptimizelyVariable{
+				"var": {ID: "100", Key: "var", Type: "string", Value: "after"},
+			}},
+			"feat_added": {ID: "12", Key: "feat_added"},
+		},
+	}
+
+	diff := NewOptimizelyConfigDiff(previous, current)
+	assert.True(t, diff.HasChanges())
+	assert.Equal(t, []string{"exp_added_a", "exp_added_b"}, diff.AddedExperiments)
+	assert.Equal(t, []string{"exp_removed"}, diff.RemovedExperiments)
+	assert.Equal(t, []string{"exp_modified"}, diff.ModifiedExperiments)
+	assert.Equal(t, []string{"feat_added"}, diff.AddedFeatures)
+	assert.Equal(t, []string{"feat_removed"}, diff.RemovedFeatures)
+	assert.Equal(t, []string{"feat_modified"}, diff.ModifiedFeatures)
+}
+
+func TestNewOptimizelyConfigDiffNoChanges(t *testing.T) {
+	previous := &OptimizelyConfig{
+		Revision:       "1",
+		ExperimentsMap: map[string]OptimizelyExperiment{"exp": {ID: "1", Key: "exp"}},
+	}
+	current := &OptimizelyConfig{
+		Revision:       "2",
+		ExperimentsMap: map[string]OptimizelyExperiment{"exp": {ID: "1", Key: "exp"}},
+	}
+
+	diff := NewOptimizelyConfigDiff(previous, current)
+	assert.False(t, diff.HasChanges())
+	assert.Equal(t, notification.ConfigDiff{}, diff)
+}
+
+func TestNewOptimizelyConfigDiffNilConfigs(t *testing.T) {
+	current := &OptimizelyConfig{
+		FeaturesMap: map[string]OptimizelyFeature{"feat": {ID: "1", Key: "feat"}},
+	}
+
+	assert.Equal(t, notification.ConfigDiff{AddedFeatures: []string{"feat"}}, NewOptimizelyConfigDiff(nil, current))
+	assert.Equal(t, notification.ConfigDiff{RemovedFeatures: []string{"feat"}}, NewOptimizelyConfigDiff(current, nil))
+	assert.False(t, NewOptimizelyConfigDiff(nil, nil).HasChanges())
+}
@@ -70,6 +70,43 @@ func (s *OptimizelyConfigTestSuite) TestOptlyConfig() {
 
 }
 
+func (s *OptimizelyConfigTestSuite) TestOptlyConfigJSONRoundTrip() {
+	optimizelyConfig := NewOptimizelyConfig(s.projectConfig)
+
+	snapshot, err := json.Marshal(optimizelyConfig)
+	s.NoError(err)
+
+	loadedConfig := OptimizelyConfig{}
+	s.NoError(json.Unmarshal(snapshot, &loadedConfig))
+	s.Equal(*optimizelyConfig, loadedConfig)
+	s.False(NewOptimizelyConfigDiff(optimizelyConfig, &loadedConfig).HasChanges())
+
+	reloadedSnapshot, err := json.Marshal(loadedConfig)
+	s.NoError(err)
+	s.Equal(string(snapshot), string(reloadedSnapshot))
+
+	fields := map[string]interface{}{}
+	s.NoError(json.Unmarshal(snapshot, &fields))
+	s.Contains(fields, "revision")
+	s.Contains(fields, "experimentsMap")
+	s.Contains(fields, "featuresMap")
+}
+
+func (s *OptimizelyConfigTestSuite) TestOptlyConfigAudiences() {
+	datafile := []byte(`{"version":"4","revision":"1","audiences":[` +
+		`{"id":"41","name":"us","conditions":"[\"and\",[\"or\",{\"type\":\"custom_attribute\",\"name\":\"country\",\"match\":\"exact\",\"value\":\"us\"}]]"},` +
+		`{"id":"42","name":"everyone","conditions":"[]"}]}`)
+	projectMgr, err := NewStaticProjectConfigManagerFromPayload(datafile)
+	s.NoError(err)
+
+	optimizelyConfig := NewOptimizelyConfig(projectMgr.projectConfig)
+	s.Equal(map[string]OptimizelyAudience{
+		"41": {ID: "41", Name: "us",
+			Conditions: `["and",["or",{"name":"country","match":"exact","type":"custom_attribute","value":"us"}]]`},
+		"42": {ID: "42", Name: "everyone"},
+	}, optimizelyConfig.AudiencesMap)
+}
+
 func (s *OptimizelyConfigTestSuite) TestOptlyConfigNullProjectConfig() {
 	optimizelyConfig := NewOptimizelyConfig(nil)
 
@@ -19,6 +19,7 @@ package config
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"net/http"
 	"sync"
@@ -45,6 +46,21 @@ const LastModified = "Last-Modified"
 // DatafileURLTemplate is used to construct the endpoint for retrieving the datafile from the CDN
 const DatafileURLTemplate = "https://cdn.optimizely.com/datafiles/%s.json"
 
+// regionalDatafileURLTemplates holds the datafile URL template for each supported data residency region, the CDN
+// currently serves the datafiles of all regions
+var regionalDatafileURLTemplates = map[string]string{
+	"US": DatafileURLTemplate,
+	"EU": DatafileURLTemplate,
+}
+
+// GetDatafileURLTemplate returns the datafile URL template for the given region, defaulting to DatafileURLTemplate
+func GetDatafileURLTemplate(region string) string {
+	if datafileURLTemplate, ok := regionalDatafileURLTemplates[region]; ok {
+		return datafileURLTemplate
+	}
+	return DatafileURLTemplate
+}
+
 // Err403Forbidden is 403Forbidden specific error
 var Err403Forbidden = errors.New("unable to fetch fresh datafile (consider rechecking SDK key), status code: 403 Forbidden")
 
@@ -54,11 +70,19 @@ var cmLogger = logging.GetLogger("PollingConfigManager")
 // from the Optimizely CDN at a given (configurable) interval.
 type PollingProjectConfigManager struct {
 	datafileURLTemplate string
+	deltaURLTemplate    string
 	initDatafile        []byte
 	lastModified        string
 	notificationCenter  notification.Center
 	pollingInterval     time.Duration
 	requester           utils.Requester
+	clock               utils.Clock
+	configDiffEnabled   bool
+	contentComparison   bool
+	datafileHash        [sha256.Size]byte
+	datafile            []byte // the datafile of the current project config when datafile deltas are enabled
+	datafileCache       DatafileCache
+	datafileCacheTTL    time.Duration
 	sdkKey              string
 
 	configLock       sync.RWMutex
@@ -84,6 +108,15 @@ func WithDatafileURLTemplate(datafileTemplate string) OptionFunc {
 	}
 }
 
+// WithDatafileDeltaURLTemplate is an optional function, sets the template of the URL of the JSON patch (RFC 6902) turning
+// the current datafile into the latest one, it is formatted with the SDK key and the current revision. The full datafile
+// is fetched when there is no current datafile yet, or when the delta can't be fetched or applied.
+func WithDatafileDeltaURLTemplate(deltaTemplate string) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.deltaURLTemplate = deltaTemplate
+	}
+}
+
 // WithPollingInterval is an optional function, sets a passed polling interval
 func WithPollingInterval(interval time.Duration) OptionFunc {
 	return func(p *PollingProjectConfigManager) {
@@ -91,6 +124,36 @@ func WithPollingInterval(interval time.Duration) OptionFunc {
 	}
 }
 
+// WithClock is an optional function, sets a passed clock used to schedule polling
+func WithClock(clock utils.Clock) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.clock = clock
+	}
+}
+
+// WithConfigDiff is an optional function, includes the diff of the configs in ProjectConfigUpdate notifications
+func WithConfigDiff() OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.configDiffEnabled = true
+	}
+}
+
+// WithContentComparison is an optional function, compares the content hash of fetched datafiles in addition to their
+// revision so that a changed datafile with an unchanged revision still updates the config
+func WithContentComparison() OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.contentComparison = true
+	}
+}
+
+// WithNotificationCenter is an optional function, sets the notification center ProjectConfigUpdate notifications are
+// sent to, in place of the one registered for the SDK key
+func WithNotificationCenter(notificationCenter notification.Center) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.notificationCenter = notificationCenter
+	}
+}
+
 // WithInitialDatafile is an optional function, sets a passed datafile
 func WithInitialDatafile(datafile []byte) OptionFunc {
 	return func(p *PollingProjectConfigManager) {
@@ -98,6 +161,17 @@ func WithInitialDatafile(datafile []byte) OptionFunc {
 	}
 }
 
+// WithDatafileCache is an optional function, sets the cache the fetched datafiles are saved to. At start, a cached
+// datafile stored less than the TTL ago is used in place of the initial fetch, a stale one is only used when the initial
+// fetch fails, e.g. during a CDN outage, and a zero TTL always fetches the datafile first. The async manager, which does
+// not fetch at start, uses the cached datafile whatever its age. The datafile is fetched again on the next poll.
+func WithDatafileCache(cache DatafileCache, ttl time.Duration) OptionFunc {
+	return func(p *PollingProjectConfigManager) {
+		p.datafileCache = cache
+		p.datafileCacheTTL = ttl
+	}
+}
+
 // SyncConfig downloads datafile and updates projectConfig
 func (cm *PollingProjectConfigManager) SyncConfig() {
 	var e error
@@ -111,7 +185,9 @@ func (cm *PollingProjectConfigManager) SyncConfig() {
 	}
 
 	url := fmt.Sprintf(cm.datafileURLTemplate, cm.sdkKey)
-	if cm.lastModified != "" {
+	if deltaDatafile, deltaCode, ok := cm.fetchDatafileDelta(); ok {
+		datafile, respHeaders, code = deltaDatafile, http.Header{}, deltaCode
+	} else if cm.lastModified != "" {
 		lastModifiedHeader := utils.Header{Name: ModifiedSince, Value: cm.lastModified}
 		datafile, respHeaders, code, e = cm.requester.Get(url, lastModifiedHeader)
 	} else {
@@ -151,30 +227,82 @@ func (cm *PollingProjectConfigManager) SyncConfig() {
 		return
 	}
 
+	previousConfig := cm.projectConfig
 	var previousRevision string
 	if cm.projectConfig != nil {
 		previousRevision = cm.projectConfig.GetRevision()
 	}
+	datafileHash := sha256.Sum256(datafile)
 	if projectConfig.GetRevision() == previousRevision {
-		cmLogger.Debug(fmt.Sprintf("No datafile updates. Current revision number: %s", cm.projectConfig.GetRevision()))
-		closeMutex(nil)
-		return
+		if !cm.contentComparison || datafileHash == cm.datafileHash {
+			cmLogger.Debug(fmt.Sprintf("No datafile updates. Current revision number: %s", cm.projectConfig.GetRevision()))
+			closeMutex(nil)
+			return
+		}
+		cmLogger.Warning(fmt.Sprintf("Datafile content changed without a revision change. Current revision number: %s", previousRevision))
 	}
 	err = cm.setConfig(projectConfig)
+	if err == nil {
+		cm.datafileHash = datafileHash
+		cm.setDatafile(datafile)
+	}
 	closeMutex(err)
 	if err == nil {
 		cmLogger.Debug(fmt.Sprintf("New datafile set with revision: %s. Old revision: %s", projectConfig.GetRevision(), previousRevision))
-		cm.sendConfigUpdateNotification()
+		cm.saveCachedDatafile(datafile)
+		cm.sendConfigUpdateNotification(previousConfig, projectConfig)
+	}
+}
+
+// fetchDatafileDelta returns the latest datafile built by applying the datafile delta to the current datafile along
+// with the status code of the delta request, it returns false when the full datafile has to be fetched instead
+func (cm *PollingProjectConfigManager) fetchDatafileDelta() (datafile []byte, code int, ok bool) {
+	if cm.deltaURLTemplate == "" {
+		return nil, 0, false
+	}
+
+	cm.configLock.RLock()
+	currentDatafile := cm.datafile
+	var revision string
+	if cm.projectConfig != nil {
+		revision = cm.projectConfig.GetRevision()
+	}
+	cm.configLock.RUnlock()
+	if len(currentDatafile) == 0 {
+		return nil, 0, false
+	}
+
+	patch, _, code, err := cm.requester.Get(fmt.Sprintf(cm.deltaURLTemplate, cm.sdkKey, revision))
+	if err != nil {
+		cmLogger.Debug(fmt.Sprintf("Unable to fetch datafile delta, fetching the full datafile: %s", err))
+		return nil, code, false
+	}
+	if code == http.StatusNotModified {
+		return nil, code, true
+	}
+
+	if datafile, err = applyJSONPatch(currentDatafile, patch); err != nil {
+		cmLogger.Warning(fmt.Sprintf("Unable to apply datafile delta, fetching the full datafile: %s", err))
+		return nil, code, false
+	}
+	return datafile, http.StatusOK, true
+}
+
+// setDatafile keeps the datafile of the current project config to apply the datafile deltas to
+func (cm *PollingProjectConfigManager) setDatafile(datafile []byte) {
+	if cm.deltaURLTemplate != "" {
+		cm.datafile = datafile
 	}
 }
 
 // Start starts the polling
 func (cm *PollingProjectConfigManager) Start(ctx context.Context) {
 	cmLogger.Debug("Polling Config Manager Initiated")
-	t := time.NewTicker(cm.pollingInterval)
+	t := cm.clock.NewTicker(cm.pollingInterval)
+	defer t.Stop()
 	for {
 		select {
-		case <-t.C:
+		case <-t.C():
 			cm.SyncConfig()
 		case <-ctx.Done():
 			cmLogger.Debug("Polling Config Manager Stopped")
@@ -190,6 +318,7 @@ func NewPollingProjectConfigManager(sdkKey string, pollingMangerOptions ...Optio
 		notificationCenter:  registry.GetNotificationCenter(sdkKey),
 		pollingInterval:     DefaultPollingInterval,
 		requester:           utils.NewHTTPRequester(),
+		clock:               utils.DefaultClock{},
 		datafileURLTemplate: DatafileURLTemplate,
 		sdkKey:              sdkKey,
 	}
@@ -200,8 +329,11 @@ func NewPollingProjectConfigManager(sdkKey string, pollingMangerOptions ...Optio
 
 	if len(pollingProjectConfigManager.initDatafile) > 0 {
 		pollingProjectConfigManager.setInitialDatafile(pollingProjectConfigManager.initDatafile)
-	} else {
+	} else if !pollingProjectConfigManager.useCachedDatafile(false) {
 		pollingProjectConfigManager.SyncConfig() // initial poll
+		if pollingProjectConfigManager.projectConfig == nil {
+			pollingProjectConfigManager.useCachedDatafile(true)
+		}
 	}
 	return &pollingProjectConfigManager
 }
@@ -213,6 +345,7 @@ func NewAsyncPollingProjectConfigManager(sdkKey string, pollingMangerOptions ...
 		notificationCenter:  registry.GetNotificationCenter(sdkKey),
 		pollingInterval:     DefaultPollingInterval,
 		requester:           utils.NewHTTPRequester(),
+		clock:               utils.DefaultClock{},
 		datafileURLTemplate: DatafileURLTemplate,
 		sdkKey:              sdkKey,
 	}
@@ -221,7 +354,11 @@ func NewAsyncPollingProjectConfigManager(sdkKey string, pollingMangerOptions ...
 		opt(&pollingProjectConfigManager)
 	}
 
-	pollingProjectConfigManager.setInitialDatafile(pollingProjectConfigManager.initDatafile)
+	if len(pollingProjectConfigManager.initDatafile) > 0 {
+		pollingProjectConfigManager.setInitialDatafile(pollingProjectConfigManager.initDatafile)
+	} else {
+		pollingProjectConfigManager.useCachedDatafile(true)
+	}
 	return &pollingProjectConfigManager
 }
 
@@ -238,12 +375,18 @@ func (cm *PollingProjectConfigManager) GetConfig() (ProjectConfig, error) {
 // GetOptimizelyConfig returns the optimizely project config
 func (cm *PollingProjectConfigManager) GetOptimizelyConfig() *OptimizelyConfig {
 	cm.configLock.RLock()
-	defer cm.configLock.RUnlock()
-	if cm.optimizelyConfig != nil {
-		return cm.optimizelyConfig
+	optimizelyConfig := cm.optimizelyConfig
+	cm.configLock.RUnlock()
+	if optimizelyConfig != nil {
+		return optimizelyConfig
+	}
+
+	// the optimizely config is built on the first call, which has to be serialized with the concurrent ones
+	cm.configLock.Lock()
+	defer cm.configLock.Unlock()
+	if cm.optimizelyConfig == nil {
+		cm.optimizelyConfig = NewOptimizelyConfig(cm.projectConfig)
 	}
-	optimizelyConfig := NewOptimizelyConfig(cm.projectConfig)
-	cm.optimizelyConfig = optimizelyConfig
 	return cm.optimizelyConfig
 }
 
@@ -290,17 +433,71 @@ func (cm *PollingProjectConfigManager) setInitialDatafile(datafile []byte) {
 		defer cm.configLock.Unlock()
 		projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig(datafile)
 		if projectConfig != nil {
-			err = cm.setConfig(projectConfig)
+			if err = cm.setConfig(projectConfig); err == nil {
+				cm.datafileHash = sha256.Sum256(datafile)
+				cm.setDatafile(datafile)
+			}
 		}
 		cm.err = err
 	}
 }
 
-func (cm *PollingProjectConfigManager) sendConfigUpdateNotification() {
+// useCachedDatafile sets the config from the cached datafile, a stale datafile is only used when staleAllowed, it
+// returns whether the config was set
+func (cm *PollingProjectConfigManager) useCachedDatafile(staleAllowed bool) bool {
+	if cm.datafileCache == nil {
+		return false
+	}
+	datafile, storedAt, ok := cm.datafileCache.Lookup(cm.sdkKey)
+	if !ok {
+		return false
+	}
+	age := cm.clock.Now().Sub(storedAt)
+	stale := age >= cm.datafileCacheTTL
+	if stale && !staleAllowed {
+		cmLogger.Debug(fmt.Sprintf("The cached datafile is stale (stored %s ago), fetching the datafile", age))
+		return false
+	}
+
+	projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig(datafile)
+	if err != nil {
+		cmLogger.Warning(fmt.Sprintf("Unable to use the cached datafile: %s", err))
+		return false
+	}
+	cm.configLock.Lock()
+	defer cm.configLock.Unlock()
+	if err = cm.setConfig(projectConfig); err != nil {
+		return false
+	}
+	cm.datafileHash = sha256.Sum256(datafile)
+	cm.setDatafile(datafile)
+	if stale {
+		cmLogger.Warning(fmt.Sprintf("Using the stale cached datafile (stored %s ago) with revision: %s", age, projectConfig.GetRevision()))
+	} else {
+		cmLogger.Debug(fmt.Sprintf("Using the cached datafile with revision: %s", projectConfig.GetRevision()))
+	}
+	return true
+}
+
+// saveCachedDatafile saves the datafile of the new config to the datafile cache
+func (cm *PollingProjectConfigManager) saveCachedDatafile(datafile []byte) {
+	if cm.datafileCache == nil {
+		return
+	}
+	if err := cm.datafileCache.Save(cm.sdkKey, datafile); err != nil {
+		cmLogger.Warning(fmt.Sprintf("Unable to save the datafile to the cache: %s", err))
+	}
+}
+
+func (cm *PollingProjectConfigManager) sendConfigUpdateNotification(previousConfig, projectConfig ProjectConfig) {
 	if cm.notificationCenter != nil {
 		projectConfigUpdateNotification := notification.ProjectConfigUpdateNotification{
 			Type:     notification.ProjectConfigUpdate,
-			Revision: cm.projectConfig.GetRevision(),
+			Revision: projectConfig.GetRevision(),
+		}
+		if cm.configDiffEnabled {
+			diff := NewOptimizelyConfigDiff(NewOptimizelyConfig(previousConfig), NewOptimizelyConfig(projectConfig))
+			projectConfigUpdateNotification.Diff = &diff
 		}
 		if err := cm.notificationCenter.Send(notification.ProjectConfigUpdate, projectConfigUpdateNotification); err != nil {
 			cmLogger.Warning("Problem with sending notification")
@@ -18,6 +18,7 @@ package config
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"sync/atomic"
 	"testing"
@@ -26,6 +27,7 @@ import (
 	"github.com/optimizely/go-sdk/pkg/config/datafileprojectconfig"
 	"github.com/optimizely/go-sdk/pkg/notification"
 	"github.com/optimizely/go-sdk/pkg/utils"
+	"github.com/optimizely/go-sdk/pkg/utils/utilstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -181,6 +183,91 @@ func TestNewPollingProjectConfigManagerWithSimilarDatafileRevisions(t *testing.T
 	assert.Equal(t, uint64(0), atomic.LoadUint64(&numberOfCalls))
 }
 
+func TestNewPollingProjectConfigManagerWithContentComparison(t *testing.T) {
+	// Test newer datafile should replace the older one if revisions are the same but the content differs
+	mockDatafile1 := []byte(`{"revision":"42","botFiltering":true,"version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"42","botFiltering":false,"version": "4"}`)
+	projectConfig2, _ := datafileprojectconfig.NewDatafileProjectConfig(mockDatafile2)
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, http.Header{}, http.StatusOK, nil)
+
+	sdkKey := "test_sdk_key_content_comparison"
+	configManager := NewPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithInitialDatafile(mockDatafile1), WithContentComparison())
+
+	var numberOfCalls uint64 = 0
+	callback := func(notification notification.ProjectConfigUpdateNotification) {
+		atomic.AddUint64(&numberOfCalls, 1)
+	}
+	id, _ := configManager.OnProjectConfigUpdate(callback)
+	assert.NotEqual(t, 0, id)
+
+	// sync with datafile having similar revision but different content
+	configManager.SyncConfig()
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, projectConfig2, actual)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&numberOfCalls))
+
+	// sync with the same datafile again
+	configManager.SyncConfig()
+	mockRequester.AssertExpectations(t)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&numberOfCalls))
+}
+
+type urlResponse struct {
+	body []byte
+	code int
+}
+
+// urlRequester answers the requests with the response of their URL, or with an error for unknown URLs
+type urlRequester struct {
+	utils.Requester
+	responses map[string]urlResponse
+	requested []string
+}
+
+func (r *urlRequester) Get(uri string, headers ...utils.Header) (response []byte, responseHeaders http.Header, code int, err error) {
+	r.requested = append(r.requested, uri)
+	if urlResponse, ok := r.responses[uri]; ok {
+		return urlResponse.body, http.Header{}, urlResponse.code, nil
+	}
+	return nil, http.Header{}, http.StatusNotFound, errors.New("not found")
+}
+
+func TestNewPollingProjectConfigManagerWithDatafileDelta(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","botFiltering":true,"version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","botFiltering":false,"version": "4"}`)
+	requester := &urlRequester{responses: map[string]urlResponse{
+		"https://delta/test_sdk_key_delta/42.json": {body: []byte(`[{"op":"replace","path":"/revision","value":"43"},{"op":"replace","path":"/botFiltering","value":false}]`), code: http.StatusOK},
+		"https://delta/test_sdk_key_delta/43.json": {body: []byte(`[{"op":"test","path":"/revision","value":"42"}]`), code: http.StatusOK},
+		"https://cdn/test_sdk_key_delta.json":      {body: mockDatafile2, code: http.StatusOK},
+	}}
+
+	configManager := NewPollingProjectConfigManager("test_sdk_key_delta", WithRequester(requester), WithInitialDatafile(mockDatafile1),
+		WithDatafileURLTemplate("https://cdn/%s.json"), WithDatafileDeltaURLTemplate("https://delta/%s/%s.json"))
+
+	// the delta is applied to the current datafile
+	configManager.SyncConfig()
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "43", actual.GetRevision())
+	assert.False(t, actual.GetBotFiltering())
+	assert.Equal(t, []string{"https://delta/test_sdk_key_delta/42.json"}, requester.requested)
+
+	// the full datafile is fetched when the delta can't be applied
+	configManager.SyncConfig()
+	assert.Equal(t, []string{"https://delta/test_sdk_key_delta/42.json", "https://delta/test_sdk_key_delta/43.json",
+		"https://cdn/test_sdk_key_delta.json"}, requester.requested)
+	actual, err = configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "43", actual.GetRevision())
+
+	// and when no delta is available
+	requester.requested = nil
+	delete(requester.responses, "https://delta/test_sdk_key_delta/43.json")
+	configManager.SyncConfig()
+	assert.Equal(t, []string{"https://delta/test_sdk_key_delta/43.json", "https://cdn/test_sdk_key_delta.json"}, requester.requested)
+}
+
 func TestNewAsyncPollingProjectConfigManagerWithSimilarDatafileRevisions(t *testing.T) {
 	// Test newer datafile should not replace the older one if revisions are the same
 	mockDatafile1 := []byte(`{"revision":"42","botFiltering":true,"version": "4"}`)
@@ -440,6 +527,29 @@ func TestNewPollingProjectConfigManagerOnDecision(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestNewPollingProjectConfigManagerOnDecisionWithConfigDiff(t *testing.T) {
+	mockDatafile1 := []byte(`{"revision":"42","version": "4","experiments":[{"id":"1","key":"exp_1","variations":[{"id":"11","key":"var_1"}]}]}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4","experiments":[{"id":"2","key":"exp_2","variations":[{"id":"21","key":"var_1"}]}]}`)
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, http.Header{}, http.StatusOK, nil)
+
+	sdkKey := "test_sdk_key_config_diff"
+	configManager := NewPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithInitialDatafile(mockDatafile1), WithConfigDiff())
+
+	var received notification.ProjectConfigUpdateNotification
+	callback := func(notification notification.ProjectConfigUpdateNotification) {
+		received = notification
+	}
+	id, _ := configManager.OnProjectConfigUpdate(callback)
+
+	configManager.SyncConfig()
+	mockRequester.AssertExpectations(t)
+	assert.Equal(t, "43", received.Revision)
+	assert.Equal(t, &notification.ConfigDiff{AddedExperiments: []string{"exp_2"}, RemovedExperiments: []string{"exp_1"}}, received.Diff)
+	err := configManager.RemoveOnProjectConfigUpdate(id)
+	assert.Nil(t, err)
+}
+
 func TestNewAsyncPollingProjectConfigManagerOnDecision(t *testing.T) {
 	mockDatafile1 := []byte(`{"revision":"42","botFiltering":true,"version": "4"}`)
 	projectConfig1, _ := datafileprojectconfig.NewDatafileProjectConfig(mockDatafile1)
@@ -577,6 +687,33 @@ func TestNewAsyncPollingProjectConfigManagerDoesNotPullImmediately(t *testing.T)
 	assert.Nil(t, config)
 }
 
+func TestPollingOnTick(t *testing.T) {
+
+	mockDatafile1 := []byte(`{"revision":"42","version": "4"}`)
+	mockDatafile2 := []byte(`{"revision":"43","version": "4"}`)
+	sdkKey := "test_sdk_key"
+
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile1, http.Header{}, http.StatusOK, nil).Times(1)
+	clock := utilstest.NewClock()
+	configManager := NewPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithClock(clock))
+
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+
+	mockRequester.On("Get", []utils.Header(nil)).Return(mockDatafile2, http.Header{}, http.StatusOK, nil).Times(2)
+	eg := newExecGroup()
+	eg.Go(configManager.Start)
+
+	// the second tick is only received once the sync for the first one has completed
+	clock.Tick()
+	clock.Tick()
+
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+	eg.TerminateAndWait()
+}
+
 func TestPollingInterval(t *testing.T) {
 
 	sdkKey := "test_sdk_key"
@@ -611,6 +748,12 @@ func TestDatafileTemplate(t *testing.T) {
 	assert.Equal(t, datafileTemplate, asyncConfigManager.datafileURLTemplate)
 }
 
+func TestGetDatafileURLTemplate(t *testing.T) {
+	assert.Equal(t, DatafileURLTemplate, GetDatafileURLTemplate("US"))
+	assert.Equal(t, DatafileURLTemplate, GetDatafileURLTemplate("EU"))
+	assert.Equal(t, DatafileURLTemplate, GetDatafileURLTemplate(""))
+}
+
 func TestWithRequester(t *testing.T) {
 
 	sdkKey := "test_sdk_key"
@@ -623,3 +766,98 @@ func TestWithRequester(t *testing.T) {
 	assert.Equal(t, mockRequester, configManager.requester)
 	assert.Equal(t, mockRequester, asyncConfigManager.requester)
 }
+
+type mapDatafileCache struct {
+	datafiles map[string][]byte
+	storedAt  time.Time
+}
+
+func (c *mapDatafileCache) Lookup(sdkKey string) ([]byte, time.Time, bool) {
+	datafile, ok := c.datafiles[sdkKey]
+	return datafile, c.storedAt, ok
+}
+
+func (c *mapDatafileCache) Save(sdkKey string, datafile []byte) error {
+	c.datafiles[sdkKey] = datafile
+	c.storedAt = time.Now()
+	return nil
+}
+
+func TestDatafileCache(t *testing.T) {
+	cachedDatafile := []byte(`{"revision":"42","version": "4"}`)
+	fetchedDatafile := []byte(`{"revision":"43","version": "4"}`)
+	sdkKey := "test_sdk_key"
+
+	// a fresh cached datafile is used in place of the initial fetch
+	cache := &mapDatafileCache{datafiles: map[string][]byte{sdkKey: cachedDatafile}, storedAt: time.Now().Add(-time.Minute)}
+	mockRequester := new(MockRequester)
+	configManager := NewPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithDatafileCache(cache, time.Hour))
+	mockRequester.AssertNotCalled(t, "Get", []utils.Header(nil))
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+
+	// the next poll fetches the datafile and saves it to the cache
+	mockRequester.On("Get", []utils.Header(nil)).Return(fetchedDatafile, http.Header{}, http.StatusOK, nil)
+	configManager.SyncConfig()
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+	assert.Equal(t, fetchedDatafile, cache.datafiles[sdkKey])
+
+	// a stale cached datafile is fetched again
+	cache = &mapDatafileCache{datafiles: map[string][]byte{sdkKey: cachedDatafile}, storedAt: time.Now().Add(-2 * time.Hour)}
+	configManager = NewPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithDatafileCache(cache, time.Hour))
+	actual, _ = configManager.GetConfig()
+	assert.Equal(t, "43", actual.GetRevision())
+}
+
+func TestDatafileCacheFetchFailure(t *testing.T) {
+	cachedDatafile := []byte(`{"revision":"42","version": "4"}`)
+	sdkKey := "test_sdk_key"
+	mockRequester := new(MockRequester)
+	mockRequester.On("Get", []utils.Header(nil)).Return([]byte{}, http.Header{}, http.StatusServiceUnavailable, errors.New("unavailable"))
+
+	// a stale cached datafile is used when the datafile can't be fetched
+	cache := &mapDatafileCache{datafiles: map[string][]byte{sdkKey: cachedDatafile}, storedAt: time.Now().Add(-48 * time.Hour)}
+	configManager := NewPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithDatafileCache(cache, time.Hour))
+	mockRequester.AssertExpectations(t)
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+
+	// without a cached datafile the fetch error is returned
+	cache = &mapDatafileCache{datafiles: map[string][]byte{}}
+	configManager = NewPollingProjectConfigManager(sdkKey, WithRequester(mockRequester), WithDatafileCache(cache, time.Hour))
+	_, err = configManager.GetConfig()
+	assert.Error(t, err)
+}
+
+func TestAsyncDatafileCache(t *testing.T) {
+	sdkKey := "test_sdk_key"
+	cache := &mapDatafileCache{datafiles: map[string][]byte{sdkKey: []byte(`{"revision":"42","version": "4"}`)},
+		storedAt: time.Now().Add(-48 * time.Hour)}
+
+	configManager := NewAsyncPollingProjectConfigManager(sdkKey, WithDatafileCache(cache, time.Hour))
+	actual, err := configManager.GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "42", actual.GetRevision())
+}
+
+func TestGetOptimizelyConfigConcurrently(t *testing.T) {
+	configManager := NewAsyncPollingProjectConfigManager("test_sdk_key", WithInitialDatafile([]byte(`{"revision":"42","version": "4"}`)))
+
+	// the optimizely config is built by the first of the concurrent calls only
+	start := make(chan struct{})
+	optimizelyConfigs := make(chan *OptimizelyConfig, 8)
+	for i := 0; i < 8; i++ {
+		go func() {
+			<-start
+			optimizelyConfigs <- configManager.GetOptimizelyConfig()
+		}()
+	}
+	close(start)
+	first := <-optimizelyConfigs
+	for i := 1; i < 8; i++ {
+		assert.True(t, first == <-optimizelyConfigs)
+	}
+}
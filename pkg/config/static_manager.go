@@ -20,7 +20,11 @@ package config
 import (
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/optimizely/go-sdk/pkg/config/datafileprojectconfig"
 	"github.com/optimizely/go-sdk/pkg/notification"
@@ -34,19 +38,96 @@ type StaticProjectConfigManager struct {
 	configLock       sync.Mutex
 }
 
+// Default values for retrying the initial datafile fetch
+const (
+	// DefaultStartupAttempts is the number of times the datafile is requested before giving up
+	DefaultStartupAttempts = 3
+	// DefaultStartupBackoff is the delay before the first retry, it doubles on every following retry
+	DefaultStartupBackoff = 500 * time.Millisecond
+)
+
+// StaticOptionFunc is used to provide custom configuration when fetching the datafile for the StaticProjectConfigManager
+type StaticOptionFunc func(*staticFetchOptions)
+
+type staticFetchOptions struct {
+	attempts  int
+	backoff   time.Duration
+	requester utils.Requester
+}
+
+// WithStartupRetry sets the number of attempts made to fetch the datafile and the delay before the first retry, which
+// doubles on every following retry. Only network errors and server errors are retried.
+func WithStartupRetry(attempts int, backoff time.Duration) StaticOptionFunc {
+	return func(o *staticFetchOptions) {
+		o.attempts = attempts
+		o.backoff = backoff
+	}
+}
+
+// WithFetchRequester sets the requester the datafile is fetched with, in place of the default one
+func WithFetchRequester(requester utils.Requester) StaticOptionFunc {
+	return func(o *staticFetchOptions) {
+		o.requester = requester
+	}
+}
+
 // NewStaticProjectConfigManagerFromURL returns new instance of StaticProjectConfigManager for URL
-func NewStaticProjectConfigManagerFromURL(sdkKey string) (*StaticProjectConfigManager, error) {
+func NewStaticProjectConfigManagerFromURL(sdkKey string, opts ...StaticOptionFunc) (*StaticProjectConfigManager, error) {
+	return NewStaticProjectConfigManagerFromURLTemplate(sdkKey, DatafileURLTemplate, opts...)
+}
+
+// NewStaticProjectConfigManagerFromURLTemplate returns new instance of StaticProjectConfigManager for the URL built
+// from the given datafile URL template, which holds a %s placeholder for the SDK key
+func NewStaticProjectConfigManagerFromURLTemplate(sdkKey, datafileURLTemplate string, opts ...StaticOptionFunc) (*StaticProjectConfigManager, error) {
+
+	options := staticFetchOptions{attempts: DefaultStartupAttempts, backoff: DefaultStartupBackoff}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	requester := options.requester
+	if requester == nil {
+		requester = utils.NewHTTPRequester()
+	}
 
-	requester := utils.NewHTTPRequester()
+	url := fmt.Sprintf(datafileURLTemplate, sdkKey)
+	datafile, err := fetchDatafile(requester, url, options)
+	if err != nil {
+		return nil, err
+	}
 
-	url := fmt.Sprintf(DatafileURLTemplate, sdkKey)
-	datafile, _, code, e := requester.Get(url)
+	staticProjectConfigManager, e := NewStaticProjectConfigManagerFromPayload(datafile)
 	if e != nil {
-		cmLogger.Error(fmt.Sprintf("request returned with http code=%d", code), e)
-		return nil, e
+		return nil, &ErrDatafileParse{URL: url, Err: e}
 	}
+	return staticProjectConfigManager, nil
+}
 
-	return NewStaticProjectConfigManagerFromPayload(datafile)
+// fetchDatafile requests the datafile, retrying with backoff on network and server errors
+func fetchDatafile(requester utils.Requester, url string, options staticFetchOptions) (datafile []byte, err error) {
+	backoff := options.backoff
+	for attempt := 1; ; attempt++ {
+		var code int
+		var e error
+		datafile, _, code, e = requester.Get(url)
+		switch {
+		case code >= http.StatusBadRequest:
+			cmLogger.Error(fmt.Sprintf("request returned with http code=%d", code), e)
+			err = &ErrDatafileStatus{URL: url, Code: code}
+		case e != nil:
+			cmLogger.Error(fmt.Sprintf("request returned with http code=%d", code), e)
+			err = &ErrDatafileFetch{URL: url, Err: e}
+		default:
+			return datafile, nil
+		}
+
+		if (code >= http.StatusBadRequest && code < http.StatusInternalServerError) || attempt >= options.attempts {
+			return nil, err
+		}
+		cmLogger.Warning(fmt.Sprintf("failed to fetch datafile, retrying in %v (attempt %d of %d)", backoff, attempt, options.attempts))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
 }
 
 // NewStaticProjectConfigManagerFromPayload returns new instance of StaticProjectConfigManager for payload
@@ -60,6 +141,28 @@ func NewStaticProjectConfigManagerFromPayload(payload []byte) (*StaticProjectCon
 	return NewStaticProjectConfigManager(projectConfig), nil
 }
 
+// NewStaticProjectConfigManagerFromReader returns new instance of StaticProjectConfigManager for the datafile read
+// from the given reader
+func NewStaticProjectConfigManagerFromReader(reader io.Reader) (*StaticProjectConfigManager, error) {
+	payload, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStaticProjectConfigManagerFromPayload(payload)
+}
+
+// NewStaticProjectConfigManagerFromFile returns new instance of StaticProjectConfigManager for the datafile at the given
+// path, see NewFileProjectConfigManager to reload the datafile when it changes
+func NewStaticProjectConfigManagerFromFile(path string) (*StaticProjectConfigManager, error) {
+	payload, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStaticProjectConfigManagerFromPayload(payload)
+}
+
 // NewStaticProjectConfigManager creates a new instance of the manager with the given project config
 func NewStaticProjectConfigManager(config ProjectConfig) *StaticProjectConfigManager {
 	return &StaticProjectConfigManager{
@@ -19,9 +19,15 @@ package config
 import (
 	"errors"
 	"github.com/optimizely/go-sdk/pkg/notification"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/optimizely/go-sdk/pkg/config/datafileprojectconfig"
+	"github.com/optimizely/go-sdk/pkg/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,6 +59,27 @@ func TestNewStaticProjectConfigManagerFromPayload(t *testing.T) {
 	assert.NotNil(t, actual)
 }
 
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestNewStaticProjectConfigManagerFromReader(t *testing.T) {
+	configManager, err := NewStaticProjectConfigManagerFromReader(strings.NewReader(`{"revision":"42","version":"4"}`))
+	assert.NoError(t, err)
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+
+	configManager, err = NewStaticProjectConfigManagerFromReader(strings.NewReader(`{"revision":"42"`))
+	assert.Error(t, err)
+	assert.Nil(t, configManager)
+
+	configManager, err = NewStaticProjectConfigManagerFromReader(failingReader{})
+	assert.EqualError(t, err, "read failed")
+	assert.Nil(t, configManager)
+}
+
 func TestStaticGetOptimizelyConfig(t *testing.T) {
 
 	mockDatafile := []byte(`{"accountId":"42","projectId":"123","version":"4"}`)
@@ -64,7 +91,7 @@ func TestStaticGetOptimizelyConfig(t *testing.T) {
 	optimizelyConfig := configManager.GetOptimizelyConfig()
 	assert.NotNil(t, configManager.optimizelyConfig)
 	assert.Equal(t, &OptimizelyConfig{ExperimentsMap: map[string]OptimizelyExperiment{},
-		FeaturesMap: map[string]OptimizelyFeature{}}, optimizelyConfig)
+		FeaturesMap: map[string]OptimizelyFeature{}, AudiencesMap: map[string]OptimizelyAudience{}}, optimizelyConfig)
 }
 func TestNewStaticProjectConfigManagerFromURL(t *testing.T) {
 
@@ -73,6 +100,109 @@ func TestNewStaticProjectConfigManagerFromURL(t *testing.T) {
 	assert.Nil(t, configManager)
 }
 
+func TestNewStaticProjectConfigManagerFromURLTemplate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/datafiles/test_sdk_key.json" {
+			w.Write([]byte(`{"revision":"42","version":"4"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	configManager, err := NewStaticProjectConfigManagerFromURLTemplate("test_sdk_key", ts.URL+"/datafiles/%s.json")
+	assert.NoError(t, err)
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+
+	configManager, err = NewStaticProjectConfigManagerFromURLTemplate("test_sdk_key", ts.URL+"/missing/%s.json")
+	assert.Error(t, err)
+	assert.Nil(t, configManager)
+}
+
+func TestNewStaticProjectConfigManagerFromURLTemplateUserAgent(t *testing.T) {
+	var userAgents []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		w.Write([]byte(`{"revision":"42","version":"4"}`))
+	}))
+	defer ts.Close()
+
+	_, err := NewStaticProjectConfigManagerFromURLTemplate("test_sdk_key", ts.URL+"/datafiles/%s.json")
+	assert.NoError(t, err)
+	requester := utils.NewHTTPRequester(utils.UserAgentSuffix("app/2.0"))
+	_, err = NewStaticProjectConfigManagerFromURLTemplate("test_sdk_key", ts.URL+"/datafiles/%s.json", WithFetchRequester(requester))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{utils.UserAgent, utils.UserAgent + " app/2.0"}, userAgents)
+}
+
+func TestNewStaticProjectConfigManagerFromURLTemplateErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/malformed/test_sdk_key.json" {
+			w.Write([]byte(`{"revision":"42"`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	_, err := NewStaticProjectConfigManagerFromURLTemplate("test_sdk_key", ts.URL+"/forbidden/%s.json")
+	if statusErr, ok := err.(*ErrDatafileStatus); assert.True(t, ok) {
+		assert.Equal(t, http.StatusForbidden, statusErr.Code)
+		assert.Equal(t, ts.URL+"/forbidden/test_sdk_key.json", statusErr.URL)
+		assert.Contains(t, statusErr.Error(), statusErr.URL)
+	}
+
+	_, err = NewStaticProjectConfigManagerFromURLTemplate("test_sdk_key", ts.URL+"/malformed/%s.json")
+	if parseErr, ok := err.(*ErrDatafileParse); assert.True(t, ok) {
+		assert.Equal(t, ts.URL+"/malformed/test_sdk_key.json", parseErr.URL)
+		assert.Error(t, parseErr.Unwrap())
+	}
+
+	ts.Close()
+	_, err = NewStaticProjectConfigManagerFromURLTemplate("test_sdk_key", ts.URL+"/datafiles/%s.json", WithStartupRetry(1, 0))
+	if fetchErr, ok := err.(*ErrDatafileFetch); assert.True(t, ok) {
+		assert.Equal(t, ts.URL+"/datafiles/test_sdk_key.json", fetchErr.URL)
+		assert.Error(t, fetchErr.Unwrap())
+	}
+}
+
+func TestNewStaticProjectConfigManagerFromURLTemplateRetry(t *testing.T) {
+	var called int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := atomic.AddInt32(&called, 1)
+		switch {
+		case r.URL.Path == "/missing/test_sdk_key.json":
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/unavailable/test_sdk_key.json", attempt < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"revision":"42","version":"4"}`))
+		}
+	}))
+	defer ts.Close()
+
+	configManager, err := NewStaticProjectConfigManagerFromURLTemplate("test_sdk_key", ts.URL+"/datafiles/%s.json", WithStartupRetry(3, time.Millisecond))
+	assert.NoError(t, err)
+	actual, _ := configManager.GetConfig()
+	assert.Equal(t, "42", actual.GetRevision())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&called))
+
+	// client errors are not retried
+	atomic.StoreInt32(&called, 0)
+	_, err = NewStaticProjectConfigManagerFromURLTemplate("test_sdk_key", ts.URL+"/missing/%s.json", WithStartupRetry(3, time.Millisecond))
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&called))
+
+	// gives up once all attempts failed
+	atomic.StoreInt32(&called, 0)
+	_, err = NewStaticProjectConfigManagerFromURLTemplate("test_sdk_key", ts.URL+"/unavailable/%s.json", WithStartupRetry(2, time.Millisecond))
+	if statusErr, ok := err.(*ErrDatafileStatus); assert.True(t, ok) {
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.Code)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&called))
+}
+
 func TestNewStaticProjectConfigManagerOnDecision(t *testing.T) {
 	mockDatafile := []byte(`{"accountId":"42","projectId":"123","version":"4"}`)
 	configManager, err := NewStaticProjectConfigManagerFromPayload(mockDatafile)
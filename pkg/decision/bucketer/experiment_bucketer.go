@@ -18,6 +18,8 @@
 package bucketer
 
 import (
+	"fmt"
+
 	"github.com/optimizely/go-sdk/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/pkg/entities"
 )
@@ -27,6 +29,13 @@ type ExperimentBucketer interface {
 	Bucket(bucketingID string, experiment entities.Experiment, group entities.Group) (*entities.Variation, reasons.Reason, error)
 }
 
+// TracingExperimentBucketer is an ExperimentBucketer which can explain how it buckets a user, the explanation is only
+// computed when asked for so that bucketing itself is not slowed down
+type TracingExperimentBucketer interface {
+	ExperimentBucketer
+	Trace(bucketingID string, experiment entities.Experiment, group entities.Group) []string
+}
+
 // MurmurhashExperimentBucketer buckets the user using the mmh3 algorightm
 type MurmurhashExperimentBucketer struct {
 	bucketer Bucketer
@@ -34,8 +43,14 @@ type MurmurhashExperimentBucketer struct {
 
 // NewMurmurhashExperimentBucketer returns a new instance of the murmurhash experiment bucketer
 func NewMurmurhashExperimentBucketer(hashSeed uint32) *MurmurhashExperimentBucketer {
+	return NewMurmurhashExperimentBucketerWithHashFunc(hashSeed, DefaultHashFunc)
+}
+
+// NewMurmurhashExperimentBucketerWithHashFunc returns a new instance of the murmurhash experiment bucketer which hashes
+// the bucketing keys with the given MurmurHash3 implementation
+func NewMurmurhashExperimentBucketerWithHashFunc(hashSeed uint32, hashFunc HashFunc) *MurmurhashExperimentBucketer {
 	return &MurmurhashExperimentBucketer{
-		bucketer: MurmurhashBucketer{hashSeed: hashSeed},
+		bucketer: *NewMurmurhashBucketerWithHashFunc(hashSeed, hashFunc),
 	}
 }
 
@@ -63,3 +78,43 @@ func (b MurmurhashExperimentBucketer) Bucket(bucketingID string, experiment enti
 
 	return nil, reasons.BucketedVariationNotFound, nil
 }
+
+// Trace explains the bucketing of the user into the given experiment with the bucket values and the traffic allocation
+// ranges they fell in
+func (b MurmurhashExperimentBucketer) Trace(bucketingID string, experiment entities.Experiment, group entities.Group) []string {
+	var trace []string
+	if experiment.GroupID != "" && group.Policy == "random" {
+		bucketValue := b.bucketer.Generate(bucketingID + group.ID)
+		trace = append(trace, traceBucket(bucketValue, group.TrafficAllocation, fmt.Sprintf(`group "%s"`, group.ID),
+			func(entityID string) string {
+				return fmt.Sprintf(`experiment "%s"`, entityID)
+			}))
+	}
+
+	bucketValue := b.bucketer.Generate(bucketingID + experiment.ID)
+	trace = append(trace, traceBucket(bucketValue, experiment.TrafficAllocation, fmt.Sprintf(`experiment "%s"`, experiment.Key),
+		func(entityID string) string {
+			if variation, ok := experiment.Variations[entityID]; ok {
+				return fmt.Sprintf(`variation "%s"`, variation.Key)
+			}
+			return fmt.Sprintf(`unknown variation "%s"`, entityID)
+		}))
+	return trace
+}
+
+// traceBucket describes the traffic allocation range the bucket value falls in and the entity it is allocated to
+func traceBucket(bucketValue int, trafficAllocations []entities.Range, allocationName string, describeEntity func(string) string) string {
+	startOfRange := 0
+	for _, trafficAllocationRange := range trafficAllocations {
+		if bucketValue < trafficAllocationRange.EndOfRange {
+			entity := "no entity"
+			if trafficAllocationRange.EntityID != "" {
+				entity = describeEntity(trafficAllocationRange.EntityID)
+			}
+			return fmt.Sprintf("bucket %d fell in [%d,%d) of %s → %s", bucketValue, startOfRange,
+				trafficAllocationRange.EndOfRange, allocationName, entity)
+		}
+		startOfRange = trafficAllocationRange.EndOfRange
+	}
+	return fmt.Sprintf("bucket %d fell outside of the traffic allocation of %s", bucketValue, allocationName)
+}
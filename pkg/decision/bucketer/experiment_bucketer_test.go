@@ -1,6 +1,7 @@
 package bucketer
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/optimizely/go-sdk/pkg/decision/reasons"
@@ -56,3 +57,40 @@ func TestBucketExclusionGroups(t *testing.T) {
 	assert.Nil(t, bucketedVariation)
 	assert.Equal(t, reasons.NotBucketedIntoVariation, reason)
 }
+
+func TestTraceBucketing(t *testing.T) {
+	experiment := entities.Experiment{
+		ID:      "1886780721",
+		Key:     "experiment_1",
+		GroupID: "1886780722",
+		Variations: map[string]entities.Variation{
+			"22222": {ID: "22222", Key: "exp_1_var_1"},
+		},
+		TrafficAllocation: []entities.Range{
+			{EntityID: "22222", EndOfRange: 10000},
+		},
+	}
+	exclusionGroup := entities.Group{
+		ID:     "1886780722",
+		Policy: "random",
+		TrafficAllocation: []entities.Range{
+			{EntityID: "1886780721", EndOfRange: 2500},
+			{EntityID: "1886780723", EndOfRange: 5000},
+		},
+	}
+
+	bucketer := NewMurmurhashExperimentBucketer(DefaultHashSeed)
+	trace := bucketer.Trace("ppid2", experiment, exclusionGroup)
+	experimentBucket := bucketer.bucketer.Generate("ppid2" + experiment.ID)
+	assert.Equal(t, []string{
+		`bucket 2434 fell in [0,2500) of group "1886780722" → experiment "1886780721"`,
+		fmt.Sprintf(`bucket %d fell in [0,10000) of experiment "experiment_1" → variation "exp_1_var_1"`, experimentBucket),
+	}, trace)
+
+	// buckets out of the traffic allocation are explained as well
+	experiment.GroupID = ""
+	experiment.TrafficAllocation = []entities.Range{{EntityID: "22222", EndOfRange: experimentBucket}}
+	assert.Equal(t, []string{
+		fmt.Sprintf(`bucket %d fell outside of the traffic allocation of experiment "experiment_1"`, experimentBucket),
+	}, bucketer.Trace("ppid2", experiment, entities.Group{}))
+}
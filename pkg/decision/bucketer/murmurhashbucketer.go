@@ -18,17 +18,10 @@
 package bucketer
 
 import (
-	"fmt"
-	"math"
-
 	"github.com/optimizely/go-sdk/pkg/entities"
-	"github.com/optimizely/go-sdk/pkg/logging"
 	"github.com/twmb/murmur3"
 )
 
-var logger = logging.GetLogger("MurmurhashBucketer")
-var maxHashValue = float32(math.Pow(2, 32))
-
 // DefaultHashSeed is the hash seed to use for murmurhash
 const DefaultHashSeed = 1
 const maxTrafficValue = 10000
@@ -39,27 +32,45 @@ type Bucketer interface {
 	BucketToEntity(bucketKey string, trafficAllocations []entities.Range) (entityID string)
 }
 
+// HashFunc returns the 32-bit hash of the data for the given seed. Alternative implementations must return the same
+// hashes as DefaultHashFunc, otherwise users are bucketed differently than in the other SDKs.
+type HashFunc func(seed uint32, data []byte) uint32
+
+// DefaultHashFunc is the canonical MurmurHash3 32-bit implementation
+func DefaultHashFunc(seed uint32, data []byte) uint32 {
+	return murmur3.SeedSum32(seed, data)
+}
+
 // MurmurhashBucketer generates the bucketing value using the mmh3 algorightm
 type MurmurhashBucketer struct {
 	hashSeed uint32
+	hashFunc HashFunc
 }
 
 // NewMurmurhashBucketer returns a new instance of the murmurhash bucketer
 func NewMurmurhashBucketer(hashSeed uint32) *MurmurhashBucketer {
+	return NewMurmurhashBucketerWithHashFunc(hashSeed, DefaultHashFunc)
+}
+
+// NewMurmurhashBucketerWithHashFunc returns a new instance of the murmurhash bucketer which hashes the bucketing keys
+// with the given MurmurHash3 implementation
+func NewMurmurhashBucketerWithHashFunc(hashSeed uint32, hashFunc HashFunc) *MurmurhashBucketer {
 	return &MurmurhashBucketer{
 		hashSeed: hashSeed,
+		hashFunc: hashFunc,
 	}
 }
 
 // Generate returns a bucketing value for bucketing key
 func (b MurmurhashBucketer) Generate(bucketingKey string) int {
-	hasher := murmur3.SeedNew32(b.hashSeed)
-	if _, err := hasher.Write([]byte(bucketingKey)); err != nil {
-		logger.Error(fmt.Sprintf("Unable to generate a hash for the bucketing key=%s", bucketingKey), err)
+	hashFunc := b.hashFunc
+	if hashFunc == nil {
+		hashFunc = DefaultHashFunc
 	}
-	hashCode := hasher.Sum32()
-	ratio := float32(hashCode) / maxHashValue
-	return int(ratio * maxTrafficValue)
+	hashCode := hashFunc(b.hashSeed, []byte(bucketingKey))
+	// scale the hash onto [0, maxTrafficValue) with integer arithmetic, the float32 ratio used to round the highest
+	// hashes up to maxTrafficValue and out of every traffic allocation range
+	return int(uint64(hashCode) * maxTrafficValue >> 32)
 }
 
 // BucketToEntity buckets into a traffic against given bucketKey
@@ -2,10 +2,12 @@ package bucketer
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/stretchr/testify/assert"
+	"github.com/twmb/murmur3"
 )
 
 func TestBucketToEntity(t *testing.T) {
@@ -66,3 +68,85 @@ func TestGenerateBucketValue(t *testing.T) {
 	assert.Equal(t, 2434, bucketer.Generate(bucketingKey3))
 	assert.Equal(t, 5439, bucketer.Generate(bucketingKey4))
 }
+
+// streamingHash is an alternative MurmurHash3 implementation going through the hash.Hash32 interface
+func streamingHash(seed uint32, data []byte) uint32 {
+	hasher := murmur3.SeedNew32(seed)
+	hasher.Write(data)
+	return hasher.Sum32()
+}
+
+func TestGenerateBucketValueWithHashFunc(t *testing.T) {
+	defaultBucketer := NewMurmurhashBucketer(DefaultHashSeed)
+	streamingBucketer := NewMurmurhashBucketerWithHashFunc(DefaultHashSeed, streamingHash)
+
+	for i := 0; i < 1000; i++ {
+		bucketingKey := fmt.Sprintf("ppid%d1886780721", i)
+		assert.Equal(t, defaultBucketer.Generate(bucketingKey), streamingBucketer.Generate(bucketingKey))
+	}
+
+	hashedKeys := []string{}
+	recordingBucketer := NewMurmurhashBucketerWithHashFunc(DefaultHashSeed, func(seed uint32, data []byte) uint32 {
+		hashedKeys = append(hashedKeys, string(data))
+		return DefaultHashFunc(seed, data)
+	})
+	assert.Equal(t, 5254, recordingBucketer.Generate("ppid11886780721"))
+	assert.Equal(t, []string{"ppid11886780721"}, hashedKeys)
+
+	// the zero value falls back to the default hash function
+	assert.Equal(t, 5254, MurmurhashBucketer{hashSeed: DefaultHashSeed}.Generate("ppid11886780721"))
+}
+
+// fixedHashBucketer returns a bucketer hashing every bucketing key to the given hash
+func fixedHashBucketer(hashCode uint32) *MurmurhashBucketer {
+	return NewMurmurhashBucketerWithHashFunc(DefaultHashSeed, func(seed uint32, data []byte) uint32 {
+		return hashCode
+	})
+}
+
+// firstHashOfBucket returns the lowest hash mapped onto the given bucket value
+func firstHashOfBucket(bucketValue uint64) uint32 {
+	return uint32((bucketValue<<32 + maxTrafficValue - 1) / maxTrafficValue)
+}
+
+func TestGenerateBucketValueBoundaries(t *testing.T) {
+	assert.Equal(t, 0, fixedHashBucketer(0).Generate("test_user"))
+	assert.Equal(t, 9999, fixedHashBucketer(math.MaxUint32).Generate("test_user"))
+	assert.Equal(t, 9999, fixedHashBucketer(math.MaxUint32-1).Generate("test_user"))
+
+	for _, bucketValue := range []uint64{1, 49, 50, 51, 5000, 9999} {
+		firstHash := firstHashOfBucket(bucketValue)
+		assert.Equal(t, int(bucketValue), fixedHashBucketer(firstHash).Generate("test_user"))
+		assert.Equal(t, int(bucketValue)-1, fixedHashBucketer(firstHash-1).Generate("test_user"))
+	}
+}
+
+func TestBucketToEntityDecimalAllocation(t *testing.T) {
+	// 0.5% of the traffic, followed by a 99.5% allocation covering the rest
+	trafficAlloc := []entities.Range{
+		{EntityID: "half_percent", EndOfRange: 50},
+		{EntityID: "rest", EndOfRange: 10000},
+	}
+
+	assert.Equal(t, "half_percent", fixedHashBucketer(0).BucketToEntity("test_user", trafficAlloc))
+	assert.Equal(t, "half_percent", fixedHashBucketer(firstHashOfBucket(50)-1).BucketToEntity("test_user", trafficAlloc))
+	assert.Equal(t, "rest", fixedHashBucketer(firstHashOfBucket(50)).BucketToEntity("test_user", trafficAlloc))
+	// the highest hashes are still allocated to the last range
+	assert.Equal(t, "rest", fixedHashBucketer(math.MaxUint32).BucketToEntity("test_user", trafficAlloc))
+}
+
+func benchmarkGenerate(b *testing.B, bucketer *MurmurhashBucketer) {
+	bucketingKey := "test_user_1886780721"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bucketer.Generate(bucketingKey)
+	}
+}
+
+func BenchmarkGenerateDefaultHashFunc(b *testing.B) {
+	benchmarkGenerate(b, NewMurmurhashBucketer(DefaultHashSeed))
+}
+
+func BenchmarkGenerateStreamingHashFunc(b *testing.B) {
+	benchmarkGenerate(b, NewMurmurhashBucketerWithHashFunc(DefaultHashSeed, streamingHash))
+}
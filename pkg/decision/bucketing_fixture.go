@@ -0,0 +1,57 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// BucketingFixture is a datafile along with the variations users are expected to be bucketed into, such as the
+// cross-SDK compatibility fixtures
+type BucketingFixture struct {
+	Datafile json.RawMessage `json:"datafile"`
+	Cases    []BucketingCase `json:"cases"`
+}
+
+// BucketingCase is a user, with its attributes, and the key of the variation it is expected to be bucketed into for
+// an experiment of the fixture datafile. An empty expected variation means the user is not bucketed.
+type BucketingCase struct {
+	UserID            string                 `json:"userId"`
+	Attributes        map[string]interface{} `json:"attributes,omitempty"`
+	ExperimentKey     string                 `json:"experimentKey"`
+	ExpectedVariation string                 `json:"expectedVariation"`
+}
+
+// LoadBucketingFixture reads the bucketing fixture at the given path, a JSON object with the "datafile" and the
+// "cases" to check
+func LoadBucketingFixture(path string) (BucketingFixture, error) {
+	fixture := BucketingFixture{}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fixture, err
+	}
+	if err = json.Unmarshal(data, &fixture); err != nil {
+		return fixture, fmt.Errorf(`unable to parse bucketing fixture "%s": %s`, path, err)
+	}
+	if len(fixture.Datafile) == 0 {
+		return fixture, fmt.Errorf(`bucketing fixture "%s" has no datafile`, path)
+	}
+	return fixture, nil
+}
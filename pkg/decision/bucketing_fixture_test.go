@@ -0,0 +1,94 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package decision
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/optimizely/go-sdk/pkg/config/datafileprojectconfig"
+	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+// BucketingFixtureTest verifies that the given experiment service buckets the users of the fixture at the given path
+// into their expected variations, each case is run as a subtest. It guards the bucketing against regressions from the
+// canonical behavior shared by the SDKs.
+func BucketingFixtureTest(t *testing.T, path string, experimentService ExperimentService) {
+	fixture, err := LoadBucketingFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectConfig, err := datafileprojectconfig.NewDatafileProjectConfig(fixture.Datafile)
+	if err != nil {
+		t.Fatalf(`unable to parse the datafile of bucketing fixture "%s": %s`, path, err)
+	}
+
+	for _, bucketingCase := range fixture.Cases {
+		bucketingCase := bucketingCase
+		t.Run(fmt.Sprintf("%s/%s", bucketingCase.ExperimentKey, bucketingCase.UserID), func(t *testing.T) {
+			experiment, err := projectConfig.GetExperimentByKey(bucketingCase.ExperimentKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decisionContext := ExperimentDecisionContext{
+				Experiment:    &experiment,
+				ProjectConfig: projectConfig,
+			}
+			userContext := entities.UserContext{ID: bucketingCase.UserID, Attributes: bucketingCase.Attributes}
+
+			experimentDecision, err := experimentService.GetDecision(decisionContext, userContext)
+			if err != nil {
+				t.Fatalf("unexpected decision error: %s", err)
+			}
+			variationKey := ""
+			if experimentDecision.Variation != nil {
+				variationKey = experimentDecision.Variation.Key
+			}
+			if variationKey != bucketingCase.ExpectedVariation {
+				t.Errorf(`expected user "%s" to be bucketed into variation "%s" of experiment "%s", got "%s" (%s)`,
+					bucketingCase.UserID, bucketingCase.ExpectedVariation, bucketingCase.ExperimentKey, variationKey,
+					experimentDecision.Reason)
+			}
+		})
+	}
+}
+
+func TestBucketingFixture(t *testing.T) {
+	BucketingFixtureTest(t, "testdata/bucketing_fixture.json", NewCompositeExperimentService())
+}
+
+func TestLoadBucketingFixture(t *testing.T) {
+	fixture, err := LoadBucketingFixture("testdata/bucketing_fixture.json")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, fixture.Datafile)
+	assert.Equal(t, BucketingCase{UserID: "ppid1", ExperimentKey: "exp_1", ExpectedVariation: "exp_1_var_b"}, fixture.Cases[0])
+
+	_, err = LoadBucketingFixture("testdata/missing.json")
+	assert.Error(t, err)
+
+	dir, err := ioutil.TempDir("", "bucketing_fixture")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	invalidPath := filepath.Join(dir, "invalid.json")
+	assert.NoError(t, ioutil.WriteFile(invalidPath, []byte(`{"cases": []}`), 0600))
+	_, err = LoadBucketingFixture(invalidPath)
+	assert.EqualError(t, err, `bucketing fixture "`+invalidPath+`" has no datafile`)
+}
@@ -0,0 +1,173 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/optimizely/go-sdk/pkg/entities"
+)
+
+// decisionCacheKey identifies a cached feature decision
+type decisionCacheKey struct {
+	userID      string
+	featureKey  string
+	bucketingID string
+	// attributes holds the user attributes, on which the audience evaluation depends, see attributesCacheKey
+	attributes string
+	revision   string
+}
+
+type decisionCacheEntry struct {
+	key      decisionCacheKey
+	decision FeatureDecision
+}
+
+// CachingFeatureService keeps the decisions of the wrapped FeatureService in an LRU cache of the given size, so that
+// deciding the same feature for the same user again within a config revision does not evaluate it again. The cache is
+// cleared once a decision is made for another config revision. Changes made to the experiment override store at runtime
+// are not reflected in the decisions which are already cached, the feature overrides are evaluated ahead of the cache by
+// the CompositeService.
+type CachingFeatureService struct {
+	featureService FeatureService
+	size           int
+	entries        map[decisionCacheKey]*list.Element
+	order          *list.List
+	revision       string
+	lock           sync.Mutex
+}
+
+// NewCachingFeatureService returns a new instance of the CachingFeatureService caching up to size decisions of the given
+// feature service
+func NewCachingFeatureService(featureService FeatureService, size int) *CachingFeatureService {
+	return &CachingFeatureService{
+		featureService: featureService,
+		size:           size,
+		entries:        map[decisionCacheKey]*list.Element{},
+		order:          list.New(),
+	}
+}
+
+// GetDecision returns the cached decision for the given feature and user, making it with the wrapped service if needed
+func (s *CachingFeatureService) GetDecision(decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
+	return s.GetDecisionWithContext(context.Background(), decisionContext, userContext)
+}
+
+// GetDecisionWithContext is like GetDecision, passing the given context to the wrapped service
+func (s *CachingFeatureService) GetDecisionWithContext(ctx context.Context, decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
+	bucketingID, err := userContext.GetBucketingID()
+	// the bucketing trace is not cached, the decisions requesting it are always made again
+	if err != nil || decisionContext.Feature == nil || decisionContext.ProjectConfig == nil || bucketingTraceRequested(ctx) {
+		return getFeatureDecision(ctx, s.featureService, decisionContext, userContext)
+	}
+
+	key := decisionCacheKey{
+		userID:      userContext.ID,
+		featureKey:  decisionContext.Feature.Key,
+		bucketingID: bucketingID,
+		attributes:  attributesCacheKey(userContext.Attributes),
+		revision:    decisionContext.ProjectConfig.GetRevision(),
+	}
+	if featureDecision, ok := s.get(key); ok {
+		return featureDecision, nil
+	}
+
+	featureDecision, err := getFeatureDecision(ctx, s.featureService, decisionContext, userContext)
+	if err == nil {
+		s.add(key, featureDecision)
+	}
+	return featureDecision, err
+}
+
+// attributesCacheKey formats the attributes sorted by key along with the types of their values, so that attributes
+// which are evaluated differently, like "1" and 1, do not share a cache key
+func attributesCacheKey(attributes map[string]interface{}) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		value := attributes[key]
+		fmt.Fprintf(&builder, "%q:%T:%#v;", key, value, value)
+	}
+	return builder.String()
+}
+
+// Purge removes every decision from the cache
+func (s *CachingFeatureService) Purge() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.purge()
+}
+
+// Len returns the number of cached decisions
+func (s *CachingFeatureService) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.order.Len()
+}
+
+func (s *CachingFeatureService) get(key decisionCacheKey) (FeatureDecision, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if key.revision != s.revision {
+		// the decisions made for the previous config are not reused
+		s.purge()
+		s.revision = key.revision
+		return FeatureDecision{}, false
+	}
+	element, ok := s.entries[key]
+	if !ok {
+		return FeatureDecision{}, false
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*decisionCacheEntry).decision, true
+}
+
+func (s *CachingFeatureService) add(key decisionCacheKey, featureDecision FeatureDecision) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.size <= 0 || key.revision != s.revision {
+		return
+	}
+	if element, ok := s.entries[key]; ok {
+		element.Value.(*decisionCacheEntry).decision = featureDecision
+		s.order.MoveToFront(element)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&decisionCacheEntry{key: key, decision: featureDecision})
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*decisionCacheEntry).key)
+	}
+}
+
+// purge must be called with the lock held
+func (s *CachingFeatureService) purge() {
+	s.entries = map[decisionCacheKey]*list.Element{}
+	s.order.Init()
+}
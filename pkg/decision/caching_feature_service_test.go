@@ -0,0 +1,189 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/optimizely/go-sdk/pkg/config"
+	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// revisionProjectConfig is a project config with the given revision
+type revisionProjectConfig struct {
+	config.ProjectConfig
+	revision string
+}
+
+func (c revisionProjectConfig) GetRevision() string {
+	return c.revision
+}
+
+type CachingFeatureServiceTestSuite struct {
+	suite.Suite
+	mockFeatureService *MockFeatureDecisionService
+	decisionContext    FeatureDecisionContext
+	testUserContext    entities.UserContext
+	expectedDecision   FeatureDecision
+}
+
+func (s *CachingFeatureServiceTestSuite) SetupTest() {
+	s.mockFeatureService = new(MockFeatureDecisionService)
+	s.decisionContext = FeatureDecisionContext{
+		Feature:       &testFeat3333,
+		ProjectConfig: revisionProjectConfig{revision: "1"},
+	}
+	s.testUserContext = entities.UserContext{ID: "test_user_1"}
+	s.expectedDecision = FeatureDecision{
+		Experiment: testExp1113,
+		Variation:  &testExp1113Var2223,
+		Source:     FeatureTest,
+	}
+}
+
+func (s *CachingFeatureServiceTestSuite) TestGetDecisionCached() {
+	s.mockFeatureService.On("GetDecision", s.decisionContext, s.testUserContext).Return(s.expectedDecision, nil).Once()
+	cachingFeatureService := NewCachingFeatureService(s.mockFeatureService, 10)
+
+	for i := 0; i < 3; i++ {
+		decision, err := cachingFeatureService.GetDecision(s.decisionContext, s.testUserContext)
+		s.NoError(err)
+		s.Equal(s.expectedDecision, decision)
+	}
+	s.Equal(1, cachingFeatureService.Len())
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 1)
+}
+
+func (s *CachingFeatureServiceTestSuite) TestGetDecisionKeys() {
+	s.mockFeatureService.On("GetDecision", mock.Anything, mock.Anything).Return(s.expectedDecision, nil)
+	cachingFeatureService := NewCachingFeatureService(s.mockFeatureService, 10)
+
+	cachingFeatureService.GetDecision(s.decisionContext, s.testUserContext)
+	cachingFeatureService.GetDecision(s.decisionContext, entities.UserContext{ID: "test_user_2"})
+	cachingFeatureService.GetDecision(s.decisionContext, entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"$opt_bucketing_id": "test_bucketing_id"}})
+	cachingFeatureService.GetDecision(s.decisionContext, entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"country": "us"}})
+	otherFeatureContext := FeatureDecisionContext{Feature: &testFeat3335, ProjectConfig: s.decisionContext.ProjectConfig}
+	cachingFeatureService.GetDecision(otherFeatureContext, s.testUserContext)
+
+	s.Equal(5, cachingFeatureService.Len())
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 5)
+}
+
+func (s *CachingFeatureServiceTestSuite) TestGetDecisionAttributeTypes() {
+	s.mockFeatureService.On("GetDecision", mock.Anything, mock.Anything).Return(s.expectedDecision, nil)
+	cachingFeatureService := NewCachingFeatureService(s.mockFeatureService, 10)
+
+	for _, value := range []interface{}{"1", 1, 1.0, "true", true, nil} {
+		cachingFeatureService.GetDecision(s.decisionContext, entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"attr": value}})
+	}
+	// the order of the attributes does not matter
+	cachingFeatureService.GetDecision(s.decisionContext, entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"a": 1, "b": "2"}})
+	cachingFeatureService.GetDecision(s.decisionContext, entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"b": "2", "a": 1}})
+
+	s.Equal(7, cachingFeatureService.Len())
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 7)
+}
+
+func (s *CachingFeatureServiceTestSuite) TestGetDecisionBucketingTraceNotCached() {
+	tracedDecision := s.expectedDecision
+	tracedDecision.BucketingTrace = []string{"bucketed"}
+	mockFeatureService := new(MockContextFeatureDecisionService)
+	mockFeatureService.On("GetDecisionWithContext", mock.Anything, s.decisionContext, s.testUserContext).Return(s.expectedDecision, nil).Once()
+	mockFeatureService.On("GetDecisionWithContext", mock.Anything, s.decisionContext, s.testUserContext).Return(tracedDecision, nil).Once()
+	cachingFeatureService := NewCachingFeatureService(mockFeatureService, 10)
+
+	cachingFeatureService.GetDecision(s.decisionContext, s.testUserContext)
+	decision, err := cachingFeatureService.GetDecision(s.decisionContext, s.testUserContext)
+	s.NoError(err)
+	s.Equal(s.expectedDecision, decision)
+	mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecisionWithContext", 1)
+
+	// the cached decision has no trace, the decision is made again
+	decision, err = cachingFeatureService.GetDecisionWithContext(WithBucketingTrace(context.Background()), s.decisionContext, s.testUserContext)
+	s.NoError(err)
+	s.Equal(tracedDecision, decision)
+	mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecisionWithContext", 2)
+}
+
+func (s *CachingFeatureServiceTestSuite) TestGetDecisionConfigUpdate() {
+	s.mockFeatureService.On("GetDecision", mock.Anything, s.testUserContext).Return(s.expectedDecision, nil)
+	cachingFeatureService := NewCachingFeatureService(s.mockFeatureService, 10)
+
+	cachingFeatureService.GetDecision(s.decisionContext, s.testUserContext)
+	cachingFeatureService.GetDecision(FeatureDecisionContext{Feature: &testFeat3335, ProjectConfig: s.decisionContext.ProjectConfig}, s.testUserContext)
+	s.Equal(2, cachingFeatureService.Len())
+
+	updatedContext := FeatureDecisionContext{Feature: &testFeat3333, ProjectConfig: revisionProjectConfig{revision: "2"}}
+	cachingFeatureService.GetDecision(updatedContext, s.testUserContext)
+	cachingFeatureService.GetDecision(updatedContext, s.testUserContext)
+	// the decisions of the previous revision are dropped
+	s.Equal(1, cachingFeatureService.Len())
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 3)
+}
+
+func (s *CachingFeatureServiceTestSuite) TestGetDecisionEviction() {
+	s.mockFeatureService.On("GetDecision", mock.Anything, mock.Anything).Return(s.expectedDecision, nil)
+	cachingFeatureService := NewCachingFeatureService(s.mockFeatureService, 2)
+	user1 := entities.UserContext{ID: "test_user_1"}
+	user2 := entities.UserContext{ID: "test_user_2"}
+	user3 := entities.UserContext{ID: "test_user_3"}
+
+	cachingFeatureService.GetDecision(s.decisionContext, user1)
+	cachingFeatureService.GetDecision(s.decisionContext, user2)
+	// user 1 is now the most recently used
+	cachingFeatureService.GetDecision(s.decisionContext, user1)
+	cachingFeatureService.GetDecision(s.decisionContext, user3)
+	s.Equal(2, cachingFeatureService.Len())
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 3)
+
+	cachingFeatureService.GetDecision(s.decisionContext, user1)
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 3)
+	cachingFeatureService.GetDecision(s.decisionContext, user2)
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 4)
+}
+
+func (s *CachingFeatureServiceTestSuite) TestGetDecisionErrorNotCached() {
+	s.mockFeatureService.On("GetDecision", s.decisionContext, s.testUserContext).Return(FeatureDecision{}, errors.New("decision failed"))
+	cachingFeatureService := NewCachingFeatureService(s.mockFeatureService, 10)
+
+	_, err := cachingFeatureService.GetDecision(s.decisionContext, s.testUserContext)
+	s.Error(err)
+	_, err = cachingFeatureService.GetDecision(s.decisionContext, s.testUserContext)
+	s.Error(err)
+	s.Equal(0, cachingFeatureService.Len())
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 2)
+}
+
+func (s *CachingFeatureServiceTestSuite) TestPurge() {
+	s.mockFeatureService.On("GetDecision", s.decisionContext, s.testUserContext).Return(s.expectedDecision, nil)
+	cachingFeatureService := NewCachingFeatureService(s.mockFeatureService, 10)
+
+	cachingFeatureService.GetDecision(s.decisionContext, s.testUserContext)
+	cachingFeatureService.Purge()
+	s.Equal(0, cachingFeatureService.Len())
+	cachingFeatureService.GetDecision(s.decisionContext, s.testUserContext)
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 2)
+}
+
+func TestCachingFeatureServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CachingFeatureServiceTestSuite))
+}
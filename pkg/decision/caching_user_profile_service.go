@@ -0,0 +1,144 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"container/list"
+	"sync"
+)
+
+type userProfileCacheEntry struct {
+	userID  string
+	profile UserProfile
+}
+
+// CachingUserProfileService keeps the profiles retrieved from another UserProfileService in an LRU cache of the given
+// size, so that only the first lookup of a user reaches it. The cache can be warmed for several users at once with
+// BatchLookup, which uses the BatchLookup of the wrapped service. Saved profiles are written through to the wrapped
+// service. It is safe for concurrent use as long as the wrapped service is.
+type CachingUserProfileService struct {
+	userProfileService UserProfileService
+	size               int
+	profiles           map[string]*list.Element
+	order              *list.List
+	lock               sync.Mutex
+}
+
+// NewCachingUserProfileService returns a new instance of the CachingUserProfileService caching up to size profiles of
+// the given user profile service
+func NewCachingUserProfileService(userProfileService UserProfileService, size int) *CachingUserProfileService {
+	return &CachingUserProfileService{
+		userProfileService: userProfileService,
+		size:               size,
+		profiles:           map[string]*list.Element{},
+		order:              list.New(),
+	}
+}
+
+// Lookup returns the profile of the given user, from the cache if it was already retrieved
+func (s *CachingUserProfileService) Lookup(userID string) UserProfile {
+	if profile, ok := s.get(userID); ok {
+		return profile
+	}
+
+	profile := s.userProfileService.Lookup(userID)
+	s.add(userID, profile)
+	return profile
+}
+
+// BatchLookup returns the profiles of the given users, retrieving the ones which are not cached yet at once
+func (s *CachingUserProfileService) BatchLookup(userIDs []string) map[string]UserProfile {
+	profiles := make(map[string]UserProfile, len(userIDs))
+	var missingUserIDs []string
+	for _, userID := range userIDs {
+		if profile, ok := s.get(userID); ok {
+			if profile.ID != "" {
+				profiles[userID] = profile
+			}
+		} else {
+			missingUserIDs = append(missingUserIDs, userID)
+		}
+	}
+
+	if len(missingUserIDs) == 0 {
+		return profiles
+	}
+
+	fetchedProfiles := s.userProfileService.BatchLookup(missingUserIDs)
+	for _, userID := range missingUserIDs {
+		// users without a profile are cached too, so that they are not looked up again
+		profile := fetchedProfiles[userID]
+		s.add(userID, profile)
+		if profile.ID != "" {
+			profiles[userID] = copyUserProfile(profile)
+		}
+	}
+	return profiles
+}
+
+// Save saves the profile to the wrapped user profile service and caches it
+func (s *CachingUserProfileService) Save(profile UserProfile) {
+	s.userProfileService.Save(profile)
+	s.add(profile.ID, profile)
+}
+
+// Clear removes every profile from the cache
+func (s *CachingUserProfileService) Clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.profiles = map[string]*list.Element{}
+	s.order.Init()
+}
+
+// Len returns the number of cached profiles
+func (s *CachingUserProfileService) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.order.Len()
+}
+
+func (s *CachingUserProfileService) get(userID string) (UserProfile, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	element, ok := s.profiles[userID]
+	if !ok {
+		return UserProfile{}, false
+	}
+	s.order.MoveToFront(element)
+	return copyUserProfile(element.Value.(*userProfileCacheEntry).profile), true
+}
+
+func (s *CachingUserProfileService) add(userID string, profile UserProfile) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.size <= 0 {
+		return
+	}
+	profile = copyUserProfile(profile)
+	if element, ok := s.profiles[userID]; ok {
+		element.Value.(*userProfileCacheEntry).profile = profile
+		s.order.MoveToFront(element)
+		return
+	}
+	s.profiles[userID] = s.order.PushFront(&userProfileCacheEntry{userID: userID, profile: profile})
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.profiles, oldest.Value.(*userProfileCacheEntry).userID)
+	}
+}
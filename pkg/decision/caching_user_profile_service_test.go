@@ -0,0 +1,113 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingUserProfileService counts the lookups made to the in memory user profile service it wraps
+type countingUserProfileService struct {
+	*InMemoryUserProfileService
+	lookups      []string
+	batchLookups [][]string
+}
+
+func (s *countingUserProfileService) Lookup(userID string) UserProfile {
+	s.lookups = append(s.lookups, userID)
+	return s.InMemoryUserProfileService.Lookup(userID)
+}
+
+func (s *countingUserProfileService) BatchLookup(userIDs []string) map[string]UserProfile {
+	s.batchLookups = append(s.batchLookups, userIDs)
+	return s.InMemoryUserProfileService.BatchLookup(userIDs)
+}
+
+func TestCachingUserProfileServiceLookup(t *testing.T) {
+	backend := &countingUserProfileService{InMemoryUserProfileService: NewInMemoryUserProfileService()}
+	backend.Save(UserProfile{ID: "test_user_1"})
+	userProfileService := NewCachingUserProfileService(backend, 10)
+
+	assert.Equal(t, UserProfile{ID: "test_user_1"}, userProfileService.Lookup("test_user_1"))
+	assert.Equal(t, UserProfile{ID: "test_user_1"}, userProfileService.Lookup("test_user_1"))
+	// users without a profile are cached as well
+	assert.Equal(t, UserProfile{}, userProfileService.Lookup("test_user_2"))
+	assert.Equal(t, UserProfile{}, userProfileService.Lookup("test_user_2"))
+	assert.Equal(t, []string{"test_user_1", "test_user_2"}, backend.lookups)
+
+	userProfileService.Clear()
+	userProfileService.Lookup("test_user_1")
+	assert.Equal(t, []string{"test_user_1", "test_user_2", "test_user_1"}, backend.lookups)
+}
+
+func TestCachingUserProfileServiceSave(t *testing.T) {
+	backend := &countingUserProfileService{InMemoryUserProfileService: NewInMemoryUserProfileService()}
+	userProfileService := NewCachingUserProfileService(backend, 10)
+
+	profile := UserProfile{ID: "test_user_1", ExperimentBucketMap: map[UserDecisionKey]string{NewUserDecisionKey("1111"): "2222"}}
+	userProfileService.Save(profile)
+	assert.Equal(t, profile, backend.InMemoryUserProfileService.Lookup("test_user_1"))
+	assert.Equal(t, profile, userProfileService.Lookup("test_user_1"))
+	assert.Empty(t, backend.lookups)
+}
+
+func TestCachingUserProfileServiceBatchLookup(t *testing.T) {
+	backend := &countingUserProfileService{InMemoryUserProfileService: NewInMemoryUserProfileService()}
+	backend.Save(UserProfile{ID: "test_user_1"})
+	backend.Save(UserProfile{ID: "test_user_2"})
+	userProfileService := NewCachingUserProfileService(backend, 10)
+	userProfileService.Lookup("test_user_1")
+
+	profiles := userProfileService.BatchLookup([]string{"test_user_1", "test_user_2", "test_user_3"})
+	assert.Equal(t, map[string]UserProfile{
+		"test_user_1": {ID: "test_user_1"},
+		"test_user_2": {ID: "test_user_2"},
+	}, profiles)
+	// only the users which were not cached are looked up, at once
+	assert.Equal(t, [][]string{{"test_user_2", "test_user_3"}}, backend.batchLookups)
+
+	userProfileService.Lookup("test_user_2")
+	userProfileService.Lookup("test_user_3")
+	userProfileService.BatchLookup([]string{"test_user_1", "test_user_2"})
+	assert.Equal(t, []string{"test_user_1"}, backend.lookups)
+	assert.Len(t, backend.batchLookups, 1)
+}
+
+func TestCachingUserProfileServiceEviction(t *testing.T) {
+	backend := &countingUserProfileService{InMemoryUserProfileService: NewInMemoryUserProfileService()}
+	userProfileService := NewCachingUserProfileService(backend, 2)
+
+	userProfileService.Lookup("test_user_1")
+	userProfileService.Lookup("test_user_2")
+	// user 1 is now the most recently used
+	userProfileService.Lookup("test_user_1")
+	userProfileService.BatchLookup([]string{"test_user_3"})
+	assert.Equal(t, 2, userProfileService.Len())
+
+	userProfileService.Lookup("test_user_1")
+	assert.Equal(t, []string{"test_user_1", "test_user_2"}, backend.lookups)
+	userProfileService.Lookup("test_user_2")
+	assert.Equal(t, []string{"test_user_1", "test_user_2", "test_user_2"}, backend.lookups)
+
+	// nothing is cached without a size
+	userProfileService = NewCachingUserProfileService(backend, 0)
+	userProfileService.Save(UserProfile{ID: "test_user_1"})
+	assert.Equal(t, 0, userProfileService.Len())
+}
@@ -18,8 +18,11 @@
 package decision
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/optimizely/go-sdk/pkg/decision/evaluator"
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/logging"
 )
@@ -43,11 +46,20 @@ func WithOverrideStore(overrideStore ExperimentOverrideStore) CESOptionFunc {
 	}
 }
 
+// WithAudienceTreeEvaluator sets the evaluator of the audience conditions of the experiments, for instance one
+// counting the evaluated conditions
+func WithAudienceTreeEvaluator(treeEvaluator evaluator.TreeEvaluator) CESOptionFunc {
+	return func(f *CompositeExperimentService) {
+		f.audienceTreeEvaluator = treeEvaluator
+	}
+}
+
 // CompositeExperimentService bridges together the various experiment decision services that ship by default with the SDK
 type CompositeExperimentService struct {
-	experimentServices []ExperimentService
-	overrideStore      ExperimentOverrideStore
-	userProfileService UserProfileService
+	experimentServices    []ExperimentService
+	overrideStore         ExperimentOverrideStore
+	userProfileService    UserProfileService
+	audienceTreeEvaluator evaluator.TreeEvaluator
 }
 
 // NewCompositeExperimentService creates a new instance of the CompositeExperimentService
@@ -71,6 +83,9 @@ func NewCompositeExperimentService(options ...CESOptionFunc) *CompositeExperimen
 	}
 
 	experimentBucketerService := NewExperimentBucketerService()
+	if compositeExperimentService.audienceTreeEvaluator != nil {
+		experimentBucketerService.audienceTreeEvaluator = compositeExperimentService.audienceTreeEvaluator
+	}
 	if compositeExperimentService.userProfileService != nil {
 		persistingExperimentService := NewPersistingExperimentService(experimentBucketerService, compositeExperimentService.userProfileService)
 		experimentServices = append(experimentServices, persistingExperimentService)
@@ -84,10 +99,20 @@ func NewCompositeExperimentService(options ...CESOptionFunc) *CompositeExperimen
 
 // GetDecision returns a decision for the given experiment and user context
 func (s CompositeExperimentService) GetDecision(decisionContext ExperimentDecisionContext, userContext entities.UserContext) (decision ExperimentDecision, err error) {
+	return s.GetDecisionWithContext(context.Background(), decisionContext, userContext)
+}
+
+// GetDecisionWithContext is like GetDecision, passing the given context to the experiment services
+func (s CompositeExperimentService) GetDecisionWithContext(ctx context.Context, decisionContext ExperimentDecisionContext, userContext entities.UserContext) (decision ExperimentDecision, err error) {
+	if experiment := decisionContext.Experiment; experiment != nil && !experiment.IsRunning() {
+		ceLogger.Info(fmt.Sprintf(`Experiment "%s" is not running.`, experiment.Key))
+		decision.Reason = reasons.ExperimentNotRunning
+		return decision, nil
+	}
 
 	// Run through the various decision services until we get a decision
 	for _, experimentService := range s.experimentServices {
-		decision, err = experimentService.GetDecision(decisionContext, userContext)
+		decision, err = getExperimentDecision(ctx, experimentService, decisionContext, userContext)
 		if err != nil {
 			ceLogger.Debug(fmt.Sprintf("%v", err))
 		}
@@ -98,3 +123,13 @@ func (s CompositeExperimentService) GetDecision(decisionContext ExperimentDecisi
 
 	return decision, err
 }
+
+// WarmUserProfiles retrieves the profiles of the given users at once ahead of their decisions, for instance at the
+// start of a request for a known cohort. It has an effect when the user profile service keeps what it retrieves, such
+// as the CachingUserProfileService.
+func (s CompositeExperimentService) WarmUserProfiles(userIDs []string) {
+	if s.userProfileService == nil {
+		return
+	}
+	s.userProfileService.BatchLookup(userIDs)
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/pkg/entities"
 )
 
@@ -68,6 +69,30 @@ func (s *CompositeExperimentTestSuite) TestGetDecision() {
 
 }
 
+func (s *CompositeExperimentTestSuite) TestGetDecisionExperimentNotRunning() {
+	// test that no decision service is called for experiments which are not running
+	testUserContext := entities.UserContext{
+		ID: "test_user_1",
+	}
+
+	pausedExperiment := testExp1111
+	pausedExperiment.Status = entities.ExperimentPaused
+	decisionContext := ExperimentDecisionContext{
+		Experiment:    &pausedExperiment,
+		ProjectConfig: s.mockConfig,
+	}
+
+	compositeExperimentService := &CompositeExperimentService{
+		experimentServices: []ExperimentService{s.mockExperimentService, s.mockExperimentService2},
+	}
+	decision, err := compositeExperimentService.GetDecision(decisionContext, testUserContext)
+	s.NoError(err)
+	s.Nil(decision.Variation)
+	s.Equal(reasons.ExperimentNotRunning, decision.Reason)
+	s.mockExperimentService.AssertNotCalled(s.T(), "GetDecision")
+	s.mockExperimentService2.AssertNotCalled(s.T(), "GetDecision")
+}
+
 func (s *CompositeExperimentTestSuite) TestGetDecisionFallthrough() {
 	// test that we move onto the next decision service if no decision is made
 	testUserContext := entities.UserContext{
@@ -150,6 +175,27 @@ func (s *CompositeExperimentTestSuite) TestGetDecisionReturnsError() {
 	s.mockExperimentService2.AssertExpectations(s.T())
 }
 
+func (s *CompositeExperimentTestSuite) TestWarmUserProfiles() {
+	backend := &countingUserProfileService{InMemoryUserProfileService: NewInMemoryUserProfileService()}
+	userProfileService := NewCachingUserProfileService(backend, 10)
+	compositeExperimentService := NewCompositeExperimentService(WithUserProfileService(userProfileService))
+
+	compositeExperimentService.WarmUserProfiles([]string{"test_user_1", "test_user_2"})
+	s.Equal([][]string{{"test_user_1", "test_user_2"}}, backend.batchLookups)
+
+	// warmed users are served from the cache
+	userProfileService.Lookup("test_user_1")
+	s.Empty(backend.lookups)
+
+	mockUserProfileService := new(MockUserProfileService)
+	mockUserProfileService.On("BatchLookup", []string{"test_user_1"}).Return(map[string]UserProfile{})
+	NewCompositeExperimentService(WithUserProfileService(mockUserProfileService)).WarmUserProfiles([]string{"test_user_1"})
+	mockUserProfileService.AssertExpectations(s.T())
+
+	// no-op without a user profile service
+	NewCompositeExperimentService().WarmUserProfiles([]string{"test_user_1"})
+}
+
 func (s *CompositeExperimentTestSuite) TestNewCompositeExperimentService() {
 	// Assert that the service is instantiated with the correct child services in the right order
 	compositeExperimentService := NewCompositeExperimentService()
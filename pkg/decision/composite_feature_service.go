@@ -18,8 +18,10 @@
 package decision
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/logging"
 )
@@ -43,10 +45,16 @@ func NewCompositeFeatureService(compositeExperimentService ExperimentService) *C
 
 // GetDecision returns a decision for the given feature and user context
 func (f CompositeFeatureService) GetDecision(decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
+	return f.GetDecisionWithContext(context.Background(), decisionContext, userContext)
+}
+
+// GetDecisionWithContext is like GetDecision, passing the given context to the feature services
+func (f CompositeFeatureService) GetDecisionWithContext(ctx context.Context, decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
 	var featureDecision = FeatureDecision{}
+	var heldOutDecision *FeatureDecision
 	var err error
 	for _, featureDecisionService := range f.featureServices {
-		featureDecision, err = featureDecisionService.GetDecision(decisionContext, userContext)
+		featureDecision, err = getFeatureDecision(ctx, featureDecisionService, decisionContext, userContext)
 		if err != nil {
 			cfLogger.Debug(fmt.Sprintf("%v", err))
 		}
@@ -54,6 +62,14 @@ func (f CompositeFeatureService) GetDecision(decisionContext FeatureDecisionCont
 		if featureDecision.Variation != nil && err == nil {
 			return featureDecision, err
 		}
+		if featureDecision.Reason == reasons.HeldOut && heldOutDecision == nil {
+			decision := featureDecision
+			heldOutDecision = &decision
+		}
+	}
+	// a user held out of the feature tests and not bucketed into a rollout gets the held out feature test decision
+	if heldOutDecision != nil {
+		return *heldOutDecision, err
 	}
 	return featureDecision, err
 }
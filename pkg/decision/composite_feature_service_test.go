@@ -52,7 +52,7 @@ func (s *CompositeFeatureServiceTestSuite) TestGetDecision() {
 	}
 
 	expectedDecision := FeatureDecision{
-		Decision:   Decision{reasons.BucketedIntoVariation},
+		Decision:   Decision{Reason: reasons.BucketedIntoVariation},
 		Source:     FeatureTest,
 		Experiment: testExp1113,
 		Variation:  &testExp1113Var2223,
@@ -99,6 +99,41 @@ func (s *CompositeFeatureServiceTestSuite) TestGetDecisionFallthrough() {
 	s.mockFeatureService2.AssertExpectations(s.T())
 }
 
+func (s *CompositeFeatureServiceTestSuite) TestGetDecisionHeldOut() {
+	// test that a user held out of the feature tests gets the held out decision unless bucketed into a rollout
+	testUserContext := entities.UserContext{
+		ID: "test_user_1",
+	}
+
+	heldOutDecision := FeatureDecision{
+		Decision:   Decision{Reason: reasons.HeldOut},
+		Source:     FeatureTest,
+		Experiment: testExp1113,
+	}
+	s.mockFeatureService.On("GetDecision", s.testFeatureDecisionContext, testUserContext).Return(heldOutDecision, nil)
+	s.mockFeatureService2.On("GetDecision", s.testFeatureDecisionContext, testUserContext).Return(FeatureDecision{Source: Rollout}, nil).Once()
+
+	compositeFeatureService := &CompositeFeatureService{
+		featureServices: []FeatureService{
+			s.mockFeatureService,
+			s.mockFeatureService2,
+		},
+	}
+	decision, err := compositeFeatureService.GetDecision(s.testFeatureDecisionContext, testUserContext)
+	s.Equal(heldOutDecision, decision)
+	s.NoError(err)
+
+	rolloutDecision := FeatureDecision{
+		Source:    Rollout,
+		Variation: &testExp1113Var2223,
+	}
+	s.mockFeatureService2.On("GetDecision", s.testFeatureDecisionContext, testUserContext).Return(rolloutDecision, nil).Once()
+	decision, err = compositeFeatureService.GetDecision(s.testFeatureDecisionContext, testUserContext)
+	s.Equal(rolloutDecision, decision)
+	s.NoError(err)
+	s.mockFeatureService2.AssertExpectations(s.T())
+}
+
 func (s *CompositeFeatureServiceTestSuite) TestGetDecisionReturnsError() {
 	// test that we move onto the next decision service if an inner service returns an error
 	testUserContext := entities.UserContext{
@@ -155,6 +190,55 @@ func (s *CompositeFeatureServiceTestSuite) TestGetDecisionReturnsLastDecisionWit
 	s.mockFeatureService2.AssertExpectations(s.T())
 }
 
+func (s *CompositeFeatureServiceTestSuite) TestGetDecisionRolloutOnlyFeature() {
+	// A feature without any feature experiments should still resolve through its rollout
+	rolloutVariation := entities.Variation{
+		ID:             "2226",
+		Key:            "2226",
+		FeatureEnabled: true,
+		Variables: map[string]entities.VariationVariable{
+			"7777": {ID: "7777", Value: "rollout_value"},
+		},
+	}
+	rolloutRule := entities.Experiment{
+		ID:                  "1115",
+		Key:                 "1115",
+		LayerID:             "4445",
+		Variations:          map[string]entities.Variation{"2226": rolloutVariation},
+		VariationKeyToIDMap: map[string]string{"2226": "2226"},
+		TrafficAllocation: []entities.Range{
+			entities.Range{EntityID: "2226", EndOfRange: 10000},
+		},
+	}
+	rolloutOnlyFeature := entities.Feature{
+		ID:                 "3336",
+		Key:                "test_feature_rollout_only_3336_key",
+		FeatureExperiments: []entities.Experiment{},
+		Rollout: entities.Rollout{
+			ID:          "4445",
+			Experiments: []entities.Experiment{rolloutRule},
+		},
+	}
+	testUserContext := entities.UserContext{
+		ID: "test_user_1",
+	}
+
+	decisionContext := FeatureDecisionContext{
+		Feature:       &rolloutOnlyFeature,
+		ProjectConfig: s.testFeatureDecisionContext.ProjectConfig,
+	}
+	compositeFeatureService := NewCompositeFeatureService(NewCompositeExperimentService())
+	decision, err := compositeFeatureService.GetDecision(decisionContext, testUserContext)
+	s.NoError(err)
+	s.Equal(Rollout, decision.Source)
+	s.Equal(reasons.BucketedIntoRollout, decision.Reason)
+	s.Equal(rolloutRule, decision.Experiment)
+	if s.NotNil(decision.Variation) {
+		s.True(decision.Variation.FeatureEnabled)
+		s.Equal("rollout_value", decision.Variation.Variables["7777"].Value)
+	}
+}
+
 func (s *CompositeFeatureServiceTestSuite) TestNewCompositeFeatureService() {
 	// Assert that the service is instantiated with the correct child services in the right order
 	compositeExperimentService := NewCompositeExperimentService()
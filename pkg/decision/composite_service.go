@@ -18,9 +18,12 @@
 package decision
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
+	"github.com/optimizely/go-sdk/pkg/decision/evaluator"
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/logging"
 	"github.com/optimizely/go-sdk/pkg/notification"
@@ -33,6 +36,11 @@ var csLogger = logging.GetLogger("CompositeDecisionService")
 type CompositeService struct {
 	compositeExperimentService ExperimentService
 	compositeFeatureService    FeatureService
+	featureOverrideStore       FeatureOverrideStore
+	holdoutPercentage          float64
+	holdoutService             *HoldoutService
+	decisionCacheSize          int
+	rolloutAudienceEvaluator   evaluator.TreeEvaluator
 	notificationCenter         notification.Center
 }
 
@@ -46,6 +54,45 @@ func WithCompositeExperimentService(compositeExperimentService ExperimentService
 	}
 }
 
+// WithFeatureOverrideStore sets the store of attribute based feature overrides, which take precedence over the feature
+// tests and rollouts when they match the user attributes
+func WithFeatureOverrideStore(featureOverrideStore FeatureOverrideStore) CSOptionFunc {
+	return func(f *CompositeService) {
+		f.featureOverrideStore = featureOverrideStore
+	}
+}
+
+// WithHoldout holds the given percentage (0 to 100) of users out of all experiments, based on a stable hash of their
+// bucketing ID. Held out users get the default experience and are flagged in the decision notifications.
+func WithHoldout(percentage float64) CSOptionFunc {
+	return func(f *CompositeService) {
+		f.holdoutPercentage = percentage
+	}
+}
+
+// WithDecisionCache caches up to size feature decisions per user and config revision, see CachingFeatureService. The
+// notifications are still sent for the cached decisions.
+func WithDecisionCache(size int) CSOptionFunc {
+	return func(f *CompositeService) {
+		f.decisionCacheSize = size
+	}
+}
+
+// WithNotificationCenter sets the notification center the Decision notifications are sent to, in place of the one
+// registered for the SDK key
+func WithNotificationCenter(notificationCenter notification.Center) CSOptionFunc {
+	return func(service *CompositeService) {
+		service.notificationCenter = notificationCenter
+	}
+}
+
+// WithRolloutAudienceTreeEvaluator sets the evaluator of the audience conditions of the feature rollout rules
+func WithRolloutAudienceTreeEvaluator(treeEvaluator evaluator.TreeEvaluator) CSOptionFunc {
+	return func(service *CompositeService) {
+		service.rolloutAudienceEvaluator = treeEvaluator
+	}
+}
+
 // NewCompositeService returns a new instance of the CompositeService with the defaults
 func NewCompositeService(sdkKey string, options ...CSOptionFunc) *CompositeService {
 	compositeService := &CompositeService{
@@ -59,22 +106,90 @@ func NewCompositeService(sdkKey string, options ...CSOptionFunc) *CompositeServi
 	if compositeService.compositeExperimentService == nil {
 		compositeService.compositeExperimentService = NewCompositeExperimentService()
 	}
-	compositeService.compositeFeatureService = NewCompositeFeatureService(compositeService.compositeExperimentService)
+	if compositeService.holdoutPercentage != 0 {
+		// the holdout is evaluated before any experiment bucketing, feature rollouts are not affected
+		compositeService.holdoutService = NewHoldoutService(compositeService.holdoutPercentage, compositeService.compositeExperimentService)
+		compositeService.compositeExperimentService = compositeService.holdoutService
+	}
+	compositeFeatureService := NewCompositeFeatureService(compositeService.compositeExperimentService)
+	if compositeService.rolloutAudienceEvaluator != nil {
+		for _, featureService := range compositeFeatureService.featureServices {
+			if rolloutService, ok := featureService.(*RolloutService); ok {
+				rolloutService.setAudienceTreeEvaluator(compositeService.rolloutAudienceEvaluator)
+			}
+		}
+	}
+	compositeService.compositeFeatureService = compositeFeatureService
+	if compositeService.decisionCacheSize > 0 {
+		compositeService.compositeFeatureService = NewCachingFeatureService(compositeFeatureService, compositeService.decisionCacheSize)
+	}
+	if compositeService.featureOverrideStore != nil {
+		// overrides short-circuit the evaluation of feature tests and rollouts, they are evaluated ahead of the decision
+		// cache so that the overrides set at runtime apply to the users with a cached decision as well
+		featureOverrideService := NewFeatureOverrideService(compositeService.featureOverrideStore)
+		compositeService.compositeFeatureService = &CompositeFeatureService{
+			featureServices: []FeatureService{featureOverrideService, compositeService.compositeFeatureService},
+		}
+	}
 
 	return compositeService
 }
 
+// WarmUserProfiles retrieves the profiles of the given users ahead of their decisions when the experiment service
+// supports it, see CompositeExperimentService.WarmUserProfiles
+func (s CompositeService) WarmUserProfiles(userIDs []string) {
+	experimentService := s.compositeExperimentService
+	if s.holdoutService != nil {
+		experimentService = s.holdoutService.experimentService
+	}
+	if warmer, ok := experimentService.(interface{ WarmUserProfiles([]string) }); ok {
+		warmer.WarmUserProfiles(userIDs)
+	}
+}
+
 // GetFeatureDecision returns a decision for the given feature key
 func (s CompositeService) GetFeatureDecision(featureDecisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
-	featureDecision, err := s.compositeFeatureService.GetDecision(featureDecisionContext, userContext)
+	return s.GetFeatureDecisionWithContext(context.Background(), featureDecisionContext, userContext)
+}
+
+// GetFeatureDecisionWithContext is like GetFeatureDecision, passing the given context to the feature services
+func (s CompositeService) GetFeatureDecisionWithContext(ctx context.Context, featureDecisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
+	if configNotReady(featureDecisionContext.ProjectConfig) {
+		csLogger.Warning("Feature decision requested before the project config is ready")
+		return FeatureDecision{}, ErrConfigNotReady
+	}
+
+	featureDecision, err := getFeatureDecision(ctx, s.compositeFeatureService, featureDecisionContext, userContext)
 
 	// @TODO: add errors
-	if s.notificationCenter != nil {
+	if s.hasDecisionHandlers() {
 		sourceInfo := map[string]string{}
+		typedFeatureInfo := &notification.FeatureDecisionInfo{
+			FeatureKey: featureDecisionContext.Feature.Key,
+			Source:     string(featureDecision.Source),
+		}
 
 		if featureDecision.Source == FeatureTest {
 			sourceInfo["experimentKey"] = featureDecision.Experiment.Key
-			sourceInfo["variationKey"] = featureDecision.Variation.Key
+			typedFeatureInfo.SourceInfo = &notification.ExperimentDecisionInfo{
+				ExperimentKey: featureDecision.Experiment.Key,
+			}
+			// the users held out of the feature tests are not bucketed into a variation
+			if featureDecision.Variation != nil {
+				sourceInfo["variationKey"] = featureDecision.Variation.Key
+				typedFeatureInfo.SourceInfo.VariationKey = featureDecision.Variation.Key
+			}
+		}
+		if featureDecision.Source == Rollout && featureDecision.Variation != nil {
+			ruleIndex := getRolloutRuleIndex(featureDecisionContext.Feature.Rollout, featureDecision.Experiment.ID)
+			sourceInfo["ruleId"] = featureDecision.Experiment.ID
+			sourceInfo["ruleKey"] = featureDecision.Experiment.Key
+			sourceInfo["ruleIndex"] = strconv.Itoa(ruleIndex)
+			typedFeatureInfo.RolloutInfo = &notification.RolloutDecisionInfo{
+				RuleID:    featureDecision.Experiment.ID,
+				RuleKey:   featureDecision.Experiment.Key,
+				RuleIndex: ruleIndex,
+			}
 		}
 
 		featureInfo := map[string]interface{}{
@@ -85,6 +200,7 @@ func (s CompositeService) GetFeatureDecision(featureDecisionContext FeatureDecis
 		}
 		if featureDecision.Variation != nil {
 			featureInfo["featureEnabled"] = featureDecision.Variation.FeatureEnabled
+			typedFeatureInfo.FeatureEnabled = featureDecision.Variation.FeatureEnabled
 		}
 
 		notificationType := notification.Feature
@@ -92,6 +208,8 @@ func (s CompositeService) GetFeatureDecision(featureDecisionContext FeatureDecis
 		if variable.ID != "" && variable.Key != "" {
 			featureInfo["variableKey"] = variable.Key
 			featureInfo["variableType"] = variable.Type
+			typedFeatureInfo.VariableKey = variable.Key
+			typedFeatureInfo.VariableType = variable.Type
 
 			notificationType = notification.FeatureVariable
 			variableValue := variable.DefaultValue
@@ -101,33 +219,31 @@ func (s CompositeService) GetFeatureDecision(featureDecisionContext FeatureDecis
 					variableValue = v.Value
 				}
 			}
-			var convertedValue interface{}
-			var e error
-
-			convertedValue = variableValue // default for String
-
-			switch variable.Type {
-			case entities.Integer:
-				convertedValue, e = strconv.Atoi(variableValue)
-			case entities.Double:
-				convertedValue, e = strconv.ParseFloat(variableValue, 64)
-			case entities.Boolean:
-				convertedValue, e = strconv.ParseBool(variableValue)
+			if !variable.Type.IsKnown() {
+				csLogger.Warning(fmt.Sprintf(`Variable "%s" has unknown type "%s", notifying its value as string`, variable.Key, variable.Type))
 			}
-
+			convertedValue, e := variable.ConvertValue(variableValue)
 			if e != nil {
-				featureInfo["variableValue"] = variableValue
-			} else {
-				featureInfo["variableValue"] = convertedValue
+				convertedValue = variableValue
 			}
+			featureInfo["variableValue"] = convertedValue
+			typedFeatureInfo.VariableValue = convertedValue
 		}
 
 		decisionInfo := map[string]interface{}{
 			"feature": featureInfo,
 		}
+		heldOut := featureDecision.Source == FeatureTest && featureDecision.Reason == reasons.HeldOut
+		if heldOut {
+			decisionInfo["heldOut"] = true
+		}
 
 		decisionNotification := notification.DecisionNotification{
 			DecisionInfo: decisionInfo,
+			FeatureInfo:  typedFeatureInfo,
+			HeldOut:      heldOut,
+			Bucketed:     featureDecision.Variation != nil,
+			Reason:       string(featureDecision.Reason),
 			Type:         notificationType,
 			UserContext:  userContext,
 		}
@@ -140,23 +256,50 @@ func (s CompositeService) GetFeatureDecision(featureDecisionContext FeatureDecis
 
 // GetExperimentDecision returns a decision for the given experiment key
 func (s CompositeService) GetExperimentDecision(experimentDecisionContext ExperimentDecisionContext, userContext entities.UserContext) (experimentDecision ExperimentDecision, err error) {
-	if experimentDecision, err = s.compositeExperimentService.GetDecision(experimentDecisionContext, userContext); err != nil {
+	return s.GetExperimentDecisionWithContext(context.Background(), experimentDecisionContext, userContext)
+}
+
+// GetExperimentDecisionWithContext is like GetExperimentDecision, passing the given context to the experiment services
+func (s CompositeService) GetExperimentDecisionWithContext(ctx context.Context, experimentDecisionContext ExperimentDecisionContext, userContext entities.UserContext) (experimentDecision ExperimentDecision, err error) {
+	if configNotReady(experimentDecisionContext.ProjectConfig) {
+		csLogger.Warning("Experiment decision requested before the project config is ready")
+		return experimentDecision, ErrConfigNotReady
+	}
+
+	if experimentDecision, err = getExperimentDecision(ctx, s.compositeExperimentService, experimentDecisionContext, userContext); err != nil {
 		return experimentDecision, err
 	}
 
-	if s.notificationCenter != nil {
+	if s.hasDecisionHandlers() {
 		decisionInfo := map[string]interface{}{
 			"experimentKey": experimentDecisionContext.Experiment.Key,
 		}
+		experimentInfo := &notification.ExperimentDecisionInfo{
+			ExperimentKey: experimentDecisionContext.Experiment.Key,
+		}
 
 		if experimentDecision.Variation != nil {
 			decisionInfo["variationKey"] = experimentDecision.Variation.Key
+			experimentInfo.VariationKey = experimentDecision.Variation.Key
+		}
+		heldOut := experimentDecision.Reason == reasons.HeldOut
+		if heldOut {
+			decisionInfo["heldOut"] = true
+		}
+		notRunning := experimentDecision.Reason == reasons.ExperimentNotRunning
+		if notRunning {
+			decisionInfo["notRunning"] = true
 		}
 
 		decisionNotification := notification.DecisionNotification{
-			DecisionInfo: decisionInfo,
-			UserContext:  userContext,
-			Type:         notification.ABTest,
+			DecisionInfo:   decisionInfo,
+			ExperimentInfo: experimentInfo,
+			HeldOut:        heldOut,
+			NotRunning:     notRunning,
+			Bucketed:       experimentDecision.Variation != nil,
+			Reason:         string(experimentDecision.Reason),
+			UserContext:    userContext,
+			Type:           notification.ABTest,
 		}
 		if experimentDecisionContext.Experiment.IsFeatureExperiment {
 			decisionNotification.Type = notification.FeatureTest
@@ -170,7 +313,23 @@ func (s CompositeService) GetExperimentDecision(experimentDecisionContext Experi
 	return experimentDecision, err
 }
 
-// OnDecision registers a handler for Decision notifications
+// getRolloutRuleIndex returns the index of the rule with the given ID in the rollout, -1 if there is none
+func getRolloutRuleIndex(rollout entities.Rollout, ruleID string) int {
+	for index, rule := range rollout.Experiments {
+		if rule.ID == ruleID {
+			return index
+		}
+	}
+	return -1
+}
+
+// hasDecisionHandlers returns whether decision notifications have to be built, they are skipped without listeners
+func (s CompositeService) hasDecisionHandlers() bool {
+	return s.notificationCenter != nil && notification.HasHandlers(s.notificationCenter, notification.Decision)
+}
+
+// OnDecision registers a handler for Decision notifications, it can be called concurrently with the decisions, which
+// call the handlers registered when the notification is sent
 func (s CompositeService) OnDecision(callback func(notification.DecisionNotification)) (int, error) {
 	handler := func(payload interface{}) {
 		if decisionNotification, ok := payload.(notification.DecisionNotification); ok {
@@ -187,6 +346,34 @@ func (s CompositeService) OnDecision(callback func(notification.DecisionNotifica
 	return id, nil
 }
 
+// OnDecisionOfTypes registers a handler for the Decision notifications of the given decision types only, e.g.
+// notification.FeatureVariable, it can be removed with RemoveOnDecision
+func (s CompositeService) OnDecisionOfTypes(callback func(notification.DecisionNotification), decisionTypes ...notification.DecisionNotificationType) (int, error) {
+	handler := func(payload interface{}) {
+		if decisionNotification, ok := payload.(notification.DecisionNotification); ok {
+			callback(decisionNotification)
+		}
+	}
+	filter := notification.DecisionTypeFilter(decisionTypes...)
+
+	var id int
+	var err error
+	if filteredCenter, ok := s.notificationCenter.(notification.FilteredCenter); ok {
+		id, err = filteredCenter.AddFilteredHandler(notification.Decision, handler, filter)
+	} else {
+		id, err = s.notificationCenter.AddHandler(notification.Decision, func(payload interface{}) {
+			if filter(payload) {
+				handler(payload)
+			}
+		})
+	}
+	if err != nil {
+		csLogger.Warning("Problem with adding notification handler")
+		return 0, err
+	}
+	return id, nil
+}
+
 // RemoveOnDecision removes handler for Decision notification with given id
 func (s CompositeService) RemoveOnDecision(id int) error {
 	if err := s.notificationCenter.RemoveHandler(id, notification.Decision); err != nil {
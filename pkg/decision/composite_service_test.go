@@ -21,6 +21,8 @@ import (
 
 	"github.com/stretchr/testify/suite"
 
+	"github.com/optimizely/go-sdk/pkg/config"
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/notification"
 	"github.com/optimizely/go-sdk/pkg/registry"
@@ -92,6 +94,69 @@ func (s *CompositeServiceFeatureTestSuite) TestDecisionListeners() {
 	s.Equal(numberOfCalls, 1)
 }
 
+func (s *CompositeServiceFeatureTestSuite) TestHoldoutNotificationInfo() {
+	decisionService := &CompositeService{
+		compositeFeatureService: s.mockFeatureService,
+		holdoutService:          NewHoldoutService(100, new(MockExperimentDecisionService)),
+		notificationCenter:      notification.NewNotificationCenter(),
+	}
+	note := notification.DecisionNotification{}
+	decisionService.OnDecision(func(notification notification.DecisionNotification) {
+		note = notification
+	})
+
+	heldOutDecision := FeatureDecision{
+		Experiment: testExp1111,
+		Decision:   Decision{Reason: reasons.HeldOut},
+		Source:     FeatureTest,
+	}
+	s.mockFeatureService.On("GetDecision", s.decisionContext, s.testUserContext).Return(heldOutDecision, nil).Once()
+	decisionService.GetFeatureDecision(s.decisionContext, s.testUserContext)
+	s.True(note.HeldOut)
+	s.False(note.Bucketed)
+	s.Equal(true, note.DecisionInfo["heldOut"])
+	s.Equal(&notification.ExperimentDecisionInfo{ExperimentKey: testExp1111.Key}, note.FeatureInfo.SourceInfo)
+
+	// the rollouts are not affected by the holdout, the users held out of the feature tests are not flagged on them
+	rolloutDecision := FeatureDecision{
+		Experiment: testExp1111,
+		Variation:  &testExp1111Var2222,
+		Source:     Rollout,
+	}
+	s.mockFeatureService.On("GetDecision", s.decisionContext, s.testUserContext).Return(rolloutDecision, nil).Once()
+	decisionService.GetFeatureDecision(s.decisionContext, s.testUserContext)
+	s.False(note.HeldOut)
+	s.True(note.Bucketed)
+	s.NotContains(note.DecisionInfo, "heldOut")
+}
+
+func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersOfTypes() {
+	expectedFeatureDecision := FeatureDecision{
+		Experiment: testExp1111,
+		Variation:  &testExp1111Var2222,
+	}
+	decisionService := &CompositeService{
+		compositeFeatureService: s.mockFeatureService,
+		notificationCenter:      notification.NewNotificationCenter(),
+	}
+	s.mockFeatureService.On("GetDecision", s.decisionContext, s.testUserContext).Return(expectedFeatureDecision, nil)
+
+	var featureNotes, variableNotes []notification.DecisionNotification
+	decisionService.OnDecisionOfTypes(func(note notification.DecisionNotification) {
+		featureNotes = append(featureNotes, note)
+	}, notification.Feature, notification.FeatureTest)
+	id, err := decisionService.OnDecisionOfTypes(func(note notification.DecisionNotification) {
+		variableNotes = append(variableNotes, note)
+	}, notification.FeatureVariable)
+	s.NoError(err)
+
+	decisionService.GetFeatureDecision(s.decisionContext, s.testUserContext)
+	s.Len(featureNotes, 1)
+	s.Empty(variableNotes)
+
+	s.NoError(decisionService.RemoveOnDecision(id))
+}
+
 func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWithFloatVariable() {
 
 	compositeExperimentService := NewCompositeExperimentService()
@@ -128,6 +193,12 @@ func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWith
 
 	s.Equal(expectedDecisionInfo, note.DecisionInfo)
 
+	expectedFeatureInfo := &notification.FeatureDecisionInfo{FeatureKey: "my_test_feature_3333", Source: string(FeatureTest),
+		SourceInfo:  &notification.ExperimentDecisionInfo{ExperimentKey: "test_experiment_1111", VariationKey: "2222"},
+		VariableKey: "Key", VariableType: entities.Double, VariableValue: 23.34}
+	s.Equal(expectedFeatureInfo, note.FeatureInfo)
+	s.Nil(note.ExperimentInfo)
+
 }
 
 func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWithIntegerVariable() {
@@ -166,6 +237,12 @@ func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWith
 
 	s.Equal(expectedDecisionInfo, note.DecisionInfo)
 
+	expectedFeatureInfo := &notification.FeatureDecisionInfo{FeatureKey: "my_test_feature_3333", Source: string(FeatureTest),
+		SourceInfo:  &notification.ExperimentDecisionInfo{ExperimentKey: "test_experiment_1111", VariationKey: "2222"},
+		VariableKey: "Key", VariableType: entities.Integer, VariableValue: 23}
+	s.Equal(expectedFeatureInfo, note.FeatureInfo)
+	s.Nil(note.ExperimentInfo)
+
 }
 
 func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWithBoolVariable() {
@@ -204,6 +281,12 @@ func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWith
 
 	s.Equal(expectedDecisionInfo, note.DecisionInfo)
 
+	expectedFeatureInfo := &notification.FeatureDecisionInfo{FeatureKey: "my_test_feature_3333", Source: string(FeatureTest),
+		SourceInfo:  &notification.ExperimentDecisionInfo{ExperimentKey: "test_experiment_1111", VariationKey: "2222"},
+		VariableKey: "Key", VariableType: entities.Boolean, VariableValue: true}
+	s.Equal(expectedFeatureInfo, note.FeatureInfo)
+	s.Nil(note.ExperimentInfo)
+
 }
 
 func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWithWrongTypelVariable() {
@@ -242,6 +325,42 @@ func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWith
 
 	s.Equal(expectedDecisionInfo, note.DecisionInfo)
 
+	expectedFeatureInfo := &notification.FeatureDecisionInfo{FeatureKey: "my_test_feature_3333", Source: string(FeatureTest),
+		SourceInfo:  &notification.ExperimentDecisionInfo{ExperimentKey: "test_experiment_1111", VariationKey: "2222"},
+		VariableKey: "Key", VariableType: entities.Double, VariableValue: "string"}
+	s.Equal(expectedFeatureInfo, note.FeatureInfo)
+	s.Nil(note.ExperimentInfo)
+
+}
+
+func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWithUnknownTypeVariable() {
+
+	compositeExperimentService := NewCompositeExperimentService()
+	compositeFeatureDecisionService := NewCompositeFeatureService(compositeExperimentService)
+	s.decisionContext.Variable = entities.Variable{
+		DefaultValue: `{"key":"value"}`,
+		ID:           "1",
+		Key:          "Key",
+		Type:         entities.VariableType("json"),
+	}
+
+	decisionService := &CompositeService{
+		compositeFeatureService: compositeFeatureDecisionService,
+		notificationCenter:      registry.GetNotificationCenter("some_key_unknown_type"),
+	}
+
+	note := notification.DecisionNotification{}
+	callback := func(notification notification.DecisionNotification) {
+		note = notification
+	}
+	id, _ := decisionService.OnDecision(callback)
+	s.NotEqual(id, 0)
+
+	decisionService.GetFeatureDecision(s.decisionContext, s.testUserContext)
+
+	s.Equal(entities.VariableType("json"), note.FeatureInfo.VariableType)
+	s.Equal(`{"key":"value"}`, note.FeatureInfo.VariableValue)
+	s.Equal(`{"key":"value"}`, note.DecisionInfo["feature"].(map[string]interface{})["variableValue"])
 }
 
 func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWithNoVariable() {
@@ -273,6 +392,87 @@ func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWith
 		"sourceInfo": map[string]string{"experimentKey": "test_experiment_1111", "variationKey": "2222"}}}
 
 	s.Equal(expectedDecisionInfo, note.DecisionInfo)
+
+	expectedFeatureInfo := &notification.FeatureDecisionInfo{FeatureKey: "my_test_feature_3333", Source: string(FeatureTest),
+		SourceInfo: &notification.ExperimentDecisionInfo{ExperimentKey: "test_experiment_1111", VariationKey: "2222"}}
+	s.Equal(expectedFeatureInfo, note.FeatureInfo)
+}
+
+func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWithRolloutSource() {
+	s.decisionContext.Feature = &testFeatRollout3336
+	featureDecision := FeatureDecision{
+		Experiment: testExp1115,
+		Variation:  &testExp1115Var2227,
+		Source:     Rollout,
+	}
+	s.mockFeatureService.On("GetDecision", s.decisionContext, s.testUserContext).Return(featureDecision, nil)
+
+	decisionService := &CompositeService{
+		compositeFeatureService: s.mockFeatureService,
+		notificationCenter:      notification.NewNotificationCenter(),
+	}
+	note := notification.DecisionNotification{}
+	callback := func(notification notification.DecisionNotification) {
+		note = notification
+	}
+	decisionService.OnDecision(callback)
+	decisionService.GetFeatureDecision(s.decisionContext, s.testUserContext)
+
+	expectedDecisionInfo := map[string]interface{}{"feature": map[string]interface{}{"featureEnabled": true, "featureKey": "test_feature_rollout_3336_key", "source": Rollout,
+		"sourceInfo": map[string]string{"ruleId": "1115", "ruleKey": "test_experiment_1115", "ruleIndex": "1"}}}
+	s.Equal(expectedDecisionInfo, note.DecisionInfo)
+
+	expectedFeatureInfo := &notification.FeatureDecisionInfo{FeatureKey: "test_feature_rollout_3336_key", FeatureEnabled: true, Source: string(Rollout),
+		RolloutInfo: &notification.RolloutDecisionInfo{RuleID: "1115", RuleKey: "test_experiment_1115", RuleIndex: 1}}
+	s.Equal(expectedFeatureInfo, note.FeatureInfo)
+	s.True(note.Bucketed)
+}
+
+func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersNotificationWithRolloutNotBucketed() {
+	s.decisionContext.Feature = &testFeatRollout3336
+	featureDecision := FeatureDecision{
+		Decision:   Decision{Reason: reasons.FailedRolloutTargeting},
+		Experiment: testExp1115,
+		Source:     Rollout,
+	}
+	s.mockFeatureService.On("GetDecision", s.decisionContext, s.testUserContext).Return(featureDecision, nil)
+
+	decisionService := &CompositeService{
+		compositeFeatureService: s.mockFeatureService,
+		notificationCenter:      notification.NewNotificationCenter(),
+	}
+	note := notification.DecisionNotification{}
+	callback := func(notification notification.DecisionNotification) {
+		note = notification
+	}
+	decisionService.OnDecision(callback)
+	decisionService.GetFeatureDecision(s.decisionContext, s.testUserContext)
+
+	s.Equal(map[string]string{}, note.DecisionInfo["feature"].(map[string]interface{})["sourceInfo"])
+	s.Nil(note.FeatureInfo.RolloutInfo)
+	s.False(note.FeatureInfo.FeatureEnabled)
+	s.False(note.Bucketed)
+	s.Equal(string(reasons.FailedRolloutTargeting), note.Reason)
+}
+
+func (s *CompositeServiceFeatureTestSuite) TestGetFeatureDecisionConfigNotReady() {
+	notificationCenter := notification.NewNotificationCenter()
+	decisionService := &CompositeService{
+		compositeFeatureService: s.mockFeatureService,
+		notificationCenter:      notificationCenter,
+	}
+	numberOfCalls := 0
+	decisionService.OnDecision(func(notification.DecisionNotification) { numberOfCalls++ })
+
+	// the static manager has not been given a config yet
+	projectConfig, _ := config.NewStaticProjectConfigManager(nil).GetConfig()
+	decisionContext := FeatureDecisionContext{Feature: &testFeat3333, ProjectConfig: projectConfig}
+	featureDecision, err := decisionService.GetFeatureDecision(decisionContext, s.testUserContext)
+
+	s.Equal(ErrConfigNotReady, err)
+	s.Equal(FeatureDecision{}, featureDecision)
+	s.Equal(0, numberOfCalls)
+	s.mockFeatureService.AssertNotCalled(s.T(), "GetDecision", decisionContext, s.testUserContext)
 }
 
 func (s *CompositeServiceFeatureTestSuite) TestNewCompositeService() {
@@ -290,6 +490,89 @@ func (s *CompositeServiceFeatureTestSuite) TestNewCompositeServiceWithCustomOpti
 	s.IsType(&CompositeFeatureService{}, compositeService.compositeFeatureService)
 }
 
+func (s *CompositeServiceFeatureTestSuite) TestNewCompositeServiceWithDecisionCache() {
+	compositeService := NewCompositeService("sdk_key", WithDecisionCache(100))
+	if cachingFeatureService, ok := compositeService.compositeFeatureService.(*CachingFeatureService); s.True(ok) {
+		s.Equal(100, cachingFeatureService.size)
+		s.IsType(&CompositeFeatureService{}, cachingFeatureService.featureService)
+	}
+}
+
+func (s *CompositeServiceFeatureTestSuite) TestFeatureOverridesWithDecisionCache() {
+	overrides := NewMapFeatureOverridesStore()
+	compositeService := NewCompositeService("sdk_key", WithDecisionCache(100), WithFeatureOverrideStore(overrides))
+	compositeFeatureService, ok := compositeService.compositeFeatureService.(*CompositeFeatureService)
+	s.Require().True(ok)
+	s.Require().Len(compositeFeatureService.featureServices, 2)
+	s.IsType(&FeatureOverrideService{}, compositeFeatureService.featureServices[0])
+	cachingFeatureService, ok := compositeFeatureService.featureServices[1].(*CachingFeatureService)
+	s.Require().True(ok)
+	cachingFeatureService.featureService = s.mockFeatureService
+
+	expectedFeatureDecision := FeatureDecision{
+		Experiment: testExp1111,
+		Variation:  &testExp1111Var2222,
+		Source:     FeatureTest,
+	}
+	decisionContext := FeatureDecisionContext{
+		Feature:       &testFeat3333,
+		ProjectConfig: revisionProjectConfig{revision: "1"},
+	}
+	testUserContext := entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"incident": "on"}}
+	s.mockFeatureService.On("GetDecision", decisionContext, testUserContext).Return(expectedFeatureDecision, nil)
+	featureDecision, err := compositeService.GetFeatureDecision(decisionContext, testUserContext)
+	s.NoError(err)
+	s.Equal(expectedFeatureDecision, featureDecision)
+	s.Equal(1, cachingFeatureService.Len())
+
+	// the override set after the decision is cached wins
+	overrides.SetOverride(testFeat3333.Key, FeatureAttributeOverride{AttributeName: "incident", AttributeValue: "on", FeatureEnabled: false})
+	featureDecision, err = compositeService.GetFeatureDecision(decisionContext, testUserContext)
+	s.NoError(err)
+	s.Equal(Override, featureDecision.Source)
+	s.False(featureDecision.Variation.FeatureEnabled)
+
+	// the cached decision applies again once the override is removed
+	overrides.RemoveOverride(testFeat3333.Key, "incident", "on")
+	featureDecision, err = compositeService.GetFeatureDecision(decisionContext, testUserContext)
+	s.NoError(err)
+	s.Equal(expectedFeatureDecision, featureDecision)
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 1)
+}
+
+func (s *CompositeServiceFeatureTestSuite) TestDecisionListenersWithDecisionCache() {
+	expectedFeatureDecision := FeatureDecision{
+		Experiment: testExp1111,
+		Variation:  &testExp1111Var2222,
+		Source:     FeatureTest,
+	}
+	decisionContext := FeatureDecisionContext{
+		Feature:       &testFeat3333,
+		ProjectConfig: revisionProjectConfig{revision: "1"},
+	}
+	decisionService := &CompositeService{
+		compositeFeatureService: NewCachingFeatureService(s.mockFeatureService, 10),
+		notificationCenter:      notification.NewNotificationCenter(),
+	}
+	s.mockFeatureService.On("GetDecision", decisionContext, s.testUserContext).Return(expectedFeatureDecision, nil)
+
+	var notes []notification.DecisionNotification
+	decisionService.OnDecision(func(note notification.DecisionNotification) {
+		notes = append(notes, note)
+	})
+	for i := 0; i < 2; i++ {
+		featureDecision, err := decisionService.GetFeatureDecision(decisionContext, s.testUserContext)
+		s.NoError(err)
+		s.Equal(expectedFeatureDecision, featureDecision)
+	}
+
+	// the cached decision is notified as well
+	s.mockFeatureService.AssertNumberOfCalls(s.T(), "GetDecision", 1)
+	if s.Len(notes, 2) {
+		s.Equal(notes[0], notes[1])
+	}
+}
+
 type CompositeServiceExperimentTestSuite struct {
 	suite.Suite
 	decisionContext       ExperimentDecisionContext
@@ -353,7 +636,189 @@ func (s *CompositeServiceExperimentTestSuite) TestDecisionListeners() {
 	s.Equal(numberOfCalls, 1)
 }
 
+func (s *CompositeServiceExperimentTestSuite) TestDecisionListenersNotificationInfo() {
+	expectedExperimentDecision := ExperimentDecision{
+		Variation: &testExp1111Var2222,
+	}
+	decisionService := &CompositeService{
+		compositeExperimentService: s.mockExperimentService,
+		notificationCenter:         notification.NewNotificationCenter(),
+	}
+	s.mockExperimentService.On("GetDecision", s.decisionContext, s.testUserContext).Return(expectedExperimentDecision, nil)
+
+	note := notification.DecisionNotification{}
+	callback := func(notification notification.DecisionNotification) {
+		note = notification
+	}
+	decisionService.OnDecision(callback)
+	decisionService.GetExperimentDecision(s.decisionContext, s.testUserContext)
+
+	s.Equal(notification.ABTest, note.Type)
+	s.Equal(map[string]interface{}{"experimentKey": "test_experiment_1111", "variationKey": "2222"}, note.DecisionInfo)
+	s.Equal(&notification.ExperimentDecisionInfo{ExperimentKey: "test_experiment_1111", VariationKey: "2222"}, note.ExperimentInfo)
+	s.Nil(note.FeatureInfo)
+	s.True(note.Bucketed)
+}
+
+func (s *CompositeServiceExperimentTestSuite) TestNotBucketedNotificationInfo() {
+	expectedExperimentDecision := ExperimentDecision{
+		Decision: Decision{Reason: reasons.FailedAudienceTargeting},
+	}
+	decisionService := &CompositeService{
+		compositeExperimentService: s.mockExperimentService,
+		notificationCenter:         notification.NewNotificationCenter(),
+	}
+	s.mockExperimentService.On("GetDecision", s.decisionContext, s.testUserContext).Return(expectedExperimentDecision, nil)
+
+	notified := false
+	note := notification.DecisionNotification{}
+	callback := func(notification notification.DecisionNotification) {
+		notified = true
+		note = notification
+	}
+	decisionService.OnDecision(callback)
+	decisionService.GetExperimentDecision(s.decisionContext, s.testUserContext)
+
+	s.True(notified)
+	s.Equal(notification.ABTest, note.Type)
+	s.Equal(&notification.ExperimentDecisionInfo{ExperimentKey: "test_experiment_1111"}, note.ExperimentInfo)
+	s.False(note.Bucketed)
+	s.Equal(string(reasons.FailedAudienceTargeting), note.Reason)
+}
+
+func (s *CompositeServiceExperimentTestSuite) TestHoldoutNotificationInfo() {
+	holdoutService := NewHoldoutService(100, s.mockExperimentService)
+	decisionService := &CompositeService{
+		compositeExperimentService: holdoutService,
+		holdoutService:             holdoutService,
+		notificationCenter:         notification.NewNotificationCenter(),
+	}
+
+	note := notification.DecisionNotification{}
+	callback := func(notification notification.DecisionNotification) {
+		note = notification
+	}
+	decisionService.OnDecision(callback)
+	experimentDecision, err := decisionService.GetExperimentDecision(s.decisionContext, s.testUserContext)
+
+	s.NoError(err)
+	s.Nil(experimentDecision.Variation)
+	s.True(note.HeldOut)
+	s.False(note.Bucketed)
+	s.Equal(string(reasons.HeldOut), note.Reason)
+	s.Equal(map[string]interface{}{"experimentKey": "test_experiment_1111", "heldOut": true}, note.DecisionInfo)
+	s.mockExperimentService.AssertNotCalled(s.T(), "GetDecision", s.decisionContext, s.testUserContext)
+}
+
+func (s *CompositeServiceExperimentTestSuite) TestNotRunningNotificationInfo() {
+	pausedExperiment := testExp1111
+	pausedExperiment.Status = entities.ExperimentPaused
+	decisionContext := ExperimentDecisionContext{
+		Experiment:    &pausedExperiment,
+		ProjectConfig: s.decisionContext.ProjectConfig,
+	}
+	decisionService := &CompositeService{
+		compositeExperimentService: &CompositeExperimentService{experimentServices: []ExperimentService{s.mockExperimentService}},
+		notificationCenter:         notification.NewNotificationCenter(),
+	}
+
+	note := notification.DecisionNotification{}
+	callback := func(notification notification.DecisionNotification) {
+		note = notification
+	}
+	decisionService.OnDecision(callback)
+	experimentDecision, err := decisionService.GetExperimentDecision(decisionContext, s.testUserContext)
+
+	s.NoError(err)
+	s.Nil(experimentDecision.Variation)
+	s.True(note.NotRunning)
+	s.False(note.Bucketed)
+	s.Equal(string(reasons.ExperimentNotRunning), note.Reason)
+	s.Equal(map[string]interface{}{"experimentKey": "test_experiment_1111", "notRunning": true}, note.DecisionInfo)
+	s.mockExperimentService.AssertNotCalled(s.T(), "GetDecision", decisionContext, s.testUserContext)
+}
+
+func (s *CompositeServiceExperimentTestSuite) TestGetExperimentDecisionConfigNotReady() {
+	notificationCenter := notification.NewNotificationCenter()
+	decisionService := &CompositeService{
+		compositeExperimentService: s.mockExperimentService,
+		notificationCenter:         notificationCenter,
+	}
+	numberOfCalls := 0
+	decisionService.OnDecision(func(notification.DecisionNotification) { numberOfCalls++ })
+
+	projectConfig, _ := config.NewStaticProjectConfigManager(nil).GetConfig()
+	decisionContext := ExperimentDecisionContext{Experiment: &testExp1111, ProjectConfig: projectConfig}
+	experimentDecision, err := decisionService.GetExperimentDecision(decisionContext, s.testUserContext)
+
+	s.Equal(ErrConfigNotReady, err)
+	s.Nil(experimentDecision.Variation)
+	s.Equal(0, numberOfCalls)
+	s.mockExperimentService.AssertNotCalled(s.T(), "GetDecision", decisionContext, s.testUserContext)
+}
+
+func (s *CompositeServiceExperimentTestSuite) TestWarmUserProfiles() {
+	backend := &countingUserProfileService{InMemoryUserProfileService: NewInMemoryUserProfileService()}
+	compositeExperimentService := NewCompositeExperimentService(WithUserProfileService(NewCachingUserProfileService(backend, 10)))
+	compositeService := NewCompositeService("sdk_key", WithCompositeExperimentService(compositeExperimentService), WithHoldout(25))
+
+	compositeService.WarmUserProfiles([]string{"test_user_1"})
+	s.Equal([][]string{{"test_user_1"}}, backend.batchLookups)
+}
+
+func (s *CompositeServiceExperimentTestSuite) TestNewCompositeServiceWithHoldout() {
+	compositeExperimentService := NewCompositeExperimentService()
+	compositeService := NewCompositeService("sdk_key", WithCompositeExperimentService(compositeExperimentService), WithHoldout(25))
+	s.IsType(&HoldoutService{}, compositeService.compositeExperimentService)
+	s.Equal(compositeService.holdoutService, compositeService.compositeExperimentService)
+	s.Equal(compositeExperimentService, compositeService.holdoutService.experimentService)
+
+	compositeService = NewCompositeService("sdk_key")
+	s.Nil(compositeService.holdoutService)
+}
+
 func TestCompositeServiceTestSuites(t *testing.T) {
 	suite.Run(t, new(CompositeServiceExperimentTestSuite))
 	suite.Run(t, new(CompositeServiceFeatureTestSuite))
 }
+
+// staticFeatureService returns the same decision for all the users, without the overhead of a mock
+type staticFeatureService struct {
+	decision FeatureDecision
+}
+
+func (f staticFeatureService) GetDecision(FeatureDecisionContext, entities.UserContext) (FeatureDecision, error) {
+	return f.decision, nil
+}
+
+func benchmarkGetFeatureDecision(b *testing.B, listeners int) {
+	notificationCenter := notification.NewNotificationCenter()
+	for i := 0; i < listeners; i++ {
+		notificationCenter.AddHandler(notification.Decision, func(interface{}) {})
+	}
+	decisionService := &CompositeService{
+		compositeFeatureService: staticFeatureService{FeatureDecision{
+			Experiment: testExp1111,
+			Variation:  &testExp1111Var2222,
+			Source:     FeatureTest,
+		}},
+		notificationCenter: notificationCenter,
+	}
+	decisionContext := FeatureDecisionContext{Feature: &testFeat3333, ProjectConfig: new(mockProjectConfig)}
+	userContext := entities.UserContext{ID: "test_user"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		decisionService.GetFeatureDecision(decisionContext, userContext)
+	}
+}
+
+// the decision notification is not built without listeners, compare the allocations with the benchmark below
+func BenchmarkGetFeatureDecisionWithoutListeners(b *testing.B) {
+	benchmarkGetFeatureDecision(b, 0)
+}
+
+func BenchmarkGetFeatureDecisionWithListener(b *testing.B) {
+	benchmarkGetFeatureDecision(b, 1)
+}
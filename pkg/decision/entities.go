@@ -18,11 +18,23 @@
 package decision
 
 import (
+	"context"
+	"errors"
+	"reflect"
+
 	"github.com/optimizely/go-sdk/pkg/config"
 	"github.com/optimizely/go-sdk/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/pkg/entities"
 )
 
+// ErrConfigNotReady is returned when a decision is requested before a project config is available
+var ErrConfigNotReady = errors.New("project config is not ready")
+
+// configNotReady returns true if the given project config is nil, including a nil pointer held by the interface
+func configNotReady(projectConfig config.ProjectConfig) bool {
+	return projectConfig == nil || (reflect.ValueOf(projectConfig).Kind() == reflect.Ptr && reflect.ValueOf(projectConfig).IsNil())
+}
+
 // ExperimentDecisionContext contains the information needed to be able to make a decision for a given experiment
 type ExperimentDecisionContext struct {
 	Experiment    *entities.Experiment
@@ -44,11 +56,35 @@ const (
 	Rollout Source = "rollout"
 	// FeatureTest - the decision came from a feature test
 	FeatureTest Source = "feature-test"
+	// Override - the decision came from an attribute based feature override
+	Override Source = "override"
 )
 
 // Decision contains base information about a decision
 type Decision struct {
 	Reason reasons.Reason
+	// BucketingTrace explains the bucketing of the user, it is only set for the decision contexts requesting it
+	BucketingTrace []string
+}
+
+type bucketingTraceKey struct{}
+
+// WithBucketingTrace returns a context requesting the bucketing of the decisions made with it to be explained in the
+// BucketingTrace of the decisions, which is not computed otherwise
+func WithBucketingTrace(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, bucketingTraceKey{}, true)
+}
+
+// bucketingTraceRequested returns whether the decisions made with the context have to explain their bucketing
+func bucketingTraceRequested(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	requested, _ := ctx.Value(bucketingTraceKey{}).(bool)
+	return requested
 }
 
 // FeatureDecision contains the decision information about a feature
@@ -19,17 +19,24 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 
 	"github.com/optimizely/go-sdk/pkg/decision/evaluator/matchers"
+	"github.com/optimizely/go-sdk/pkg/decision/evaluator/matchers/utils"
 	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/logging"
 )
 
+var logger = logging.GetLogger("AudienceEvaluator")
+
 const (
 	exactMatchType     = "exact"
 	existsMatchType    = "exists"
 	ltMatchType        = "lt"
 	gtMatchType        = "gt"
 	substringMatchType = "substring"
+	containsMatchType  = "contains"
 )
 
 // ItemEvaluator evaluates a condition against the given user's attributes
@@ -38,7 +45,10 @@ type ItemEvaluator interface {
 }
 
 // CustomAttributeConditionEvaluator evaluates conditions with custom attributes
-type CustomAttributeConditionEvaluator struct{}
+type CustomAttributeConditionEvaluator struct {
+	// lenientTypes coerces string attributes to the type of the condition value before matching
+	lenientTypes bool
+}
 
 // Evaluate returns true if the given user's attributes match the condition
 func (c CustomAttributeConditionEvaluator) Evaluate(condition entities.Condition, condTreeParams *entities.TreeParameters) (bool, error) {
@@ -74,24 +84,81 @@ func (c CustomAttributeConditionEvaluator) Evaluate(condition entities.Condition
 		matcher = matchers.SubstringMatcher{
 			Condition: condition,
 		}
+	case containsMatchType:
+		matcher = matchers.ContainsMatcher{
+			Condition: condition,
+		}
 	default:
 		return false, fmt.Errorf(`invalid Condition matcher "%s"`, condition.Match)
 	}
 
 	user := *condTreeParams.User
+	if c.lenientTypes {
+		user = coerceAttribute(condition, matchType, user)
+	}
 	result, err := matcher.Match(user)
 	return result, err
 }
 
+// coerceAttribute returns the user with the string attribute of the condition converted to the number or bool type
+// of the condition value, the user is returned as is when the attribute cannot be safely converted
+func coerceAttribute(condition entities.Condition, matchType string, user entities.UserContext) entities.UserContext {
+	stringValue, ok := user.Attributes[condition.Name].(string)
+	if !ok {
+		return user
+	}
+
+	var coercedValue interface{}
+	switch conditionValue := condition.Value.(type) {
+	case string:
+		return user
+	case bool:
+		if matchType != exactMatchType {
+			return user
+		}
+		boolValue, err := strconv.ParseBool(stringValue)
+		if err != nil {
+			return user
+		}
+		coercedValue = boolValue
+	default:
+		if _, isNumber := utils.ToFloat(conditionValue); !isNumber ||
+			(matchType != exactMatchType && matchType != ltMatchType && matchType != gtMatchType) {
+			return user
+		}
+		floatValue, err := strconv.ParseFloat(stringValue, 64)
+		if err != nil || math.IsInf(floatValue, 0) || math.IsNaN(floatValue) {
+			return user
+		}
+		coercedValue = floatValue
+	}
+
+	logger.Debug(fmt.Sprintf(`Coerced the value "%s" of user attribute "%s" to %T for audience condition "%s".`,
+		stringValue, condition.Name, coercedValue, condition.Name))
+	attributes := make(map[string]interface{}, len(user.Attributes))
+	for key, value := range user.Attributes {
+		attributes[key] = value
+	}
+	attributes[condition.Name] = coercedValue
+	user.Attributes = attributes
+	return user
+}
+
 // AudienceConditionEvaluator evaluates conditions with audience condition
-type AudienceConditionEvaluator struct{}
+type AudienceConditionEvaluator struct {
+	// conditionTreeEvaluator evaluates the tree of the audience, a new MixedTreeEvaluator if nil
+	conditionTreeEvaluator TreeEvaluator
+}
 
 // Evaluate returns true if the given user's attributes match the condition
 func (c AudienceConditionEvaluator) Evaluate(audienceID string, condTreeParams *entities.TreeParameters) (bool, error) {
 
 	if audience, ok := condTreeParams.AudienceMap[audienceID]; ok {
 		condTree := audience.ConditionTree
-		conditionTreeEvaluator := NewMixedTreeEvaluator()
+		conditionTreeEvaluator := c.conditionTreeEvaluator
+		if conditionTreeEvaluator == nil {
+			conditionTreeEvaluator = NewMixedTreeEvaluator()
+		}
 		retValue, isValid := conditionTreeEvaluator.Evaluate(condTree, condTreeParams)
 		if !isValid {
 			return false, fmt.Errorf(`an error occurred while evaluating nested tree for audience ID "%s"`, audienceID)
@@ -81,3 +81,61 @@ func TestCustomAttributeConditionEvaluatorWithoutMatchType(t *testing.T) {
 	result, _ = conditionEvaluator.Evaluate(condition, condTreeParams)
 	assert.Equal(t, result, false)
 }
+
+func TestCustomAttributeConditionEvaluatorContains(t *testing.T) {
+	conditionEvaluator := CustomAttributeConditionEvaluator{}
+	condition := entities.Condition{
+		Match: "contains",
+		Value: "beta",
+		Name:  "roles",
+		Type:  "custom_attribute",
+	}
+
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"roles": []interface{}{"admin", "beta"},
+		},
+	}
+
+	condTreeParams := entities.NewTreeParameters(&user, map[string]entities.Audience{})
+	result, err := conditionEvaluator.Evaluate(condition, condTreeParams)
+	assert.NoError(t, err)
+	assert.True(t, result)
+}
+
+func TestCustomAttributeConditionEvaluatorLenientTypes(t *testing.T) {
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"age":     "25",
+			"beta":    "true",
+			"country": "us",
+		},
+	}
+	condTreeParams := entities.NewTreeParameters(&user, map[string]entities.Audience{})
+	conditions := []entities.Condition{
+		{Match: "gt", Value: 18.0, Name: "age", Type: "custom_attribute"},
+		{Match: "exact", Value: 25.0, Name: "age", Type: "custom_attribute"},
+		{Match: "exact", Value: true, Name: "beta", Type: "custom_attribute"},
+	}
+
+	// string attributes do not match number and bool conditions by default
+	for _, condition := range conditions {
+		_, err := CustomAttributeConditionEvaluator{}.Evaluate(condition, condTreeParams)
+		assert.Error(t, err)
+
+		result, err := CustomAttributeConditionEvaluator{lenientTypes: true}.Evaluate(condition, condTreeParams)
+		assert.NoError(t, err)
+		assert.True(t, result)
+	}
+	// the attributes of the user are left untouched
+	assert.Equal(t, "25", user.Attributes["age"])
+
+	// values which cannot be converted still evaluate to NULL
+	condition := entities.Condition{Match: "lt", Value: 18.0, Name: "country", Type: "custom_attribute"}
+	_, err := CustomAttributeConditionEvaluator{lenientTypes: true}.Evaluate(condition, condTreeParams)
+	assert.Error(t, err)
+	condition = entities.Condition{Match: "exact", Value: "us", Name: "country", Type: "custom_attribute"}
+	result, err := CustomAttributeConditionEvaluator{lenientTypes: true}.Evaluate(condition, condTreeParams)
+	assert.NoError(t, err)
+	assert.True(t, result)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/metrics"
 )
 
 const customAttributeType = "custom_attribute"
@@ -41,11 +42,40 @@ type TreeEvaluator interface {
 
 // MixedTreeEvaluator evaluates a tree of mixed node types (condition node or audience nodes)
 type MixedTreeEvaluator struct {
+	// counters are nil unless evaluation metrics are enabled
+	conditionCounter    metrics.Counter
+	shortCircuitCounter metrics.Counter
+	// lenientTypes coerces string attributes to the type of the condition values, it is strict by default
+	lenientTypes bool
+}
+
+// TreeEvaluatorOptionFunc is used to provide custom configuration to the MixedTreeEvaluator
+type TreeEvaluatorOptionFunc func(*MixedTreeEvaluator)
+
+// WithEvaluationMetrics counts the conditions evaluated and the short-circuited nodes of the trees with the counters
+// of the given registry, it is off by default
+func WithEvaluationMetrics(metricsRegistry metrics.Registry) TreeEvaluatorOptionFunc {
+	return func(c *MixedTreeEvaluator) {
+		c.conditionCounter = metricsRegistry.GetCounter(metrics.AudienceConditionEvaluations)
+		c.shortCircuitCounter = metricsRegistry.GetCounter(metrics.AudienceShortCircuits)
+	}
+}
+
+// WithLenientTypeMatching coerces string attributes to the number or bool type of the condition values before they
+// are matched, so that a "25" attribute matches a number condition. Attributes are matched strictly by default.
+func WithLenientTypeMatching() TreeEvaluatorOptionFunc {
+	return func(c *MixedTreeEvaluator) {
+		c.lenientTypes = true
+	}
 }
 
 // NewMixedTreeEvaluator creates a condition tree evaluator with the out-of-the-box condition evaluators
-func NewMixedTreeEvaluator() *MixedTreeEvaluator {
-	return &MixedTreeEvaluator{}
+func NewMixedTreeEvaluator(options ...TreeEvaluatorOptionFunc) *MixedTreeEvaluator {
+	mixedTreeEvaluator := &MixedTreeEvaluator{}
+	for _, opt := range options {
+		opt(mixedTreeEvaluator)
+	}
+	return mixedTreeEvaluator
 }
 
 // Evaluate returns whether the userAttributes satisfy the given condition tree and the evaluation of the condition is valid or not (to handle null bubbling)
@@ -66,10 +96,14 @@ func (c MixedTreeEvaluator) Evaluate(node *entities.TreeNode, condTreeParams *en
 	var err error
 	switch v := node.Item.(type) {
 	case entities.Condition:
-		evaluator := CustomAttributeConditionEvaluator{}
+		if c.conditionCounter != nil {
+			c.conditionCounter.Add(1)
+		}
+		evaluator := CustomAttributeConditionEvaluator{lenientTypes: c.lenientTypes}
 		result, err = evaluator.Evaluate(node.Item.(entities.Condition), condTreeParams)
 	case string:
-		evaluator := AudienceConditionEvaluator{}
+		// the nested tree of the audience is evaluated, and counted, by this evaluator as well
+		evaluator := AudienceConditionEvaluator{conditionTreeEvaluator: c}
 		result, err = evaluator.Evaluate(node.Item.(string), condTreeParams)
 	default:
 		fmt.Printf("I don't know about type %T!\n", v)
@@ -85,12 +119,11 @@ func (c MixedTreeEvaluator) Evaluate(node *entities.TreeNode, condTreeParams *en
 
 func (c MixedTreeEvaluator) evaluateAnd(nodes []*entities.TreeNode, condTreeParams *entities.TreeParameters) (evalResult, isValid bool) {
 	sawInvalid := false
-	for _, node := range nodes {
+	for i, node := range nodes {
 		result, isValid := c.Evaluate(node, condTreeParams)
-		if !isValid {
+		if !isValid || !result {
+			c.shortCircuit(i, nodes)
 			return false, isValid
-		} else if !result {
-			return result, isValid
 		}
 	}
 
@@ -115,11 +148,12 @@ func (c MixedTreeEvaluator) evaluateNot(nodes []*entities.TreeNode, condTreePara
 
 func (c MixedTreeEvaluator) evaluateOr(nodes []*entities.TreeNode, condTreeParams *entities.TreeParameters) (evalResult, isValid bool) {
 	sawInvalid := false
-	for _, node := range nodes {
+	for i, node := range nodes {
 		result, isValid := c.Evaluate(node, condTreeParams)
 		if !isValid {
 			sawInvalid = true
 		} else if result {
+			c.shortCircuit(i, nodes)
 			return result, isValid
 		}
 	}
@@ -131,3 +165,10 @@ func (c MixedTreeEvaluator) evaluateOr(nodes []*entities.TreeNode, condTreeParam
 
 	return false, true
 }
+
+// shortCircuit counts the evaluation of the nodes stopping at the given index when there are nodes left to evaluate
+func (c MixedTreeEvaluator) shortCircuit(index int, nodes []*entities.TreeNode) {
+	if c.shortCircuitCounter != nil && index < len(nodes)-1 {
+		c.shortCircuitCounter.Add(1)
+	}
+}
@@ -6,8 +6,29 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	e "github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/metrics"
 )
 
+type testCounter struct {
+	value float64
+}
+
+func (c *testCounter) Add(delta float64) {
+	c.value += delta
+}
+
+type testMetricsRegistry struct {
+	metrics.NoopRegistry
+	counters map[string]*testCounter
+}
+
+func (r *testMetricsRegistry) GetCounter(key string) metrics.Counter {
+	if r.counters[key] == nil {
+		r.counters[key] = &testCounter{}
+	}
+	return r.counters[key]
+}
+
 var stringFooCondition = e.Condition{
 	Type:  "custom_attribute",
 	Match: "exact",
@@ -429,3 +450,34 @@ func TestConditionTreeEvaluateAnAudienceTreeMultipleAudiences(t *testing.T) {
 	result, _ = conditionTreeEvaluator.Evaluate(audienceTree, treeParams)
 	assert.True(t, result)
 }
+
+func TestConditionTreeEvaluateWithEvaluationMetrics(t *testing.T) {
+	registry := &testMetricsRegistry{counters: map[string]*testCounter{}}
+	conditionTreeEvaluator := NewMixedTreeEvaluator(WithEvaluationMetrics(registry))
+	nodes := []*e.TreeNode{{Item: stringFooCondition}, {Item: boolTrueCondition}}
+	user := e.UserContext{
+		Attributes: map[string]interface{}{
+			"string_foo": "foo",
+			"bool_true":  true,
+		},
+	}
+	condTreeParams := e.NewTreeParameters(&user, map[string]e.Audience{})
+
+	// the "or" node is satisfied by its first condition
+	result, _ := conditionTreeEvaluator.Evaluate(&e.TreeNode{Operator: "or", Nodes: nodes}, condTreeParams)
+	assert.True(t, result)
+	assert.Equal(t, float64(1), registry.counters[metrics.AudienceConditionEvaluations].value)
+	assert.Equal(t, float64(1), registry.counters[metrics.AudienceShortCircuits].value)
+
+	// all the conditions of the "and" node are evaluated
+	result, _ = conditionTreeEvaluator.Evaluate(&e.TreeNode{Operator: "and", Nodes: nodes}, condTreeParams)
+	assert.True(t, result)
+	assert.Equal(t, float64(3), registry.counters[metrics.AudienceConditionEvaluations].value)
+	assert.Equal(t, float64(1), registry.counters[metrics.AudienceShortCircuits].value)
+
+	// the conditions of nested audiences are counted too
+	treeParams := &e.TreeParameters{User: &user, AudienceMap: audienceMap}
+	result, _ = conditionTreeEvaluator.Evaluate(&e.TreeNode{Operator: "or", Nodes: []*e.TreeNode{{Item: audience11111.ID}}}, treeParams)
+	assert.True(t, result)
+	assert.True(t, registry.counters[metrics.AudienceConditionEvaluations].value > 3)
+}
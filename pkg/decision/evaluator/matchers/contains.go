@@ -0,0 +1,70 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package matchers //
+package matchers
+
+import (
+	"fmt"
+
+	"github.com/optimizely/go-sdk/pkg/decision/evaluator/matchers/utils"
+	"github.com/optimizely/go-sdk/pkg/entities"
+)
+
+// ContainsMatcher matches against the "contains" match type
+type ContainsMatcher struct {
+	Condition entities.Condition
+}
+
+// Match returns true if the user's list attribute contains the condition's value. The condition evaluates to NULL when
+// the attribute is missing or is not a list.
+func (m ContainsMatcher) Match(user entities.UserContext) (bool, error) {
+	switch m.Condition.Value.(type) {
+	case string, bool:
+	default:
+		if _, ok := utils.ToFloat(m.Condition.Value); !ok {
+			return false, fmt.Errorf("audience condition %s evaluated to NULL because the condition value type is not supported", m.Condition.Name)
+		}
+	}
+
+	attributeValue, err := user.GetListAttribute(m.Condition.Name)
+	if err != nil {
+		return false, err
+	}
+
+	for _, item := range attributeValue {
+		if m.matchItem(item) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchItem returns true if the item of the list attribute is equal to the condition's value
+func (m ContainsMatcher) matchItem(item interface{}) bool {
+	switch conditionValue := m.Condition.Value.(type) {
+	case string:
+		itemValue, ok := item.(string)
+		return ok && itemValue == conditionValue
+	case bool:
+		itemValue, ok := item.(bool)
+		return ok && itemValue == conditionValue
+	}
+
+	floatValue, _ := utils.ToFloat(m.Condition.Value)
+	itemValue, ok := utils.ToFloat(item)
+	return ok && itemValue == floatValue
+}
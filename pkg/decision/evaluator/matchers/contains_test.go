@@ -0,0 +1,153 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/go-sdk/pkg/entities"
+)
+
+func TestContainsMatcherString(t *testing.T) {
+	matcher := ContainsMatcher{
+		Condition: entities.Condition{
+			Match: "contains",
+			Value: "admin",
+			Name:  "roles",
+		},
+	}
+
+	// Test match
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"roles": []interface{}{"beta", "admin"},
+		},
+	}
+
+	result, err := matcher.Match(user)
+	assert.NoError(t, err)
+	assert.True(t, result)
+
+	// Test match with a string slice
+	user = entities.UserContext{
+		Attributes: map[string]interface{}{
+			"roles": []string{"admin"},
+		},
+	}
+
+	result, err = matcher.Match(user)
+	assert.NoError(t, err)
+	assert.True(t, result)
+
+	// Test no match
+	user = entities.UserContext{
+		Attributes: map[string]interface{}{
+			"roles": []interface{}{"beta", 1, true},
+		},
+	}
+
+	result, err = matcher.Match(user)
+	assert.NoError(t, err)
+	assert.False(t, result)
+
+	// Test empty list
+	user = entities.UserContext{
+		Attributes: map[string]interface{}{
+			"roles": []interface{}{},
+		},
+	}
+
+	result, err = matcher.Match(user)
+	assert.NoError(t, err)
+	assert.False(t, result)
+
+	// Test attribute is not a list
+	user = entities.UserContext{
+		Attributes: map[string]interface{}{
+			"roles": "admin",
+		},
+	}
+
+	_, err = matcher.Match(user)
+	assert.Error(t, err)
+
+	// Test attribute not found
+	user = entities.UserContext{
+		Attributes: map[string]interface{}{
+			"not_roles": []interface{}{"admin"},
+		},
+	}
+
+	_, err = matcher.Match(user)
+	assert.Error(t, err)
+}
+
+func TestContainsMatcherNumberAndBool(t *testing.T) {
+	matcher := ContainsMatcher{
+		Condition: entities.Condition{
+			Match: "contains",
+			Value: 42.0,
+			Name:  "numbers",
+		},
+	}
+
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"numbers": []interface{}{1, int64(42)},
+		},
+	}
+
+	result, err := matcher.Match(user)
+	assert.NoError(t, err)
+	assert.True(t, result)
+
+	user = entities.UserContext{
+		Attributes: map[string]interface{}{
+			"numbers": []interface{}{"42", true},
+		},
+	}
+
+	result, err = matcher.Match(user)
+	assert.NoError(t, err)
+	assert.False(t, result)
+
+	matcher.Condition.Value = true
+	result, err = matcher.Match(user)
+	assert.NoError(t, err)
+	assert.True(t, result)
+}
+
+func TestContainsMatcherUnsupportedValue(t *testing.T) {
+	matcher := ContainsMatcher{
+		Condition: entities.Condition{
+			Match: "contains",
+			Value: map[string]interface{}{},
+			Name:  "roles",
+		},
+	}
+
+	user := entities.UserContext{
+		Attributes: map[string]interface{}{
+			"roles": []interface{}{"admin"},
+		},
+	}
+
+	_, err := matcher.Match(user)
+	assert.Error(t, err)
+}
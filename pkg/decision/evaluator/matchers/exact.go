@@ -34,26 +34,52 @@ func (m ExactMatcher) Match(user entities.UserContext) (bool, error) {
 	if stringValue, ok := m.Condition.Value.(string); ok {
 		attributeValue, err := user.GetStringAttribute(m.Condition.Name)
 		if err != nil {
-			return false, err
+			return false, attributeTypeMismatch(m.Condition, user, err)
 		}
 		return stringValue == attributeValue, nil
 	}
 
 	if boolValue, ok := m.Condition.Value.(bool); ok {
+		// bools are only compared to bools, a "true" string attribute does not match a true condition
 		attributeValue, err := user.GetBoolAttribute(m.Condition.Name)
 		if err != nil {
-			return false, err
+			return false, attributeTypeMismatch(m.Condition, user, err)
 		}
 		return boolValue == attributeValue, nil
 	}
 
 	if floatValue, ok := utils.ToFloat(m.Condition.Value); ok {
-		attributeValue, err := user.GetFloatAttribute(m.Condition.Name)
+		attributeValue, err := getNumericAttribute(m.Condition, floatValue, user)
 		if err != nil {
-			return false, err
+			return false, attributeTypeMismatch(m.Condition, user, err)
 		}
 		return floatValue == attributeValue, nil
 	}
 
 	return false, fmt.Errorf("audience condition %s evaluated to NULL because the condition value type is not supported", m.Condition.Name)
 }
+
+// attributeTypeMismatch warns when the user attribute of the condition is set to a value of another type than the
+// condition value, in which case the condition evaluates to NULL, and returns the error to evaluate it with
+func attributeTypeMismatch(condition entities.Condition, user entities.UserContext, err error) error {
+	value, ok := user.Attributes[condition.Name]
+	if !ok || value == nil || valueType(value) == valueType(condition.Value) {
+		return err
+	}
+	logger.Warning(fmt.Sprintf(`Audience condition "%s" evaluated to NULL because a value of type "%T" was passed for user attribute "%s".`, condition.Name, value, condition.Name))
+	return fmt.Errorf(`audience condition %s evaluated to NULL because the attribute value "%v" does not have the type of the condition value "%v"`, condition.Name, value, condition.Value)
+}
+
+// valueType returns the type of the value as far as exact matching is concerned, numbers of any type are comparable
+func valueType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	}
+	if _, ok := utils.ToFloat(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
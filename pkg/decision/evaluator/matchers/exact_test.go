@@ -106,6 +106,65 @@ func TestExactMatcherBool(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestExactMatcherBoolFalse(t *testing.T) {
+	matcher := ExactMatcher{
+		Condition: entities.Condition{
+			Match: "exact",
+			Value: false,
+			Name:  "is_beta_user",
+		},
+	}
+
+	result, err := matcher.Match(entities.UserContext{Attributes: map[string]interface{}{"is_beta_user": false}})
+	assert.NoError(t, err)
+	assert.True(t, result)
+
+	result, err = matcher.Match(entities.UserContext{Attributes: map[string]interface{}{"is_beta_user": true}})
+	assert.NoError(t, err)
+	assert.False(t, result)
+}
+
+func TestExactMatcherBoolTypeMismatch(t *testing.T) {
+	boolMatcher := ExactMatcher{
+		Condition: entities.Condition{
+			Match: "exact",
+			Value: true,
+			Name:  "is_beta_user",
+		},
+	}
+	stringMatcher := ExactMatcher{
+		Condition: entities.Condition{
+			Match: "exact",
+			Value: "true",
+			Name:  "is_beta_user",
+		},
+	}
+
+	// Test bool condition against string and number attributes
+	for _, value := range []interface{}{"true", "false", 1, 0.0} {
+		result, err := boolMatcher.Match(entities.UserContext{Attributes: map[string]interface{}{"is_beta_user": value}})
+		assert.False(t, result)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "does not have the type of the condition value")
+		}
+	}
+
+	// Test string condition against bool attributes
+	for _, value := range []interface{}{true, false} {
+		result, err := stringMatcher.Match(entities.UserContext{Attributes: map[string]interface{}{"is_beta_user": value}})
+		assert.False(t, result)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "does not have the type of the condition value")
+		}
+	}
+
+	// Test attribute not found is not reported as a type mismatch
+	_, err := boolMatcher.Match(entities.UserContext{Attributes: map[string]interface{}{}})
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "does not have the type of the condition value")
+	}
+}
+
 func TestExactMatcherInt(t *testing.T) {
 	matcher := ExactMatcher{
 		Condition: entities.Condition{
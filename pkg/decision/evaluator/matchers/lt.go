@@ -33,7 +33,7 @@ type LtMatcher struct {
 func (m LtMatcher) Match(user entities.UserContext) (bool, error) {
 
 	if floatValue, ok := utils.ToFloat(m.Condition.Value); ok {
-		attributeValue, err := user.GetFloatAttribute(m.Condition.Name)
+		attributeValue, err := getNumericAttribute(m.Condition, floatValue, user)
 		if err != nil {
 			return false, err
 		}
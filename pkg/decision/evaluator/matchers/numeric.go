@@ -0,0 +1,49 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package matchers //
+package matchers
+
+import (
+	"fmt"
+
+	"github.com/optimizely/go-sdk/pkg/decision/evaluator/matchers/utils"
+	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/logging"
+)
+
+var logger = logging.GetLogger("AudienceEvaluator")
+
+// getNumericAttribute returns the user's numeric attribute for the condition, making sure that both the condition
+// value and the attribute value are within the safe integer range before they are compared
+func getNumericAttribute(condition entities.Condition, conditionValue float64, user entities.UserContext) (float64, error) {
+	if !utils.IsValidNumber(conditionValue) {
+		logger.Warning(fmt.Sprintf(`Audience condition "%s" has a number value "%v" outside of the valid range.`, condition.Name, condition.Value))
+		return 0, fmt.Errorf("audience condition %s evaluated to NULL because the condition value is out of range", condition.Name)
+	}
+
+	attributeValue, err := user.GetFloatAttribute(condition.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	if !utils.IsValidNumber(attributeValue) {
+		logger.Warning(fmt.Sprintf(`Audience condition "%s" evaluated to NULL because the number value for user attribute "%s" is outside of the valid range.`, condition.Name, condition.Name))
+		return 0, fmt.Errorf("audience condition %s evaluated to NULL because the attribute value is out of range", condition.Name)
+	}
+
+	return attributeValue, nil
+}
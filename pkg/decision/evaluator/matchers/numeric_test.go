@@ -0,0 +1,68 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optimizely/go-sdk/pkg/entities"
+)
+
+func TestNumericMatchersOutOfRange(t *testing.T) {
+	maxSafe := float64(1 << 53)
+	newMatchers := []struct {
+		match      string
+		newMatcher func(entities.Condition) Matcher
+	}{
+		{match: "exact", newMatcher: func(condition entities.Condition) Matcher { return ExactMatcher{Condition: condition} }},
+		{match: "gt", newMatcher: func(condition entities.Condition) Matcher { return GtMatcher{Condition: condition} }},
+		{match: "lt", newMatcher: func(condition entities.Condition) Matcher { return LtMatcher{Condition: condition} }},
+	}
+	testCases := []struct {
+		name           string
+		conditionValue float64
+		attributeValue interface{}
+		expectError    bool
+	}{
+		{name: "boundary", conditionValue: maxSafe, attributeValue: maxSafe},
+		{name: "attribute out of range", conditionValue: maxSafe, attributeValue: maxSafe * 2, expectError: true},
+		{name: "condition value out of range", conditionValue: -maxSafe * 2, attributeValue: 1, expectError: true},
+	}
+
+	for _, matcherCase := range newMatchers {
+		for _, testCase := range testCases {
+			t.Run(matcherCase.match+" "+testCase.name, func(t *testing.T) {
+				matcher := matcherCase.newMatcher(entities.Condition{Match: matcherCase.match, Value: testCase.conditionValue, Name: "number"})
+				user := entities.UserContext{
+					Attributes: map[string]interface{}{
+						"number": testCase.attributeValue,
+					},
+				}
+
+				result, err := matcher.Match(user)
+				if testCase.expectError {
+					assert.Error(t, err)
+					assert.False(t, result)
+				} else {
+					assert.NoError(t, err)
+				}
+			})
+		}
+	}
+}
@@ -17,7 +17,13 @@
 // Package utils //
 package utils
 
-import "reflect"
+import (
+	"math"
+	"reflect"
+)
+
+// maxSafeInteger is the largest magnitude for which every integer is exactly representable as a float64 (2^53)
+const maxSafeInteger = 1 << 53
 
 // ToFloat attempts to convert the given value to a float
 func ToFloat(value interface{}) (float64, bool) {
@@ -36,3 +42,12 @@ func ToFloat(value interface{}) (float64, bool) {
 	}
 	return 0, false
 }
+
+// IsValidNumber returns true if the given value is finite and falls within the safe integer range (±2^53),
+// outside of which precision loss can produce incorrect comparisons
+func IsValidNumber(value float64) bool {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return false
+	}
+	return math.Abs(value) <= maxSafeInteger
+}
@@ -66,3 +66,18 @@ func TestToFloat(t *testing.T) {
 	assert.Equal(t, float64(5000), result)
 	assert.Equal(t, true, success)
 }
+
+func TestIsValidNumber(t *testing.T) {
+	maxSafe := math.Pow(2, 53)
+
+	assert.True(t, IsValidNumber(0))
+	assert.True(t, IsValidNumber(maxSafe))
+	assert.True(t, IsValidNumber(-maxSafe))
+	assert.True(t, IsValidNumber(maxSafe-1))
+
+	assert.False(t, IsValidNumber(maxSafe*2))
+	assert.False(t, IsValidNumber(-maxSafe*2))
+	assert.False(t, IsValidNumber(math.Inf(1)))
+	assert.False(t, IsValidNumber(math.Inf(-1)))
+	assert.False(t, IsValidNumber(math.NaN()))
+}
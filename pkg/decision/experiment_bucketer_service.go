@@ -18,6 +18,7 @@
 package decision
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/optimizely/go-sdk/pkg/decision/bucketer"
@@ -47,6 +48,11 @@ func NewExperimentBucketerService() *ExperimentBucketerService {
 
 // GetDecision returns the decision with the variation the user is bucketed into
 func (s ExperimentBucketerService) GetDecision(decisionContext ExperimentDecisionContext, userContext entities.UserContext) (ExperimentDecision, error) {
+	return s.GetDecisionWithContext(context.Background(), decisionContext, userContext)
+}
+
+// GetDecisionWithContext is like GetDecision, explaining the bucketing in the BucketingTrace of the decision when the context requests it, see WithBucketingTrace
+func (s ExperimentBucketerService) GetDecisionWithContext(ctx context.Context, decisionContext ExperimentDecisionContext, userContext entities.UserContext) (ExperimentDecision, error) {
 	experimentDecision := ExperimentDecision{}
 	experiment := decisionContext.Experiment
 
@@ -60,6 +66,13 @@ func (s ExperimentBucketerService) GetDecision(decisionContext ExperimentDecisio
 		}
 	}
 
+	// a missing traffic allocation allocates 0% of the traffic, for experiments and rollout rules alike
+	if len(experiment.TrafficAllocation) == 0 {
+		bLogger.Warning(fmt.Sprintf(`Experiment "%s" has no traffic allocation, no user is bucketed into it.`, experiment.Key))
+		experimentDecision.Reason = reasons.NoTrafficAllocation
+		return experimentDecision, nil
+	}
+
 	var group entities.Group
 	if experiment.GroupID != "" {
 		// @TODO: figure out what to do if group is not found
@@ -78,5 +91,10 @@ func (s ExperimentBucketerService) GetDecision(decisionContext ExperimentDecisio
 	variation, reason, _ := s.bucketer.Bucket(bucketingID, *experiment, group)
 	experimentDecision.Reason = reason
 	experimentDecision.Variation = variation
+	if bucketingTraceRequested(ctx) {
+		if tracingBucketer, ok := s.bucketer.(bucketer.TracingExperimentBucketer); ok {
+			experimentDecision.BucketingTrace = tracingBucketer.Trace(bucketingID, *experiment, group)
+		}
+	}
 	return experimentDecision, nil
 }
@@ -17,6 +17,7 @@
 package decision
 
 import (
+	"context"
 	"testing"
 
 	"github.com/optimizely/go-sdk/pkg/decision/reasons"
@@ -130,6 +131,54 @@ func (s *ExperimentBucketerTestSuite) TestGetDecisionWithTargetingFails() {
 	s.mockBucketer.AssertNotCalled(s.T(), "Bucket")
 }
 
+func (s *ExperimentBucketerTestSuite) TestGetDecisionNoTrafficAllocation() {
+	testUserContext := entities.UserContext{
+		ID: "test_user_1",
+	}
+	experiment := testExp1111
+	experiment.TrafficAllocation = nil
+
+	expectedDecision := ExperimentDecision{
+		Decision: Decision{
+			Reason: reasons.NoTrafficAllocation,
+		},
+	}
+	experimentBucketerService := ExperimentBucketerService{
+		bucketer: s.mockBucketer,
+	}
+	testDecisionContext := ExperimentDecisionContext{
+		Experiment:    &experiment,
+		ProjectConfig: s.mockConfig,
+	}
+	decision, err := experimentBucketerService.GetDecision(testDecisionContext, testUserContext)
+	s.Equal(expectedDecision, decision)
+	s.NoError(err)
+	s.mockBucketer.AssertNotCalled(s.T(), "Bucket")
+}
+
+func (s *ExperimentBucketerTestSuite) TestGetDecisionBucketingTrace() {
+	testUserContext := entities.UserContext{
+		ID: "test_user_1",
+	}
+	experimentBucketerService := NewExperimentBucketerService()
+	testDecisionContext := ExperimentDecisionContext{
+		Experiment:    &testExp1111,
+		ProjectConfig: s.mockConfig,
+	}
+
+	// the bucketing is not explained unless requested
+	decision, err := experimentBucketerService.GetDecision(testDecisionContext, testUserContext)
+	s.NoError(err)
+	s.Nil(decision.BucketingTrace)
+
+	decision, err = experimentBucketerService.GetDecisionWithContext(WithBucketingTrace(context.Background()), testDecisionContext, testUserContext)
+	s.NoError(err)
+	s.Equal(reasons.BucketedIntoVariation, decision.Reason)
+	if s.Len(decision.BucketingTrace, 1) {
+		s.Contains(decision.BucketingTrace[0], `of experiment "test_experiment_1111" → variation "2222"`)
+	}
+}
+
 func TestExperimentBucketerTestSuite(t *testing.T) {
 	suite.Run(t, new(ExperimentBucketerTestSuite))
 }
@@ -18,8 +18,10 @@
 package decision
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/logging"
 )
@@ -40,6 +42,11 @@ func NewFeatureExperimentService(compositeExperimentService ExperimentService) *
 
 // GetDecision returns a decision for the given feature test and user context
 func (f FeatureExperimentService) GetDecision(decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
+	return f.GetDecisionWithContext(context.Background(), decisionContext, userContext)
+}
+
+// GetDecisionWithContext is like GetDecision, passing the given context to the experiment service
+func (f FeatureExperimentService) GetDecisionWithContext(ctx context.Context, decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
 	feature := decisionContext.Feature
 	// @TODO this can be improved by getting group ID first and determining experiment and then bucketing in experiment
 	for _, featureExperiment := range feature.FeatureExperiments {
@@ -49,7 +56,7 @@ func (f FeatureExperimentService) GetDecision(decisionContext FeatureDecisionCon
 			ProjectConfig: decisionContext.ProjectConfig,
 		}
 
-		experimentDecision, err := f.compositeExperimentService.GetDecision(experimentDecisionContext, userContext)
+		experimentDecision, err := getExperimentDecision(ctx, f.compositeExperimentService, experimentDecisionContext, userContext)
 		fesLogger.Debug(fmt.Sprintf(
 			`Decision made for feature test with key "%s" for user "%s" with the following reason: "%s".`,
 			feature.Key,
@@ -68,6 +75,11 @@ func (f FeatureExperimentService) GetDecision(decisionContext FeatureDecisionCon
 
 			return featureDecision, err
 		}
+
+		// the holdout applies to all the feature tests, the user is not bucketed into any of them
+		if experimentDecision.Reason == reasons.HeldOut {
+			return FeatureDecision{Experiment: experiment, Decision: experimentDecision.Decision, Source: FeatureTest}, err
+		}
 	}
 
 	return FeatureDecision{}, nil
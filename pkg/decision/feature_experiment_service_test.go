@@ -17,8 +17,10 @@
 package decision
 
 import (
+	"context"
 	"testing"
 
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/stretchr/testify/suite"
 )
@@ -70,6 +72,54 @@ func (s *FeatureExperimentServiceTestSuite) TestGetDecision() {
 	s.mockExperimentService.AssertExpectations(s.T())
 }
 
+func (s *FeatureExperimentServiceTestSuite) TestGetDecisionHeldOut() {
+	testUserContext := entities.UserContext{
+		ID: "test_user_1",
+	}
+
+	featureExperimentService := &FeatureExperimentService{
+		compositeExperimentService: NewHoldoutService(100, s.mockExperimentService),
+	}
+
+	expectedFeatureDecision := FeatureDecision{
+		Experiment: testExp1113,
+		Decision:   Decision{Reason: reasons.HeldOut},
+		Source:     FeatureTest,
+	}
+	decision, err := featureExperimentService.GetDecision(s.testFeatureDecisionContext, testUserContext)
+	s.Equal(expectedFeatureDecision, decision)
+	s.NoError(err)
+	s.mockExperimentService.AssertNotCalled(s.T(), "GetDecision")
+}
+
+func (s *FeatureExperimentServiceTestSuite) TestGetDecisionPropagatesContext() {
+	type contextKey struct{}
+	ctx := context.WithValue(context.Background(), contextKey{}, "span")
+	testUserContext := entities.UserContext{
+		ID: "test_user_1",
+	}
+
+	expectedVariation := testExp1113.Variations["2223"]
+	returnExperimentDecision := ExperimentDecision{
+		Variation: &expectedVariation,
+	}
+	testExperimentDecisionContext := ExperimentDecisionContext{
+		Experiment:    &testExp1113,
+		ProjectConfig: s.mockConfig,
+	}
+	mockExperimentService := new(MockContextExperimentDecisionService)
+	mockExperimentService.On("GetDecisionWithContext", ctx, testExperimentDecisionContext, testUserContext).Return(returnExperimentDecision, nil)
+
+	featureExperimentService := &FeatureExperimentService{
+		compositeExperimentService: mockExperimentService,
+	}
+
+	decision, err := featureExperimentService.GetDecisionWithContext(ctx, s.testFeatureDecisionContext, testUserContext)
+	s.NoError(err)
+	s.Equal(&expectedVariation, decision.Variation)
+	mockExperimentService.AssertExpectations(s.T())
+}
+
 func (s *FeatureExperimentServiceTestSuite) TestGetDecisionMutex() {
 	testUserContext := entities.UserContext{
 		ID: "test_user_1",
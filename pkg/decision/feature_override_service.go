@@ -0,0 +1,156 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/optimizely/go-sdk/pkg/decision/evaluator/matchers/utils"
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
+	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/logging"
+)
+
+var fosLogger = logging.GetLogger("FeatureOverrideService")
+
+// FeatureAttributeOverride forces a feature on or off for the users having the given attribute value
+type FeatureAttributeOverride struct {
+	AttributeName  string
+	AttributeValue interface{}
+	FeatureEnabled bool
+}
+
+// matches returns true if the user has the attribute value of the override
+func (o FeatureAttributeOverride) matches(userContext entities.UserContext) bool {
+	value, ok := userContext.Attributes[o.AttributeName]
+	if !ok || value == nil {
+		return false
+	}
+
+	if floatValue, ok := utils.ToFloat(o.AttributeValue); ok {
+		attributeValue, ok := utils.ToFloat(value)
+		return ok && floatValue == attributeValue
+	}
+
+	switch overrideValue := o.AttributeValue.(type) {
+	case string:
+		attributeValue, ok := value.(string)
+		return ok && overrideValue == attributeValue
+	case bool:
+		attributeValue, ok := value.(bool)
+		return ok && overrideValue == attributeValue
+	}
+	return false
+}
+
+// FeatureOverrideStore provides read access to attribute based feature overrides
+type FeatureOverrideStore interface {
+	// Returns the override of the feature matching the attributes of the user
+	GetOverride(featureKey string, userContext entities.UserContext) (FeatureAttributeOverride, bool)
+}
+
+// MapFeatureOverridesStore is a map-based implementation of FeatureOverrideStore that is safe to use concurrently
+type MapFeatureOverridesStore struct {
+	overridesMap map[string][]FeatureAttributeOverride
+	mutex        sync.RWMutex
+}
+
+// NewMapFeatureOverridesStore returns a new MapFeatureOverridesStore
+func NewMapFeatureOverridesStore() *MapFeatureOverridesStore {
+	return &MapFeatureOverridesStore{
+		overridesMap: make(map[string][]FeatureAttributeOverride),
+	}
+}
+
+// GetOverride returns the first override of the feature, in the order they were set, matching the attributes of the user
+func (m *MapFeatureOverridesStore) GetOverride(featureKey string, userContext entities.UserContext) (FeatureAttributeOverride, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	for _, override := range m.overridesMap[featureKey] {
+		if override.matches(userContext) {
+			return override, true
+		}
+	}
+	return FeatureAttributeOverride{}, false
+}
+
+// SetOverride forces the feature on or off for the users having the given attribute value, replacing any previous
+// override of the feature for the same attribute value
+func (m *MapFeatureOverridesStore) SetOverride(featureKey string, override FeatureAttributeOverride) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	overrides := m.overridesMap[featureKey]
+	for i, existing := range overrides {
+		if existing.AttributeName == override.AttributeName && reflect.DeepEqual(existing.AttributeValue, override.AttributeValue) {
+			overrides[i] = override
+			return
+		}
+	}
+	m.overridesMap[featureKey] = append(overrides, override)
+}
+
+// RemoveOverride removes the override of the feature for the given attribute value.
+// If there is no such override, this method has no effect.
+func (m *MapFeatureOverridesStore) RemoveOverride(featureKey, attributeName string, attributeValue interface{}) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	overrides := m.overridesMap[featureKey]
+	for i, existing := range overrides {
+		if existing.AttributeName == attributeName && reflect.DeepEqual(existing.AttributeValue, attributeValue) {
+			m.overridesMap[featureKey] = append(overrides[:i:i], overrides[i+1:]...)
+			return
+		}
+	}
+}
+
+// FeatureOverrideService makes a decision using a FeatureOverrideStore
+// Implements the FeatureService interface
+type FeatureOverrideService struct {
+	Overrides FeatureOverrideStore
+}
+
+// NewFeatureOverrideService returns a pointer to an initialized FeatureOverrideService
+func NewFeatureOverrideService(overrides FeatureOverrideStore) *FeatureOverrideService {
+	return &FeatureOverrideService{
+		Overrides: overrides,
+	}
+}
+
+// GetDecision returns a decision forcing the feature on or off when the store has an override matching the user attributes
+func (s FeatureOverrideService) GetDecision(decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
+	featureDecision := FeatureDecision{Source: Override}
+
+	if decisionContext.Feature == nil {
+		return featureDecision, errors.New("decisionContext Feature is nil")
+	}
+
+	override, ok := s.Overrides.GetOverride(decisionContext.Feature.Key, userContext)
+	if !ok {
+		featureDecision.Reason = reasons.NoFeatureOverride
+		return featureDecision, nil
+	}
+
+	featureDecision.Variation = &entities.Variation{FeatureEnabled: override.FeatureEnabled}
+	featureDecision.Reason = reasons.FeatureOverrideFound
+	fosLogger.Debug(fmt.Sprintf(`Feature "%s" forced to enabled=%v for user "%s" by attribute "%s"`,
+		decisionContext.Feature.Key, override.FeatureEnabled, userContext.ID, override.AttributeName))
+	return featureDecision, nil
+}
@@ -0,0 +1,133 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"testing"
+
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
+	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/notification"
+	"github.com/stretchr/testify/suite"
+)
+
+type FeatureOverrideServiceTestSuite struct {
+	suite.Suite
+	mockConfig          *mockProjectConfig
+	overrides           *MapFeatureOverridesStore
+	overrideService     *FeatureOverrideService
+	testDecisionContext FeatureDecisionContext
+}
+
+func (s *FeatureOverrideServiceTestSuite) SetupTest() {
+	s.mockConfig = new(mockProjectConfig)
+	s.overrides = NewMapFeatureOverridesStore()
+	s.overrideService = NewFeatureOverrideService(s.overrides)
+	s.testDecisionContext = FeatureDecisionContext{
+		Feature:       &testFeat3333,
+		ProjectConfig: s.mockConfig,
+	}
+}
+
+func (s *FeatureOverrideServiceTestSuite) TestOverrideMatchesAttribute() {
+	s.overrides.SetOverride(testFeat3333.Key, FeatureAttributeOverride{AttributeName: "country", AttributeValue: "NZ", FeatureEnabled: false})
+	s.overrides.SetOverride(testFeat3333.Key, FeatureAttributeOverride{AttributeName: "beta", AttributeValue: true, FeatureEnabled: true})
+	s.overrides.SetOverride(testFeat3333.Key, FeatureAttributeOverride{AttributeName: "tier", AttributeValue: 2, FeatureEnabled: true})
+
+	testUserContext := entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"country": "NZ"}}
+	decision, err := s.overrideService.GetDecision(s.testDecisionContext, testUserContext)
+	s.NoError(err)
+	s.NotNil(decision.Variation)
+	s.False(decision.Variation.FeatureEnabled)
+	s.Equal(Override, decision.Source)
+	s.Exactly(reasons.FeatureOverrideFound, decision.Reason)
+
+	testUserContext = entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"beta": true}}
+	decision, err = s.overrideService.GetDecision(s.testDecisionContext, testUserContext)
+	s.NoError(err)
+	s.True(decision.Variation.FeatureEnabled)
+
+	testUserContext = entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"tier": 2.0}}
+	decision, err = s.overrideService.GetDecision(s.testDecisionContext, testUserContext)
+	s.NoError(err)
+	s.True(decision.Variation.FeatureEnabled)
+}
+
+func (s *FeatureOverrideServiceTestSuite) TestNoOverrideMatch() {
+	s.overrides.SetOverride(testFeat3333.Key, FeatureAttributeOverride{AttributeName: "country", AttributeValue: "NZ", FeatureEnabled: true})
+	s.overrides.SetOverride("other_feature", FeatureAttributeOverride{AttributeName: "country", AttributeValue: "US", FeatureEnabled: true})
+
+	testUserContexts := []entities.UserContext{
+		{ID: "test_user_1"},
+		{ID: "test_user_1", Attributes: map[string]interface{}{"country": "US"}},
+		{ID: "test_user_1", Attributes: map[string]interface{}{"country": true}},
+	}
+	for _, testUserContext := range testUserContexts {
+		decision, err := s.overrideService.GetDecision(s.testDecisionContext, testUserContext)
+		s.NoError(err)
+		s.Nil(decision.Variation)
+		s.Exactly(reasons.NoFeatureOverride, decision.Reason)
+	}
+}
+
+func (s *FeatureOverrideServiceTestSuite) TestSetAndRemoveOverride() {
+	testUserContext := entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"country": "NZ"}}
+
+	s.overrides.SetOverride(testFeat3333.Key, FeatureAttributeOverride{AttributeName: "country", AttributeValue: "NZ", FeatureEnabled: true})
+	s.overrides.SetOverride(testFeat3333.Key, FeatureAttributeOverride{AttributeName: "country", AttributeValue: "NZ", FeatureEnabled: false})
+	decision, _ := s.overrideService.GetDecision(s.testDecisionContext, testUserContext)
+	s.False(decision.Variation.FeatureEnabled)
+
+	s.overrides.RemoveOverride(testFeat3333.Key, "country", "NZ")
+	decision, _ = s.overrideService.GetDecision(s.testDecisionContext, testUserContext)
+	s.Nil(decision.Variation)
+
+	// removing a missing override has no effect
+	s.overrides.RemoveOverride(testFeat3333.Key, "country", "NZ")
+}
+
+func (s *FeatureOverrideServiceTestSuite) TestNilDecisionContextFeature() {
+	decision, err := s.overrideService.GetDecision(FeatureDecisionContext{ProjectConfig: s.mockConfig}, entities.UserContext{ID: "test_user_1"})
+	s.Error(err)
+	s.Nil(decision.Variation)
+}
+
+func (s *FeatureOverrideServiceTestSuite) TestCompositeServiceWithFeatureOverrides() {
+	s.overrides.SetOverride(testFeat3333.Key, FeatureAttributeOverride{AttributeName: "incident", AttributeValue: "on", FeatureEnabled: false})
+	compositeService := NewCompositeService("feature_override_sdk_key", WithFeatureOverrideStore(s.overrides))
+
+	note := notification.DecisionNotification{}
+	compositeService.OnDecision(func(decisionNotification notification.DecisionNotification) {
+		note = decisionNotification
+	})
+
+	testUserContext := entities.UserContext{ID: "test_user_1", Attributes: map[string]interface{}{"incident": "on"}}
+	decision, err := compositeService.GetFeatureDecision(s.testDecisionContext, testUserContext)
+	s.NoError(err)
+	s.Equal(Override, decision.Source)
+	s.False(decision.Variation.FeatureEnabled)
+
+	if s.NotNil(note.FeatureInfo) {
+		s.Equal(string(Override), note.FeatureInfo.Source)
+		s.False(note.FeatureInfo.FeatureEnabled)
+	}
+}
+
+func TestFeatureOverrideServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(FeatureOverrideServiceTestSuite))
+}
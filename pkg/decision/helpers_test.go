@@ -22,6 +22,7 @@
 package decision
 
 import (
+	"context"
 	"github.com/optimizely/go-sdk/pkg/config"
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/stretchr/testify/mock"
@@ -62,6 +63,16 @@ func (m *MockExperimentDecisionService) GetDecision(decisionContext ExperimentDe
 	return args.Get(0).(ExperimentDecision), args.Error(1)
 }
 
+// MockContextExperimentDecisionService is a MockExperimentDecisionService accepting the context of the decisions
+type MockContextExperimentDecisionService struct {
+	MockExperimentDecisionService
+}
+
+func (m *MockContextExperimentDecisionService) GetDecisionWithContext(ctx context.Context, decisionContext ExperimentDecisionContext, userContext entities.UserContext) (ExperimentDecision, error) {
+	args := m.Called(ctx, decisionContext, userContext)
+	return args.Get(0).(ExperimentDecision), args.Error(1)
+}
+
 type MockFeatureDecisionService struct {
 	mock.Mock
 }
@@ -71,6 +82,16 @@ func (m *MockFeatureDecisionService) GetDecision(decisionContext FeatureDecision
 	return args.Get(0).(FeatureDecision), args.Error(1)
 }
 
+// MockContextFeatureDecisionService is a MockFeatureDecisionService accepting the context of the decisions
+type MockContextFeatureDecisionService struct {
+	MockFeatureDecisionService
+}
+
+func (m *MockContextFeatureDecisionService) GetDecisionWithContext(ctx context.Context, decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
+	args := m.Called(ctx, decisionContext, userContext)
+	return args.Get(0).(FeatureDecision), args.Error(1)
+}
+
 type MockAudienceTreeEvaluator struct {
 	mock.Mock
 }
@@ -89,6 +110,11 @@ func (m *MockUserProfileService) Save(userProfile UserProfile) {
 	m.Called(userProfile)
 }
 
+func (m *MockUserProfileService) BatchLookup(userIDs []string) map[string]UserProfile {
+	args := m.Called(userIDs)
+	return args.Get(0).(map[string]UserProfile)
+}
+
 func (m *MockAudienceTreeEvaluator) Evaluate(node *entities.TreeNode, condTreeParams *entities.TreeParameters) (evalResult, isValid bool) {
 	args := m.Called(node, condTreeParams)
 	return args.Bool(0), args.Bool(1)
@@ -264,6 +290,18 @@ var testTargetedExp1116 = entities.Experiment{
 	},
 }
 
+// Feature with a targeted rollout rule followed by an everyone else rollout rule
+const testFeatRollout3336Key = "test_feature_rollout_3336_key"
+
+var testFeatRollout3336 = entities.Feature{
+	ID:  "3336",
+	Key: testFeatRollout3336Key,
+	Rollout: entities.Rollout{
+		ID:          "4446",
+		Experiments: []entities.Experiment{testTargetedExp1116, testExp1115},
+	},
+}
+
 // Experiment with a whitelist
 const testExpWhitelistKey = "test_experiment_whitelist"
 
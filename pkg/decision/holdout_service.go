@@ -0,0 +1,85 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/optimizely/go-sdk/pkg/decision/bucketer"
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
+	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/logging"
+)
+
+var hsLogger = logging.GetLogger("HoldoutService")
+
+// holdoutBucketingKey is appended to the bucketing ID so the holdout does not correlate with the experiment bucketing
+const holdoutBucketingKey = "holdout"
+
+// HoldoutService holds a stable percentage of users out of every experiment, so the overall impact of experimentation
+// can be measured against them. Held out users get the default experience.
+type HoldoutService struct {
+	experimentService ExperimentService
+	bucketer          bucketer.Bucketer
+	endOfRange        int
+}
+
+// NewHoldoutService returns a new instance of the HoldoutService that holds the given percentage (0 to 100) of users
+// out of the experiments decided by the given experiment service
+func NewHoldoutService(percentage float64, experimentService ExperimentService) *HoldoutService {
+	if percentage < 0 || percentage > 100 {
+		hsLogger.Warning(fmt.Sprintf("Invalid holdout percentage %v, it must be between 0 and 100.", percentage))
+		percentage = math.Max(0, math.Min(100, percentage))
+	}
+
+	return &HoldoutService{
+		experimentService: experimentService,
+		bucketer:          bucketer.NewMurmurhashBucketer(bucketer.DefaultHashSeed),
+		endOfRange:        int(math.Round(percentage * 100)),
+	}
+}
+
+// IsHeldOut returns whether the given user is held out of all experiments
+func (h HoldoutService) IsHeldOut(userContext entities.UserContext) bool {
+	if h.endOfRange == 0 {
+		return false
+	}
+
+	bucketingID, err := userContext.GetBucketingID()
+	if err != nil {
+		hsLogger.Debug(fmt.Sprintf(`Error computing bucketing ID for holdout: "%s"`, err.Error()))
+	}
+	return h.bucketer.Generate(bucketingID+holdoutBucketingKey) < h.endOfRange
+}
+
+// GetDecision returns an empty decision for held out users, otherwise the decision of the wrapped experiment service
+func (h HoldoutService) GetDecision(decisionContext ExperimentDecisionContext, userContext entities.UserContext) (ExperimentDecision, error) {
+	return h.GetDecisionWithContext(context.Background(), decisionContext, userContext)
+}
+
+// GetDecisionWithContext is like GetDecision, passing the given context to the wrapped experiment service
+func (h HoldoutService) GetDecisionWithContext(ctx context.Context, decisionContext ExperimentDecisionContext, userContext entities.UserContext) (ExperimentDecision, error) {
+	if h.IsHeldOut(userContext) {
+		hsLogger.Debug(fmt.Sprintf(`User "%s" is held out of experiment "%s".`, userContext.ID, decisionContext.Experiment.Key))
+		return ExperimentDecision{Decision: Decision{Reason: reasons.HeldOut}}, nil
+	}
+
+	return getExperimentDecision(ctx, h.experimentService, decisionContext, userContext)
+}
@@ -0,0 +1,101 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/optimizely/go-sdk/pkg/decision/reasons"
+	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/stretchr/testify/suite"
+)
+
+type HoldoutServiceTestSuite struct {
+	suite.Suite
+	mockExperimentService *MockExperimentDecisionService
+	testDecisionContext   ExperimentDecisionContext
+}
+
+func (s *HoldoutServiceTestSuite) SetupTest() {
+	s.mockExperimentService = new(MockExperimentDecisionService)
+	s.testDecisionContext = ExperimentDecisionContext{
+		Experiment:    &testExp1111,
+		ProjectConfig: new(mockProjectConfig),
+	}
+}
+
+func (s *HoldoutServiceTestSuite) TestHeldOutUserGetsDefaultExperience() {
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	holdoutService := NewHoldoutService(100, s.mockExperimentService)
+
+	decision, err := holdoutService.GetDecision(s.testDecisionContext, testUserContext)
+	s.NoError(err)
+	s.Nil(decision.Variation)
+	s.Exactly(reasons.HeldOut, decision.Reason)
+	s.mockExperimentService.AssertNotCalled(s.T(), "GetDecision", s.testDecisionContext, testUserContext)
+}
+
+func (s *HoldoutServiceTestSuite) TestUserNotHeldOutIsBucketed() {
+	testUserContext := entities.UserContext{ID: "test_user_1"}
+	expectedDecision := ExperimentDecision{
+		Variation: &testExp1111Var2222,
+		Decision:  Decision{Reason: reasons.BucketedIntoVariation},
+	}
+	s.mockExperimentService.On("GetDecision", s.testDecisionContext, testUserContext).Return(expectedDecision, nil)
+	holdoutService := NewHoldoutService(0, s.mockExperimentService)
+
+	decision, err := holdoutService.GetDecision(s.testDecisionContext, testUserContext)
+	s.NoError(err)
+	s.Equal(expectedDecision, decision)
+	s.mockExperimentService.AssertExpectations(s.T())
+}
+
+func (s *HoldoutServiceTestSuite) TestHoldoutIsStableAndProportional() {
+	holdoutService := NewHoldoutService(20, s.mockExperimentService)
+
+	heldOut := 0
+	for i := 0; i < 1000; i++ {
+		testUserContext := entities.UserContext{ID: fmt.Sprintf("test_user_%d", i)}
+		isHeldOut := holdoutService.IsHeldOut(testUserContext)
+		s.Equal(isHeldOut, holdoutService.IsHeldOut(testUserContext))
+		if isHeldOut {
+			heldOut++
+		}
+	}
+	s.InDelta(200, heldOut, 50)
+}
+
+func (s *HoldoutServiceTestSuite) TestHoldoutUsesBucketingID() {
+	holdoutService := NewHoldoutService(50, s.mockExperimentService)
+
+	for i := 0; i < 100; i++ {
+		testUserContext := entities.UserContext{ID: fmt.Sprintf("test_user_%d", i), Attributes: map[string]interface{}{"$opt_bucketing_id": "shared_bucketing_id"}}
+		s.Equal(holdoutService.IsHeldOut(entities.UserContext{ID: "shared_bucketing_id"}), holdoutService.IsHeldOut(testUserContext))
+	}
+}
+
+func (s *HoldoutServiceTestSuite) TestInvalidPercentage() {
+	s.Equal(0, NewHoldoutService(-10, s.mockExperimentService).endOfRange)
+	s.Equal(10000, NewHoldoutService(150, s.mockExperimentService).endOfRange)
+	s.Equal(1250, NewHoldoutService(12.5, s.mockExperimentService).endOfRange)
+}
+
+func TestHoldoutServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(HoldoutServiceTestSuite))
+}
@@ -0,0 +1,74 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import "sync"
+
+// InMemoryUserProfileService stores the user profiles in memory. It is safe for concurrent use.
+type InMemoryUserProfileService struct {
+	profiles map[string]UserProfile
+	lock     sync.RWMutex
+}
+
+// NewInMemoryUserProfileService returns a new instance of the InMemoryUserProfileService
+func NewInMemoryUserProfileService() *InMemoryUserProfileService {
+	return &InMemoryUserProfileService{
+		profiles: map[string]UserProfile{},
+	}
+}
+
+// Lookup returns the profile of the given user, or an empty profile if none is stored
+func (s *InMemoryUserProfileService) Lookup(userID string) UserProfile {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return copyUserProfile(s.profiles[userID])
+}
+
+// BatchLookup returns the stored profiles of the given users keyed by user ID
+func (s *InMemoryUserProfileService) BatchLookup(userIDs []string) map[string]UserProfile {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	profiles := make(map[string]UserProfile, len(userIDs))
+	for _, userID := range userIDs {
+		if profile, ok := s.profiles[userID]; ok {
+			profiles[userID] = copyUserProfile(profile)
+		}
+	}
+	return profiles
+}
+
+// Save stores the given profile, replacing the previous profile of the user
+func (s *InMemoryUserProfileService) Save(profile UserProfile) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.profiles[profile.ID] = copyUserProfile(profile)
+}
+
+// copyUserProfile returns a copy of the profile which does not share its decisions map, the map of a looked up profile
+// is updated by the PersistingExperimentService before being saved back
+func copyUserProfile(profile UserProfile) UserProfile {
+	if profile.ExperimentBucketMap == nil {
+		return profile
+	}
+	bucketMap := make(map[UserDecisionKey]string, len(profile.ExperimentBucketMap))
+	for key, variationID := range profile.ExperimentBucketMap {
+		bucketMap[key] = variationID
+	}
+	profile.ExperimentBucketMap = bucketMap
+	return profile
+}
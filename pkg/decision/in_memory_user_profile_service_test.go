@@ -0,0 +1,73 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package decision //
+package decision
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryUserProfileService(t *testing.T) {
+	userProfileService := NewInMemoryUserProfileService()
+	assert.Equal(t, UserProfile{}, userProfileService.Lookup("test_user_1"))
+
+	profile := UserProfile{ID: "test_user_1", ExperimentBucketMap: map[UserDecisionKey]string{NewUserDecisionKey("1111"): "2222"}}
+	userProfileService.Save(profile)
+	assert.Equal(t, profile, userProfileService.Lookup("test_user_1"))
+
+	// the stored profile is not affected by changes to the saved or looked up ones
+	profile.ExperimentBucketMap[NewUserDecisionKey("1112")] = "2223"
+	userProfileService.Lookup("test_user_1").ExperimentBucketMap[NewUserDecisionKey("1113")] = "2224"
+	assert.Equal(t, map[UserDecisionKey]string{NewUserDecisionKey("1111"): "2222"}, userProfileService.Lookup("test_user_1").ExperimentBucketMap)
+}
+
+func TestInMemoryUserProfileServiceBatchLookup(t *testing.T) {
+	userProfileService := NewInMemoryUserProfileService()
+	userProfileService.Save(UserProfile{ID: "test_user_1"})
+	userProfileService.Save(UserProfile{ID: "test_user_2"})
+
+	profiles := userProfileService.BatchLookup([]string{"test_user_1", "test_user_2", "test_user_3"})
+	assert.Equal(t, map[string]UserProfile{
+		"test_user_1": {ID: "test_user_1"},
+		"test_user_2": {ID: "test_user_2"},
+	}, profiles)
+}
+
+func TestInMemoryUserProfileServiceConcurrency(t *testing.T) {
+	userProfileService := NewInMemoryUserProfileService()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			userID := fmt.Sprintf("test_user_%d", i)
+			profile := userProfileService.Lookup(userID)
+			profile.ID = userID
+			profile.ExperimentBucketMap = map[UserDecisionKey]string{NewUserDecisionKey("1111"): "2222"}
+			userProfileService.Save(profile)
+			userProfileService.BatchLookup([]string{"test_user_0", userID})
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, userProfileService.BatchLookup([]string{"test_user_0", "test_user_5", "test_user_9"}), 3)
+}
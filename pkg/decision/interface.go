@@ -18,11 +18,15 @@
 package decision
 
 import (
+	"context"
+
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/notification"
 )
 
-// Service interface is used to make a decision for a given feature or experiment
+// Service interface is used to make a decision for a given feature or experiment. The services of this package are safe
+// for concurrent use: decisions can be made from many goroutines sharing one project config, while decision handlers
+// are registered with OnDecision and removed with RemoveOnDecision, the handlers being called on the deciding goroutine.
 type Service interface {
 	GetFeatureDecision(FeatureDecisionContext, entities.UserContext) (FeatureDecision, error)
 	GetExperimentDecision(ExperimentDecisionContext, entities.UserContext) (ExperimentDecision, error)
@@ -40,8 +44,63 @@ type FeatureService interface {
 	GetDecision(decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error)
 }
 
-// UserProfileService is used to save and retrieve past bucketing decisions for users
+// FilteredService is a Service whose Decision handlers can be registered for some decision types only, such as the
+// CompositeService
+type FilteredService interface {
+	Service
+	OnDecisionOfTypes(callback func(notification.DecisionNotification), decisionTypes ...notification.DecisionNotificationType) (int, error)
+}
+
+// UserProfileWarmingService is a Service which can retrieve the profiles of a set of users ahead of their decisions,
+// such as the CompositeService
+type UserProfileWarmingService interface {
+	Service
+	WarmUserProfiles(userIDs []string)
+}
+
+// ContextService is a Service accepting the context of the decision calls, which it passes on to the services it is
+// composed of, such as the CompositeService. It allows deadlines and tracing spans to flow into the decisions.
+type ContextService interface {
+	Service
+	GetFeatureDecisionWithContext(context.Context, FeatureDecisionContext, entities.UserContext) (FeatureDecision, error)
+	GetExperimentDecisionWithContext(context.Context, ExperimentDecisionContext, entities.UserContext) (ExperimentDecision, error)
+}
+
+// ContextExperimentService is an ExperimentService accepting the context of the decision calls
+type ContextExperimentService interface {
+	ExperimentService
+	GetDecisionWithContext(ctx context.Context, decisionContext ExperimentDecisionContext, userContext entities.UserContext) (ExperimentDecision, error)
+}
+
+// ContextFeatureService is a FeatureService accepting the context of the decision calls
+type ContextFeatureService interface {
+	FeatureService
+	GetDecisionWithContext(ctx context.Context, decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error)
+}
+
+// getExperimentDecision returns the decision of the experiment service, passing it the context if it accepts one
+func getExperimentDecision(ctx context.Context, experimentService ExperimentService, decisionContext ExperimentDecisionContext, userContext entities.UserContext) (ExperimentDecision, error) {
+	if contextService, ok := experimentService.(ContextExperimentService); ok {
+		return contextService.GetDecisionWithContext(ctx, decisionContext, userContext)
+	}
+	return experimentService.GetDecision(decisionContext, userContext)
+}
+
+// getFeatureDecision returns the decision of the feature service, passing it the context if it accepts one
+func getFeatureDecision(ctx context.Context, featureService FeatureService, decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
+	if contextService, ok := featureService.(ContextFeatureService); ok {
+		return contextService.GetDecisionWithContext(ctx, decisionContext, userContext)
+	}
+	return featureService.GetDecision(decisionContext, userContext)
+}
+
+// UserProfileService is used to save and retrieve past bucketing decisions for users. Decisions are made concurrently,
+// so implementations must be safe for concurrent use: Lookup, BatchLookup and Save may be called at the same time.
 type UserProfileService interface {
 	Lookup(string) UserProfile
+	// BatchLookup returns the profiles of the given users keyed by user ID, users without a profile can be left out. It
+	// is used to warm the profiles of a known set of users ahead of their decisions, see
+	// CompositeExperimentService.WarmUserProfiles.
+	BatchLookup(userIDs []string) map[string]UserProfile
 	Save(UserProfile)
 }
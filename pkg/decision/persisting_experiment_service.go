@@ -18,6 +18,7 @@
 package decision
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/optimizely/go-sdk/pkg/entities"
@@ -46,8 +47,13 @@ func NewPersistingExperimentService(experimentBucketerService ExperimentService,
 
 // GetDecision returns the decision with the variation the user is bucketed into
 func (p PersistingExperimentService) GetDecision(decisionContext ExperimentDecisionContext, userContext entities.UserContext) (experimentDecision ExperimentDecision, err error) {
+	return p.GetDecisionWithContext(context.Background(), decisionContext, userContext)
+}
+
+// GetDecisionWithContext is like GetDecision, passing the given context to the wrapped experiment service
+func (p PersistingExperimentService) GetDecisionWithContext(ctx context.Context, decisionContext ExperimentDecisionContext, userContext entities.UserContext) (experimentDecision ExperimentDecision, err error) {
 	if p.userProfileService == nil {
-		return p.experimentBucketedService.GetDecision(decisionContext, userContext)
+		return getExperimentDecision(ctx, p.experimentBucketedService, decisionContext, userContext)
 	}
 
 	var userProfile UserProfile
@@ -57,7 +63,7 @@ func (p PersistingExperimentService) GetDecision(decisionContext ExperimentDecis
 		return experimentDecision, nil
 	}
 
-	experimentDecision, err = p.experimentBucketedService.GetDecision(decisionContext, userContext)
+	experimentDecision, err = getExperimentDecision(ctx, p.experimentBucketedService, decisionContext, userContext)
 	if experimentDecision.Variation != nil {
 		// save decision if a user profile service is provided
 		userProfile.ID = userContext.ID
@@ -77,6 +83,8 @@ func (p PersistingExperimentService) getSavedDecision(decisionContext Experiment
 		return experimentDecision, userProfile
 	}
 
+	// a saved variation is honored as long as it still exists in the experiment, even if the traffic allocation has
+	// changed since, so that previously bucketed users are not re-bucketed
 	if savedVariationID, ok := userProfile.ExperimentBucketMap[decisionKey]; ok {
 		if variation, ok := decisionContext.Experiment.Variations[savedVariationID]; ok {
 			experimentDecision.Variation = &variation
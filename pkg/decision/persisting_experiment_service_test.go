@@ -120,6 +120,68 @@ func (s *PersistingExperimentServiceTestSuite) TestSavedVariationNoLongerValid()
 	s.mockUserProfileService.AssertExpectations(s.T())
 }
 
+func (s *PersistingExperimentServiceTestSuite) TestSavedVariationKeptWhenTrafficAllocationShrinks() {
+	// the variation the user was saved into no longer receives any traffic, but still exists
+	shrunkExperiment := testExp1113
+	shrunkExperiment.TrafficAllocation = []entities.Range{
+		entities.Range{EntityID: "2223", EndOfRange: 5000},
+	}
+	decisionContext := ExperimentDecisionContext{
+		Experiment:    &shrunkExperiment,
+		ProjectConfig: s.mockProjectConfig,
+	}
+
+	decisionKey := NewUserDecisionKey(shrunkExperiment.ID)
+	savedUserProfile := UserProfile{
+		ID:                  testUserContext.ID,
+		ExperimentBucketMap: map[UserDecisionKey]string{decisionKey: testExp1113Var2224.ID},
+	}
+	s.mockUserProfileService.On("Lookup", testUserContext.ID).Return(savedUserProfile)
+
+	persistingExperimentService := NewPersistingExperimentService(s.mockExperimentService, s.mockUserProfileService)
+	decision, err := persistingExperimentService.GetDecision(decisionContext, testUserContext)
+	s.Equal(ExperimentDecision{Variation: &testExp1113Var2224}, decision)
+	s.NoError(err)
+	s.mockExperimentService.AssertNotCalled(s.T(), "GetDecision", decisionContext, testUserContext)
+	s.mockUserProfileService.AssertNotCalled(s.T(), "Save", mock.Anything)
+}
+
+func (s *PersistingExperimentServiceTestSuite) TestSavedVariationRemovedFromConfig() {
+	// the variation the user was saved into has been removed from the experiment
+	updatedExperiment := testExp1113
+	updatedExperiment.Variations = map[string]entities.Variation{"2223": testExp1113Var2223}
+	updatedExperiment.VariationKeyToIDMap = map[string]string{"2223": "2223"}
+	updatedExperiment.TrafficAllocation = []entities.Range{
+		entities.Range{EntityID: "2223", EndOfRange: 10000},
+	}
+	decisionContext := ExperimentDecisionContext{
+		Experiment:    &updatedExperiment,
+		ProjectConfig: s.mockProjectConfig,
+	}
+
+	decisionKey := NewUserDecisionKey(updatedExperiment.ID)
+	savedUserProfile := UserProfile{
+		ID:                  testUserContext.ID,
+		ExperimentBucketMap: map[UserDecisionKey]string{decisionKey: testExp1113Var2224.ID},
+	}
+	s.mockUserProfileService.On("Lookup", testUserContext.ID).Return(savedUserProfile)
+
+	computedDecision := ExperimentDecision{Variation: &testExp1113Var2223}
+	s.mockExperimentService.On("GetDecision", decisionContext, testUserContext).Return(computedDecision, nil)
+	updatedUserProfile := UserProfile{
+		ID:                  testUserContext.ID,
+		ExperimentBucketMap: map[UserDecisionKey]string{decisionKey: testExp1113Var2223.ID},
+	}
+	s.mockUserProfileService.On("Save", updatedUserProfile)
+
+	persistingExperimentService := NewPersistingExperimentService(s.mockExperimentService, s.mockUserProfileService)
+	decision, err := persistingExperimentService.GetDecision(decisionContext, testUserContext)
+	s.Equal(computedDecision, decision)
+	s.NoError(err)
+	s.mockExperimentService.AssertCalled(s.T(), "GetDecision", decisionContext, testUserContext)
+	s.mockUserProfileService.AssertExpectations(s.T())
+}
+
 func TestPersistingExperimentServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(PersistingExperimentServiceTestSuite))
 }
@@ -41,6 +41,8 @@ const (
 	RolloutHasNoExperiments Reason = "Rollout has no experiments"
 	// NotBucketedIntoVariation - the user is not bucketed into a variation for the given experiment
 	NotBucketedIntoVariation Reason = "Not bucketed into a variation"
+	// NoTrafficAllocation - the experiment or rollout rule has no traffic allocation, so no one is bucketed into it
+	NoTrafficAllocation Reason = "No traffic allocation"
 	// NotInGroup - the user is not bucketed into the mutex group
 	NotInGroup Reason = "Not bucketed into any experiment in mutex group"
 	// NoWhitelistVariationAssignment - there is no variation assignment for the given user and experiment
@@ -55,4 +57,12 @@ const (
 	InvalidOverrideVariationAssignment Reason = "Invalid override variation assignment"
 	// OverrideVariationAssignmentFound - A valid override variation was found for the given user and experiment
 	OverrideVariationAssignmentFound Reason = "Override variation assignment found"
+	// NoFeatureOverride - No feature override matches the attributes of the given user
+	NoFeatureOverride Reason = "No feature override"
+	// FeatureOverrideFound - A feature override matches the attributes of the given user
+	FeatureOverrideFound Reason = "Feature override found"
+	// HeldOut - the user is held out of all experiments
+	HeldOut Reason = "Held out of all experiments"
+	// ExperimentNotRunning - the experiment is not running so the user is not bucketed into it
+	ExperimentNotRunning Reason = "Experiment is not running"
 )
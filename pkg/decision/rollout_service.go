@@ -18,6 +18,7 @@
 package decision
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/optimizely/go-sdk/pkg/decision/evaluator"
@@ -43,8 +44,21 @@ func NewRolloutService() *RolloutService {
 	}
 }
 
+// setAudienceTreeEvaluator sets the evaluator of the audience conditions of the rollout rules
+func (r *RolloutService) setAudienceTreeEvaluator(treeEvaluator evaluator.TreeEvaluator) {
+	r.audienceTreeEvaluator = treeEvaluator
+	if experimentBucketerService, ok := r.experimentBucketerService.(*ExperimentBucketerService); ok {
+		experimentBucketerService.audienceTreeEvaluator = treeEvaluator
+	}
+}
+
 // GetDecision returns a decision for the given feature and user context
 func (r RolloutService) GetDecision(decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
+	return r.GetDecisionWithContext(context.Background(), decisionContext, userContext)
+}
+
+// GetDecisionWithContext is like GetDecision, passing the given context to the bucketing of the rules
+func (r RolloutService) GetDecisionWithContext(ctx context.Context, decisionContext FeatureDecisionContext, userContext entities.UserContext) (FeatureDecision, error) {
 	featureDecision := FeatureDecision{
 		Source: Rollout,
 	}
@@ -61,38 +75,85 @@ func (r RolloutService) GetDecision(decisionContext FeatureDecisionContext, user
 		return featureDecision, nil
 	}
 
-	// For now, Rollouts is just a single experiment layer
-	experiment := rollout.Experiments[0]
+	// Targeted rules are evaluated in order until the user meets the audience conditions of one. If the user is then
+	// not bucketed into that rule, they fall through to the last "everyone else" rule.
+	lastRuleIndex := numberOfExperiments - 1
+	// the bucketing trace of a targeted rule the user falls through from is kept ahead of the one of the last rule
+	var fallThroughTrace []string
+	for index := 0; index < lastRuleIndex; index++ {
+		experiment := rollout.Experiments[index]
+		if !r.meetsTargeting(experiment, decisionContext, userContext) {
+			rsLogger.Debug(fmt.Sprintf(`User "%s" failed targeting for rule %d of feature rollout with key "%s".`, userContext.ID, index, feature.Key))
+			continue
+		}
+
+		featureDecision = r.getRuleDecision(ctx, index, experiment, decisionContext, userContext)
+		if featureDecision.Variation != nil {
+			rsLogger.Debug(fmt.Sprintf(`Decision made for user "%s" for rule %d of feature rollout with key "%s": %s.`, userContext.ID, index, feature.Key, featureDecision.Reason))
+			return featureDecision, nil
+		}
+		rsLogger.Debug(fmt.Sprintf(`User "%s" was not bucketed into rule %d of feature rollout with key "%s", falling through to the everyone else rule.`, userContext.ID, index, feature.Key))
+		fallThroughTrace = featureDecision.BucketingTrace
+		break
+	}
+
+	experiment := rollout.Experiments[lastRuleIndex]
+	if !r.meetsTargeting(experiment, decisionContext, userContext) {
+		featureDecision = FeatureDecision{
+			Decision: Decision{Reason: reasons.FailedRolloutTargeting},
+			Source:   Rollout,
+		}
+		rsLogger.Debug(fmt.Sprintf(`User "%s" failed targeting for feature rollout with key "%s".`, userContext.ID, feature.Key))
+		return featureDecision, nil
+	}
+
+	featureDecision = r.getRuleDecision(ctx, lastRuleIndex, experiment, decisionContext, userContext)
+	if fallThroughTrace != nil {
+		featureDecision.BucketingTrace = append(fallThroughTrace, featureDecision.BucketingTrace...)
+	}
+	rsLogger.Debug(fmt.Sprintf(`Decision made for user "%s" for rule %d of feature rollout with key "%s": %s.`, userContext.ID, lastRuleIndex, feature.Key, featureDecision.Reason))
+
+	return featureDecision, nil
+}
+
+// meetsTargeting returns whether the user meets the audience conditions of the given rollout rule
+func (r RolloutService) meetsTargeting(experiment entities.Experiment, decisionContext FeatureDecisionContext, userContext entities.UserContext) bool {
+	if experiment.AudienceConditionTree == nil {
+		return true
+	}
+
+	condTreeParams := entities.NewTreeParameters(&userContext, decisionContext.ProjectConfig.GetAudienceMap())
+	evalResult, _ := r.audienceTreeEvaluator.Evaluate(experiment.AudienceConditionTree, condTreeParams)
+	return evalResult
+}
+
+// getRuleDecision buckets the user into the rollout rule at the given index
+func (r RolloutService) getRuleDecision(ctx context.Context, index int, experiment entities.Experiment, decisionContext FeatureDecisionContext, userContext entities.UserContext) FeatureDecision {
 	experimentDecisionContext := ExperimentDecisionContext{
 		Experiment:    &experiment,
 		ProjectConfig: decisionContext.ProjectConfig,
 	}
 
-	// if user fails rollout targeting rule we return out of it
-	if experiment.AudienceConditionTree != nil {
-		condTreeParams := entities.NewTreeParameters(&userContext, decisionContext.ProjectConfig.GetAudienceMap())
-		evalResult, _ := r.audienceTreeEvaluator.Evaluate(experiment.AudienceConditionTree, condTreeParams)
-		if !evalResult {
-			featureDecision.Reason = reasons.FailedRolloutTargeting
-			rsLogger.Debug(fmt.Sprintf(`User "%s" failed targeting for feature rollout with key "%s".`, userContext.ID, feature.Key))
-			return featureDecision, nil
-		}
+	featureDecision := FeatureDecision{
+		Source:     Rollout,
+		Experiment: experiment,
 	}
-
-	decision, _ := r.experimentBucketerService.GetDecision(experimentDecisionContext, userContext)
+	decision, _ := getExperimentDecision(ctx, r.experimentBucketerService, experimentDecisionContext, userContext)
 	// translate the experiment reason into a more rollouts-appropriate reason
 	switch decision.Reason {
 	case reasons.NotBucketedIntoVariation:
-		featureDecision.Decision = Decision{Reason: reasons.FailedRolloutBucketing}
+		featureDecision.Decision = Decision{Reason: reasons.FailedRolloutBucketing, BucketingTrace: decision.BucketingTrace}
 	case reasons.BucketedIntoVariation:
-		featureDecision.Decision = Decision{Reason: reasons.BucketedIntoRollout}
+		featureDecision.Decision = Decision{Reason: reasons.BucketedIntoRollout, BucketingTrace: decision.BucketingTrace}
 	default:
 		featureDecision.Decision = decision.Decision
 	}
 
-	featureDecision.Experiment = experiment
-	featureDecision.Variation = decision.Variation
-	rsLogger.Debug(fmt.Sprintf(`Decision made for user "%s" for feature rollout with key "%s": %s.`, userContext.ID, feature.Key, featureDecision.Reason))
+	if bucketingTraceRequested(ctx) {
+		featureDecision.BucketingTrace = append(featureDecision.BucketingTrace,
+			fmt.Sprintf(`Rule %d of feature rollout with key "%s": %s.`, index, decisionContext.Feature.Key, featureDecision.Reason))
+	}
 
-	return featureDecision, nil
+	featureDecision.Variation = decision.Variation
+	return featureDecision
 }
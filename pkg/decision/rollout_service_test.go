@@ -17,6 +17,7 @@
 package decision
 
 import (
+	"context"
 	"testing"
 
 	"github.com/optimizely/go-sdk/pkg/decision/evaluator"
@@ -133,6 +134,176 @@ func (s *RolloutServiceTestSuite) TestGetDecisionFailsTargeting() {
 	s.mockExperimentService.AssertExpectations(s.T())
 }
 
+func (s *RolloutServiceTestSuite) getMultiRuleFeature() (entities.Feature, []entities.Experiment) {
+	rule1 := testExp1112
+	rule1.ID = "1201"
+	rule1.AudienceConditionTree = &entities.TreeNode{Operator: "or", Item: "rule_1_audience"}
+	rule2 := testExp1112
+	rule2.ID = "1202"
+	rule2.AudienceConditionTree = &entities.TreeNode{Operator: "or", Item: "rule_2_audience"}
+	everyoneElse := testExp1112
+	everyoneElse.ID = "1203"
+	everyoneElse.AudienceConditionTree = nil
+
+	rules := []entities.Experiment{rule1, rule2, everyoneElse}
+	feature := entities.Feature{
+		ID:      "3336",
+		Key:     "test_feature_multi_rule_rollout",
+		Rollout: entities.Rollout{ID: "4446", Experiments: rules},
+	}
+	return feature, rules
+}
+
+func (s *RolloutServiceTestSuite) TestGetDecisionFallsThroughToEveryoneElseOnTargeting() {
+	feature, rules := s.getMultiRuleFeature()
+	featureDecisionContext := FeatureDecisionContext{Feature: &feature, ProjectConfig: s.mockConfig}
+	everyoneElseContext := ExperimentDecisionContext{Experiment: &rules[2], ProjectConfig: s.mockConfig}
+
+	s.mockAudienceTreeEvaluator.On("Evaluate", rules[0].AudienceConditionTree, s.testConditionTreeParams).Return(false, true)
+	s.mockAudienceTreeEvaluator.On("Evaluate", rules[1].AudienceConditionTree, s.testConditionTreeParams).Return(false, true)
+	s.mockExperimentService.On("GetDecision", everyoneElseContext, s.testUserContext).Return(ExperimentDecision{
+		Variation: &testExp1112Var2222,
+		Decision:  Decision{Reason: reasons.BucketedIntoVariation},
+	}, nil)
+
+	testRolloutService := RolloutService{
+		audienceTreeEvaluator:     s.mockAudienceTreeEvaluator,
+		experimentBucketerService: s.mockExperimentService,
+	}
+	expectedFeatureDecision := FeatureDecision{
+		Experiment: rules[2],
+		Variation:  &testExp1112Var2222,
+		Source:     Rollout,
+		Decision:   Decision{Reason: reasons.BucketedIntoRollout},
+	}
+	decision, _ := testRolloutService.GetDecision(featureDecisionContext, s.testUserContext)
+	s.Equal(expectedFeatureDecision, decision)
+	s.mockAudienceTreeEvaluator.AssertExpectations(s.T())
+	s.mockExperimentService.AssertExpectations(s.T())
+}
+
+func (s *RolloutServiceTestSuite) TestGetDecisionFallsThroughToEveryoneElseOnBucketing() {
+	feature, rules := s.getMultiRuleFeature()
+	featureDecisionContext := FeatureDecisionContext{Feature: &feature, ProjectConfig: s.mockConfig}
+	rule1Context := ExperimentDecisionContext{Experiment: &rules[0], ProjectConfig: s.mockConfig}
+	everyoneElseContext := ExperimentDecisionContext{Experiment: &rules[2], ProjectConfig: s.mockConfig}
+
+	s.mockAudienceTreeEvaluator.On("Evaluate", rules[0].AudienceConditionTree, s.testConditionTreeParams).Return(true, true)
+	s.mockExperimentService.On("GetDecision", rule1Context, s.testUserContext).Return(ExperimentDecision{
+		Decision: Decision{Reason: reasons.NotBucketedIntoVariation},
+	}, nil)
+	s.mockExperimentService.On("GetDecision", everyoneElseContext, s.testUserContext).Return(ExperimentDecision{
+		Variation: &testExp1112Var2222,
+		Decision:  Decision{Reason: reasons.BucketedIntoVariation},
+	}, nil)
+
+	testRolloutService := RolloutService{
+		audienceTreeEvaluator:     s.mockAudienceTreeEvaluator,
+		experimentBucketerService: s.mockExperimentService,
+	}
+	expectedFeatureDecision := FeatureDecision{
+		Experiment: rules[2],
+		Variation:  &testExp1112Var2222,
+		Source:     Rollout,
+		Decision:   Decision{Reason: reasons.BucketedIntoRollout},
+	}
+	decision, _ := testRolloutService.GetDecision(featureDecisionContext, s.testUserContext)
+	s.Equal(expectedFeatureDecision, decision)
+	// the remaining targeted rule is skipped once the user met the targeting of an earlier one
+	s.mockAudienceTreeEvaluator.AssertNotCalled(s.T(), "Evaluate", rules[1].AudienceConditionTree, s.testConditionTreeParams)
+	s.mockExperimentService.AssertExpectations(s.T())
+}
+
+func (s *RolloutServiceTestSuite) TestGetDecisionBucketingTraceRuleIndex() {
+	feature, rules := s.getMultiRuleFeature()
+	featureDecisionContext := FeatureDecisionContext{Feature: &feature, ProjectConfig: s.mockConfig}
+	rule1Context := ExperimentDecisionContext{Experiment: &rules[0], ProjectConfig: s.mockConfig}
+	everyoneElseContext := ExperimentDecisionContext{Experiment: &rules[2], ProjectConfig: s.mockConfig}
+
+	s.mockAudienceTreeEvaluator.On("Evaluate", rules[0].AudienceConditionTree, s.testConditionTreeParams).Return(true, true)
+	s.mockExperimentService.On("GetDecision", rule1Context, s.testUserContext).Return(ExperimentDecision{
+		Decision: Decision{Reason: reasons.NotBucketedIntoVariation},
+	}, nil)
+	s.mockExperimentService.On("GetDecision", everyoneElseContext, s.testUserContext).Return(ExperimentDecision{
+		Variation: &testExp1112Var2222,
+		Decision:  Decision{Reason: reasons.BucketedIntoVariation},
+	}, nil)
+
+	testRolloutService := RolloutService{
+		audienceTreeEvaluator:     s.mockAudienceTreeEvaluator,
+		experimentBucketerService: s.mockExperimentService,
+	}
+	decision, _ := testRolloutService.GetDecisionWithContext(WithBucketingTrace(context.Background()), featureDecisionContext, s.testUserContext)
+	s.Equal([]string{
+		`Rule 0 of feature rollout with key "test_feature_multi_rule_rollout": Not bucketed into rollout.`,
+		`Rule 2 of feature rollout with key "test_feature_multi_rule_rollout": Bucketed into feature rollout.`,
+	}, decision.BucketingTrace)
+
+	// the rule index is only traced on request
+	decision, _ = testRolloutService.GetDecision(featureDecisionContext, s.testUserContext)
+	s.Nil(decision.BucketingTrace)
+}
+
+func (s *RolloutServiceTestSuite) TestGetDecisionBucketedIntoTargetedRule() {
+	feature, rules := s.getMultiRuleFeature()
+	featureDecisionContext := FeatureDecisionContext{Feature: &feature, ProjectConfig: s.mockConfig}
+	rule2Context := ExperimentDecisionContext{Experiment: &rules[1], ProjectConfig: s.mockConfig}
+
+	s.mockAudienceTreeEvaluator.On("Evaluate", rules[0].AudienceConditionTree, s.testConditionTreeParams).Return(false, true)
+	s.mockAudienceTreeEvaluator.On("Evaluate", rules[1].AudienceConditionTree, s.testConditionTreeParams).Return(true, true)
+	s.mockExperimentService.On("GetDecision", rule2Context, s.testUserContext).Return(ExperimentDecision{
+		Variation: &testExp1112Var2222,
+		Decision:  Decision{Reason: reasons.BucketedIntoVariation},
+	}, nil)
+
+	testRolloutService := RolloutService{
+		audienceTreeEvaluator:     s.mockAudienceTreeEvaluator,
+		experimentBucketerService: s.mockExperimentService,
+	}
+	expectedFeatureDecision := FeatureDecision{
+		Experiment: rules[1],
+		Variation:  &testExp1112Var2222,
+		Source:     Rollout,
+		Decision:   Decision{Reason: reasons.BucketedIntoRollout},
+	}
+	decision, _ := testRolloutService.GetDecision(featureDecisionContext, s.testUserContext)
+	s.Equal(expectedFeatureDecision, decision)
+	s.mockAudienceTreeEvaluator.AssertExpectations(s.T())
+	s.mockExperimentService.AssertExpectations(s.T())
+}
+
+func TestRolloutServiceNoTrafficAllocation(t *testing.T) {
+	targetedRule := testExp1112
+	targetedRule.AudienceConditionTree = nil
+	targetedRule.TrafficAllocation = []entities.Range{}
+	everyoneElseRule := testExp1112
+	everyoneElseRule.ID = "1117"
+	everyoneElseRule.AudienceConditionTree = nil
+	everyoneElseRule.TrafficAllocation = nil
+
+	feature := testFeatRollout3334
+	feature.Rollout = entities.Rollout{ID: "4444", Experiments: []entities.Experiment{everyoneElseRule}}
+	decisionContext := FeatureDecisionContext{Feature: &feature, ProjectConfig: new(mockProjectConfig)}
+	userContext := entities.UserContext{ID: "test_user"}
+
+	// no one is bucketed into a rule without traffic allocation
+	decision, err := NewRolloutService().GetDecision(decisionContext, userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, decision.Variation)
+	assert.Equal(t, reasons.NoTrafficAllocation, decision.Reason)
+
+	// the users fall through a targeted rule without traffic allocation to the everyone else rule
+	everyoneElseRule.TrafficAllocation = []entities.Range{{EntityID: "2222", EndOfRange: 10000}}
+	feature.Rollout.Experiments = []entities.Experiment{targetedRule, everyoneElseRule}
+	decision, err = NewRolloutService().GetDecision(decisionContext, userContext)
+	assert.NoError(t, err)
+	if assert.NotNil(t, decision.Variation) {
+		assert.Equal(t, testExp1112Var2222, *decision.Variation)
+	}
+	assert.Equal(t, everyoneElseRule.ID, decision.Experiment.ID)
+	assert.Equal(t, reasons.BucketedIntoRollout, decision.Reason)
+}
+
 func TestNewRolloutService(t *testing.T) {
 	rolloutService := NewRolloutService()
 	assert.IsType(t, &evaluator.MixedTreeEvaluator{}, rolloutService.audienceTreeEvaluator)
@@ -25,6 +25,22 @@ type Variation struct {
 	FeatureEnabled bool
 }
 
+// ExperimentStatus is the status of an experiment in the datafile
+type ExperimentStatus string
+
+const (
+	// ExperimentRunning is the status of an experiment which currently buckets users
+	ExperimentRunning ExperimentStatus = "Running"
+	// ExperimentLaunched is the status of an experiment which was launched to a single variation
+	ExperimentLaunched ExperimentStatus = "Launched"
+	// ExperimentPaused is the status of an experiment which is paused
+	ExperimentPaused ExperimentStatus = "Paused"
+	// ExperimentNotStarted is the status of an experiment which has not been started yet
+	ExperimentNotStarted ExperimentStatus = "Not started"
+	// ExperimentArchived is the status of an experiment which is archived
+	ExperimentArchived ExperimentStatus = "Archived"
+)
+
 // Experiment represents an experiment
 type Experiment struct {
 	AudienceIds           []string
@@ -38,6 +54,17 @@ type Experiment struct {
 	AudienceConditionTree *TreeNode
 	Whitelist             map[string]string
 	IsFeatureExperiment   bool
+	Status                ExperimentStatus
+}
+
+// IsRunning returns whether users are bucketed into the experiment. Experiments without a status, which were not
+// loaded from a datafile, are considered to be running.
+func (e Experiment) IsRunning() bool {
+	switch e.Status {
+	case "", ExperimentRunning, ExperimentLaunched:
+		return true
+	}
+	return false
 }
 
 // Range represents bucketing range that the specify entityID falls into
@@ -17,6 +17,8 @@
 // Package entities //
 package entities
 
+import "strconv"
+
 // Feature represents a feature flag
 type Feature struct {
 	ID                 string
@@ -53,3 +55,26 @@ const (
 	// Boolean - the feature-variable type is boolean
 	Boolean VariableType = "boolean"
 )
+
+// IsKnown returns true for the variable types supported by the SDK, the values of the other types are handled as strings
+func (t VariableType) IsKnown() bool {
+	switch t {
+	case String, Integer, Double, Boolean:
+		return true
+	}
+	return false
+}
+
+// ConvertValue returns the given value of the variable converted to the Go type of the variable type, the value is
+// returned as is for strings and unknown types
+func (v Variable) ConvertValue(value string) (interface{}, error) {
+	switch v.Type {
+	case Integer:
+		return strconv.Atoi(value)
+	case Double:
+		return strconv.ParseFloat(value, 64)
+	case Boolean:
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
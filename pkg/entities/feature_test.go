@@ -0,0 +1,57 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariableTypeIsKnown(t *testing.T) {
+	for _, variableType := range []VariableType{String, Integer, Double, Boolean} {
+		assert.True(t, variableType.IsKnown())
+	}
+	assert.False(t, VariableType("json").IsKnown())
+	assert.False(t, VariableType("").IsKnown())
+}
+
+func TestVariableConvertValue(t *testing.T) {
+	value, err := Variable{Type: Integer}.ConvertValue("10")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, value)
+
+	value, err = Variable{Type: Double}.ConvertValue("1.5")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, value)
+
+	value, err = Variable{Type: Boolean}.ConvertValue("true")
+	assert.NoError(t, err)
+	assert.Equal(t, true, value)
+
+	value, err = Variable{Type: String}.ConvertValue("value")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	// unknown types are handled as strings
+	value, err = Variable{Type: VariableType("json")}.ConvertValue(`{"key":"value"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"key":"value"}`, value)
+
+	_, err = Variable{Type: Integer}.ConvertValue("invalid")
+	assert.Error(t, err)
+}
@@ -23,7 +23,21 @@ import (
 	"github.com/optimizely/go-sdk/pkg/utils"
 )
 
-const bucketingIDAttributeName = "$opt_bucketing_id"
+// Reserved attribute keys consumed internally by the SDK
+const (
+	// bucketingIDAttributeName overrides the user ID when bucketing the user
+	bucketingIDAttributeName = "$opt_bucketing_id"
+	// userAgentAttributeName is forwarded with dispatched events and used for bot filtering
+	userAgentAttributeName = "$opt_user_agent"
+	// OptOutAttributeName suppresses impression and conversion events for the user when set to true
+	OptOutAttributeName = "$opt_opt_out"
+)
+
+// reservedStringAttributes lists the reserved attribute keys which must hold string values
+var reservedStringAttributes = []string{bucketingIDAttributeName, userAgentAttributeName}
+
+// reservedBoolAttributes lists the reserved attribute keys which must hold bool values
+var reservedBoolAttributes = []string{OptOutAttributeName}
 
 // UserContext holds information about a user
 type UserContext struct {
@@ -88,6 +102,25 @@ func (u UserContext) GetIntAttribute(attrName string) (int64, error) {
 	return 0, fmt.Errorf(`no int attribute named "%s"`, attrName)
 }
 
+// GetListAttribute returns the list value for the specified attribute name in the attributes map. Returns error if not found
+// or if the value is not a list.
+func (u UserContext) GetListAttribute(attrName string) ([]interface{}, error) {
+	if value, ok := u.Attributes[attrName]; ok {
+		switch listVal := value.(type) {
+		case []interface{}:
+			return listVal, nil
+		case []string:
+			items := make([]interface{}, len(listVal))
+			for i, item := range listVal {
+				items[i] = item
+			}
+			return items, nil
+		}
+	}
+
+	return nil, fmt.Errorf(`no list attribute named "%s"`, attrName)
+}
+
 // GetBucketingID returns the bucketing ID to use for the given user
 func (u UserContext) GetBucketingID() (string, error) {
 	// by default
@@ -104,3 +137,34 @@ func (u UserContext) GetBucketingID() (string, error) {
 
 	return bucketingID, nil
 }
+
+// IsOptedOut returns whether the user opted out of event tracking, decisions are still made for opted out users
+// but no impression or conversion events are sent for them
+func (u UserContext) IsOptedOut() bool {
+	optedOut, err := u.GetBoolAttribute(OptOutAttributeName)
+	return err == nil && optedOut
+}
+
+// ValidateReservedAttributes returns an error for every reserved attribute that is set to a value of the wrong type
+func (u UserContext) ValidateReservedAttributes() (errs []error) {
+	for _, attrName := range reservedStringAttributes {
+		value, ok := u.Attributes[attrName]
+		if !ok || value == nil {
+			continue
+		}
+		if _, err := utils.GetStringValue(value); err != nil {
+			errs = append(errs, fmt.Errorf(`reserved attribute "%s" must be a string, got "%v"`, attrName, value))
+		}
+	}
+	for _, attrName := range reservedBoolAttributes {
+		value, ok := u.Attributes[attrName]
+		if !ok || value == nil {
+			continue
+		}
+		if _, err := utils.GetBoolValue(value); err != nil {
+			errs = append(errs, fmt.Errorf(`reserved attribute "%s" must be a bool, got "%v"`, attrName, value))
+		}
+	}
+
+	return errs
+}
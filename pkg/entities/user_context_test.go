@@ -162,6 +162,34 @@ func TestUserAttributesGetIntAttribute(t *testing.T) {
 	}
 }
 
+func TestUserAttributesGetListAttribute(t *testing.T) {
+	userContext := UserContext{
+		Attributes: map[string]interface{}{
+			"list_roles":   []interface{}{"admin", "beta"},
+			"string_roles": []string{"admin", "beta"},
+			"string_foo":   "foo",
+		},
+	}
+
+	// Test happy path
+	listAttribute, _ := userContext.GetListAttribute("list_roles")
+	assert.Equal(t, []interface{}{"admin", "beta"}, listAttribute)
+	listAttribute, _ = userContext.GetListAttribute("string_roles")
+	assert.Equal(t, []interface{}{"admin", "beta"}, listAttribute)
+
+	// Test non-existent attr name
+	_, err := userContext.GetListAttribute("list_bar")
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), `no list attribute named "list_bar"`)
+	}
+
+	// Test non-list attribute
+	_, err = userContext.GetListAttribute("string_foo")
+	if assert.Error(t, err) {
+		assert.Equal(t, err.Error(), `no list attribute named "string_foo"`)
+	}
+}
+
 func TestGetBucketingID(t *testing.T) {
 
 	/******** No bucketingID *********/
@@ -210,3 +238,52 @@ func TestGetBucketingID(t *testing.T) {
 	assert.Equal(t, err, errors.New(`invalid bucketing ID provided: "234"`))
 	assert.Equal(t, id, "12312")
 }
+
+func TestValidateReservedAttributes(t *testing.T) {
+
+	/******** No reserved attributes *********/
+
+	userContext := UserContext{
+		ID: "12312",
+		Attributes: map[string]interface{}{
+			"int_42": 42,
+		},
+	}
+	assert.Empty(t, userContext.ValidateReservedAttributes())
+
+	/******** Valid reserved attributes *********/
+
+	userContext = UserContext{
+		ID: "12312",
+		Attributes: map[string]interface{}{
+			"$opt_bucketing_id": "234",
+			"$opt_user_agent":   "Mozilla/5.0",
+			"$opt_opt_out":      true,
+		},
+	}
+	assert.Empty(t, userContext.ValidateReservedAttributes())
+
+	/******** Invalid reserved attributes *********/
+
+	userContext = UserContext{
+		ID: "12312",
+		Attributes: map[string]interface{}{
+			"$opt_bucketing_id": 234,
+			"$opt_user_agent":   true,
+			"$opt_opt_out":      "yes",
+		},
+	}
+	errs := userContext.ValidateReservedAttributes()
+	assert.Equal(t, []error{
+		errors.New(`reserved attribute "$opt_bucketing_id" must be a string, got "234"`),
+		errors.New(`reserved attribute "$opt_user_agent" must be a string, got "true"`),
+		errors.New(`reserved attribute "$opt_opt_out" must be a bool, got "yes"`),
+	}, errs)
+}
+
+func TestIsOptedOut(t *testing.T) {
+	assert.False(t, UserContext{ID: "test_user"}.IsOptedOut())
+	assert.False(t, UserContext{ID: "test_user", Attributes: map[string]interface{}{"$opt_opt_out": false}}.IsOptedOut())
+	assert.False(t, UserContext{ID: "test_user", Attributes: map[string]interface{}{"$opt_opt_out": "true"}}.IsOptedOut())
+	assert.True(t, UserContext{ID: "test_user", Attributes: map[string]interface{}{"$opt_opt_out": true}}.IsOptedOut())
+}
@@ -18,6 +18,9 @@
 package event
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -26,15 +29,28 @@ import (
 	"github.com/optimizely/go-sdk/pkg/logging"
 	"github.com/optimizely/go-sdk/pkg/metrics"
 	"github.com/optimizely/go-sdk/pkg/utils"
+	"golang.org/x/sync/semaphore"
 )
 
 const maxRetries = 3
 const defaultQueueSize = 1000
 const sleepTime = 1 * time.Second
 
+// DefaultMaxInFlightBatches holds the default value for the max number of batches awaiting dispatch in the
+// QueueEventDispatcher of a BatchEventProcessor
+const DefaultMaxInFlightBatches = 100
+
+// ErrTooManyInFlightBatches is returned by the QueueEventDispatcher when the max number of log events awaiting dispatch
+// is reached, the processor then keeps the events queued until the dispatcher catches up
+var ErrTooManyInFlightBatches = errors.New("too many log events awaiting dispatch")
+
+// errDispatchFailed is the error reported for a log event the dispatcher failed to dispatch without returning an error
+var errDispatchFailed = errors.New("dispatch event failed")
+
 var dispatcherLogger = logging.GetLogger("EventDispatcher")
 
-// Dispatcher dispatches events
+// Dispatcher dispatches events. Implementations are free to use any transport, the processor considers a LogEvent
+// delivered when DispatchEvent returns (true, nil) and keeps it queued for a later retry otherwise.
 type Dispatcher interface {
 	DispatchEvent(event LogEvent) (bool, error)
 }
@@ -42,12 +58,39 @@ type Dispatcher interface {
 // HTTPEventDispatcher is the HTTP implementation of the Dispatcher interface
 type HTTPEventDispatcher struct {
 	requester *utils.HTTPRequester
+	encoder   Encoder
+}
+
+// NewHTTPEventDispatcher returns a new instance of the HTTPEventDispatcher sending the events with the given requester,
+// serialized with the given encoder. Nil values fall back to the default requester and the JSONEncoder.
+func NewHTTPEventDispatcher(requester *utils.HTTPRequester, encoder Encoder) *HTTPEventDispatcher {
+	if requester == nil {
+		requester = utils.NewHTTPRequester()
+	}
+	if encoder == nil {
+		encoder = JSONEncoder{}
+	}
+	return &HTTPEventDispatcher{requester: requester, encoder: encoder}
 }
 
 // DispatchEvent dispatches event with callback
 func (ed *HTTPEventDispatcher) DispatchEvent(event LogEvent) (bool, error) {
 
-	_, _, code, err := ed.requester.Post(event.EndPoint, event.Event)
+	endPoint := event.EndPoint
+	if endPoint == "" {
+		endPoint = getEventEndPoint(event.Region)
+	}
+
+	encoder := ed.encoder
+	if encoder == nil {
+		encoder = JSONEncoder{}
+	}
+	body, err := encoder.Encode(event.Event)
+	if err != nil {
+		dispatcherLogger.Error("failed to encode event", err)
+		return false, err
+	}
+	_, _, code, err := ed.requester.Do(endPoint, http.MethodPost, bytes.NewReader(body), []utils.Header{{Name: "Content-Type", Value: encoder.ContentType()}})
 
 	// also check response codes
 	// resp.StatusCode == 400 is an error
@@ -72,6 +115,22 @@ type QueueEventDispatcher struct {
 	eventFlushLock sync.Mutex
 	Dispatcher     Dispatcher
 
+	// projectStreams holds a queue per project when events are dispatched concurrently by more than one worker, the
+	// queue of a project is dropped once all its events are dispatched
+	workers            int
+	workerPool         *semaphore.Weighted
+	projectStreams     map[string]*dispatchStream
+	projectStreamsLock sync.Mutex
+
+	// onFailure is called with the event which failed to be dispatched once the retries are exhausted
+	onFailure func(event LogEvent, err error)
+	// onSuccess is called with every event which was dispatched
+	onSuccess func(event LogEvent)
+
+	// maxInFlight is the max number of events awaiting dispatch in all the streams, zero or less for no limit
+	maxInFlight  int
+	inFlightLock sync.Mutex
+
 	// metrics
 	queueSize         metrics.Gauge
 	sucessFlush       metrics.Counter
@@ -79,34 +138,204 @@ type QueueEventDispatcher struct {
 	retryFlushCounter metrics.Counter
 }
 
+// dispatchStream is a queue of events which are dispatched in order
+type dispatchStream struct {
+	queue     Queue
+	flushLock *sync.Mutex
+	// projectID is the project of the events of the stream, when they are dispatched concurrently
+	projectID string
+}
+
+// QDOptionFunc is used to pass custom options into the QueueEventDispatcher
+type QDOptionFunc func(*QueueEventDispatcher)
+
+// WithDispatcherWorkers sets the max number of log events dispatched concurrently. Events of different projects are
+// dispatched in parallel while the events of a project keep being dispatched one at a time, in order.
+func WithDispatcherWorkers(workers int) QDOptionFunc {
+	return func(ed *QueueEventDispatcher) {
+		ed.workers = workers
+	}
+}
+
+// WithDispatcherMaxInFlight sets the max number of log events awaiting dispatch, further events are rejected with
+// ErrTooManyInFlightBatches until some are dispatched. Zero or less disables the limit, which is the default.
+func WithDispatcherMaxInFlight(max int) QDOptionFunc {
+	return func(ed *QueueEventDispatcher) {
+		ed.maxInFlight = max
+	}
+}
+
+// WithDispatchFailureHandler sets the handler called with the log event and the last error once an event failed to be
+// dispatched after all retries. The event stays queued and is retried on the next flush.
+func WithDispatchFailureHandler(handler func(event LogEvent, err error)) QDOptionFunc {
+	return func(ed *QueueEventDispatcher) {
+		ed.onFailure = handler
+	}
+}
+
+// WithDispatchSuccessHandler sets the handler called with every log event which was dispatched
+func WithDispatchSuccessHandler(handler func(event LogEvent)) QDOptionFunc {
+	return func(ed *QueueEventDispatcher) {
+		ed.onSuccess = handler
+	}
+}
+
+// WithEncoder sets the encoder serializing the payload of the events, in place of the JSONEncoder. The events are then
+// sent by an HTTPEventDispatcher using it.
+func WithEncoder(encoder Encoder) QDOptionFunc {
+	return func(ed *QueueEventDispatcher) {
+		httpEventDispatcher := ed.httpEventDispatcher()
+		if encoder != nil {
+			httpEventDispatcher.encoder = encoder
+		}
+	}
+}
+
+// WithHTTPRequester sets the requester sending the events, in place of the default one. The events are then sent by an
+// HTTPEventDispatcher using it.
+func WithHTTPRequester(requester *utils.HTTPRequester) QDOptionFunc {
+	return func(ed *QueueEventDispatcher) {
+		httpEventDispatcher := ed.httpEventDispatcher()
+		if requester != nil {
+			httpEventDispatcher.requester = requester
+		}
+	}
+}
+
+// httpEventDispatcher returns the HTTPEventDispatcher the events are sent with, replacing a custom dispatcher with one
+func (ed *QueueEventDispatcher) httpEventDispatcher() *HTTPEventDispatcher {
+	if httpEventDispatcher, ok := ed.Dispatcher.(*HTTPEventDispatcher); ok {
+		return httpEventDispatcher
+	}
+	httpEventDispatcher := NewHTTPEventDispatcher(nil, nil)
+	ed.Dispatcher = httpEventDispatcher
+	return httpEventDispatcher
+}
+
 // DispatchEvent queues event with callback and calls flush in a go routine.
 func (ed *QueueEventDispatcher) DispatchEvent(event LogEvent) (bool, error) {
-	ed.eventQueue.Add(event)
+	stream, admitted := ed.admit(event)
+	if !admitted {
+		dispatcherLogger.Warning(fmt.Sprintf("%d log events are awaiting dispatch, rejecting event", ed.maxInFlight))
+		return false, ErrTooManyInFlightBatches
+	}
 	go func() {
-		ed.flushEvents()
+		ed.flushStream(stream)
 	}()
 	return true, nil
 }
 
+// admit queues the event in the stream it is dispatched in unless the max number of events awaiting dispatch is
+// reached, and returns that stream
+func (ed *QueueEventDispatcher) admit(event LogEvent) (*dispatchStream, bool) {
+	if ed.maxInFlight > 0 {
+		// the events are only removed concurrently, the count cannot exceed the max once checked
+		ed.inFlightLock.Lock()
+		defer ed.inFlightLock.Unlock()
+		if ed.queuedEventsCount() >= ed.maxInFlight {
+			return nil, false
+		}
+	}
+
+	if ed.workerPool == nil {
+		stream := ed.defaultStream()
+		stream.queue.Add(event)
+		return stream, true
+	}
+
+	// the event is queued with the lock held, so that the stream is not dropped before it is dispatched
+	ed.projectStreamsLock.Lock()
+	defer ed.projectStreamsLock.Unlock()
+	projectID := event.Event.ProjectID
+	stream, ok := ed.projectStreams[projectID]
+	if !ok {
+		stream = &dispatchStream{queue: NewInMemoryQueue(defaultQueueSize), flushLock: &sync.Mutex{}, projectID: projectID}
+		ed.projectStreams[projectID] = stream
+	}
+	stream.queue.Add(event)
+	return stream, true
+}
+
+// dropDrainedStream removes the stream of a project once all its events are dispatched, so that the streams of the
+// projects which stopped sending events do not accumulate. It must be called with the flush lock of the stream held.
+func (ed *QueueEventDispatcher) dropDrainedStream(stream *dispatchStream) {
+	if ed.projectStreams == nil {
+		return
+	}
+	ed.projectStreamsLock.Lock()
+	defer ed.projectStreamsLock.Unlock()
+	if ed.projectStreams[stream.projectID] == stream && stream.queue.Size() == 0 {
+		delete(ed.projectStreams, stream.projectID)
+	}
+}
+
+// defaultStream returns the stream of the events which are not dispatched concurrently
+func (ed *QueueEventDispatcher) defaultStream() *dispatchStream {
+	return &dispatchStream{queue: ed.eventQueue, flushLock: &ed.eventFlushLock}
+}
+
+// queuedEventsCount returns the number of events waiting to be dispatched in all the streams
+func (ed *QueueEventDispatcher) queuedEventsCount() int {
+	ed.projectStreamsLock.Lock()
+	defer ed.projectStreamsLock.Unlock()
+	count := ed.eventQueue.Size()
+	for _, stream := range ed.projectStreams {
+		count += stream.queue.Size()
+	}
+	return count
+}
+
 // flush the events
 func (ed *QueueEventDispatcher) flushEvents() {
+	ed.flushStream(ed.defaultStream())
+
+	ed.projectStreamsLock.Lock()
+	streams := make([]*dispatchStream, 0, len(ed.projectStreams))
+	for _, stream := range ed.projectStreams {
+		streams = append(streams, stream)
+	}
+	ed.projectStreamsLock.Unlock()
+
+	for _, stream := range streams {
+		ed.flushStream(stream)
+	}
+}
+
+// dispatch sends the event with the underlying dispatcher, waiting for a free worker when dispatching concurrently
+func (ed *QueueEventDispatcher) dispatch(event LogEvent) (bool, error) {
+	if ed.workerPool != nil {
+		if err := ed.workerPool.Acquire(context.Background(), 1); err != nil {
+			return false, err
+		}
+		defer ed.workerPool.Release(1)
+	}
+	return ed.Dispatcher.DispatchEvent(event)
+}
+
+// flush the events of the stream
+func (ed *QueueEventDispatcher) flushStream(stream *dispatchStream) {
 
-	ed.eventFlushLock.Lock()
+	stream.flushLock.Lock()
 
 	defer func() {
-		ed.eventFlushLock.Unlock()
+		stream.flushLock.Unlock()
 	}()
 
 	retryCount := 0
-	ed.queueSize.Set(float64(ed.eventQueue.Size()))
-	for ed.eventQueue.Size() > 0 {
+	var lastEvent LogEvent
+	var lastErr error
+	ed.queueSize.Set(float64(ed.queuedEventsCount()))
+	for stream.queue.Size() > 0 {
 		if retryCount > maxRetries {
 			dispatcherLogger.Error(fmt.Sprintf("event failed to send %d times. It will retry on next event sent", maxRetries), nil)
 			ed.failFlushCounter.Add(1)
+			if ed.onFailure != nil {
+				ed.onFailure(lastEvent, lastErr)
+			}
 			break
 		}
 
-		items := ed.eventQueue.Get(1)
+		items := stream.queue.Get(1)
 		if len(items) == 0 {
 			// something happened.  Just continue and you should expect size to be zero.
 			continue
@@ -115,21 +344,27 @@ func (ed *QueueEventDispatcher) flushEvents() {
 		if !ok {
 			// remove it
 			dispatcherLogger.Error("invalid type passed to event Dispatcher", nil)
-			ed.eventQueue.Remove(1)
+			stream.queue.Remove(1)
 			ed.failFlushCounter.Add(1)
 			continue
 		}
 
-		success, err := ed.Dispatcher.DispatchEvent(event)
+		success, err := ed.dispatch(event)
+		lastEvent = event
+		lastErr = err
 
 		if err == nil {
 			if success {
 				dispatcherLogger.Debug(fmt.Sprintf("Dispatched log event %+v", event))
-				ed.eventQueue.Remove(1)
+				stream.queue.Remove(1)
 				retryCount = 0
 				ed.sucessFlush.Add(1)
+				if ed.onSuccess != nil {
+					ed.onSuccess(event)
+				}
 			} else {
 				dispatcherLogger.Warning("dispatch event failed")
+				lastErr = errDispatchFailed
 				// we failed.  Sleep some seconds and try again.
 				time.Sleep(sleepTime)
 				// increase retryCount.  We exit if we have retried x times.
@@ -147,11 +382,12 @@ func (ed *QueueEventDispatcher) flushEvents() {
 			ed.retryFlushCounter.Add(1)
 		}
 	}
-	ed.queueSize.Set(float64(ed.eventQueue.Size()))
+	ed.dropDrainedStream(stream)
+	ed.queueSize.Set(float64(ed.queuedEventsCount()))
 }
 
 // NewQueueEventDispatcher creates a Dispatcher that queues in memory and then sends via go routine.
-func NewQueueEventDispatcher(metricsRegistry metrics.Registry) *QueueEventDispatcher {
+func NewQueueEventDispatcher(metricsRegistry metrics.Registry, options ...QDOptionFunc) *QueueEventDispatcher {
 
 	var dispatcherMetricsRegistry metrics.Registry
 	if metricsRegistry != nil {
@@ -160,13 +396,24 @@ func NewQueueEventDispatcher(metricsRegistry metrics.Registry) *QueueEventDispat
 		dispatcherMetricsRegistry = metrics.NewNoopRegistry() // protective code to set
 	}
 
-	return &QueueEventDispatcher{
+	dispatcher := &QueueEventDispatcher{
 		eventQueue: NewInMemoryQueue(defaultQueueSize),
-		Dispatcher: &HTTPEventDispatcher{requester: utils.NewHTTPRequester()},
+		Dispatcher: NewHTTPEventDispatcher(nil, nil),
 
 		queueSize:         dispatcherMetricsRegistry.GetGauge(metrics.DispatcherQueueSize),
 		retryFlushCounter: dispatcherMetricsRegistry.GetCounter(metrics.DispatcherRetryFlush),
 		failFlushCounter:  dispatcherMetricsRegistry.GetCounter(metrics.DispatcherFailedFlush),
 		sucessFlush:       dispatcherMetricsRegistry.GetCounter(metrics.DispatcherSuccessFlush),
 	}
+
+	for _, opt := range options {
+		opt(dispatcher)
+	}
+
+	if dispatcher.workers > 1 {
+		dispatcher.workerPool = semaphore.NewWeighted(int64(dispatcher.workers))
+		dispatcher.projectStreams = make(map[string]*dispatchStream)
+	}
+
+	return dispatcher
 }
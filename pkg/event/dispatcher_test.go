@@ -18,12 +18,18 @@
 package event
 
 import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/metrics"
+	"github.com/optimizely/go-sdk/pkg/utils"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -100,6 +106,95 @@ func NewMetricsRegistry() *MetricsRegistry {
 	}
 }
 
+// prefixEncoder is a custom encoder prefixing the JSON encoding of the payload
+type prefixEncoder struct {
+	err error
+}
+
+func (e prefixEncoder) Encode(batch Batch) ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	payload, err := JSONEncoder{}.Encode(batch)
+	return append([]byte("custom:"), payload...), err
+}
+
+func (prefixEncoder) ContentType() string {
+	return "application/x-custom"
+}
+
+// testLogEvent returns a log event to be sent to the given endpoint
+func testLogEvent(endPoint string) LogEvent {
+	conversionUserEvent := CreateConversionUserEvent(TestConfig{}, entities.Event{ExperimentIds: []string{"15402980349"}, ID: "15368860886", Key: "sample_conversion"}, userContext, nil)
+	logEvent := createLogEvent(createBatchEvent(conversionUserEvent, createVisitorFromUserEvent(conversionUserEvent)))
+	logEvent.EndPoint = endPoint
+	return logEvent
+}
+
+func TestHTTPEventDispatcher_Encoder(t *testing.T) {
+	var contentTypes []string
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	logEvent := testLogEvent(ts.URL)
+	payload, err := json.Marshal(logEvent.Event)
+	assert.NoError(t, err)
+
+	success, err := NewHTTPEventDispatcher(nil, nil).DispatchEvent(logEvent)
+	assert.True(t, success)
+	assert.NoError(t, err)
+
+	success, err = NewHTTPEventDispatcher(nil, prefixEncoder{}).DispatchEvent(logEvent)
+	assert.True(t, success)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"application/json", "application/x-custom"}, contentTypes)
+	assert.Equal(t, []string{string(payload), "custom:" + string(payload)}, bodies)
+
+	// nothing is sent when the payload can't be encoded
+	success, err = NewHTTPEventDispatcher(nil, prefixEncoder{err: errors.New("encoding failed")}).DispatchEvent(logEvent)
+	assert.False(t, success)
+	assert.EqualError(t, err, "encoding failed")
+	assert.Len(t, bodies, 2)
+}
+
+func TestQueueEventDispatcher_WithEncoder(t *testing.T) {
+	q := NewQueueEventDispatcher(nil, WithEncoder(prefixEncoder{}))
+	if dispatcher, ok := q.Dispatcher.(*HTTPEventDispatcher); assert.True(t, ok) {
+		assert.Equal(t, prefixEncoder{}, dispatcher.encoder)
+	}
+}
+
+func TestHTTPEventDispatcher_UserAgent(t *testing.T) {
+	var userAgents []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	logEvent := testLogEvent(ts.URL)
+	NewHTTPEventDispatcher(nil, nil).DispatchEvent(logEvent)
+	NewHTTPEventDispatcher(utils.NewHTTPRequester(utils.UserAgentSuffix("app/2.0")), nil).DispatchEvent(logEvent)
+
+	assert.Equal(t, []string{"go-sdk/" + SDKVersion, "go-sdk/" + SDKVersion + " app/2.0"}, userAgents)
+}
+
+func TestQueueEventDispatcher_WithHTTPRequester(t *testing.T) {
+	requester := utils.NewHTTPRequester(utils.UserAgentSuffix("app/2.0"))
+	q := NewQueueEventDispatcher(nil, WithHTTPRequester(requester), WithEncoder(prefixEncoder{}))
+	if dispatcher, ok := q.Dispatcher.(*HTTPEventDispatcher); assert.True(t, ok) {
+		assert.Equal(t, requester, dispatcher.requester)
+		assert.Equal(t, prefixEncoder{}, dispatcher.encoder)
+	}
+}
+
 func TestQueueEventDispatcher_DispatchEvent(t *testing.T) {
 	metricsRegistry := NewMetricsRegistry()
 
@@ -194,3 +289,175 @@ func TestQueueEventDispatcher_FailDispath(t *testing.T) {
 	// check the queue. bad event type should be removed.  but, not sent.
 	assert.Equal(t, 1, q.eventQueue.Size())
 }
+
+func TestQueueEventDispatcher_DispatchFailureHandler(t *testing.T) {
+	var failedEvents []LogEvent
+	var failedErrs []error
+	q := NewQueueEventDispatcher(nil, WithDispatchFailureHandler(func(event LogEvent, err error) {
+		failedEvents = append(failedEvents, event)
+		failedErrs = append(failedErrs, err)
+	}))
+	q.Dispatcher = &MockDispatcher{ShouldFail: true, Events: NewInMemoryQueue(100)}
+
+	conversionUserEvent := CreateConversionUserEvent(TestConfig{}, entities.Event{ExperimentIds: []string{"15402980349"}, ID: "15368860886", Key: "sample_conversion"}, userContext, nil)
+	logEvent := createLogEvent(createBatchEvent(conversionUserEvent, createVisitorFromUserEvent(conversionUserEvent)))
+	q.eventQueue.Add(logEvent)
+
+	q.flushEvents()
+
+	assert.Equal(t, []LogEvent{logEvent}, failedEvents)
+	if assert.Len(t, failedErrs, 1) {
+		assert.Error(t, failedErrs[0])
+	}
+	// the event is kept for the next flush
+	assert.Equal(t, 1, q.eventQueue.Size())
+}
+
+func TestQueueEventDispatcher_DispatchSuccessHandler(t *testing.T) {
+	var dispatchedEvents []LogEvent
+	q := NewQueueEventDispatcher(nil, WithDispatchSuccessHandler(func(event LogEvent) {
+		dispatchedEvents = append(dispatchedEvents, event)
+	}))
+	q.Dispatcher = &MockDispatcher{Events: NewInMemoryQueue(100)}
+
+	conversionUserEvent := CreateConversionUserEvent(TestConfig{}, entities.Event{ExperimentIds: []string{"15402980349"}, ID: "15368860886", Key: "sample_conversion"}, userContext, nil)
+	logEvent := createLogEvent(createBatchEvent(conversionUserEvent, createVisitorFromUserEvent(conversionUserEvent)))
+	q.eventQueue.Add(logEvent)
+
+	q.flushEvents()
+
+	assert.Equal(t, []LogEvent{logEvent}, dispatchedEvents)
+	assert.Equal(t, 0, q.eventQueue.Size())
+}
+
+type BlockingDispatcher struct {
+	started    chan LogEvent
+	release    chan struct{}
+	eventsLock sync.Mutex
+	Events     []LogEvent
+}
+
+func (b *BlockingDispatcher) DispatchEvent(event LogEvent) (bool, error) {
+	b.started <- event
+	<-b.release
+
+	b.eventsLock.Lock()
+	defer b.eventsLock.Unlock()
+	b.Events = append(b.Events, event)
+	return true, nil
+}
+
+func (b *BlockingDispatcher) eventsCount() int {
+	b.eventsLock.Lock()
+	defer b.eventsLock.Unlock()
+	return len(b.Events)
+}
+
+func TestQueueEventDispatcher_ConcurrentDispatch(t *testing.T) {
+	q := NewQueueEventDispatcher(nil, WithDispatcherWorkers(2))
+	sender := &BlockingDispatcher{started: make(chan LogEvent), release: make(chan struct{})}
+	q.Dispatcher = sender
+
+	q.DispatchEvent(LogEvent{Event: Batch{ProjectID: "project_a", Revision: "1"}})
+	q.DispatchEvent(LogEvent{Event: Batch{ProjectID: "project_a", Revision: "2"}})
+	q.DispatchEvent(LogEvent{Event: Batch{ProjectID: "project_b", Revision: "1"}})
+
+	// both projects are dispatched at the same time, the second event of a project waits for the first one
+	started := map[string]string{}
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-sender.started:
+			started[event.Event.ProjectID] = event.Event.Revision
+		case <-time.After(time.Second):
+			assert.Fail(t, "events of different projects were not dispatched concurrently")
+		}
+	}
+	assert.Equal(t, map[string]string{"project_a": "1", "project_b": "1"}, started)
+
+	select {
+	case <-sender.started:
+		assert.Fail(t, "events of the same project were dispatched concurrently")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(sender.release)
+	event := <-sender.started
+	assert.Equal(t, "project_a", event.Event.ProjectID)
+	assert.Equal(t, "2", event.Event.Revision)
+
+	assert.True(t, waitFor(func() bool { return sender.eventsCount() == 3 }))
+	assert.Equal(t, 0, q.queuedEventsCount())
+
+	var revisions []string
+	for _, logEvent := range sender.Events {
+		if logEvent.Event.ProjectID == "project_a" {
+			revisions = append(revisions, logEvent.Event.Revision)
+		}
+	}
+	assert.Equal(t, []string{"1", "2"}, revisions)
+}
+
+func TestQueueEventDispatcher_MaxInFlightBatches(t *testing.T) {
+	q := NewQueueEventDispatcher(nil, WithDispatcherMaxInFlight(2))
+	sender := &BlockingDispatcher{started: make(chan LogEvent, 10), release: make(chan struct{})}
+	q.Dispatcher = sender
+
+	success, err := q.DispatchEvent(LogEvent{Event: Batch{Revision: "1"}})
+	assert.True(t, success)
+	assert.NoError(t, err)
+	success, err = q.DispatchEvent(LogEvent{Event: Batch{Revision: "2"}})
+	assert.True(t, success)
+	assert.NoError(t, err)
+
+	success, err = q.DispatchEvent(LogEvent{Event: Batch{Revision: "3"}})
+	assert.False(t, success)
+	assert.Equal(t, ErrTooManyInFlightBatches, err)
+	assert.Equal(t, 2, q.queuedEventsCount())
+
+	close(sender.release)
+	assert.True(t, waitFor(func() bool { return sender.eventsCount() == 2 }))
+	success, err = q.DispatchEvent(LogEvent{Event: Batch{Revision: "4"}})
+	assert.True(t, success)
+	assert.NoError(t, err)
+	assert.True(t, waitFor(func() bool { return sender.eventsCount() == 3 }))
+
+	// the dispatcher has no limit by default
+	assert.Equal(t, 0, NewQueueEventDispatcher(nil).maxInFlight)
+}
+
+func TestQueueEventDispatcher_DropsDrainedStreams(t *testing.T) {
+	q := NewQueueEventDispatcher(nil, WithDispatcherWorkers(2))
+	sender := &BlockingDispatcher{started: make(chan LogEvent, 10), release: make(chan struct{})}
+	q.Dispatcher = sender
+	streamsCount := func() int {
+		q.projectStreamsLock.Lock()
+		defer q.projectStreamsLock.Unlock()
+		return len(q.projectStreams)
+	}
+
+	q.DispatchEvent(LogEvent{Event: Batch{ProjectID: "project_a"}})
+	q.DispatchEvent(LogEvent{Event: Batch{ProjectID: "project_b"}})
+	// the streams are kept while their events are awaiting dispatch
+	assert.Equal(t, 2, streamsCount())
+
+	close(sender.release)
+	assert.True(t, waitFor(func() bool { return sender.eventsCount() == 2 }))
+	assert.True(t, waitFor(func() bool { return streamsCount() == 0 }))
+
+	// a new stream is created for the next events of a project
+	q.DispatchEvent(LogEvent{Event: Batch{ProjectID: "project_a"}})
+	assert.True(t, waitFor(func() bool { return sender.eventsCount() == 3 }))
+	assert.True(t, waitFor(func() bool { return streamsCount() == 0 }))
+}
+
+func TestQueueEventDispatcher_SingleWorker(t *testing.T) {
+	q := NewQueueEventDispatcher(nil)
+	assert.Nil(t, q.workerPool)
+	assert.Nil(t, q.projectStreams)
+
+	processor := NewBatchEventProcessor(WithDispatchWorkers(3))
+	if dispatcher, ok := processor.EventDispatcher.(*QueueEventDispatcher); assert.True(t, ok) {
+		assert.Equal(t, 3, dispatcher.workers)
+		assert.NotNil(t, dispatcher.workerPool)
+	}
+}
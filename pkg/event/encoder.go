@@ -0,0 +1,40 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package event //
+package event
+
+import "encoding/json"
+
+// Encoder serializes the payload of the log events sent by the HTTPEventDispatcher
+type Encoder interface {
+	Encode(batch Batch) ([]byte, error)
+	// ContentType is sent as the Content-Type header of the requests
+	ContentType() string
+}
+
+// JSONEncoder encodes the payload as JSON, which is the format expected by the Optimizely log endpoint
+type JSONEncoder struct{}
+
+// Encode returns the JSON encoding of the batch
+func (JSONEncoder) Encode(batch Batch) ([]byte, error) {
+	return json.Marshal(batch)
+}
+
+// ContentType returns the JSON media type
+func (JSONEncoder) ContentType() string {
+	return "application/json"
+}
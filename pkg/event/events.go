@@ -17,6 +17,11 @@
 // Package event //
 package event
 
+import (
+	"errors"
+	"time"
+)
+
 // Context holds project-related contextual information about a UserEvent
 type Context struct {
 	Revision      string `json:"revision"`
@@ -26,6 +31,7 @@ type Context struct {
 	ClientName    string `json:"client_name"`
 	AnonymizeIP   bool   `json:"anonymize_ip"`
 	BotFiltering  bool   `json:"bot_filtering"`
+	Region        string `json:"-"`
 }
 
 // UserEvent represents a user event
@@ -36,6 +42,37 @@ type UserEvent struct {
 	VisitorID    string
 	Impression   *ImpressionEvent
 	Conversion   *ConversionEvent
+
+	enqueuedAt time.Time
+}
+
+// Validate checks that the user event carries the fields required to be batched and dispatched, it returns an error
+// describing the first missing field
+func (e UserEvent) Validate() error {
+	switch {
+	case e.UUID == "":
+		return errors.New("user event is missing a uuid")
+	case e.Timestamp <= 0:
+		return errors.New("user event is missing a timestamp")
+	case e.EventContext.ProjectID == "":
+		return errors.New("user event is missing a project id")
+	case e.EventContext.Revision == "":
+		return errors.New("user event is missing a revision")
+	case e.Impression == nil && e.Conversion == nil:
+		return errors.New("user event must be an impression or a conversion")
+	case e.Impression != nil && e.Conversion != nil:
+		return errors.New("user event cannot be both an impression and a conversion")
+	}
+
+	if e.Impression != nil && e.Impression.VariationID == "" {
+		return errors.New("impression event is missing a variation id")
+	}
+
+	if e.Conversion != nil && (e.Conversion.EntityID == "" || e.Conversion.Key == "") {
+		return errors.New("conversion event is missing an event id or key")
+	}
+
+	return nil
 }
 
 // ImpressionEvent represents an impression event
@@ -58,14 +95,39 @@ type ConversionEvent struct {
 	// 0 is equivalent to omitempty for json marshaling.
 	Revenue *int64   `json:"revenue,omitempty"`
 	Value   *float64 `json:"value,omitempty"`
+	// EventContext holds the project ID and revision of the config the event was generated against
+	EventContext Context `json:"-"`
+	// Decisions holds the experiment exposures of the user the conversion is attributed to
+	Decisions []Decision `json:"decisions,omitempty"`
 }
 
 // LogEvent represents a log event
 type LogEvent struct {
 	EndPoint string
-	Event    Batch
+	// Region selects the regional endpoint when EndPoint is not set
+	Region string
+	// Event is the payload of the log event, it can be serialized to JSON independently of the transport
+	Event Batch
+	// FlushReason is what triggered the dispatch of the log event, it is not part of the payload
+	FlushReason FlushReason
 }
 
+// FlushReason is what triggered the flush of the queued events
+type FlushReason string
+
+const (
+	// FlushReasonTimer is the flush of the flush interval
+	FlushReasonTimer FlushReason = "timer"
+	// FlushReasonBatchSize is the flush of a queue reaching the batch size
+	FlushReasonBatchSize FlushReason = "batch_size"
+	// FlushReasonFlush is an explicit flush, such as the ones of Flush and FlushWithTimeout
+	FlushReasonFlush FlushReason = "flush"
+	// FlushReasonClose is the final flush of a processor being stopped
+	FlushReasonClose FlushReason = "close"
+	// FlushReasonImmediate is the dispatch of an event which is not queued
+	FlushReasonImmediate FlushReason = "immediate"
+)
+
 // Batch - Context about the event to send in batch
 type Batch struct {
 	Revision        string    `json:"revision"`
@@ -76,6 +138,7 @@ type Batch struct {
 	ClientName      string    `json:"client_name"`
 	AnonymizeIP     bool      `json:"anonymize_ip"`
 	EnrichDecisions bool      `json:"enrich_decisions"`
+	Region          string    `json:"-"`
 }
 
 // Visitor represents a visitor of an eventbatch
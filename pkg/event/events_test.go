@@ -84,3 +84,32 @@ func TestSnapshotHasNonOptionalEvents(t *testing.T) {
 	_, ok = dict2["events"]
 	assert.True(t, ok)
 }
+
+func TestUserEventValidate(t *testing.T) {
+	assert.NoError(t, BuildTestImpressionEvent().Validate())
+	assert.NoError(t, BuildTestConversionEvent().Validate())
+
+	missingUUID := BuildTestImpressionEvent()
+	missingUUID.UUID = ""
+	assert.Error(t, missingUUID.Validate())
+
+	missingRevision := BuildTestConversionEvent()
+	missingRevision.EventContext.Revision = ""
+	assert.Error(t, missingRevision.Validate())
+
+	empty := BuildTestImpressionEvent()
+	empty.Impression = nil
+	assert.Error(t, empty.Validate())
+
+	both := BuildTestImpressionEvent()
+	both.Conversion = BuildTestConversionEvent().Conversion
+	assert.Error(t, both.Validate())
+
+	missingVariation := BuildTestImpressionEvent()
+	missingVariation.Impression.VariationID = ""
+	assert.Error(t, missingVariation.Validate())
+
+	missingKey := BuildTestConversionEvent()
+	missingKey.Conversion.Key = ""
+	assert.Error(t, missingKey.Validate())
+}
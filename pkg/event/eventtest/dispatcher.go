@@ -0,0 +1,104 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package eventtest provides an event dispatcher for testing applications that embed the SDK //
+package eventtest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/optimizely/go-sdk/pkg/event"
+)
+
+// ErrDispatchFailed is returned by the Dispatcher while it is set to fail
+var ErrDispatchFailed = errors.New("failed to dispatch")
+
+// Dispatcher is a thread-safe event.Dispatcher that captures the log events instead of sending them
+type Dispatcher struct {
+	events     []event.LogEvent
+	shouldFail bool
+	dispatched chan struct{}
+	lock       sync.Mutex
+}
+
+// NewDispatcher returns a new instance of the Dispatcher
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{dispatched: make(chan struct{})}
+}
+
+// DispatchEvent captures the log event, or fails with ErrDispatchFailed when the dispatcher is set to fail
+func (d *Dispatcher) DispatchEvent(logEvent event.LogEvent) (bool, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.shouldFail {
+		return false, ErrDispatchFailed
+	}
+
+	d.events = append(d.events, logEvent)
+	// wake up everyone waiting for events
+	close(d.dispatched)
+	d.dispatched = make(chan struct{})
+	return true, nil
+}
+
+// SetShouldFail toggles whether the following dispatches fail, failed log events are not captured
+func (d *Dispatcher) SetShouldFail(shouldFail bool) {
+	d.lock.Lock()
+	d.shouldFail = shouldFail
+	d.lock.Unlock()
+}
+
+// Events returns the captured log events in dispatch order
+func (d *Dispatcher) Events() []event.LogEvent {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return append([]event.LogEvent{}, d.events...)
+}
+
+// Reset drops the captured log events
+func (d *Dispatcher) Reset() {
+	d.lock.Lock()
+	d.events = nil
+	d.lock.Unlock()
+}
+
+// WaitForEvents blocks until at least count log events were captured or the timeout expires, and returns the captured
+// log events. An error is returned along with the events captured so far if the timeout expires.
+func (d *Dispatcher) WaitForEvents(count int, timeout time.Duration) ([]event.LogEvent, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		d.lock.Lock()
+		events := append([]event.LogEvent{}, d.events...)
+		dispatched := d.dispatched
+		d.lock.Unlock()
+
+		if len(events) >= count {
+			return events, nil
+		}
+
+		select {
+		case <-dispatched:
+		case <-timer.C:
+			return events, fmt.Errorf("timed out waiting for %d events, got %d", count, len(events))
+		}
+	}
+}
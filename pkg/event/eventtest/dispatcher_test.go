@@ -0,0 +1,100 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package eventtest //
+package eventtest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/optimizely/go-sdk/pkg/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher(t *testing.T) {
+	dispatcher := NewDispatcher()
+
+	success, err := dispatcher.DispatchEvent(event.LogEvent{EndPoint: "first"})
+	assert.True(t, success)
+	assert.NoError(t, err)
+
+	dispatcher.SetShouldFail(true)
+	success, err = dispatcher.DispatchEvent(event.LogEvent{EndPoint: "failed"})
+	assert.False(t, success)
+	assert.Equal(t, ErrDispatchFailed, err)
+
+	dispatcher.SetShouldFail(false)
+	success, err = dispatcher.DispatchEvent(event.LogEvent{EndPoint: "second"})
+	assert.True(t, success)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []event.LogEvent{{EndPoint: "first"}, {EndPoint: "second"}}, dispatcher.Events())
+
+	dispatcher.Reset()
+	assert.Empty(t, dispatcher.Events())
+}
+
+func TestDispatcherWaitForEvents(t *testing.T) {
+	dispatcher := NewDispatcher()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dispatcher.DispatchEvent(event.LogEvent{})
+		}()
+	}
+
+	events, err := dispatcher.WaitForEvents(5, time.Second)
+	assert.NoError(t, err)
+	assert.Len(t, events, 5)
+	wg.Wait()
+
+	events, err = dispatcher.WaitForEvents(6, 10*time.Millisecond)
+	assert.Error(t, err)
+	assert.Len(t, events, 5)
+}
+
+func TestDispatcherWithProcessor(t *testing.T) {
+	dispatcher := NewDispatcher()
+	processor := event.NewBatchEventProcessor(event.WithEventDispatcher(dispatcher))
+
+	processor.ProcessEvent(event.UserEvent{
+		Timestamp: time.Now().Unix(),
+		UUID:      "uuid",
+		EventContext: event.Context{
+			ProjectID: "1",
+			Revision:  "1",
+		},
+		VisitorID: "test_user",
+		Impression: &event.ImpressionEvent{
+			EntityID:     "1",
+			Key:          "campaign_activated",
+			ExperimentID: "2",
+			VariationID:  "3",
+			CampaignID:   "1",
+		},
+	})
+	processor.Flush()
+
+	events, err := dispatcher.WaitForEvents(1, time.Second)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "test_user", events[0].Event.Visitors[0].VisitorID)
+	}
+}
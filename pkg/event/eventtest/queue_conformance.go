@@ -0,0 +1,170 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package eventtest
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/optimizely/go-sdk/pkg/event"
+)
+
+// QueueConformanceTest verifies that the queues returned by newQueue behave the way the event processor expects:
+// Add appends items, Get(n) returns up to the n oldest items without removing them, Remove(n) removes and returns up
+// to the n oldest items, Size reports the number of queued items, and all of them are safe for concurrent use.
+// newQueue must return a new empty queue able to hold at least 100 items on every call. Custom Queue implementations
+// can call it from their own tests. Queues implementing SnapshotQueue are also verified to return a copy of their items.
+func QueueConformanceTest(t *testing.T, newQueue func() event.Queue) {
+	t.Run("Snapshot", func(t *testing.T) {
+		queue, ok := newQueue().(event.SnapshotQueue)
+		if !ok {
+			t.Skip("queue does not implement SnapshotQueue")
+		}
+		expectItems(t, "Snapshot", queue.Snapshot(), []interface{}{})
+		for i := 1; i <= 3; i++ {
+			queue.Add(i)
+		}
+		snapshot := queue.Snapshot()
+		expectItems(t, "Snapshot", snapshot, []interface{}{1, 2, 3})
+		expectSize(t, queue, 3)
+
+		queue.Remove(2)
+		queue.Add(4)
+		expectItems(t, "Snapshot", snapshot, []interface{}{1, 2, 3})
+		expectItems(t, "Snapshot", queue.Snapshot(), []interface{}{3, 4})
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		queue := newQueue()
+		expectSize(t, queue, 0)
+		expectItems(t, "Get", queue.Get(1), []interface{}{})
+		expectItems(t, "Remove", queue.Remove(1), []interface{}{})
+		expectSize(t, queue, 0)
+	})
+
+	t.Run("FIFO", func(t *testing.T) {
+		queue := newQueue()
+		for i := 1; i <= 5; i++ {
+			queue.Add(i)
+		}
+		expectSize(t, queue, 5)
+
+		expectItems(t, "Get", queue.Get(0), []interface{}{})
+		expectItems(t, "Get", queue.Get(3), []interface{}{1, 2, 3})
+		expectItems(t, "Get", queue.Get(10), []interface{}{1, 2, 3, 4, 5})
+		expectSize(t, queue, 5)
+
+		expectItems(t, "Remove", queue.Remove(2), []interface{}{1, 2})
+		expectSize(t, queue, 3)
+		expectItems(t, "Get", queue.Get(1), []interface{}{3})
+
+		queue.Add(6)
+		expectItems(t, "Remove", queue.Remove(10), []interface{}{3, 4, 5, 6})
+		expectSize(t, queue, 0)
+	})
+
+	t.Run("ConcurrentAdd", func(t *testing.T) {
+		queue := newQueue()
+		producers, itemsPerProducer := 10, 10
+
+		var wg sync.WaitGroup
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func(p int) {
+				defer wg.Done()
+				for i := 0; i < itemsPerProducer; i++ {
+					queue.Add(fmt.Sprintf("%d-%d", p, i))
+				}
+			}(p)
+		}
+		wg.Wait()
+
+		expectSize(t, queue, producers*itemsPerProducer)
+		expectPerProducerOrder(t, queue.Remove(producers*itemsPerProducer), producers, itemsPerProducer)
+		expectSize(t, queue, 0)
+	})
+
+	t.Run("ConcurrentAddRemove", func(t *testing.T) {
+		queue := newQueue()
+		producers, itemsPerProducer := 5, 20
+		total := producers * itemsPerProducer
+
+		for p := 0; p < producers; p++ {
+			go func(p int) {
+				for i := 0; i < itemsPerProducer; i++ {
+					queue.Add(fmt.Sprintf("%d-%d", p, i))
+				}
+			}(p)
+		}
+
+		removed := []interface{}{}
+		deadline := time.Now().Add(5 * time.Second)
+		for len(removed) < total && time.Now().Before(deadline) {
+			queue.Get(3)
+			removed = append(removed, queue.Remove(3)...)
+		}
+
+		if len(removed) != total {
+			t.Fatalf("expected to remove %d items, removed %d", total, len(removed))
+		}
+		expectPerProducerOrder(t, removed, producers, itemsPerProducer)
+		expectSize(t, queue, 0)
+	})
+}
+
+func expectSize(t *testing.T, queue event.Queue, expected int) {
+	t.Helper()
+	if size := queue.Size(); size != expected {
+		t.Errorf("expected Size to return %d, got %d", expected, size)
+	}
+}
+
+func expectItems(t *testing.T, method string, items, expected []interface{}) {
+	t.Helper()
+	if len(items) == 0 && len(expected) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("expected %s to return %v, got %v", method, expected, items)
+	}
+}
+
+// expectPerProducerOrder checks every "<producer>-<index>" item is present exactly once and in the order it was added
+func expectPerProducerOrder(t *testing.T, items []interface{}, producers, itemsPerProducer int) {
+	t.Helper()
+	next := make([]int, producers)
+	for _, item := range items {
+		var p, i int
+		if _, err := fmt.Sscanf(fmt.Sprintf("%v", item), "%d-%d", &p, &i); err != nil || p < 0 || p >= producers {
+			t.Errorf("unexpected item %v", item)
+			return
+		}
+		if i != next[p] {
+			t.Errorf("expected item %d-%d, got %v", p, next[p], item)
+			return
+		}
+		next[p]++
+	}
+	for p, count := range next {
+		if count != itemsPerProducer {
+			t.Errorf("expected %d items from producer %d, got %d", itemsPerProducer, p, count)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package eventtest
+
+import (
+	"testing"
+
+	"github.com/optimizely/go-sdk/pkg/event"
+)
+
+func TestInMemoryQueue_Conformance(t *testing.T) {
+	QueueConformanceTest(t, func() event.Queue {
+		return event.NewInMemoryQueue(100)
+	})
+}
+
+func TestChanQueue_Conformance(t *testing.T) {
+	QueueConformanceTest(t, func() event.Queue {
+		return event.NewChanQueue(100)
+	})
+	QueueConformanceTest(t, func() event.Queue {
+		return event.NewChanQueue(1)
+	})
+}
@@ -36,16 +36,72 @@ const impressionKey string = "campaign_activated"
 const attributeType = "custom"
 const specialPrefix = "$opt_"
 const botFilteringKey = "$opt_bot_filtering"
+const userAgentKey = "$opt_user_agent"
 const eventEndPoint = "https://logx.optimizely.com/v1/events"
 const revenueKey = "revenue"
 const valueKey = "value"
 
+// DefaultRegion is the region events are dispatched to when the project config does not specify one
+const DefaultRegion = "US"
+
+// EURegion is the data residency region of the European Union
+const EURegion = "EU"
+
+// regionalEventEndPoints holds the event endpoint for each supported data residency region
+var regionalEventEndPoints = map[string]string{
+	DefaultRegion: eventEndPoint,
+	EURegion:      "https://eu.logx.optimizely.com/v1/events",
+}
+
+// ValidateRegion returns an error if the given region is not a supported data residency region
+func ValidateRegion(region string) error {
+	if _, ok := regionalEventEndPoints[region]; !ok {
+		return fmt.Errorf(`unknown region "%s"`, region)
+	}
+	return nil
+}
+
+// getEventEndPoint returns the endpoint for the given region, defaulting to the US endpoint
+func getEventEndPoint(region string) string {
+	if endPoint, ok := regionalEventEndPoints[region]; ok {
+		return endPoint
+	}
+	if region != "" {
+		efLogger.Warning(fmt.Sprintf(`Unknown region "%s", dispatching events to the %s endpoint`, region, DefaultRegion))
+	}
+	return eventEndPoint
+}
+
 func createLogEvent(event Batch) LogEvent {
-	return LogEvent{EndPoint: eventEndPoint, Event: event}
+	return LogEvent{EndPoint: getEventEndPoint(event.Region), Region: event.Region, Event: event}
+}
+
+// timestamps of conversion events older than this or further ahead in the future than the allowed skew are suspicious
+const maxTimestampAge = 30 * 24 * time.Hour
+const maxTimestampSkew = time.Hour
+
+func toTimestamp(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
 }
 
-func makeTimestamp() int64 {
-	return time.Now().UnixNano() / int64(time.Millisecond)
+// eventTimestamp returns the timestamp of an event created at the given time, a zero time defaults to the current time
+// of the clock
+func eventTimestamp(timestamp time.Time, clock utils.Clock) int64 {
+	now := clock.Now()
+	if timestamp.IsZero() {
+		return toTimestamp(now)
+	}
+	checkTimestamp(timestamp, now)
+	return toTimestamp(timestamp)
+}
+
+// warn about timestamp overrides which are unlikely to be accepted by the log endpoint, the event is still created
+func checkTimestamp(timestamp, now time.Time) {
+	if timestamp.Before(now.Add(-maxTimestampAge)) {
+		efLogger.Warning(fmt.Sprintf("Event timestamp %s is older than %s", timestamp, maxTimestampAge))
+	} else if timestamp.After(now.Add(maxTimestampSkew)) {
+		efLogger.Warning(fmt.Sprintf("Event timestamp %s is more than %s in the future", timestamp, maxTimestampSkew))
+	}
 }
 
 // CreateEventContext creates and returns EventContext
@@ -58,6 +114,9 @@ func CreateEventContext(projectConfig config.ProjectConfig) Context {
 	context.ClientVersion = Version
 	context.AnonymizeIP = projectConfig.GetAnonymizeIP()
 	context.BotFiltering = projectConfig.GetBotFiltering()
+	if regionConfig, ok := projectConfig.(config.RegionProjectConfig); ok {
+		context.Region = regionConfig.GetRegion()
+	}
 
 	return context
 }
@@ -80,11 +139,25 @@ func createImpressionEvent(projectConfig config.ProjectConfig, experiment entiti
 func CreateImpressionUserEvent(projectConfig config.ProjectConfig, experiment entities.Experiment,
 	variation entities.Variation,
 	userContext entities.UserContext) UserEvent {
+	return CreateImpressionUserEventAt(projectConfig, experiment, variation, userContext, time.Time{})
+}
+
+// CreateImpressionUserEventAt is like CreateImpressionUserEvent, using the given timestamp for the event instead of the
+// current time. A zero timestamp defaults to the current time, a timestamp out of a reasonable range is logged.
+func CreateImpressionUserEventAt(projectConfig config.ProjectConfig, experiment entities.Experiment,
+	variation entities.Variation, userContext entities.UserContext, timestamp time.Time) UserEvent {
+	return CreateImpressionUserEventWithClock(projectConfig, experiment, variation, userContext, timestamp, utils.DefaultClock{})
+}
+
+// CreateImpressionUserEventWithClock is like CreateImpressionUserEventAt, taking the current time from the given clock
+// instead of the system clock, both to default a zero timestamp to and to check the timestamp against.
+func CreateImpressionUserEventWithClock(projectConfig config.ProjectConfig, experiment entities.Experiment,
+	variation entities.Variation, userContext entities.UserContext, timestamp time.Time, clock utils.Clock) UserEvent {
 
 	impression := createImpressionEvent(projectConfig, experiment, variation, userContext.Attributes)
 
 	userEvent := UserEvent{}
-	userEvent.Timestamp = makeTimestamp()
+	userEvent.Timestamp = eventTimestamp(timestamp, clock)
 	userEvent.VisitorID = userContext.ID
 	userEvent.UUID = guuid.New().String()
 	userEvent.Impression = &impression
@@ -101,7 +174,7 @@ func createImpressionVisitor(userEvent UserEvent) Visitor {
 	decision.VariationID = userEvent.Impression.VariationID
 
 	dispatchEvent := SnapshotEvent{}
-	dispatchEvent.Timestamp = makeTimestamp()
+	dispatchEvent.Timestamp = userEvent.Timestamp
 	dispatchEvent.Key = userEvent.Impression.Key
 	dispatchEvent.EntityID = userEvent.Impression.EntityID
 	dispatchEvent.UUID = guuid.New().String()
@@ -126,14 +199,29 @@ func createConversionEvent(projectConfig config.ProjectConfig, event entities.Ev
 
 // CreateConversionUserEvent creates and returns ConversionEvent for user
 func CreateConversionUserEvent(projectConfig config.ProjectConfig, event entities.Event, userContext entities.UserContext, eventTags map[string]interface{}) UserEvent {
+	return CreateConversionUserEventAt(projectConfig, event, userContext, eventTags, time.Time{})
+}
+
+// CreateConversionUserEventAt is like CreateConversionUserEvent, using the given timestamp for the event instead of the
+// current time. A zero timestamp defaults to the current time, a timestamp out of a reasonable range is logged.
+func CreateConversionUserEventAt(projectConfig config.ProjectConfig, event entities.Event, userContext entities.UserContext,
+	eventTags map[string]interface{}, timestamp time.Time) UserEvent {
+	return CreateConversionUserEventWithClock(projectConfig, event, userContext, eventTags, timestamp, utils.DefaultClock{})
+}
+
+// CreateConversionUserEventWithClock is like CreateConversionUserEventAt, taking the current time from the given clock
+// instead of the system clock, both to default a zero timestamp to and to check the timestamp against.
+func CreateConversionUserEventWithClock(projectConfig config.ProjectConfig, event entities.Event,
+	userContext entities.UserContext, eventTags map[string]interface{}, timestamp time.Time, clock utils.Clock) UserEvent {
 
 	userEvent := UserEvent{}
-	userEvent.Timestamp = makeTimestamp()
+	userEvent.Timestamp = eventTimestamp(timestamp, clock)
 	userEvent.VisitorID = userContext.ID
 	userEvent.UUID = guuid.New().String()
 
 	userEvent.EventContext = CreateEventContext(projectConfig)
 	conversion := createConversionEvent(projectConfig, event, userContext.Attributes, eventTags)
+	conversion.EventContext = userEvent.EventContext
 	revenue, err := getRevenueValue(eventTags)
 	if err == nil {
 		conversion.Revenue = &revenue
@@ -165,7 +253,7 @@ func createVisitorFromUserEvent(event UserEvent) Visitor {
 func createConversionVisitor(userEvent UserEvent) Visitor {
 
 	dispatchEvent := SnapshotEvent{}
-	dispatchEvent.Timestamp = makeTimestamp()
+	dispatchEvent.Timestamp = userEvent.Timestamp
 	dispatchEvent.Key = userEvent.Conversion.Key
 	dispatchEvent.EntityID = userEvent.Conversion.EntityID
 	dispatchEvent.UUID = userEvent.UUID
@@ -177,7 +265,12 @@ func createConversionVisitor(userEvent UserEvent) Visitor {
 		dispatchEvent.Value = userEvent.Conversion.Value
 	}
 
-	visitor := createVisitor(userEvent, userEvent.Conversion.Attributes, []Decision{}, []SnapshotEvent{dispatchEvent})
+	decisions := []Decision{}
+	if len(userEvent.Conversion.Decisions) > 0 {
+		decisions = userEvent.Conversion.Decisions
+	}
+
+	visitor := createVisitor(userEvent, userEvent.Conversion.Attributes, decisions, []SnapshotEvent{dispatchEvent})
 
 	return visitor
 }
@@ -211,7 +304,15 @@ func createBatchEvent(userEvent UserEvent, visitor Visitor) Batch {
 	eventBatch.Visitors = []Visitor{visitor}
 	eventBatch.ClientName = userEvent.EventContext.ClientName
 	eventBatch.ClientVersion = userEvent.EventContext.ClientVersion
+	// the backend attributes the events to the client, so they are never sent without one
+	if eventBatch.ClientName == "" {
+		eventBatch.ClientName = ClientName
+	}
+	if eventBatch.ClientVersion == "" {
+		eventBatch.ClientVersion = Version
+	}
 	eventBatch.AnonymizeIP = userEvent.EventContext.AnonymizeIP
+	eventBatch.Region = userEvent.EventContext.Region
 	eventBatch.EnrichDecisions = true
 
 	return eventBatch
@@ -220,18 +321,28 @@ func createBatchEvent(userEvent UserEvent, visitor Visitor) Batch {
 // get visitor attributes from user attributes
 func getEventAttributes(projectConfig config.ProjectConfig, attributes map[string]interface{}) []VisitorAttribute {
 	var eventAttributes = []VisitorAttribute{}
+	botFiltering := projectConfig.GetBotFiltering()
 
 	for key, value := range attributes {
 		if value == nil {
 			continue
 		}
+		if key == userAgentKey && !botFiltering {
+			// the user agent is only used by the backend to filter out bots
+			efLogger.Debug("Bot filtering is disabled. Pruning user agent before sending event to Optimizely.")
+			continue
+		}
 		visitorAttribute := VisitorAttribute{}
 		attribute, _ := projectConfig.GetAttributeByKey(key)
 
 		switch {
 		case attribute.ID != "":
+			if strings.HasPrefix(key, specialPrefix) {
+				efLogger.Warning(fmt.Sprintf("Attribute %s has the reserved prefix %s, sending its attribute ID.", key, specialPrefix))
+			}
 			visitorAttribute.EntityID = attribute.ID
 		case strings.HasPrefix(key, specialPrefix):
+			// reserved attributes are identified by their key
 			visitorAttribute.EntityID = key
 		default:
 			efLogger.Debug(fmt.Sprintf("Unrecognized attribute %s provided. Pruning before sending event to Optimizely.", key))
@@ -245,7 +356,7 @@ func getEventAttributes(projectConfig config.ProjectConfig, attributes map[strin
 	}
 
 	visitorAttribute := VisitorAttribute{}
-	visitorAttribute.Value = projectConfig.GetBotFiltering()
+	visitorAttribute.Value = botFiltering
 	visitorAttribute.AttributeType = attributeType
 	visitorAttribute.Key = botFilteringKey
 	visitorAttribute.EntityID = botFilteringKey
@@ -18,13 +18,19 @@
 package event
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"math/rand"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/optimizely/go-sdk/pkg/config"
 	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/logging"
+	"github.com/optimizely/go-sdk/pkg/utils/utilstest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -133,11 +139,11 @@ func TestCreateAndSendImpressionEvent(t *testing.T) {
 
 	processor.ProcessEvent(impressionUserEvent)
 
-	assert.Equal(t, 1, processor.eventsCount())
+	assert.Equal(t, 1, processor.EventsCount())
 
 	time.Sleep(100 * time.Millisecond)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 }
 
 func TestCreateAndSendConversionEvent(t *testing.T) {
@@ -151,11 +157,11 @@ func TestCreateAndSendConversionEvent(t *testing.T) {
 
 	processor.ProcessEvent(conversionUserEvent)
 
-	assert.Equal(t, 1, processor.eventsCount())
+	assert.Equal(t, 1, processor.EventsCount())
 
 	time.Sleep(100 * time.Millisecond)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 }
 
 func TestCreateConversionEventRevenue(t *testing.T) {
@@ -172,3 +178,217 @@ func TestCreateConversionEventRevenue(t *testing.T) {
 	assert.Equal(t, 25.1, *batch.Visitors[0].Snapshots[0].Events[0].Value)
 
 }
+
+func TestCreateConversionEventContext(t *testing.T) {
+	conversionUserEvent := BuildTestConversionEvent()
+
+	assert.Equal(t, conversionUserEvent.EventContext, conversionUserEvent.Conversion.EventContext)
+	assert.Equal(t, "15389410617", conversionUserEvent.Conversion.EventContext.ProjectID)
+	assert.Equal(t, "7", conversionUserEvent.Conversion.EventContext.Revision)
+}
+
+func TestCreateConversionUserEventAt(t *testing.T) {
+	timestamp := time.Now().Add(-24 * time.Hour)
+	event := entities.Event{ExperimentIds: []string{"15402980349"}, ID: "15368860886", Key: "sample_conversion"}
+
+	conversionUserEvent := CreateConversionUserEventAt(TestConfig{}, event, userContext, nil, timestamp)
+	assert.Equal(t, timestamp.UnixNano()/int64(time.Millisecond), conversionUserEvent.Timestamp)
+
+	// the timestamp is the one of the dispatched snapshot
+	batch := createBatchEvent(conversionUserEvent, createVisitorFromUserEvent(conversionUserEvent))
+	assert.Equal(t, conversionUserEvent.Timestamp, batch.Visitors[0].Snapshots[0].Events[0].Timestamp)
+
+	before := toTimestamp(time.Now())
+	conversionUserEvent = CreateConversionUserEventAt(TestConfig{}, event, userContext, nil, time.Time{})
+	assert.True(t, conversionUserEvent.Timestamp >= before)
+}
+
+func TestCreateConversionUserEventWithClock(t *testing.T) {
+	out := &bytes.Buffer{}
+	logging.SetLogger(logging.NewFilteredLevelLogConsumer(logging.LogLevelWarning, out))
+	defer logging.SetLogger(logging.NewFilteredLevelLogConsumer(logging.LogLevelInfo, os.Stdout))
+
+	clock := utilstest.NewClock()
+	clock.Set(time.Now().Add(-365 * 24 * time.Hour).Truncate(time.Millisecond))
+	event := entities.Event{ExperimentIds: []string{"15402980349"}, ID: "15368860886", Key: "sample_conversion"}
+
+	conversionUserEvent := CreateConversionUserEventWithClock(TestConfig{}, event, userContext, nil, time.Time{}, clock)
+	assert.Equal(t, clock.Now().UnixNano()/int64(time.Millisecond), conversionUserEvent.Timestamp)
+
+	// the timestamps are checked against the time of the clock rather than the system time
+	timestamp := clock.Now().Add(-24 * time.Hour)
+	conversionUserEvent = CreateConversionUserEventWithClock(TestConfig{}, event, userContext, nil, timestamp, clock)
+	assert.Equal(t, timestamp.UnixNano()/int64(time.Millisecond), conversionUserEvent.Timestamp)
+	assert.Empty(t, out.String())
+
+	CreateConversionUserEventWithClock(TestConfig{}, event, userContext, nil, time.Now(), clock)
+	assert.Contains(t, out.String(), "in the future")
+}
+
+func TestCreateImpressionUserEventAt(t *testing.T) {
+	timestamp := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	experiment := entities.Experiment{ID: "15402980349", Key: "background_experiment", LayerID: "15399420423"}
+	variation := entities.Variation{ID: "15410990633", Key: "variation_a"}
+
+	impressionUserEvent := CreateImpressionUserEventAt(TestConfig{}, experiment, variation, userContext, timestamp)
+	assert.Equal(t, timestamp.UnixNano()/int64(time.Millisecond), impressionUserEvent.Timestamp)
+
+	// the timestamp is the one of the serialized log event
+	logEvent := createLogEvent(createBatchEvent(impressionUserEvent, createVisitorFromUserEvent(impressionUserEvent)))
+	payload, err := json.Marshal(logEvent.Event)
+	assert.NoError(t, err)
+	batch := Batch{}
+	assert.NoError(t, json.Unmarshal(payload, &batch))
+	assert.Equal(t, impressionUserEvent.Timestamp, batch.Visitors[0].Snapshots[0].Events[0].Timestamp)
+
+	before := toTimestamp(time.Now())
+	impressionUserEvent = CreateImpressionUserEventAt(TestConfig{}, experiment, variation, userContext, time.Time{})
+	assert.True(t, impressionUserEvent.Timestamp >= before)
+}
+
+func TestCreateImpressionUserEventWithClock(t *testing.T) {
+	clock := utilstest.NewClock()
+	clock.Set(time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC))
+	experiment := entities.Experiment{ID: "15402980349", Key: "background_experiment", LayerID: "15399420423"}
+	variation := entities.Variation{ID: "15410990633", Key: "variation_a"}
+
+	impressionUserEvent := CreateImpressionUserEventWithClock(TestConfig{}, experiment, variation, userContext,
+		time.Time{}, clock)
+	assert.Equal(t, clock.Now().UnixNano()/int64(time.Millisecond), impressionUserEvent.Timestamp)
+
+	// the timestamp of the serialized log event is the time of the clock
+	logEvent := createLogEvent(createBatchEvent(impressionUserEvent, createVisitorFromUserEvent(impressionUserEvent)))
+	payload, err := json.Marshal(logEvent.Event)
+	assert.NoError(t, err)
+	batch := Batch{}
+	assert.NoError(t, json.Unmarshal(payload, &batch))
+	assert.Equal(t, int64(1583064000000), batch.Visitors[0].Snapshots[0].Events[0].Timestamp)
+}
+
+func TestCreateConversionVisitorDecisions(t *testing.T) {
+	conversionUserEvent := BuildTestConversionEvent()
+	visitor := createVisitorFromUserEvent(conversionUserEvent)
+	assert.Equal(t, []Decision{}, visitor.Snapshots[0].Decisions)
+
+	decisions := []Decision{
+		{CampaignID: "1", ExperimentID: "11", VariationID: "111"},
+		{CampaignID: "2", ExperimentID: "22", VariationID: "222"},
+	}
+	conversionUserEvent.Conversion.Decisions = decisions
+	visitor = createVisitorFromUserEvent(conversionUserEvent)
+	assert.Equal(t, decisions, visitor.Snapshots[0].Decisions)
+	assert.Len(t, visitor.Snapshots[0].Events, 1)
+}
+
+type BotFilteringTestConfig struct {
+	TestConfig
+	botFiltering bool
+}
+
+func (c BotFilteringTestConfig) GetBotFiltering() bool {
+	return c.botFiltering
+}
+
+func (BotFilteringTestConfig) GetAttributeByKey(key string) (entities.Attribute, error) {
+	switch key {
+	case "sample_attribute":
+		return entities.Attribute{ID: "100000", Key: key}, nil
+	case "$opt_datafile_attribute":
+		return entities.Attribute{ID: "100001", Key: key}, nil
+	}
+	return entities.Attribute{}, errors.New("attribute not found")
+}
+
+func TestGetEventAttributesUserAgent(t *testing.T) {
+	attributes := map[string]interface{}{"$opt_user_agent": "Googlebot/2.1", "sample_attribute": "value"}
+
+	eventAttributes := getEventAttributes(BotFilteringTestConfig{botFiltering: true}, attributes)
+	byKey := map[string]VisitorAttribute{}
+	for _, attribute := range eventAttributes {
+		byKey[attribute.Key] = attribute
+	}
+	assert.Len(t, byKey, 3)
+	assert.Equal(t, "100000", byKey["sample_attribute"].EntityID)
+	assert.Equal(t, true, byKey["$opt_bot_filtering"].Value)
+
+	jsonValue, err := json.Marshal(byKey["$opt_user_agent"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"entity_id":"$opt_user_agent","key":"$opt_user_agent","type":"custom","value":"Googlebot/2.1"}`, string(jsonValue))
+
+	// the user agent is not sent when bot filtering is disabled
+	eventAttributes = getEventAttributes(BotFilteringTestConfig{}, attributes)
+	for _, attribute := range eventAttributes {
+		assert.NotEqual(t, "$opt_user_agent", attribute.Key)
+	}
+	assert.Len(t, eventAttributes, 2)
+}
+
+func TestGetEventAttributesReservedPrefix(t *testing.T) {
+	attributes := map[string]interface{}{"$opt_datafile_attribute": "value", "$opt_reserved": "value"}
+
+	eventAttributes := getEventAttributes(BotFilteringTestConfig{}, attributes)
+	byKey := map[string]VisitorAttribute{}
+	for _, attribute := range eventAttributes {
+		byKey[attribute.Key] = attribute
+	}
+	assert.Len(t, byKey, 3)
+	// the datafile attributes are identified by their ID even when their key has the reserved prefix
+	assert.Equal(t, "100001", byKey["$opt_datafile_attribute"].EntityID)
+	assert.Equal(t, "$opt_reserved", byKey["$opt_reserved"].EntityID)
+}
+
+type EUTestConfig struct {
+	TestConfig
+}
+
+func (EUTestConfig) GetRegion() string {
+	return "EU"
+}
+
+func TestCreateEventContextRegion(t *testing.T) {
+	assert.Equal(t, "", CreateEventContext(TestConfig{}).Region)
+	assert.Equal(t, "EU", CreateEventContext(EUTestConfig{}).Region)
+}
+
+func TestCreateLogEventRegionalEndPoint(t *testing.T) {
+	impressionUserEvent := BuildTestImpressionEvent()
+	impressionUserEvent.EventContext = CreateEventContext(EUTestConfig{})
+	logEvent := createLogEvent(createBatchEvent(impressionUserEvent, createVisitorFromUserEvent(impressionUserEvent)))
+	assert.Equal(t, "EU", logEvent.Region)
+	assert.Equal(t, "https://eu.logx.optimizely.com/v1/events", logEvent.EndPoint)
+
+	impressionUserEvent = BuildTestImpressionEvent()
+	logEvent = createLogEvent(createBatchEvent(impressionUserEvent, createVisitorFromUserEvent(impressionUserEvent)))
+	assert.Equal(t, "", logEvent.Region)
+	assert.Equal(t, "https://logx.optimizely.com/v1/events", logEvent.EndPoint)
+}
+
+func TestCreateLogEventClientNameAndVersion(t *testing.T) {
+	assert.Equal(t, SDKVersion, Version)
+
+	impressionUserEvent := BuildTestImpressionEvent()
+	assert.Equal(t, ClientName, impressionUserEvent.EventContext.ClientName)
+	assert.Equal(t, Version, impressionUserEvent.EventContext.ClientVersion)
+
+	// events created without an event context are still attributed to the client
+	conversionUserEvent := BuildTestConversionEvent()
+	conversionUserEvent.EventContext = Context{ProjectID: "15389410617", Revision: "7"}
+
+	for _, userEvent := range []UserEvent{impressionUserEvent, conversionUserEvent} {
+		logEvent := createLogEvent(createBatchEvent(userEvent, createVisitorFromUserEvent(userEvent)))
+		payload, err := json.Marshal(logEvent.Event)
+		assert.NoError(t, err)
+
+		fields := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(payload, &fields))
+		assert.Equal(t, "go-sdk", fields["client_name"])
+		assert.Equal(t, SDKVersion, fields["client_version"])
+	}
+}
+
+func TestGetEventEndPoint(t *testing.T) {
+	assert.Equal(t, "https://logx.optimizely.com/v1/events", getEventEndPoint(""))
+	assert.Equal(t, "https://logx.optimizely.com/v1/events", getEventEndPoint("US"))
+	assert.Equal(t, "https://eu.logx.optimizely.com/v1/events", getEventEndPoint("EU"))
+	assert.Equal(t, "https://logx.optimizely.com/v1/events", getEventEndPoint("unknown"))
+}
@@ -21,7 +21,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/sync/semaphore"
@@ -30,6 +32,7 @@ import (
 	"github.com/optimizely/go-sdk/pkg/metrics"
 	"github.com/optimizely/go-sdk/pkg/notification"
 	"github.com/optimizely/go-sdk/pkg/registry"
+	"github.com/optimizely/go-sdk/pkg/utils"
 )
 
 // Processor processes events
@@ -39,18 +42,117 @@ type Processor interface {
 	RemoveOnEventDispatch(id int) error
 }
 
+// StartableProcessor is a Processor which runs in the background for the lifetime of the client, such as the
+// BatchEventProcessor. Custom implementations are started by the client factory within the client execution context.
+type StartableProcessor interface {
+	Processor
+	Start(ctx context.Context)
+}
+
+// EventProcessor is a StartableProcessor which reports the number of events it holds, such as the BatchEventProcessor.
+// It is implemented by the custom processors replacing the batching entirely, e.g. to forward the events to another
+// pipeline.
+type EventProcessor interface {
+	StartableProcessor
+	EventsCount() int
+}
+
+// FlushableProcessor is a Processor whose queued events can be dispatched on demand
+type FlushableProcessor interface {
+	Processor
+	Flush()
+}
+
+// DrainableProcessor is a FlushableProcessor whose final flush can be bounded by a deadline, so that shutting down
+// does not hang on a slow or failing endpoint
+type DrainableProcessor interface {
+	FlushableProcessor
+	FlushWithTimeout(timeout time.Duration) int
+}
+
+// ContextProcessor is a Processor which accepts the context of the call the event is created in, such as the
+// BatchEventProcessor. It allows deadlines and tracing spans to flow into the event processing.
+type ContextProcessor interface {
+	Processor
+	ProcessEventWithContext(ctx context.Context, event UserEvent) bool
+}
+
+// HealthCheckProcessor is a Processor which reports whether its events are being dispatched
+type HealthCheckProcessor interface {
+	Processor
+	DispatchHealthy() bool
+}
+
+// DispatchFailureProcessor is a Processor which notifies the batches it failed to dispatch, such as the
+// BatchEventProcessor. The DispatchFailure handlers are registered with the processor so that they are notified by the
+// notification center it sends to.
+type DispatchFailureProcessor interface {
+	Processor
+	OnDispatchFailure(callback func(logEvent LogEvent, err error)) (int, error)
+	RemoveOnDispatchFailure(id int) error
+}
+
+// NoopProcessor is a Processor which discards the events, for the clients which only make decisions. It runs no
+// goroutine and makes no request, the events are reported as processed so that their notifications are still sent.
+type NoopProcessor struct{}
+
+// NewNoopProcessor returns a processor discarding the events
+func NewNoopProcessor() *NoopProcessor {
+	return &NoopProcessor{}
+}
+
+// ProcessEvent discards the event
+func (p *NoopProcessor) ProcessEvent(event UserEvent) bool {
+	return true
+}
+
+// OnEventDispatch returns a handler ID without registering the handler, no event is ever dispatched
+func (p *NoopProcessor) OnEventDispatch(callback func(logEvent LogEvent)) (int, error) {
+	return 0, nil
+}
+
+// RemoveOnEventDispatch does nothing as no handler is registered
+func (p *NoopProcessor) RemoveOnEventDispatch(id int) error {
+	return nil
+}
+
 // BatchEventProcessor is used out of the box by the SDK to queue up and batch events to be sent to the Optimizely
 // log endpoint for results processing.
 type BatchEventProcessor struct {
 	sdkKey          string
 	MaxQueueSize    int           // max size of the queue before flush
-	FlushInterval   time.Duration // in milliseconds
+	FlushInterval   time.Duration // in milliseconds, an explicit zero disables time-based flushing
 	BatchSize       int
+	MaxEventAge     time.Duration // events queued for longer are dropped at flush time; zero disables
+	Immediate       bool          // events are dispatched within ProcessEvent instead of queued
 	Q               Queue
 	flushLock       sync.Mutex
 	Ticker          *time.Ticker
 	EventDispatcher Dispatcher
 	processing      *semaphore.Weighted
+	running         bool
+	runningLock     sync.Mutex
+	clock           utils.Clock
+	dispatchWorkers int
+	granularity     VisitorGranularity
+	encoder         Encoder
+	requester       *utils.HTTPRequester
+	intervalSet     bool
+	region          string
+	// notificationCenter is the center the notifications are sent to, defaulting to the one registered for the SDK key
+	notificationCenter notification.Center
+	// dispatchFailing is set while the last dispatch failed, until events are dispatched again
+	dispatchFailing int32
+	// maxInFlightBatches is the max number of batches awaiting dispatch in the default dispatcher, zero or less for no limit
+	maxInFlightBatches int
+	// staleEventHandler is called with the events dropped for being queued for longer than MaxEventAge
+	staleEventHandler func(events []UserEvent)
+	// retryingRejectedBatch is set while the last batch was rejected for too many batches in flight, so that the
+	// handlers are not notified again of the batch retried by the next flush, guarded by flushLock
+	retryingRejectedBatch bool
+	// batchSizeFlushMode and timerFlushMode are how the flushes of the batch size and of the flush interval dispatch
+	batchSizeFlushMode FlushMode
+	timerFlushMode     FlushMode
 
 	metricsRegistry metrics.Registry
 }
@@ -66,6 +168,27 @@ const DefaultEventFlushInterval = 30 * time.Second
 
 const maxFlushWorkers = 1
 
+// VisitorGranularity controls how the events of a batch are grouped into visitor entries
+type VisitorGranularity int
+
+const (
+	// VisitorPerEvent creates a visitor entry for every event of the batch
+	VisitorPerEvent VisitorGranularity = iota
+	// VisitorPerSession merges the events of the same visitor with the same attributes into the snapshots of a single
+	// visitor entry
+	VisitorPerSession
+)
+
+// FlushMode controls how a flush dispatches the queued events
+type FlushMode int
+
+const (
+	// FlushBatched dispatches the queued events in log events of up to the batch size
+	FlushBatched FlushMode = iota
+	// FlushPerEvent dispatches every queued event in a log event of its own, for the lowest latency per event
+	FlushPerEvent
+)
+
 var pLogger = logging.GetLogger("EventProcessor")
 
 // BPOptionConfig is the BatchProcessor options that give you the ability to add one more more options before the processor is initialized.
@@ -85,14 +208,17 @@ func WithQueueSize(qsize int) BPOptionConfig {
 	}
 }
 
-// WithFlushInterval sets the flush interval as a config option to be passed into the NewProcessor method
+// WithFlushInterval sets the flush interval as a config option to be passed into the NewProcessor method. A zero
+// interval disables the flush ticker, events are then only flushed when the batch size is met or the processor stops.
 func WithFlushInterval(flushInterval time.Duration) BPOptionConfig {
 	return func(qp *BatchEventProcessor) {
 		qp.FlushInterval = flushInterval
+		qp.intervalSet = true
 	}
 }
 
-// WithQueue sets the Processor Queue as a config option to be passed into the NewProcessor method
+// WithQueue sets the Processor Queue as a config option to be passed into the NewProcessor method, the default is a
+// ChanQueue of the queue size
 func WithQueue(q Queue) BPOptionConfig {
 	return func(qp *BatchEventProcessor) {
 		qp.Q = q
@@ -114,6 +240,14 @@ func WithSDKKey(sdkKey string) BPOptionConfig {
 	}
 }
 
+// WithNotificationCenter sets the notification center the LogEvent and DispatchFailure notifications are sent to, in
+// place of the one registered for the SDK key
+func WithNotificationCenter(notificationCenter notification.Center) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.notificationCenter = notificationCenter
+	}
+}
+
 // WithEventDispatcherMetrics sets metrics into the NewProcessor method
 func WithEventDispatcherMetrics(metricsRegistry metrics.Registry) BPOptionConfig {
 	return func(qp *BatchEventProcessor) {
@@ -121,9 +255,111 @@ func WithEventDispatcherMetrics(metricsRegistry metrics.Registry) BPOptionConfig
 	}
 }
 
+// WithMaxEventAge sets the max age of a queued event as a config option to be passed into the NewProcessor method.
+// Events which have been queued for longer than maxEventAge are dropped instead of dispatched.
+func WithMaxEventAge(maxEventAge time.Duration) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.MaxEventAge = maxEventAge
+	}
+}
+
+// WithStaleEventHandler sets the handler called with the events dropped for being queued for longer than the max event
+// age, see WithMaxEventAge, as a config option to be passed into the NewProcessor method. It is called within the flush
+// which dropped the events, so it should not block.
+func WithStaleEventHandler(handler func(events []UserEvent)) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.staleEventHandler = handler
+	}
+}
+
+// WithDispatchWorkers sets the max number of log events the default dispatcher sends concurrently as a config option
+// to be passed into the NewProcessor method. It has no effect on a custom dispatcher.
+func WithDispatchWorkers(workers int) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.dispatchWorkers = workers
+	}
+}
+
+// WithMaxInFlightBatches sets the max number of batches awaiting dispatch in the default dispatcher as a config option
+// to be passed into the NewProcessor method, defaulting to DefaultMaxInFlightBatches. Once reached, the events stay in
+// the processor queue and new events are discarded when it is full, which bounds the memory used during an outage of
+// the event endpoint. Zero or less disables the limit. It has no effect on a custom dispatcher.
+func WithMaxInFlightBatches(max int) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.maxInFlightBatches = max
+	}
+}
+
+// WithBatchSizeFlushMode sets how the flush triggered by the queue reaching the batch size dispatches the events as a
+// config option to be passed into the NewProcessor method, the flush still happens exactly when the batch size is met
+func WithBatchSizeFlushMode(mode FlushMode) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.batchSizeFlushMode = mode
+	}
+}
+
+// WithTimerFlushMode sets how the flush of the flush interval dispatches the events as a config option to be passed
+// into the NewProcessor method
+func WithTimerFlushMode(mode FlushMode) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.timerFlushMode = mode
+	}
+}
+
+// WithVisitorGranularity sets how the events of a batch are grouped into visitor entries as a config option to be
+// passed into the NewProcessor method
+func WithVisitorGranularity(granularity VisitorGranularity) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.granularity = granularity
+	}
+}
+
+// WithImmediateDispatch sets whether every event is dispatched synchronously within ProcessEvent, which then returns
+// whether it was delivered, as a config option to be passed into the NewProcessor method. Events are neither queued
+// nor batched and no flush ticker is started.
+func WithImmediateDispatch(immediate bool) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.Immediate = immediate
+	}
+}
+
+// WithEventEncoder sets the encoder serializing the payload of the events sent by the default dispatcher as a config
+// option to be passed into the NewProcessor method. It has no effect when a dispatcher is provided.
+func WithEventEncoder(encoder Encoder) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.encoder = encoder
+	}
+}
+
+// WithRegion sets the region events are dispatched to when the project config they were created against does not
+// specify one, as a config option to be passed into the NewProcessor method
+func WithRegion(region string) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.region = region
+	}
+}
+
+// WithEventHTTPRequester sets the requester sending the events of the default dispatcher as a config option to be
+// passed into the NewProcessor method
+func WithEventHTTPRequester(requester *utils.HTTPRequester) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.requester = requester
+	}
+}
+
+// WithClock sets the Clock used to create the flush ticker and age queued events as a config option to be passed into the NewProcessor method
+func WithClock(clock utils.Clock) BPOptionConfig {
+	return func(qp *BatchEventProcessor) {
+		qp.clock = clock
+	}
+}
+
 // NewBatchEventProcessor returns a new instance of BatchEventProcessor with queueSize and flushInterval
 func NewBatchEventProcessor(options ...BPOptionConfig) *BatchEventProcessor {
-	p := &BatchEventProcessor{processing: semaphore.NewWeighted(int64(maxFlushWorkers))}
+	p := &BatchEventProcessor{
+		processing:         semaphore.NewWeighted(int64(maxFlushWorkers)),
+		maxInFlightBatches: DefaultMaxInFlightBatches,
+	}
 
 	for _, opt := range options {
 		opt(p)
@@ -133,7 +369,7 @@ func NewBatchEventProcessor(options ...BPOptionConfig) *BatchEventProcessor {
 		p.MaxQueueSize = defaultQueueSize
 	}
 
-	if p.FlushInterval == 0 {
+	if p.FlushInterval < 0 || (p.FlushInterval == 0 && !p.intervalSet) {
 		p.FlushInterval = DefaultEventFlushInterval
 	}
 
@@ -151,34 +387,64 @@ func NewBatchEventProcessor(options ...BPOptionConfig) *BatchEventProcessor {
 	}
 
 	if p.Q == nil {
-		p.Q = NewInMemoryQueue(p.MaxQueueSize)
+		p.Q = NewChanQueue(p.MaxQueueSize)
+	}
+
+	if p.clock == nil {
+		p.clock = utils.DefaultClock{}
+	}
+
+	if p.EventDispatcher == nil && p.Immediate {
+		// the queued dispatcher would confirm the events before they are actually sent
+		p.EventDispatcher = NewHTTPEventDispatcher(p.requester, p.encoder)
 	}
 
 	if p.EventDispatcher == nil {
-		dispatcher := NewQueueEventDispatcher(p.metricsRegistry)
+		dispatcher := NewQueueEventDispatcher(p.metricsRegistry, WithDispatcherWorkers(p.dispatchWorkers),
+			WithDispatchFailureHandler(p.onDispatchFailure), WithDispatchSuccessHandler(p.onDispatchSuccess),
+			WithEncoder(p.encoder), WithHTTPRequester(p.requester), WithDispatcherMaxInFlight(p.maxInFlightBatches))
 		p.EventDispatcher = dispatcher
 	}
 
 	return p
 }
 
-// Start does not do any initialization, just starts the ticker
+// Start does not do any initialization, just starts the ticker and blocks until the context is done. Calling Start
+// while the processor is running has no effect, it can be started again with a fresh context once the previous one is done.
 func (p *BatchEventProcessor) Start(ctx context.Context) {
-
-	pLogger.Info("Batch event processor started")
+	if p.Immediate {
+		// there is nothing to flush, events are dispatched as they are processed
+		<-ctx.Done()
+		return
+	}
 	p.startTicker(ctx)
 }
 
 // ProcessEvent takes the given user event (can be an impression or conversion event) and queues it up to be dispatched
 // to the Optimizely log endpoint. A dispatch happens when we flush the events, which can happen on a set interval or
-// when the specified batch size (defaulted to 10) is reached.
+// when the specified batch size (defaulted to 10) is reached. Events which fail validation are discarded and false is
+// returned, so that pre-built events cannot malform a batch.
 func (p *BatchEventProcessor) ProcessEvent(event UserEvent) bool {
 
+	if err := event.Validate(); err != nil {
+		pLogger.Error("Discarding invalid user event", err)
+		return false
+	}
+
+	if event.EventContext.Region == "" {
+		event.EventContext.Region = p.region
+	}
+
+	if p.Immediate {
+		return p.dispatchNow(event)
+	}
+
 	if p.Q.Size() >= p.MaxQueueSize {
 		pLogger.Warning("MaxQueueSize has been met. Discarding event")
 		return false
 	}
 
+	event.enqueuedAt = p.now()
 	p.Q.Add(event)
 
 	if p.Q.Size() < p.BatchSize {
@@ -190,7 +456,7 @@ func (p *BatchEventProcessor) ProcessEvent(event UserEvent) bool {
 		// we just want to start one go routine when the batch size is met.
 		pLogger.Debug("batch size reached.  Flushing routine being called")
 		go func() {
-			p.flushEvents()
+			p.flushEvents(FlushReasonBatchSize)
 			p.processing.Release(1)
 		}()
 	}
@@ -198,8 +464,151 @@ func (p *BatchEventProcessor) ProcessEvent(event UserEvent) bool {
 	return true
 }
 
-// eventsCount returns size of an event queue
-func (p *BatchEventProcessor) eventsCount() int {
+// dispatchNow dispatches the event on its own without queueing it and returns whether it was delivered
+func (p *BatchEventProcessor) dispatchNow(event UserEvent) bool {
+	logEvent := createLogEvent(createBatchEvent(event, createVisitorFromUserEvent(event)))
+	logEvent.FlushReason = FlushReasonImmediate
+	p.sendLogEventNotification(logEvent)
+
+	success, err := p.EventDispatcher.DispatchEvent(logEvent)
+	p.recordDirectDispatch(logEvent, success, err)
+	if !success || err != nil {
+		pLogger.Warning("Failed to dispatch event successfully")
+		return false
+	}
+	pLogger.Debug("Dispatched event successfully")
+	return true
+}
+
+// recordDirectDispatch records the dispatch of a log event by a dispatcher which is not queued, and notifies the
+// DispatchFailure handlers if it failed, as there are no retries to wait for
+func (p *BatchEventProcessor) recordDirectDispatch(logEvent LogEvent, success bool, err error) {
+	p.recordDispatch(success && err == nil)
+	if success && err == nil {
+		return
+	}
+	if err == nil {
+		err = errDispatchFailed
+	}
+	p.sendDispatchFailureNotification(logEvent, err)
+}
+
+// recordDispatch records whether the last dispatch of the processor succeeded, a queued dispatcher reports it through
+// its handlers once the events are actually sent
+func (p *BatchEventProcessor) recordDispatch(dispatched bool) {
+	var failing int32
+	if !dispatched {
+		failing = 1
+	}
+	atomic.StoreInt32(&p.dispatchFailing, failing)
+}
+
+// DispatchHealthy returns false while the last dispatch failed after all retries, until events are dispatched again
+func (p *BatchEventProcessor) DispatchHealthy() bool {
+	return atomic.LoadInt32(&p.dispatchFailing) == 0
+}
+
+// onDispatchSuccess records the log event dispatched by the queued dispatcher
+func (p *BatchEventProcessor) onDispatchSuccess(logEvent LogEvent) {
+	p.recordDispatch(true)
+}
+
+// onDispatchFailure records and notifies the log event which the queued dispatcher failed to dispatch
+func (p *BatchEventProcessor) onDispatchFailure(logEvent LogEvent, err error) {
+	p.recordDispatch(false)
+	p.sendDispatchFailureNotification(logEvent, err)
+}
+
+// getNotificationCenter returns the notification center of the processor
+func (p *BatchEventProcessor) getNotificationCenter() notification.Center {
+	if p.notificationCenter != nil {
+		return p.notificationCenter
+	}
+	return registry.GetNotificationCenter(p.sdkKey)
+}
+
+// sendLogEventNotification notifies the LogEvent handlers of the log event about to be dispatched
+func (p *BatchEventProcessor) sendLogEventNotification(logEvent LogEvent) {
+	notificationCenter := p.getNotificationCenter()
+	if err := notificationCenter.Send(notification.LogEvent, logEvent); err != nil {
+		pLogger.Error("Send Log Event notification failed.", err)
+	}
+}
+
+// sendDispatchFailureNotification notifies the DispatchFailure handlers of the log event which could not be dispatched
+func (p *BatchEventProcessor) sendDispatchFailureNotification(logEvent LogEvent, err error) {
+	notificationCenter := p.getNotificationCenter()
+	failureNotification := notification.DispatchFailureNotification{LogEvent: logEvent, Err: err}
+	if e := notificationCenter.Send(notification.DispatchFailure, failureNotification); e != nil {
+		pLogger.Error("Send Dispatch Failure notification failed.", e)
+	}
+}
+
+// Flush dispatches the queued events without waiting for the flush interval
+func (p *BatchEventProcessor) Flush() {
+	p.flushEvents(FlushReasonFlush)
+}
+
+// FlushWithTimeout dispatches the queued events, including those held by the default queue dispatcher, and gives up
+// once the timeout elapses. It returns the number of events left undispatched, a flush still in progress at the
+// deadline carries on in the background.
+func (p *BatchEventProcessor) FlushWithTimeout(timeout time.Duration) int {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.flushEvents(FlushReasonFlush)
+		if d, ok := p.EventDispatcher.(*QueueEventDispatcher); ok {
+			d.flushEvents()
+		}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		pLogger.Warning(fmt.Sprintf("Event flush did not complete within %v", timeout))
+	}
+	return p.undispatchedCount()
+}
+
+// undispatchedCount returns the number of queued events, plus the ones waiting in the default queue dispatcher
+func (p *BatchEventProcessor) undispatchedCount() int {
+	count := p.EventsCount()
+	if d, ok := p.EventDispatcher.(*QueueEventDispatcher); ok {
+		count += d.queuedEventsCount()
+	}
+	return count
+}
+
+// ProcessEventWithContext queues up the given user event like ProcessEvent. The event is queued even if the context is
+// done, as the decision or the conversion it is for has already been returned to the caller.
+func (p *BatchEventProcessor) ProcessEventWithContext(ctx context.Context, event UserEvent) bool {
+	return p.ProcessEvent(event)
+}
+
+// PendingEvents returns the events queued for dispatch, each as a log event of its own, without removing them from
+// the queue. It does not wait for an in-progress flush, events being flushed may be included. The log events can be
+// inspected or replayed into another dispatcher, e.g. for debugging.
+func (p *BatchEventProcessor) PendingEvents() []LogEvent {
+	var items []interface{}
+	if snapshotQueue, ok := p.Q.(SnapshotQueue); ok {
+		items = snapshotQueue.Snapshot()
+	} else {
+		items = p.Q.Get(p.Q.Size())
+	}
+
+	logEvents := make([]LogEvent, 0, len(items))
+	for _, item := range items {
+		if userEvent, ok := item.(UserEvent); ok {
+			logEvents = append(logEvents, createLogEvent(createBatchEvent(userEvent, createVisitorFromUserEvent(userEvent))))
+		}
+	}
+	return logEvents
+}
+
+// EventsCount returns size of an event queue
+func (p *BatchEventProcessor) EventsCount() int {
 	return p.Q.Size()
 }
 
@@ -213,20 +622,86 @@ func (p *BatchEventProcessor) remove(count int) []interface{} {
 	return p.Q.Remove(count)
 }
 
-// StartTicker starts new ticker for flushing events
+// now returns the current time of the processor clock
+func (p *BatchEventProcessor) now() time.Time {
+	return p.getClock().Now()
+}
+
+// getClock returns the processor clock, the processors built without NewBatchEventProcessor have none and use the
+// default clock, which is never stored as the clock is read concurrently once the processor is started
+func (p *BatchEventProcessor) getClock() utils.Clock {
+	if p.clock == nil {
+		return utils.DefaultClock{}
+	}
+	return p.clock
+}
+
+// evictStaleEvents drops the events at the head of the queue which have been queued for longer than MaxEventAge
+func (p *BatchEventProcessor) evictStaleEvents() {
+	if p.MaxEventAge <= 0 {
+		return
+	}
+
+	now := p.now()
+	var dropped []UserEvent
+	for p.EventsCount() > 0 {
+		events := p.getEvents(1)
+		if len(events) == 0 {
+			break
+		}
+		userEvent, ok := events[0].(UserEvent)
+		if !ok || userEvent.enqueuedAt.IsZero() || now.Sub(userEvent.enqueuedAt) <= p.MaxEventAge {
+			break
+		}
+		p.remove(1)
+		dropped = append(dropped, userEvent)
+	}
+
+	if len(dropped) > 0 {
+		pLogger.Warning(fmt.Sprintf("Dropped %d events queued for longer than the max event age of %s", len(dropped), p.MaxEventAge))
+		if p.staleEventHandler != nil {
+			p.staleEventHandler(dropped)
+		}
+	}
+}
+
+// StartTicker starts new ticker for flushing events, it is a no-op while a previously started ticker is running
 func (p *BatchEventProcessor) startTicker(ctx context.Context) {
-	if p.Ticker != nil {
+	p.runningLock.Lock()
+	if p.running {
+		p.runningLock.Unlock()
+		pLogger.Debug("Batch event processor already started")
 		return
 	}
-	p.Ticker = time.NewTicker(p.FlushInterval)
+	p.running = true
+	// a nil channel never delivers, flushes are then only triggered by the batch size
+	var ticks <-chan time.Time
+	if p.FlushInterval > 0 {
+		ticker := p.getClock().NewTicker(p.FlushInterval)
+		if timeTicker, ok := ticker.(*utils.TimeTicker); ok {
+			p.Ticker = timeTicker.Ticker
+		}
+		ticks = ticker.C()
+		defer ticker.Stop()
+	} else {
+		pLogger.Debug("Flush interval is zero, time-based flushing is disabled")
+	}
+	p.runningLock.Unlock()
+	pLogger.Info("Batch event processor started")
+
+	defer func() {
+		p.runningLock.Lock()
+		p.running = false
+		p.runningLock.Unlock()
+	}()
 
 	for {
 		select {
-		case <-p.Ticker.C:
-			p.flushEvents()
+		case <-ticks:
+			p.flushEvents(FlushReasonTimer)
 		case <-ctx.Done():
 			pLogger.Debug("Event processor stopped, flushing events.")
-			p.flushEvents()
+			p.flushEvents(FlushReasonClose)
 			d, ok := p.EventDispatcher.(*QueueEventDispatcher)
 			if ok {
 				d.flushEvents()
@@ -246,34 +721,68 @@ func (p *BatchEventProcessor) canBatch(current *Batch, user UserEvent) bool {
 	return false
 }
 
-// add the visitor to the current batch
+// add the visitor to the current batch, merging its snapshots into an existing entry of the same session if grouped
 func (p *BatchEventProcessor) addToBatch(current *Batch, visitor Visitor) {
+	if p.granularity == VisitorPerSession {
+		for i := range current.Visitors {
+			if current.Visitors[i].VisitorID == visitor.VisitorID &&
+				reflect.DeepEqual(current.Visitors[i].Attributes, visitor.Attributes) {
+				current.Visitors[i].Snapshots = append(current.Visitors[i].Snapshots, visitor.Snapshots...)
+				return
+			}
+		}
+	}
+
 	visitors := append(current.Visitors, visitor)
 	current.Visitors = visitors
 }
 
-// flushEvents flushes events in queue
-func (p *BatchEventProcessor) flushEvents() {
+// flushMode returns how the flush of the given reason dispatches the events, the explicit and final flushes are batched
+func (p *BatchEventProcessor) flushMode(reason FlushReason) FlushMode {
+	switch reason {
+	case FlushReasonBatchSize:
+		return p.batchSizeFlushMode
+	case FlushReasonTimer:
+		return p.timerFlushMode
+	default:
+		return FlushBatched
+	}
+}
+
+// flushEvents flushes events in queue, the log events are dispatched with the reason of the flush
+func (p *BatchEventProcessor) flushEvents(reason FlushReason) {
 	// we flush when queue size is reached.
 	// however, if there is a ticker cycle already processing, we should wait
 	p.flushLock.Lock()
 	defer p.flushLock.Unlock()
 
+	// maxBatchEventCount is the max number of events dispatched in a log event by this flush
+	maxBatchEventCount := p.BatchSize
+	if p.flushMode(reason) == FlushPerEvent {
+		maxBatchEventCount = 1
+	}
+
 	var batchEvent Batch
 	var batchEventCount = 0
+	// queuedEventCount is the number of queued items the current batch spans, including the invalid ones it skipped,
+	// so that only those are removed from the queue once the batch is dispatched
+	var queuedEventCount = 0
 	var failedToSend = false
 
-	for p.eventsCount() > 0 {
+	for p.EventsCount() > 0 {
 		if failedToSend {
 			pLogger.Error("last Event Batch failed to send; retry on next flush", errors.New("dispatcher failed"))
 			break
 		}
+		p.evictStaleEvents()
 		events := p.getEvents(p.BatchSize)
 
 		if len(events) > 0 {
 			for i := 0; i < len(events); i++ {
 				userEvent, ok := events[i].(UserEvent)
-				if ok {
+				if !ok {
+					queuedEventCount++
+				} else {
 					if batchEventCount == 0 {
 						batchEvent = createBatchEvent(userEvent, createVisitorFromUserEvent(userEvent))
 						batchEventCount = 1
@@ -287,28 +796,41 @@ func (p *BatchEventProcessor) flushEvents() {
 							batchEventCount++
 						}
 					}
+					queuedEventCount++
 
-					if batchEventCount >= p.BatchSize {
+					if batchEventCount >= maxBatchEventCount {
 						// the batch size is reached so take the current batchEvent and send it.
 						break
 					}
 				}
 			}
 		}
+		if batchEventCount == 0 && queuedEventCount > 0 {
+			pLogger.Warning(fmt.Sprintf("Dropping %d queued items which are not user events", queuedEventCount))
+			p.remove(queuedEventCount)
+			queuedEventCount = 0
+		}
 		if batchEventCount > 0 {
 			// TODO: figure out what to do with the error
 			logEvent := createLogEvent(batchEvent)
-			notificationCenter := registry.GetNotificationCenter(p.sdkKey)
-
-			err := notificationCenter.Send(notification.LogEvent, logEvent)
+			logEvent.FlushReason = reason
+			// the handlers are notified of every attempt, except the retries of a batch rejected for too many batches
+			// in flight, which are only waiting for a free slot
+			if !p.retryingRejectedBatch {
+				p.sendLogEventNotification(logEvent)
+			}
 
-			if err != nil {
-				pLogger.Error("Send Log Event notification failed.", err)
+			success, err := p.EventDispatcher.DispatchEvent(logEvent)
+			p.retryingRejectedBatch = err == ErrTooManyInFlightBatches
+			if _, queued := p.EventDispatcher.(*QueueEventDispatcher); !queued {
+				p.recordDirectDispatch(logEvent, success, err)
 			}
-			if success, _ := p.EventDispatcher.DispatchEvent(logEvent); success {
+			if success && err == nil {
 				pLogger.Debug("Dispatched event successfully")
-				p.remove(batchEventCount)
+				// only the events of the dispatched batch are removed, the following ones are kept for the next batch
+				p.remove(queuedEventCount)
 				batchEventCount = 0
+				queuedEventCount = 0
 				batchEvent = Batch{}
 			} else {
 				pLogger.Warning("Failed to dispatch event successfully")
@@ -320,7 +842,7 @@ func (p *BatchEventProcessor) flushEvents() {
 
 // OnEventDispatch registers a handler for LogEvent notifications
 func (p *BatchEventProcessor) OnEventDispatch(callback func(logEvent LogEvent)) (int, error) {
-	notificationCenter := registry.GetNotificationCenter(p.sdkKey)
+	notificationCenter := p.getNotificationCenter()
 
 	handler := func(payload interface{}) {
 		if ev, ok := payload.(LogEvent); ok {
@@ -339,7 +861,7 @@ func (p *BatchEventProcessor) OnEventDispatch(callback func(logEvent LogEvent))
 
 // RemoveOnEventDispatch removes handler for LogEvent notification with given id
 func (p *BatchEventProcessor) RemoveOnEventDispatch(id int) error {
-	notificationCenter := registry.GetNotificationCenter(p.sdkKey)
+	notificationCenter := p.getNotificationCenter()
 
 	if err := notificationCenter.RemoveHandler(id, notification.LogEvent); err != nil {
 		pLogger.Warning("Problem with removing notification handler.")
@@ -347,3 +869,35 @@ func (p *BatchEventProcessor) RemoveOnEventDispatch(id int) error {
 	}
 	return nil
 }
+
+// OnDispatchFailure registers a handler for DispatchFailure notifications
+func (p *BatchEventProcessor) OnDispatchFailure(callback func(logEvent LogEvent, err error)) (int, error) {
+	notificationCenter := p.getNotificationCenter()
+
+	handler := func(payload interface{}) {
+		if failureNotification, ok := payload.(notification.DispatchFailureNotification); ok {
+			if logEvent, ok := failureNotification.LogEvent.(LogEvent); ok {
+				callback(logEvent, failureNotification.Err)
+				return
+			}
+		}
+		pLogger.Warning(fmt.Sprintf("Unable to convert notification payload %v into DispatchFailureNotification", payload))
+	}
+	id, err := notificationCenter.AddHandler(notification.DispatchFailure, handler)
+	if err != nil {
+		pLogger.Error("Problem with adding notification handler.", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// RemoveOnDispatchFailure removes handler for DispatchFailure notification with given id
+func (p *BatchEventProcessor) RemoveOnDispatchFailure(id int) error {
+	notificationCenter := p.getNotificationCenter()
+
+	if err := notificationCenter.RemoveHandler(id, notification.DispatchFailure); err != nil {
+		pLogger.Warning("Problem with removing notification handler.")
+		return err
+	}
+	return nil
+}
@@ -19,12 +19,18 @@ package event
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/optimizely/go-sdk/pkg/logging"
+	"github.com/optimizely/go-sdk/pkg/notification"
+	"github.com/optimizely/go-sdk/pkg/registry"
 	"github.com/optimizely/go-sdk/pkg/utils"
+	"github.com/optimizely/go-sdk/pkg/utils/utilstest"
 	"github.com/stretchr/testify/assert"
 	"math"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -72,13 +78,70 @@ func TestDefaultEventProcessor_ProcessImpression(t *testing.T) {
 
 	processor.ProcessEvent(impression)
 
-	assert.Equal(t, 1, processor.eventsCount())
+	assert.Equal(t, 1, processor.EventsCount())
 
 	eg.TerminateAndWait()
 
 	assert.NotNil(t, processor.Ticker)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
+}
+
+func TestDefaultEventProcessor_EventsCount(t *testing.T) {
+	var processor EventProcessor = NewBatchEventProcessor()
+	assert.Equal(t, 0, processor.EventsCount())
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.ProcessEvent(BuildTestConversionEvent())
+	assert.Equal(t, 2, processor.EventsCount())
+}
+
+func TestNoopProcessor(t *testing.T) {
+	processor := NewNoopProcessor()
+	id, err := processor.OnEventDispatch(func(LogEvent) { t.Error("no event is dispatched") })
+	assert.NoError(t, err)
+
+	assert.True(t, processor.ProcessEvent(BuildTestImpressionEvent()))
+	assert.True(t, processor.ProcessEvent(BuildTestConversionEvent()))
+	assert.NoError(t, processor.RemoveOnEventDispatch(id))
+}
+
+func TestDefaultEventProcessor_RejectsInvalidEvent(t *testing.T) {
+	processor := NewBatchEventProcessor()
+
+	invalid := BuildTestImpressionEvent()
+	invalid.UUID = ""
+
+	assert.False(t, processor.ProcessEvent(invalid))
+	assert.Equal(t, 0, processor.EventsCount())
+	assert.True(t, processor.ProcessEvent(BuildTestImpressionEvent()))
+	assert.Equal(t, 1, processor.EventsCount())
+}
+
+func TestDefaultEventProcessor_PendingEvents(t *testing.T) {
+	processor := NewBatchEventProcessor()
+	assert.Empty(t, processor.PendingEvents())
+
+	impression := BuildTestImpressionEvent()
+	conversion := BuildTestConversionEvent()
+	processor.ProcessEvent(impression)
+	processor.ProcessEvent(conversion)
+
+	pending := processor.PendingEvents()
+	assert.Len(t, pending, 2)
+	assert.Equal(t, 2, processor.EventsCount())
+	assert.Equal(t, impression.VisitorID, pending[0].Event.Visitors[0].VisitorID)
+	assert.Equal(t, impression.Impression.VariationID, pending[0].Event.Visitors[0].Snapshots[0].Decisions[0].VariationID)
+	assert.Equal(t, conversion.Conversion.Key, pending[1].Event.Visitors[0].Snapshots[0].Events[0].Key)
+
+	dispatcher := NewMockDispatcher(100, false)
+	for _, logEvent := range pending {
+		success, err := dispatcher.DispatchEvent(logEvent)
+		assert.True(t, success)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, dispatcher.Events.Size())
+	assert.Equal(t, 2, processor.EventsCount())
 }
 
 func TestCustomEventProcessor_Create(t *testing.T) {
@@ -93,13 +156,13 @@ func TestCustomEventProcessor_Create(t *testing.T) {
 
 	processor.ProcessEvent(impression)
 
-	assert.Equal(t, 1, processor.eventsCount())
+	assert.Equal(t, 1, processor.EventsCount())
 
 	eg.TerminateAndWait()
 
 	assert.NotNil(t, processor.Ticker)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 }
 
 func TestDefaultEventProcessor_LogEventNotification(t *testing.T) {
@@ -125,7 +188,7 @@ func TestDefaultEventProcessor_LogEventNotification(t *testing.T) {
 	processor.ProcessEvent(conversion)
 	processor.ProcessEvent(conversion)
 
-	assert.Equal(t, 4, processor.eventsCount())
+	assert.Equal(t, 4, processor.EventsCount())
 
 	eg.TerminateAndWait()
 
@@ -137,6 +200,100 @@ func TestDefaultEventProcessor_LogEventNotification(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestDefaultEventProcessor_NotificationCenter(t *testing.T) {
+	notificationCenter := notification.NewNotificationCenter()
+	processor := NewBatchEventProcessor(
+		WithEventDispatcher(NewMockDispatcher(100, false)),
+		WithSDKKey("fakeSDKKeyNotificationCenter"),
+		WithNotificationCenter(notificationCenter))
+
+	var logEvents []LogEvent
+	_, err := notificationCenter.AddHandler(notification.LogEvent, func(payload interface{}) {
+		logEvents = append(logEvents, payload.(LogEvent))
+	})
+	assert.NoError(t, err)
+	var registryCalls int
+	_, err = registry.GetNotificationCenter("fakeSDKKeyNotificationCenter").AddHandler(notification.LogEvent, func(payload interface{}) {
+		registryCalls++
+	})
+	assert.NoError(t, err)
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.Flush()
+
+	assert.Len(t, logEvents, 1)
+	assert.Zero(t, registryCalls)
+}
+
+func TestDefaultEventProcessor_DispatchFailureNotification(t *testing.T) {
+	processor := NewBatchEventProcessor(WithSDKKey("test_dispatch_failure"))
+	dispatcher, ok := processor.EventDispatcher.(*QueueEventDispatcher)
+	if !assert.True(t, ok) || !assert.NotNil(t, dispatcher.onFailure) {
+		return
+	}
+
+	var failureNotification notification.DispatchFailureNotification
+	notificationCenter := registry.GetNotificationCenter("test_dispatch_failure")
+	_, err := notificationCenter.AddHandler(notification.DispatchFailure, func(payload interface{}) {
+		failureNotification, _ = payload.(notification.DispatchFailureNotification)
+	})
+	assert.NoError(t, err)
+
+	logEvent := createLogEvent(createBatchEvent(BuildTestConversionEvent(), createVisitorFromUserEvent(BuildTestConversionEvent())))
+	dispatchErr := errors.New("dispatch event failed")
+	dispatcher.onFailure(logEvent, dispatchErr)
+
+	assert.Equal(t, logEvent, failureNotification.LogEvent)
+	assert.Equal(t, dispatchErr, failureNotification.Err)
+}
+
+func TestBatchEventProcessor_OnDispatchFailure(t *testing.T) {
+	notificationCenter := notification.NewNotificationCenter()
+	processor := NewBatchEventProcessor(WithNotificationCenter(notificationCenter))
+
+	var failedEvents []LogEvent
+	var failedErrs []error
+	id, err := processor.OnDispatchFailure(func(logEvent LogEvent, err error) {
+		failedEvents = append(failedEvents, logEvent)
+		failedErrs = append(failedErrs, err)
+	})
+	assert.NoError(t, err)
+
+	logEvent := createLogEvent(createBatchEvent(BuildTestConversionEvent(), createVisitorFromUserEvent(BuildTestConversionEvent())))
+	dispatchErr := errors.New("dispatch event failed")
+	processor.onDispatchFailure(logEvent, dispatchErr)
+	assert.Equal(t, []LogEvent{logEvent}, failedEvents)
+	assert.Equal(t, []error{dispatchErr}, failedErrs)
+
+	assert.NoError(t, processor.RemoveOnDispatchFailure(id))
+	processor.onDispatchFailure(logEvent, dispatchErr)
+	assert.Len(t, failedEvents, 1)
+}
+
+func TestBatchEventProcessor_DirectDispatchFailureIsNotified(t *testing.T) {
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(NewMockDispatcher(100, true)),
+		WithNotificationCenter(notification.NewNotificationCenter()))
+	var failedErrs []error
+	_, err := processor.OnDispatchFailure(func(logEvent LogEvent, err error) { failedErrs = append(failedErrs, err) })
+	assert.NoError(t, err)
+
+	// a dispatcher which is not queued has no retries to wait for, each failed attempt is notified
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.Flush()
+	assert.Equal(t, 1, processor.EventsCount())
+	assert.Equal(t, []error{errors.New("Failed to dispatch")}, failedErrs)
+
+	immediateProcessor := NewBatchEventProcessor(WithImmediateDispatch(true), WithEventDispatcher(&ChannelDispatcher{}),
+		WithNotificationCenter(notification.NewNotificationCenter()))
+	failedErrs = nil
+	_, err = immediateProcessor.OnDispatchFailure(func(logEvent LogEvent, err error) { failedErrs = append(failedErrs, err) })
+	assert.NoError(t, err)
+	assert.False(t, immediateProcessor.ProcessEvent(BuildTestImpressionEvent()))
+	assert.Equal(t, []error{errDispatchFailed}, failedErrs)
+}
+
 func TestDefaultEventProcessor_BatchSizes(t *testing.T) {
 	eg := newExecutionContext()
 	processor := NewBatchEventProcessor(
@@ -156,7 +313,7 @@ func TestDefaultEventProcessor_BatchSizes(t *testing.T) {
 	// sleep for 1 second here. to allow event processor to run.
 	time.Sleep(1 * time.Second)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 
 	result, ok := (processor.EventDispatcher).(*MockDispatcher)
 
@@ -179,6 +336,7 @@ func TestDefaultEventProcessor_DefaultConfig(t *testing.T) {
 		WithEventDispatcher(dispatcher),
 		// here we are setting the timing interval so that we don't have to wait the default 30 seconds
 		WithFlushInterval(500*time.Millisecond))
+	assert.IsType(t, &ChanQueue{}, processor.Q)
 	eg.Go(processor.Start)
 
 	impression := BuildTestImpressionEvent()
@@ -189,12 +347,12 @@ func TestDefaultEventProcessor_DefaultConfig(t *testing.T) {
 	processor.ProcessEvent(conversion)
 	processor.ProcessEvent(conversion)
 
-	assert.Equal(t, 4, processor.eventsCount())
+	assert.Equal(t, 4, processor.EventsCount())
 
 	// sleep for 1 second here. to allow event processor to run.
 	time.Sleep(1 * time.Second)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 	assert.Equal(t, 1, dispatcher.Events.Size())
 	evs := dispatcher.Events.Get(1)
 	logEvent, _ := evs[0].(LogEvent)
@@ -219,11 +377,11 @@ func TestDefaultEventProcessor_ProcessBatch(t *testing.T) {
 	processor.ProcessEvent(conversion)
 	processor.ProcessEvent(conversion)
 
-	assert.Equal(t, 4, processor.eventsCount())
+	assert.Equal(t, 4, processor.EventsCount())
 
 	time.Sleep(1500 * time.Millisecond)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 	assert.Equal(t, 1, dispatcher.Events.Size())
 	evs := dispatcher.Events.Get(1)
 	logEvent, _ := evs[0].(LogEvent)
@@ -246,7 +404,7 @@ func TestDefaultEventProcessor_BatchSizeMet(t *testing.T) {
 	processor.ProcessEvent(impression)
 	processor.ProcessEvent(impression)
 
-	assert.Equal(t, 2, processor.eventsCount())
+	assert.Equal(t, 2, processor.EventsCount())
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -258,13 +416,13 @@ func TestDefaultEventProcessor_BatchSizeMet(t *testing.T) {
 	processor.ProcessEvent(conversion)
 	processor.ProcessEvent(conversion)
 
-	assert.Equal(t, 2, processor.eventsCount())
+	assert.Equal(t, 2, processor.EventsCount())
 
 	eg.TerminateAndWait()
 
 	assert.NotNil(t, processor.Ticker)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 	assert.Equal(t, 2, dispatcher.Events.Size())
 }
 
@@ -295,12 +453,12 @@ func TestDefaultEventProcessor_QSizeExceeded(t *testing.T) {
 	processor.ProcessEvent(impression)
 	processor.ProcessEvent(impression)
 
-	assert.Equal(t, 2, processor.eventsCount())
+	assert.Equal(t, 2, processor.EventsCount())
 
 	processor.ProcessEvent(impression)
 	processor.ProcessEvent(impression)
 
-	assert.Equal(t, 2, processor.eventsCount())
+	assert.Equal(t, 2, processor.EventsCount())
 
 }
 
@@ -322,16 +480,38 @@ func TestDefaultEventProcessor_FailedDispatch(t *testing.T) {
 	processor.ProcessEvent(conversion)
 	processor.ProcessEvent(conversion)
 
-	assert.Equal(t, 4, processor.eventsCount())
+	assert.Equal(t, 4, processor.EventsCount())
 
 	eg.TerminateAndWait()
 
 	assert.NotNil(t, processor.Ticker)
 
-	assert.Equal(t, 4, processor.eventsCount())
+	assert.Equal(t, 4, processor.EventsCount())
 	assert.Equal(t, 0, dispatcher.Events.Size())
 }
 
+func TestDefaultEventProcessor_DispatchHealthy(t *testing.T) {
+	dispatcher := NewMockDispatcher(100, true)
+	processor := NewBatchEventProcessor(WithEventDispatcher(dispatcher))
+	assert.True(t, processor.DispatchHealthy())
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.Flush()
+	assert.False(t, processor.DispatchHealthy())
+
+	dispatcher.ShouldFail = false
+	processor.Flush()
+	assert.True(t, processor.DispatchHealthy())
+	assert.Equal(t, 1, dispatcher.Events.Size())
+
+	// the default dispatcher reports the result once the events are sent
+	processor = NewBatchEventProcessor()
+	processor.onDispatchFailure(LogEvent{}, errors.New("failed"))
+	assert.False(t, processor.DispatchHealthy())
+	processor.onDispatchSuccess(LogEvent{})
+	assert.True(t, processor.DispatchHealthy())
+}
+
 func TestBatchEventProcessor_FlushesOnClose(t *testing.T) {
 	eg := newExecutionContext()
 	processor := NewBatchEventProcessor(
@@ -348,12 +528,646 @@ func TestBatchEventProcessor_FlushesOnClose(t *testing.T) {
 	processor.ProcessEvent(conversion)
 	processor.ProcessEvent(conversion)
 
-	assert.Equal(t, 4, processor.eventsCount())
+	assert.Equal(t, 4, processor.EventsCount())
 
 	// Triggers the flush in the processor
 	eg.TerminateAndWait()
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
+}
+
+func TestBatchEventProcessor_FlushesOnTick(t *testing.T) {
+	eg := newExecutionContext()
+	clock := utilstest.NewClock()
+	dispatcher := NewMockDispatcher(100, false)
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(dispatcher),
+		WithClock(clock))
+	eg.Go(processor.Start)
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	assert.Equal(t, 1, processor.EventsCount())
+
+	// the second tick is only received once the flush for the first one has completed
+	clock.Tick()
+	clock.Tick()
+
+	assert.Equal(t, 0, processor.EventsCount())
+	assert.Equal(t, 1, dispatcher.Events.Size())
+
+	eg.TerminateAndWait()
+}
+
+// flushReasons records the flush reasons of the log events dispatched by the processor
+type flushReasons struct {
+	lock    sync.Mutex
+	reasons []FlushReason
+}
+
+func (f *flushReasons) add(logEvent LogEvent) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.reasons = append(f.reasons, logEvent.FlushReason)
+}
+
+func (f *flushReasons) get() []FlushReason {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]FlushReason{}, f.reasons...)
+}
+
+func TestBatchEventProcessor_FlushReason(t *testing.T) {
+	newProcessor := func(options ...BPOptionConfig) (*BatchEventProcessor, *flushReasons) {
+		options = append(options, WithEventDispatcher(NewMockDispatcher(100, false)),
+			WithNotificationCenter(notification.NewNotificationCenter()))
+		processor := NewBatchEventProcessor(options...)
+		reasons := &flushReasons{}
+		_, err := processor.OnEventDispatch(reasons.add)
+		assert.NoError(t, err)
+		return processor, reasons
+	}
+
+	// timer
+	eg := newExecutionContext()
+	clock := utilstest.NewClock()
+	processor, reasons := newProcessor(WithClock(clock))
+	eg.Go(processor.Start)
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	clock.Tick()
+	clock.Tick()
+	eg.TerminateAndWait()
+	assert.Equal(t, []FlushReason{FlushReasonTimer}, reasons.get())
+
+	// close
+	eg = newExecutionContext()
+	processor, reasons = newProcessor(WithClock(utilstest.NewClock()))
+	eg.Go(processor.Start)
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	eg.TerminateAndWait()
+	assert.Equal(t, []FlushReason{FlushReasonClose}, reasons.get())
+
+	// explicit flush
+	processor, reasons = newProcessor()
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.Flush()
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.FlushWithTimeout(time.Second)
+	assert.Equal(t, []FlushReason{FlushReasonFlush, FlushReasonFlush}, reasons.get())
+
+	// queue size
+	processor, reasons = newProcessor(WithBatchSize(1))
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	for i := 0; i < 100 && len(reasons.get()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []FlushReason{FlushReasonBatchSize}, reasons.get())
+
+	// immediate dispatch
+	processor, reasons = newProcessor(WithImmediateDispatch(true))
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	assert.Equal(t, []FlushReason{FlushReasonImmediate}, reasons.get())
+}
+
+// flushedBatches records the flush reason and the visitor count of the log events dispatched by the processor
+type flushedBatches struct {
+	lock    sync.Mutex
+	batches []string
+}
+
+func (f *flushedBatches) add(logEvent LogEvent) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.batches = append(f.batches, fmt.Sprintf("%s:%d", logEvent.FlushReason, len(logEvent.Event.Visitors)))
+}
+
+func (f *flushedBatches) get() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]string{}, f.batches...)
+}
+
+// waitFor polls the condition until it holds or a second has passed, it is used over assert.Eventually which in this
+// version of testify may send on a closed channel when the condition is slower than the tick
+func waitFor(condition func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+// tickFlush ticks the processor clock and waits for the flush of the tick to complete
+func tickFlush(processor *BatchEventProcessor, clock *utilstest.Clock) {
+	clock.Tick()
+	for processor.EventsCount() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// the flush holds the lock until it is done
+	processor.flushLock.Lock()
+	processor.flushLock.Unlock()
+}
+
+func TestBatchEventProcessor_FlushModes(t *testing.T) {
+	newProcessor := func(clock utils.Clock, options ...BPOptionConfig) (*BatchEventProcessor, *flushedBatches) {
+		options = append(options, WithBatchSize(3), WithClock(clock), WithEventDispatcher(NewMockDispatcher(100, false)),
+			WithNotificationCenter(notification.NewNotificationCenter()))
+		processor := NewBatchEventProcessor(options...)
+		batches := &flushedBatches{}
+		_, err := processor.OnEventDispatch(batches.add)
+		assert.NoError(t, err)
+		return processor, batches
+	}
+
+	// per event when the batch size is met, batched on the timer
+	eg := newExecutionContext()
+	clock := utilstest.NewClock()
+	processor, batches := newProcessor(clock, WithBatchSizeFlushMode(FlushPerEvent))
+	eg.Go(processor.Start)
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	tickFlush(processor, clock)
+	assert.Equal(t, []string{"timer:2"}, batches.get())
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	assert.Equal(t, []string{"timer:2"}, batches.get())
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	assert.True(t, waitFor(func() bool { return len(batches.get()) == 4 }))
+	assert.Equal(t, []string{"timer:2", "batch_size:1", "batch_size:1", "batch_size:1"}, batches.get())
+	eg.TerminateAndWait()
+
+	// per event on the timer, the explicit flushes stay batched
+	eg = newExecutionContext()
+	clock = utilstest.NewClock()
+	processor, batches = newProcessor(clock, WithTimerFlushMode(FlushPerEvent))
+	eg.Go(processor.Start)
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	tickFlush(processor, clock)
+	assert.Equal(t, []string{"timer:1", "timer:1"}, batches.get())
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.Flush()
+	assert.Equal(t, []string{"timer:1", "timer:1", "flush:2"}, batches.get())
+	eg.TerminateAndWait()
+
+	processor = NewBatchEventProcessor()
+	assert.Equal(t, FlushBatched, processor.batchSizeFlushMode)
+	assert.Equal(t, FlushBatched, processor.timerFlushMode)
+}
+
+func TestBatchEventProcessor_ZeroFlushIntervalDisablesTicker(t *testing.T) {
+	eg := newExecutionContext()
+	clock := utilstest.NewClock()
+	dispatcher := NewMockDispatcher(100, false)
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithBatchSize(2),
+		WithFlushInterval(0),
+		WithEventDispatcher(dispatcher),
+		WithClock(clock))
+	assert.Equal(t, time.Duration(0), processor.FlushInterval)
+	eg.Go(processor.Start)
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, 0, clock.Tickers())
+	assert.Nil(t, processor.Ticker)
+	assert.Equal(t, 1, processor.EventsCount())
+	assert.Equal(t, 0, dispatcher.Events.Size())
+
+	// the queue size still triggers a flush
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	assert.True(t, waitFor(func() bool { return processor.EventsCount() == 0 }))
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	eg.TerminateAndWait()
+	assert.Equal(t, 0, processor.EventsCount())
+}
+
+func TestBatchEventProcessor_NegativeFlushIntervalUsesDefault(t *testing.T) {
+	processor := NewBatchEventProcessor(WithFlushInterval(-time.Second))
+	assert.Equal(t, DefaultEventFlushInterval, processor.FlushInterval)
+
+	processor = NewBatchEventProcessor()
+	assert.Equal(t, DefaultEventFlushInterval, processor.FlushInterval)
+}
+
+func TestBatchEventProcessor_StartIsIdempotent(t *testing.T) {
+	eg := newExecutionContext()
+	clock := utilstest.NewClock()
+	processor := NewBatchEventProcessor(
+		WithEventDispatcher(NewMockDispatcher(100, false)),
+		WithClock(clock))
+	eg.Go(processor.Start)
+
+	// the tick is only received once the flush loop is running
+	clock.Tick()
+
+	done := make(chan struct{})
+	go func() {
+		processor.Start(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "Start did not return while the processor was running")
+	}
+	assert.Equal(t, 1, clock.Tickers())
+
+	eg.TerminateAndWait()
+}
+
+func TestBatchEventProcessor_Restart(t *testing.T) {
+	clock := utilstest.NewClock()
+	dispatcher := NewMockDispatcher(100, false)
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(dispatcher),
+		WithClock(clock))
+
+	eg := newExecutionContext()
+	eg.Go(processor.Start)
+	clock.Tick()
+	eg.TerminateAndWait()
+
+	eg = newExecutionContext()
+	eg.Go(processor.Start)
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	clock.Tick()
+	clock.Tick()
+
+	assert.Equal(t, 0, processor.EventsCount())
+	assert.Equal(t, 1, dispatcher.Events.Size())
+	assert.Equal(t, 2, clock.Tickers())
+
+	eg.TerminateAndWait()
+}
+
+type ChannelDispatcher struct {
+	events chan LogEvent
+	err    error
+}
+
+func (c *ChannelDispatcher) DispatchEvent(event LogEvent) (bool, error) {
+	if c.err != nil {
+		return true, c.err
+	}
+	select {
+	case c.events <- event:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func TestBatchEventProcessor_ChannelDispatcher(t *testing.T) {
+	dispatcher := &ChannelDispatcher{events: make(chan LogEvent, 1)}
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(dispatcher))
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.Flush()
+	assert.Equal(t, 0, processor.EventsCount())
+
+	logEvent := <-dispatcher.events
+	payload, err := json.Marshal(logEvent.Event)
+	assert.NoError(t, err)
+	batch := Batch{}
+	assert.NoError(t, json.Unmarshal(payload, &batch))
+	assert.Len(t, batch.Visitors, 1)
+
+	// a full channel is a failed dispatch, the event stays queued
+	dispatcher.events <- logEvent
+	processor.ProcessEvent(BuildTestConversionEvent())
+	processor.Flush()
+	assert.Equal(t, 1, processor.EventsCount())
+
+	<-dispatcher.events
+	processor.Flush()
+	assert.Equal(t, 0, processor.EventsCount())
+	assert.Len(t, dispatcher.events, 1)
+}
+
+func TestBatchEventProcessor_DispatchErrorKeepsEvents(t *testing.T) {
+	dispatcher := &ChannelDispatcher{events: make(chan LogEvent, 1), err: errors.New("transport error")}
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(dispatcher))
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.Flush()
+	assert.Equal(t, 1, processor.EventsCount())
+}
+
+func TestBatchEventProcessor_DispatchErrorIsNotified(t *testing.T) {
+	dispatcher := &ChannelDispatcher{events: make(chan LogEvent, 1), err: errors.New("transport error")}
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(dispatcher),
+		WithNotificationCenter(notification.NewNotificationCenter()))
+	var logEvents []LogEvent
+	_, err := processor.OnEventDispatch(func(logEvent LogEvent) { logEvents = append(logEvents, logEvent) })
+	assert.NoError(t, err)
+
+	// the handlers are notified right before each attempt, including the ones which fail
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.Flush()
+	processor.Flush()
+	assert.Equal(t, 1, processor.EventsCount())
+	assert.Len(t, logEvents, 2)
+
+	immediateProcessor := NewBatchEventProcessor(WithImmediateDispatch(true), WithEventDispatcher(dispatcher),
+		WithNotificationCenter(notification.NewNotificationCenter()))
+	logEvents = nil
+	_, err = immediateProcessor.OnEventDispatch(func(logEvent LogEvent) { logEvents = append(logEvents, logEvent) })
+	assert.NoError(t, err)
+	assert.False(t, immediateProcessor.ProcessEvent(BuildTestImpressionEvent()))
+	assert.Len(t, logEvents, 1)
+}
+
+type FailingCallDispatcher struct {
+	failingCall int
+	calls       int
+	Events      []LogEvent
+}
+
+func (f *FailingCallDispatcher) DispatchEvent(event LogEvent) (bool, error) {
+	f.calls++
+	if f.calls == f.failingCall {
+		return false, errors.New("failed to dispatch")
+	}
+	f.Events = append(f.Events, event)
+	return true, nil
+}
+
+func TestBatchEventProcessor_PartialDispatchFailure(t *testing.T) {
+	dispatcher := &FailingCallDispatcher{failingCall: 2}
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithBatchSize(2),
+		WithEventDispatcher(dispatcher))
+
+	impression := BuildTestImpressionEvent()
+	for _, visitorID := range []string{"1", "2", "3", "4", "5"} {
+		impression.VisitorID = visitorID
+		processor.Q.Add(impression)
+	}
+
+	// the first batch is dispatched, the second one fails and is kept along with the remaining event
+	processor.Flush()
+	assert.Equal(t, 3, processor.EventsCount())
+	if assert.Len(t, dispatcher.Events, 1) {
+		assert.Equal(t, "1", dispatcher.Events[0].Event.Visitors[0].VisitorID)
+		assert.Equal(t, "2", dispatcher.Events[0].Event.Visitors[1].VisitorID)
+	}
+
+	// the retry only sends the events which failed
+	processor.Flush()
+	assert.Equal(t, 0, processor.EventsCount())
+	var visitorIDs []string
+	for _, logEvent := range dispatcher.Events {
+		for _, visitor := range logEvent.Event.Visitors {
+			visitorIDs = append(visitorIDs, visitor.VisitorID)
+		}
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, visitorIDs)
+}
+
+func TestBatchEventProcessor_FlushSkipsInvalidQueueItems(t *testing.T) {
+	dispatcher := &FailingCallDispatcher{}
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithBatchSize(2),
+		WithEventDispatcher(dispatcher))
+
+	processor.Q.Add("not an event")
+	processor.Q.Add(BuildTestImpressionEvent())
+	processor.Q.Add(BuildTestConversionEvent())
+	processor.Q.Add("not an event either")
+
+	processor.Flush()
+	assert.Equal(t, 0, processor.EventsCount())
+	visitorCount := 0
+	for _, logEvent := range dispatcher.Events {
+		visitorCount += len(logEvent.Event.Visitors)
+	}
+	assert.Equal(t, 2, visitorCount)
+}
+
+func TestBatchEventProcessor_Flush(t *testing.T) {
+	dispatcher := NewMockDispatcher(100, false)
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(dispatcher))
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.ProcessEvent(BuildTestConversionEvent())
+	assert.Equal(t, 2, processor.EventsCount())
+
+	processor.Flush()
+	assert.Equal(t, 0, processor.EventsCount())
+	assert.Equal(t, 1, dispatcher.Events.Size())
+}
+
+func TestBatchEventProcessor_FlushWithTimeout(t *testing.T) {
+	dispatcher := NewMockDispatcher(100, false)
+	processor := NewBatchEventProcessor(WithEventDispatcher(dispatcher))
+
+	assert.Equal(t, 0, processor.FlushWithTimeout(time.Second))
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.ProcessEvent(BuildTestConversionEvent())
+	assert.Equal(t, 0, processor.FlushWithTimeout(time.Second))
+	assert.Equal(t, 1, dispatcher.Events.Size())
+
+	// a slow endpoint leaves the events undispatched once the deadline is reached
+	blocking := &BlockingDispatcher{started: make(chan LogEvent, 10), release: make(chan struct{})}
+	processor = NewBatchEventProcessor(WithEventDispatcher(blocking))
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.ProcessEvent(BuildTestConversionEvent())
+	assert.Equal(t, 2, processor.FlushWithTimeout(10*time.Millisecond))
+
+	close(blocking.release)
+	assert.Equal(t, 0, processor.FlushWithTimeout(time.Second))
+}
+
+func TestBatchEventProcessor_DropsEventsOlderThanMaxEventAge(t *testing.T) {
+	clock := utilstest.NewClock()
+	clock.Set(time.Now())
+	dispatcher := NewMockDispatcher(100, false)
+	var staleEvents []UserEvent
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(dispatcher),
+		WithMaxEventAge(time.Hour),
+		WithStaleEventHandler(func(events []UserEvent) { staleEvents = append(staleEvents, events...) }),
+		WithClock(clock))
+
+	impression := BuildTestImpressionEvent()
+	conversion := BuildTestConversionEvent()
+	processor.ProcessEvent(impression)
+	processor.ProcessEvent(conversion)
+
+	clock.Advance(30 * time.Minute)
+	processor.ProcessEvent(BuildTestConversionEvent())
+	assert.Equal(t, 3, processor.EventsCount())
+
+	clock.Advance(45 * time.Minute)
+	processor.Flush()
+	assert.Equal(t, 0, processor.EventsCount())
+
+	// the stale events are reported in the order they were queued
+	if assert.Len(t, staleEvents, 2) {
+		assert.Equal(t, impression.UUID, staleEvents[0].UUID)
+		assert.Equal(t, conversion.UUID, staleEvents[1].UUID)
+	}
+
+	if assert.Equal(t, 1, dispatcher.Events.Size()) {
+		logEvent, ok := dispatcher.Events.Get(1)[0].(LogEvent)
+		assert.True(t, ok)
+		assert.Len(t, logEvent.Event.Visitors, 1)
+	}
+}
+
+func TestBatchEventProcessor_WithoutMaxEventAgeKeepsEvents(t *testing.T) {
+	clock := utilstest.NewClock()
+	clock.Set(time.Now())
+	dispatcher := NewMockDispatcher(100, false)
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(dispatcher),
+		WithClock(clock))
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	clock.Advance(24 * time.Hour)
+	processor.Flush()
+
+	assert.Equal(t, 0, processor.EventsCount())
+	assert.Equal(t, 1, dispatcher.Events.Size())
+}
+
+func TestBatchEventProcessor_ProcessEventWithContext(t *testing.T) {
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(NewMockDispatcher(100, false)))
+
+	assert.True(t, processor.ProcessEventWithContext(context.Background(), BuildTestImpressionEvent()))
+	assert.Equal(t, 1, processor.EventsCount())
+
+	// the event of a call whose context is done is still counted
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.True(t, processor.ProcessEventWithContext(ctx, BuildTestConversionEvent()))
+	assert.Equal(t, 2, processor.EventsCount())
+}
+
+func TestBatchEventProcessor_VisitorPerSession(t *testing.T) {
+	dispatcher := NewMockDispatcher(100, false)
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(dispatcher),
+		WithVisitorGranularity(VisitorPerSession))
+
+	otherVisitor := BuildTestConversionEvent()
+	otherVisitor.VisitorID = "other_visitor"
+
+	processor.ProcessEvent(BuildTestConversionEvent())
+	processor.ProcessEvent(otherVisitor)
+	processor.ProcessEvent(BuildTestConversionEvent())
+	processor.Flush()
+
+	if assert.Equal(t, 1, dispatcher.Events.Size()) {
+		logEvent, ok := dispatcher.Events.Get(1)[0].(LogEvent)
+		assert.True(t, ok)
+		if assert.Len(t, logEvent.Event.Visitors, 2) {
+			assert.Equal(t, userContext.ID, logEvent.Event.Visitors[0].VisitorID)
+			assert.Len(t, logEvent.Event.Visitors[0].Snapshots, 2)
+			assert.Equal(t, "sample_conversion", logEvent.Event.Visitors[0].Snapshots[1].Events[0].Key)
+			assert.Equal(t, "other_visitor", logEvent.Event.Visitors[1].VisitorID)
+			assert.Len(t, logEvent.Event.Visitors[1].Snapshots, 1)
+		}
+	}
+}
+
+func TestBatchEventProcessor_VisitorPerEvent(t *testing.T) {
+	dispatcher := NewMockDispatcher(100, false)
+	processor := NewBatchEventProcessor(
+		WithQueueSize(100),
+		WithEventDispatcher(dispatcher))
+
+	processor.ProcessEvent(BuildTestConversionEvent())
+	processor.ProcessEvent(BuildTestConversionEvent())
+	processor.Flush()
+
+	if assert.Equal(t, 1, dispatcher.Events.Size()) {
+		logEvent, ok := dispatcher.Events.Get(1)[0].(LogEvent)
+		assert.True(t, ok)
+		assert.Len(t, logEvent.Event.Visitors, 2)
+	}
+}
+
+func TestBatchEventProcessor_ImmediateDispatch(t *testing.T) {
+	clock := utilstest.NewClock()
+	dispatcher := NewMockDispatcher(100, false)
+	processor := NewBatchEventProcessor(
+		WithEventDispatcher(dispatcher),
+		WithImmediateDispatch(true),
+		WithClock(clock),
+		WithSDKKey("immediate_dispatch"))
+
+	var logEvents []LogEvent
+	id, err := processor.OnEventDispatch(func(logEvent LogEvent) {
+		logEvents = append(logEvents, logEvent)
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, processor.ProcessEvent(BuildTestImpressionEvent()))
+	assert.Equal(t, 0, processor.EventsCount())
+	assert.Equal(t, 1, dispatcher.Events.Size())
+	assert.Len(t, logEvents, 1)
+
+	dispatcher.ShouldFail = true
+	assert.False(t, processor.ProcessEvent(BuildTestConversionEvent()))
+	assert.Equal(t, 0, processor.EventsCount())
+	assert.Equal(t, 1, dispatcher.Events.Size())
+	assert.NoError(t, processor.RemoveOnEventDispatch(id))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	processor.Start(ctx)
+	assert.Equal(t, 0, clock.Tickers())
+}
+
+func TestBatchEventProcessor_WithEventEncoder(t *testing.T) {
+	processor := NewBatchEventProcessor(WithImmediateDispatch(true), WithEventEncoder(prefixEncoder{}))
+	if dispatcher, ok := processor.EventDispatcher.(*HTTPEventDispatcher); assert.True(t, ok) {
+		assert.Equal(t, prefixEncoder{}, dispatcher.encoder)
+	}
+
+	processor = NewBatchEventProcessor(WithEventEncoder(prefixEncoder{}))
+	if queueDispatcher, ok := processor.EventDispatcher.(*QueueEventDispatcher); assert.True(t, ok) {
+		if dispatcher, ok := queueDispatcher.Dispatcher.(*HTTPEventDispatcher); assert.True(t, ok) {
+			assert.Equal(t, prefixEncoder{}, dispatcher.encoder)
+		}
+	}
+}
+
+func TestBatchEventProcessor_ImmediateDispatchDefaultDispatcher(t *testing.T) {
+	processor := NewBatchEventProcessor(WithImmediateDispatch(true))
+	assert.IsType(t, &HTTPEventDispatcher{}, processor.EventDispatcher)
+
+	processor = NewBatchEventProcessor()
+	assert.IsType(t, &QueueEventDispatcher{}, processor.EventDispatcher)
 }
 
 func TestDefaultEventProcessor_ProcessBatchRevisionMismatch(t *testing.T) {
@@ -375,13 +1189,13 @@ func TestDefaultEventProcessor_ProcessBatchRevisionMismatch(t *testing.T) {
 	processor.ProcessEvent(conversion)
 	processor.ProcessEvent(conversion)
 
-	assert.Equal(t, 4, processor.eventsCount())
+	assert.Equal(t, 4, processor.EventsCount())
 
 	eg.TerminateAndWait()
 
 	assert.NotNil(t, processor.Ticker)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 	assert.Equal(t, 3, dispatcher.Events.Size())
 	evs := dispatcher.Events.Get(3)
 	logEvent, _ := evs[len(evs)-1].(LogEvent)
@@ -407,24 +1221,63 @@ func TestDefaultEventProcessor_ProcessBatchProjectMismatch(t *testing.T) {
 	processor.ProcessEvent(conversion)
 	processor.ProcessEvent(conversion)
 
-	assert.Equal(t, 4, processor.eventsCount())
+	assert.Equal(t, 4, processor.EventsCount())
 
 	eg.TerminateAndWait()
 
 	assert.NotNil(t, processor.Ticker)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 	assert.Equal(t, 3, dispatcher.Events.Size())
 	evs := dispatcher.Events.Get(3)
 	logEvent, _ := evs[len(evs)-1].(LogEvent)
 	assert.Equal(t, 2, len(logEvent.Event.Visitors))
 }
 
+func TestDefaultEventProcessor_MaxInFlightBatches(t *testing.T) {
+	processor := NewBatchEventProcessor(WithMaxInFlightBatches(1), WithNotificationCenter(notification.NewNotificationCenter()))
+	dispatcher, ok := processor.EventDispatcher.(*QueueEventDispatcher)
+	if !assert.True(t, ok) {
+		return
+	}
+	sender := &BlockingDispatcher{started: make(chan LogEvent, 10), release: make(chan struct{})}
+	dispatcher.Dispatcher = sender
+	var notified int32
+	_, err := processor.OnEventDispatch(func(LogEvent) { atomic.AddInt32(&notified, 1) })
+	assert.NoError(t, err)
+
+	processor.ProcessEvent(BuildTestImpressionEvent())
+	processor.Flush()
+	assert.Equal(t, 0, processor.EventsCount())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notified))
+
+	// the first batch is still awaiting dispatch, the event stays in the processor queue
+	processor.ProcessEvent(BuildTestConversionEvent())
+	processor.Flush()
+	processor.Flush()
+	assert.Equal(t, 1, processor.EventsCount())
+	// the rejected batch is notified once, not again when it is retried
+	assert.Equal(t, int32(2), atomic.LoadInt32(&notified))
+
+	close(sender.release)
+	assert.True(t, waitFor(func() bool { return sender.eventsCount() == 1 }))
+	assert.True(t, waitFor(func() bool { return dispatcher.queuedEventsCount() == 0 }))
+	processor.Flush()
+	assert.Equal(t, 0, processor.EventsCount())
+	assert.True(t, waitFor(func() bool { return sender.eventsCount() == 2 }))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&notified))
+
+	processor = NewBatchEventProcessor()
+	assert.Equal(t, DefaultMaxInFlightBatches, processor.EventDispatcher.(*QueueEventDispatcher).maxInFlight)
+	processor = NewBatchEventProcessor(WithMaxInFlightBatches(0))
+	assert.Equal(t, 0, processor.EventDispatcher.(*QueueEventDispatcher).maxInFlight)
+}
+
 func TestChanQueueEventProcessor_ProcessImpression(t *testing.T) {
 	eg := newExecutionContext()
 	processor := NewBatchEventProcessor(
 		WithQueueSize(100),
-		WithQueue(NewInMemoryQueue(100)),
+		WithQueue(NewChanQueue(100)),
 		WithEventDispatcher(&HTTPEventDispatcher{requester: utils.NewHTTPRequester()}))
 
 	eg.Go(processor.Start)
@@ -438,7 +1291,7 @@ func TestChanQueueEventProcessor_ProcessImpression(t *testing.T) {
 	eg.TerminateAndWait()
 	assert.NotNil(t, processor.Ticker)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 }
 
 func TestChanQueueEventProcessor_ProcessBatch(t *testing.T) {
@@ -446,7 +1299,7 @@ func TestChanQueueEventProcessor_ProcessBatch(t *testing.T) {
 	dispatcher := NewMockDispatcher(100, false)
 	processor := NewBatchEventProcessor(
 		WithQueueSize(100),
-		WithQueue(NewInMemoryQueue(100)),
+		WithQueue(NewChanQueue(100)),
 		WithEventDispatcher(dispatcher))
 	eg.Go(processor.Start)
 
@@ -462,7 +1315,7 @@ func TestChanQueueEventProcessor_ProcessBatch(t *testing.T) {
 
 	assert.NotNil(t, processor.Ticker)
 
-	assert.Equal(t, 0, processor.eventsCount())
+	assert.Equal(t, 0, processor.EventsCount())
 	assert.Equal(t, 1, dispatcher.Events.Size())
 	evs := dispatcher.Events.Get(1)
 	logEvent, _ := evs[0].(LogEvent)
@@ -524,6 +1377,7 @@ func BenchmarkProcessor(b *testing.B) {
 		fun  func(qSize int) Queue
 	}{
 		{"InMemory", NewInMemoryQueue},
+		{"Chan", NewChanQueue},
 	}
 
 	for _, merge := range merges {
@@ -541,6 +1395,39 @@ func BenchmarkProcessor(b *testing.B) {
 
 }
 
+// BenchmarkProcessorParallel processes events from concurrent goroutines, as a high traffic service does
+func BenchmarkProcessorParallel(b *testing.B) {
+	logging.SetLogger(&NoOpLogger{})
+
+	merges := []struct {
+		name string
+		fun  func(qSize int) Queue
+	}{
+		{"InMemory", NewInMemoryQueue},
+		{"Chan", NewChanQueue},
+	}
+
+	for _, merge := range merges {
+		b.Run(merge.name, func(b *testing.B) {
+			eg := newExecutionContext()
+			processor := NewBatchEventProcessor(
+				WithQueue(merge.fun(DefaultEventQueueSize)),
+				WithQueueSize(DefaultEventQueueSize),
+				WithEventDispatcher(&CountingDispatcher{}))
+			eg.Go(processor.Start)
+
+			conversion := BuildTestConversionEvent()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					processor.ProcessEvent(conversion)
+				}
+			})
+
+			eg.TerminateAndWait()
+		})
+	}
+}
+
 func benchmarkProcessor(q Queue, bSize int, b *testing.B) {
 	eg := newExecutionContext()
 	dispatcher := &CountingDispatcher{}
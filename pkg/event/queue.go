@@ -19,6 +19,7 @@ package event
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
 // Queue represents a queue
@@ -29,6 +30,13 @@ type Queue interface {
 	Size() int
 }
 
+// SnapshotQueue is a Queue which can return a copy of all its items, without removing them and without being
+// affected by later changes of the queue
+type SnapshotQueue interface {
+	Queue
+	Snapshot() []interface{}
+}
+
 // InMemoryQueue represents a in-memory queue
 type InMemoryQueue struct {
 	Queue []interface{}
@@ -37,11 +45,11 @@ type InMemoryQueue struct {
 
 // Get returns queue for given count size
 func (i *InMemoryQueue) Get(count int) []interface{} {
-	if i.Size() < count {
-		count = i.Size()
-	}
 	i.Mux.Lock()
 	defer i.Mux.Unlock()
+	if len(i.Queue) < count {
+		count = len(i.Queue)
+	}
 	return i.Queue[:count]
 }
 
@@ -54,16 +62,25 @@ func (i *InMemoryQueue) Add(item interface{}) {
 
 // Remove removes item from queue and returns elements slice
 func (i *InMemoryQueue) Remove(count int) []interface{} {
-	if i.Size() < count {
-		count = i.Size()
-	}
 	i.Mux.Lock()
 	defer i.Mux.Unlock()
+	if len(i.Queue) < count {
+		count = len(i.Queue)
+	}
 	elem := i.Queue[:count]
 	i.Queue = i.Queue[count:]
 	return elem
 }
 
+// Snapshot returns a copy of the items of the queue
+func (i *InMemoryQueue) Snapshot() []interface{} {
+	i.Mux.Lock()
+	defer i.Mux.Unlock()
+	snapshot := make([]interface{}, len(i.Queue))
+	copy(snapshot, i.Queue)
+	return snapshot
+}
+
 // Size returns size of queue
 func (i *InMemoryQueue) Size() int {
 	i.Mux.Lock()
@@ -76,3 +93,89 @@ func NewInMemoryQueue(queueSize int) Queue {
 	i := &InMemoryQueue{Queue: make([]interface{}, 0, queueSize)}
 	return i
 }
+
+// ChanQueue is an in-memory queue for many concurrent producers and a single consumer, such as the flush of the event
+// processor. Items are added through a buffered channel so that Add and Size do not contend on a lock, the consumer
+// moves them to the items it reads from when it calls Get, Remove or Snapshot. Items are only added under the lock
+// when the channel is full, Add never blocks.
+type ChanQueue struct {
+	ch    chan interface{}
+	items []interface{}
+	mux   sync.Mutex
+	size  int64
+}
+
+// Get returns queue for given count size
+func (c *ChanQueue) Get(count int) []interface{} {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.drain()
+	if len(c.items) < count {
+		count = len(c.items)
+	}
+	return c.items[:count]
+}
+
+// Add appends item to queue
+func (c *ChanQueue) Add(item interface{}) {
+	select {
+	case c.ch <- item:
+	default:
+		c.mux.Lock()
+		// the items already in the channel are drained first to keep them in order
+		c.drain()
+		c.items = append(c.items, item)
+		c.mux.Unlock()
+	}
+	atomic.AddInt64(&c.size, 1)
+}
+
+// Remove removes item from queue and returns elements slice
+func (c *ChanQueue) Remove(count int) []interface{} {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.drain()
+	if len(c.items) < count {
+		count = len(c.items)
+	}
+	elem := c.items[:count]
+	c.items = c.items[count:]
+	atomic.AddInt64(&c.size, -int64(count))
+	return elem
+}
+
+// Snapshot returns a copy of the items of the queue
+func (c *ChanQueue) Snapshot() []interface{} {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.drain()
+	snapshot := make([]interface{}, len(c.items))
+	copy(snapshot, c.items)
+	return snapshot
+}
+
+// Size returns size of queue
+func (c *ChanQueue) Size() int {
+	// an item removed before its Add returned is briefly uncounted
+	if size := atomic.LoadInt64(&c.size); size > 0 {
+		return int(size)
+	}
+	return 0
+}
+
+// drain moves the items of the channel to the items, it must be called with the lock held
+func (c *ChanQueue) drain() {
+	for {
+		select {
+		case item := <-c.ch:
+			c.items = append(c.items, item)
+		default:
+			return
+		}
+	}
+}
+
+// NewChanQueue returns new ChanQueue with given queueSize, which is the size of its channel buffer
+func NewChanQueue(queueSize int) Queue {
+	return &ChanQueue{ch: make(chan interface{}, queueSize), items: make([]interface{}, 0, queueSize)}
+}
@@ -18,8 +18,11 @@
 package event
 
 import (
-	"github.com/stretchr/testify/assert"
+	"fmt"
+	"sync"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestInMemoryQueue_Add_Size_Remove(t *testing.T) {
@@ -86,3 +89,63 @@ func TestInMemoryQueue_Concurrent(t *testing.T) {
 
 	assert.Equal(t, 8, q.Size())
 }
+
+func TestChanQueue_Add_Size_Remove(t *testing.T) {
+	q := NewChanQueue(2)
+
+	// the items added once the channel is full are kept in order
+	for i := 1; i <= 5; i++ {
+		q.Add(i)
+	}
+	assert.Equal(t, 5, q.Size())
+	assert.Equal(t, []interface{}{1, 2, 3}, q.Get(3))
+	assert.Equal(t, []interface{}{1, 2}, q.Remove(2))
+
+	q.Add(6)
+	assert.Equal(t, 4, q.Size())
+	assert.Equal(t, []interface{}{3, 4, 5, 6}, q.Remove(10))
+	assert.Equal(t, 0, q.Size())
+}
+
+func BenchmarkQueueAddParallel(b *testing.B) {
+	queues := []struct {
+		name string
+		fun  func(qSize int) Queue
+	}{
+		{"InMemory", NewInMemoryQueue},
+		{"Chan", NewChanQueue},
+	}
+
+	for _, queue := range queues {
+		b.Run(queue.name, func(b *testing.B) {
+			q := queue.fun(DefaultEventQueueSize)
+			done := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				// a single consumer removes the items like the processor flush
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+						q.Remove(DefaultBatchSize)
+					}
+				}
+			}()
+
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					if q.Size() < DefaultEventQueueSize {
+						q.Add(fmt.Sprint(i))
+					}
+					i++
+				}
+			})
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -17,8 +17,13 @@
 // Package event //
 package event
 
-// Version is the current version of the client
-var Version = "1.0.0"
+import "github.com/optimizely/go-sdk/pkg/utils"
+
+// SDKVersion is the version of the Go SDK
+const SDKVersion = utils.SDKVersion
+
+// Version is the current version of the client, sent as the client_version of the dispatched events
+var Version = SDKVersion
 
 // ClientName is the name of the client
 var ClientName = "go-sdk"
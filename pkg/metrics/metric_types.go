@@ -24,3 +24,10 @@ const (
 	DispatcherRetryFlush   = "dispatcher.retryFlush"
 	DispatcherQueueSize    = "dispatcher.queueSize"
 )
+
+// AudienceConditionEvaluations counts the audience conditions evaluated, AudienceShortCircuits counts the "and" and
+// "or" nodes of audience condition trees whose remaining conditions were skipped
+const (
+	AudienceConditionEvaluations = "audience.conditionEvaluations"
+	AudienceShortCircuits        = "audience.shortCircuits"
+)
@@ -26,6 +26,39 @@ type Center interface {
 	Send(Type, interface{}) error
 }
 
+// FilteredCenter is a Center which can register handlers for some of the notifications of a type only
+type FilteredCenter interface {
+	Center
+	AddFilteredHandler(Type, func(interface{}), func(interface{}) bool) (int, error)
+}
+
+// ListeningCenter is a Center which can tell whether any handler is registered for a type of notification
+type ListeningCenter interface {
+	Center
+	HasHandlers(Type) bool
+}
+
+// HasHandlers returns whether the center has handlers for the given notification type, centers which cannot tell are
+// assumed to have some
+func HasHandlers(center Center, notificationType Type) bool {
+	if listeningCenter, ok := center.(ListeningCenter); ok {
+		return listeningCenter.HasHandlers(notificationType)
+	}
+	return true
+}
+
+// DecisionTypeFilter returns a filter accepting the decision notifications of the given decision types only
+func DecisionTypeFilter(decisionTypes ...DecisionNotificationType) func(interface{}) bool {
+	accepted := make(map[DecisionNotificationType]bool, len(decisionTypes))
+	for _, decisionType := range decisionTypes {
+		accepted[decisionType] = true
+	}
+	return func(payload interface{}) bool {
+		decisionNotification, ok := payload.(DecisionNotification)
+		return ok && accepted[decisionNotification.Type]
+	}
+}
+
 // DefaultCenter contains all the notification managers
 type DefaultCenter struct {
 	managerMap map[Type]Manager
@@ -37,11 +70,15 @@ func NewNotificationCenter() *DefaultCenter {
 	projectConfigUpdateNotificationManager := NewAtomicManager()
 	processLogEventNotificationManager := NewAtomicManager()
 	trackNotificationManager := NewAtomicManager()
+	dispatchFailureNotificationManager := NewAtomicManager()
+	unknownKeyNotificationManager := NewAtomicManager()
 	managerMap := make(map[Type]Manager)
 	managerMap[Decision] = decisionNotificationManager
 	managerMap[ProjectConfigUpdate] = projectConfigUpdateNotificationManager
 	managerMap[LogEvent] = processLogEventNotificationManager
 	managerMap[Track] = trackNotificationManager
+	managerMap[DispatchFailure] = dispatchFailureNotificationManager
+	managerMap[UnknownKey] = unknownKeyNotificationManager
 	return &DefaultCenter{
 		managerMap: managerMap,
 	}
@@ -56,6 +93,35 @@ func (c *DefaultCenter) AddHandler(notificationType Type, handler func(interface
 	return -1, fmt.Errorf("no notification manager found for type %s", notificationType)
 }
 
+// AddFilteredHandler adds a handler for the given notification type, which is only called with the notifications the
+// filter returns true for
+func (c *DefaultCenter) AddFilteredHandler(notificationType Type, handler func(interface{}), filter func(interface{}) bool) (int, error) {
+	manager, ok := c.managerMap[notificationType]
+	if !ok {
+		return -1, fmt.Errorf("no notification manager found for type %s", notificationType)
+	}
+	if filteredManager, ok := manager.(FilteredManager); ok {
+		return filteredManager.AddFiltered(handler, filter)
+	}
+	return manager.Add(func(payload interface{}) {
+		if filter(payload) {
+			handler(payload)
+		}
+	})
+}
+
+// HasHandlers returns whether any handler is registered for the given notification type
+func (c *DefaultCenter) HasHandlers(notificationType Type) bool {
+	manager, ok := c.managerMap[notificationType]
+	if !ok {
+		return false
+	}
+	if countingManager, ok := manager.(CountingManager); ok {
+		return countingManager.HasHandlers()
+	}
+	return true
+}
+
 // RemoveHandler removes a handler for the given id and notification type
 func (c *DefaultCenter) RemoveHandler(id int, notificationType Type) error {
 	if manager, ok := c.managerMap[notificationType]; ok {
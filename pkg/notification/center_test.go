@@ -55,3 +55,48 @@ func TestNotificationCenter(t *testing.T) {
 	mockReceiver.AssertNumberOfCalls(t, "handleNotification", 1)
 	mockReceiver2.AssertNumberOfCalls(t, "handleNotification", 2)
 }
+
+func TestNotificationCenterFilteredHandler(t *testing.T) {
+	featureVariableNotification := DecisionNotification{Type: FeatureVariable}
+	abTestNotification := DecisionNotification{Type: ABTest}
+
+	mockReceiver := new(MockReceiver)
+	mockReceiver.On("handleNotification", featureVariableNotification)
+
+	notificationCenter := NewNotificationCenter()
+	id, err := notificationCenter.AddFilteredHandler(Decision, mockReceiver.handleNotification, DecisionTypeFilter(FeatureVariable))
+	assert.NoError(t, err)
+
+	notificationCenter.Send(Decision, abTestNotification)
+	notificationCenter.Send(Decision, featureVariableNotification)
+	mockReceiver.AssertNumberOfCalls(t, "handleNotification", 1)
+	mockReceiver.AssertCalled(t, "handleNotification", featureVariableNotification)
+
+	notificationCenter.RemoveHandler(id, Decision)
+	notificationCenter.Send(Decision, featureVariableNotification)
+	mockReceiver.AssertNumberOfCalls(t, "handleNotification", 1)
+
+	_, err = notificationCenter.AddFilteredHandler(Type("unknown"), mockReceiver.handleNotification, DecisionTypeFilter(FeatureVariable))
+	assert.Error(t, err)
+}
+
+type countlessCenter struct {
+	Center
+}
+
+func TestNotificationCenterHasHandlers(t *testing.T) {
+	notificationCenter := NewNotificationCenter()
+	assert.False(t, HasHandlers(notificationCenter, Decision))
+
+	id, err := notificationCenter.AddHandler(Decision, func(interface{}) {})
+	assert.NoError(t, err)
+	assert.True(t, HasHandlers(notificationCenter, Decision))
+	assert.False(t, HasHandlers(notificationCenter, Track))
+	assert.False(t, HasHandlers(notificationCenter, Type("unknown")))
+
+	assert.NoError(t, notificationCenter.RemoveHandler(id, Decision))
+	assert.False(t, HasHandlers(notificationCenter, Decision))
+
+	// centers which cannot tell are assumed to have handlers
+	assert.True(t, HasHandlers(countlessCenter{notificationCenter}, Decision))
+}
@@ -45,13 +45,57 @@ const (
 	FeatureVariable DecisionNotificationType = "feature-variable"
 	// LogEvent notification type
 	LogEvent Type = "log_event_notification"
+	// DispatchFailure notification type
+	DispatchFailure Type = "dispatch_failure"
+	// UnknownKey notification type
+	UnknownKey Type = "unknown_key"
 )
 
 // DecisionNotification is a notification triggered when a decision is made for either a feature or an experiment
 type DecisionNotification struct {
-	Type         DecisionNotificationType
-	UserContext  entities.UserContext
+	Type        DecisionNotificationType
+	UserContext entities.UserContext
+	// DecisionInfo holds the untyped decision info, prefer ExperimentInfo and FeatureInfo
 	DecisionInfo map[string]interface{}
+	// ExperimentInfo is set for ab-test and feature-test decisions
+	ExperimentInfo *ExperimentDecisionInfo
+	// FeatureInfo is set for feature and feature-variable decisions
+	FeatureInfo *FeatureDecisionInfo
+	// HeldOut is true when the user is held out of all experiments
+	HeldOut bool
+	// NotRunning is true when the experiment is not running so the user was not evaluated for it
+	NotRunning bool
+	// Bucketed is false when the user is not bucketed into any variation, the decision is then the control or default
+	Bucketed bool
+	// Reason is the reason of the decision, such as why the user is not bucketed
+	Reason string
+}
+
+// ExperimentDecisionInfo holds the info of a decision made for an experiment
+type ExperimentDecisionInfo struct {
+	ExperimentKey string
+	VariationKey  string
+}
+
+// FeatureDecisionInfo holds the info of a decision made for a feature and, if requested, one of its variables
+type FeatureDecisionInfo struct {
+	FeatureKey     string
+	FeatureEnabled bool
+	Source         string
+	// SourceInfo is set when the decision comes from a feature test
+	SourceInfo *ExperimentDecisionInfo
+	// RolloutInfo is set when the decision comes from a rollout rule
+	RolloutInfo   *RolloutDecisionInfo
+	VariableKey   string
+	VariableType  entities.VariableType
+	VariableValue interface{}
+}
+
+// RolloutDecisionInfo holds the info of the rollout rule a feature decision was made for
+type RolloutDecisionInfo struct {
+	RuleID    string
+	RuleKey   string
+	RuleIndex int
 }
 
 // TrackNotification is a notification triggered when track is called
@@ -66,6 +110,24 @@ type TrackNotification struct {
 type ProjectConfigUpdateNotification struct {
 	Type     Type
 	Revision string
+	// Diff holds the changes from the previous config, it is only set when diffing is enabled on the config manager
+	Diff *ConfigDiff
+}
+
+// ConfigDiff holds the keys of the experiments and features which changed between two project configs
+type ConfigDiff struct {
+	AddedExperiments    []string `json:"addedExperiments"`
+	RemovedExperiments  []string `json:"removedExperiments"`
+	ModifiedExperiments []string `json:"modifiedExperiments"`
+	AddedFeatures       []string `json:"addedFeatures"`
+	RemovedFeatures     []string `json:"removedFeatures"`
+	ModifiedFeatures    []string `json:"modifiedFeatures"`
+}
+
+// HasChanges returns whether any experiment or feature changed
+func (d ConfigDiff) HasChanges() bool {
+	return len(d.AddedExperiments)+len(d.RemovedExperiments)+len(d.ModifiedExperiments)+
+		len(d.AddedFeatures)+len(d.RemovedFeatures)+len(d.ModifiedFeatures) > 0
 }
 
 // LogEventNotification is the notification triggered before log event is dispatched.
@@ -73,3 +135,17 @@ type LogEventNotification struct {
 	Type     Type
 	LogEvent interface{}
 }
+
+// DispatchFailureNotification is the notification triggered when a log event could not be dispatched after all retries
+type DispatchFailureNotification struct {
+	LogEvent interface{}
+	Err      error
+}
+
+// UnknownKeyNotification is the notification triggered when an experiment, a feature or a feature variable is requested
+// by a key which is not in the project config
+type UnknownKeyNotification struct {
+	Kind        string
+	Key         string
+	UserContext entities.UserContext
+}
@@ -34,9 +34,23 @@ type Manager interface {
 	Send(message interface{})
 }
 
+// FilteredManager is a Manager which can skip handlers for the notifications they are not interested in
+type FilteredManager interface {
+	Manager
+	AddFiltered(handler func(interface{}), filter func(interface{}) bool) (int, error)
+}
+
+// CountingManager is a Manager which can tell whether it has handlers, so that notifications nobody listens to are
+// not built
+type CountingManager interface {
+	Manager
+	HasHandlers() bool
+}
+
 // AtomicManager adds handlers atomically
 type AtomicManager struct {
 	handlers map[uint32]func(interface{})
+	filters  map[uint32]func(interface{}) bool
 	counter  uint32
 	lock     sync.RWMutex
 }
@@ -45,16 +59,26 @@ type AtomicManager struct {
 func NewAtomicManager() *AtomicManager {
 	return &AtomicManager{
 		handlers: make(map[uint32]func(interface{})),
+		filters:  make(map[uint32]func(interface{}) bool),
 	}
 }
 
 // Add adds the given handler
 func (am *AtomicManager) Add(newHandler func(interface{})) (int, error) {
+	return am.AddFiltered(newHandler, nil)
+}
+
+// AddFiltered adds the given handler, which is only called with the notifications the filter returns true for. A nil
+// filter accepts all notifications.
+func (am *AtomicManager) AddFiltered(newHandler func(interface{}), filter func(interface{}) bool) (int, error) {
 	am.lock.Lock()
 	defer am.lock.Unlock()
 
 	atomic.AddUint32(&am.counter, 1)
 	am.handlers[am.counter] = newHandler
+	if filter != nil {
+		am.filters[am.counter] = filter
+	}
 	return int(am.counter), nil
 }
 
@@ -66,26 +90,37 @@ func (am *AtomicManager) Remove(id int) {
 	handlerID := uint32(id)
 	if _, ok := am.handlers[handlerID]; ok {
 		delete(am.handlers, handlerID)
+		delete(am.filters, handlerID)
 		return
 	}
 	managerLogger.Debug(fmt.Sprintf("Handler for id:%d not found", id))
 
 }
 
+// HasHandlers returns whether any handler is registered
+func (am *AtomicManager) HasHandlers() bool {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+	return len(am.handlers) > 0
+}
+
 // Send sends the notification to the registered handlers
 func (am *AtomicManager) Send(notification interface{}) {
 	// copying handler to avoid race condition
-	handlers := am.copyHandlers()
+	handlers := am.copyHandlers(notification)
 	for _, handler := range handlers {
 		handler(notification)
 	}
 }
 
-// Return a copy of the given handlers
-func (am *AtomicManager) copyHandlers() (handlers []func(interface{})) {
+// Return a copy of the handlers whose filter accepts the given notification
+func (am *AtomicManager) copyHandlers(notification interface{}) (handlers []func(interface{})) {
 	am.lock.RLock()
 	defer am.lock.RUnlock()
-	for _, v := range am.handlers {
+	for k, v := range am.handlers {
+		if filter, ok := am.filters[k]; ok && !filter(notification) {
+			continue
+		}
 		handlers = append(handlers, v)
 	}
 	return handlers
@@ -51,6 +51,26 @@ func TestAtomicManager(t *testing.T) {
 	atomicManager.Remove(55)
 }
 
+func TestAtomicManagerAddFiltered(t *testing.T) {
+	accepted := map[string]interface{}{"key": "accepted"}
+	rejected := map[string]interface{}{"key": "rejected"}
+
+	mockReceiver := new(managerMockReceiver)
+	mockReceiver.On("handle", accepted)
+
+	atomicManager := NewAtomicManager()
+	id, _ := atomicManager.AddFiltered(mockReceiver.handle, func(notification interface{}) bool {
+		return notification.(map[string]interface{})["key"] == "accepted"
+	})
+
+	atomicManager.Send(rejected)
+	atomicManager.Send(accepted)
+	mockReceiver.AssertNumberOfCalls(t, "handle", 1)
+
+	atomicManager.Remove(id)
+	assert.Empty(t, atomicManager.filters)
+}
+
 func TestSendRaceCondition(t *testing.T) {
 	sync := make(chan interface{})
 	payload := map[string]interface{}{
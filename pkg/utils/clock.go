@@ -0,0 +1,60 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package utils //
+package utils
+
+import "time"
+
+// Clock is a source of the current time and of tickers
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks on a channel at intervals
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// DefaultClock is the Clock backed by the time package
+type DefaultClock struct{}
+
+// Now returns the current local time
+func (DefaultClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker returns a new TimeTicker firing every d
+func (DefaultClock) NewTicker(d time.Duration) Ticker {
+	return &TimeTicker{Ticker: time.NewTicker(d)}
+}
+
+// TimeTicker is the Ticker backed by a time.Ticker
+type TimeTicker struct {
+	Ticker *time.Ticker
+}
+
+// C returns the channel the ticks are delivered on
+func (t *TimeTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// Stop turns off the ticker
+func (t *TimeTicker) Stop() {
+	t.Ticker.Stop()
+}
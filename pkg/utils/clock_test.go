@@ -0,0 +1,45 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultClockNow(t *testing.T) {
+	clock := DefaultClock{}
+	before := time.Now()
+	now := clock.Now()
+
+	assert.False(t, now.Before(before))
+	assert.False(t, now.After(time.Now()))
+}
+
+func TestDefaultClockNewTicker(t *testing.T) {
+	clock := DefaultClock{}
+	ticker := clock.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	select {
+	case <-ticker.C():
+	case <-time.After(time.Second):
+		assert.Fail(t, "ticker did not fire")
+	}
+}
@@ -33,6 +33,14 @@ import (
 
 const defaultTTL = 5 * time.Second
 
+// SDKVersion is the version of the Go SDK
+const SDKVersion = "1.0.0"
+
+// UserAgent identifies the SDK in the User-Agent header of the requests
+const UserAgent = "go-sdk/" + SDKVersion
+
+const userAgentHeader = "User-Agent"
+
 var requesterLogger = logging.GetLogger("Requester")
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
@@ -74,11 +82,20 @@ func Headers(headers ...Header) func(r *HTTPRequester) {
 	}
 }
 
+// UserAgentSuffix appends the given suffix to the User-Agent header identifying the SDK, so that the traffic can be
+// attributed to an app as well
+func UserAgentSuffix(suffix string) func(r *HTTPRequester) {
+	return func(r *HTTPRequester) {
+		r.userAgent = UserAgent + " " + suffix
+	}
+}
+
 // HTTPRequester contains main info
 type HTTPRequester struct {
-	client  http.Client
-	retries int
-	headers []Header
+	client    http.Client
+	retries   int
+	headers   []Header
+	userAgent string
 }
 
 // NewHTTPRequester makes Requester with api and parameters. Sets defaults
@@ -86,9 +103,10 @@ type HTTPRequester struct {
 func NewHTTPRequester(params ...func(*HTTPRequester)) *HTTPRequester {
 
 	res := HTTPRequester{
-		retries: 1,
-		headers: []Header{{"Content-Type", "application/json"}, {"Accept", "application/json"}},
-		client:  http.Client{Timeout: defaultTTL},
+		retries:   1,
+		headers:   []Header{{"Content-Type", "application/json"}, {"Accept", "application/json"}},
+		client:    http.Client{Timeout: defaultTTL},
+		userAgent: UserAgent,
 	}
 
 	for _, param := range params {
@@ -191,9 +209,16 @@ func (r HTTPRequester) addHeaders(req *http.Request, headers []Header) *http.Req
 	for _, h := range r.headers {
 		req.Header.Add(h.Name, h.Value)
 	}
+	// the headers of the call replace the default ones of the same name
+	for _, h := range headers {
+		req.Header.Del(h.Name)
+	}
 	for _, h := range headers {
 		req.Header.Add(h.Name, h.Value)
 	}
+	if r.userAgent != "" && req.Header.Get(userAgentHeader) == "" {
+		req.Header.Set(userAgentHeader, r.userAgent)
+	}
 	return req
 }
 
@@ -48,6 +48,31 @@ func TestAddHeaders(t *testing.T) {
 	assert.Equal(t, req.Header, http.Header{"One": []string{"1"}, "Two": []string{"2"}})
 }
 
+func TestAddHeadersUserAgent(t *testing.T) {
+
+	req, _ := http.NewRequest("GET", "", nil)
+	NewHTTPRequester().addHeaders(req, nil)
+	assert.Equal(t, "go-sdk/"+SDKVersion, req.UserAgent())
+
+	req, _ = http.NewRequest("GET", "", nil)
+	NewHTTPRequester(UserAgentSuffix("app/2.0")).addHeaders(req, nil)
+	assert.Equal(t, "go-sdk/"+SDKVersion+" app/2.0", req.UserAgent())
+
+	// a user agent passed with the call is kept
+	req, _ = http.NewRequest("GET", "", nil)
+	NewHTTPRequester().addHeaders(req, []Header{{"User-Agent", "custom"}})
+	assert.Equal(t, "custom", req.UserAgent())
+}
+
+func TestAddHeadersReplacesDefaults(t *testing.T) {
+
+	req, _ := http.NewRequest("POST", "", nil)
+	requester := NewHTTPRequester()
+	requester.addHeaders(req, []Header{{"Content-Type", "application/x-protobuf"}})
+	assert.Equal(t, []string{"application/x-protobuf"}, req.Header["Content-Type"])
+	assert.Equal(t, []string{"application/json"}, req.Header["Accept"])
+}
+
 func TestGet(t *testing.T) {
 
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,100 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+// Package utilstest provides a clock for testing the components of the SDK which read the time or use tickers //
+package utilstest
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/optimizely/go-sdk/pkg/utils"
+)
+
+// Clock is a utils.Clock for tests, its time only moves when set and its tickers only fire on Tick
+type Clock struct {
+	lock    sync.RWMutex
+	now     time.Time
+	ticker  *Ticker
+	tickers int32
+}
+
+// NewClock returns a new Clock telling the current time until its time is set
+func NewClock() *Clock {
+	return &Clock{ticker: NewTicker()}
+}
+
+// Now returns the time set on the clock, or the current time while none is set
+func (m *Clock) Now() time.Time {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.now.IsZero() {
+		return time.Now()
+	}
+	return m.now
+}
+
+// Set sets the time returned by Now
+func (m *Clock) Set(now time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.now = now
+}
+
+// Advance moves the time returned by Now forward by d
+func (m *Clock) Advance(d time.Duration) {
+	m.Set(m.Now().Add(d))
+}
+
+// NewTicker returns the ticker of the clock, which only fires on Tick
+func (m *Clock) NewTicker(d time.Duration) utils.Ticker {
+	atomic.AddInt32(&m.tickers, 1)
+	return m.ticker
+}
+
+// Tickers returns the number of tickers created with the clock
+func (m *Clock) Tickers() int {
+	return int(atomic.LoadInt32(&m.tickers))
+}
+
+// Tick blocks until the owner of the ticker of the clock receives a tick
+func (m *Clock) Tick() {
+	m.ticker.Tick()
+}
+
+// Ticker is a utils.Ticker for tests which only fires on Tick
+type Ticker struct {
+	ticks chan time.Time
+}
+
+// NewTicker returns a new Ticker
+func NewTicker() *Ticker {
+	return &Ticker{ticks: make(chan time.Time)}
+}
+
+// C returns the channel the ticks are delivered on
+func (m *Ticker) C() <-chan time.Time {
+	return m.ticks
+}
+
+// Stop does nothing, ticks are only delivered on Tick
+func (m *Ticker) Stop() {}
+
+// Tick blocks until the owner of the ticker receives the tick
+func (m *Ticker) Tick() {
+	m.ticks <- time.Now()
+}
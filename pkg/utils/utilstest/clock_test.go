@@ -0,0 +1,45 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package utilstest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	clock := NewClock()
+	assert.WithinDuration(t, time.Now(), clock.Now(), time.Minute)
+
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock.Set(now)
+	assert.Equal(t, now, clock.Now())
+	clock.Advance(time.Second)
+	assert.Equal(t, now.Add(time.Second), clock.Now())
+
+	ticker := clock.NewTicker(time.Hour)
+	assert.Equal(t, 1, clock.Tickers())
+	go clock.Tick()
+	select {
+	case <-ticker.C():
+	case <-time.After(time.Second):
+		assert.Fail(t, "the tick was not delivered")
+	}
+	ticker.Stop()
+}
@@ -18,11 +18,13 @@ package models
 
 import (
 	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/event"
 )
 
 // APIResponse represents result for a scenario
 type APIResponse struct {
-	Result         interface{}
-	Type           entities.VariableType
-	ListenerCalled []interface{}
+	Result           interface{}
+	Type             entities.VariableType
+	ListenerCalled   []interface{}
+	DispatchedEvents []event.LogEvent
 }
@@ -17,6 +17,8 @@
 package optlyplugins
 
 import (
+	"sync"
+
 	"github.com/optimizely/go-sdk/pkg/client"
 	"github.com/optimizely/go-sdk/pkg/decision"
 	"github.com/optimizely/go-sdk/pkg/entities"
@@ -27,7 +29,14 @@ import (
 
 // NotificationManager manager class for notification listeners
 type NotificationManager struct {
+	// KeepOnRead makes the notifications accumulate across the reads of GetListenersCalled and
+	// GetListenersCalledCounts, e.g. for the sessions spanning several requests, by default each read clears only the
+	// data it returns, so reading the notifications does not reset the counts and vice versa
+	KeepOnRead bool
+
+	lock            sync.Mutex
 	listenersCalled []interface{}
+	counts          map[string]int
 }
 
 // SubscribeNotifications subscribes to the provided notification listeners
@@ -51,6 +60,45 @@ func (n *NotificationManager) SubscribeNotifications(listeners map[string]int, c
 	}
 }
 
+// GetListenersCalled returns the notifications received by the listeners
+func (n *NotificationManager) GetListenersCalled() []interface{} {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	listenersCalled := n.listenersCalled
+	// Since for every scenario, a new sdk instance is created, emptying listenersCalled is required for scenario's
+	// where multiple requests are executed but no session is to be maintained among them.
+	if !n.KeepOnRead {
+		n.listenersCalled = nil
+	}
+	return listenersCalled
+}
+
+// GetListenersCalledCounts returns the number of notifications received by the listeners of each type, keyed by
+// listener type such as models.KeyDecision
+func (n *NotificationManager) GetListenersCalledCounts() map[string]int {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	counts := make(map[string]int, len(n.counts))
+	for key, count := range n.counts {
+		counts[key] = count
+	}
+	if !n.KeepOnRead {
+		n.counts = nil
+	}
+	return counts
+}
+
+// addListenerCalled records a notification received by a listener of the given type
+func (n *NotificationManager) addListenerCalled(listenerType string, listener interface{}) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.listenersCalled = append(n.listenersCalled, listener)
+	if n.counts == nil {
+		n.counts = map[string]int{}
+	}
+	n.counts[listenerType]++
+}
+
 func (n *NotificationManager) decisionCallback(notification notification.DecisionNotification) {
 
 	model := models.DecisionListener{}
@@ -64,7 +112,7 @@ func (n *NotificationManager) decisionCallback(notification notification.Decisio
 
 	decisionInfoDict := getDecisionInfoForNotification(notification)
 	model.DecisionInfo = decisionInfoDict
-	n.listenersCalled = append(n.listenersCalled, model)
+	n.addListenerCalled(models.KeyDecision, model)
 }
 
 func (n *NotificationManager) trackCallback(eventKey string, userContext entities.UserContext, eventTags map[string]interface{}, conversionEvent event.ConversionEvent) {
@@ -74,77 +122,117 @@ func (n *NotificationManager) trackCallback(eventKey string, userContext entitie
 		Attributes: userContext.Attributes,
 		EventTags:  eventTags,
 	}
-	n.listenersCalled = append(n.listenersCalled, listener)
+	n.addListenerCalled(models.KeyTrack, listener)
 }
 
 func getDecisionInfoForNotification(decisionNotification notification.DecisionNotification) map[string]interface{} {
 	decisionInfoDict := make(map[string]interface{})
 
-	updateSourceInfo := func(source string) {
+	experimentInfo := decisionNotification.ExperimentInfo
+	if experimentInfo == nil {
+		experimentInfo = getExperimentInfo(decisionNotification.DecisionInfo)
+	}
+	featureInfo := decisionNotification.FeatureInfo
+	if featureInfo == nil {
+		featureInfo = getFeatureInfo(decisionNotification.DecisionInfo)
+	}
+
+	updateFeatureInfo := func(featureInfo *notification.FeatureDecisionInfo) {
+		decisionInfoDict["source"] = featureInfo.Source
+		decisionInfoDict["feature_enabled"] = featureInfo.FeatureEnabled
+		decisionInfoDict["feature_key"] = featureInfo.FeatureKey
 		decisionInfoDict["source_info"] = make(map[string]interface{})
-		if source == string(decision.FeatureTest) {
-			featureInfoDict := decisionNotification.DecisionInfo["feature"].(map[string]interface{})
-			if sourceInfo, ok := featureInfoDict["sourceInfo"].(interface{}); ok {
-				sourceInfoDict := sourceInfo.((map[string]string))
-				if experimentKey, ok := sourceInfoDict["experimentKey"]; ok {
-					if variationKey, ok := sourceInfoDict["variationKey"]; ok {
-						dict := make(map[string]interface{})
-						dict["experiment_key"] = experimentKey
-						dict["variation_key"] = variationKey
-						decisionInfoDict["source_info"] = dict
-					}
-				}
-			}
+		if featureInfo.Source == string(decision.FeatureTest) && featureInfo.SourceInfo != nil {
+			dict := make(map[string]interface{})
+			dict["experiment_key"] = featureInfo.SourceInfo.ExperimentKey
+			dict["variation_key"] = featureInfo.SourceInfo.VariationKey
+			decisionInfoDict["source_info"] = dict
 		}
 	}
 
 	switch notificationType := decisionNotification.Type; notificationType {
 	case notification.ABTest, notification.FeatureTest:
-		decisionInfoDict["experiment_key"] = decisionNotification.DecisionInfo["experimentKey"]
-		decisionInfoDict["variation_key"] = decisionNotification.DecisionInfo["variationKey"]
-		break
+		if experimentInfo != nil {
+			decisionInfoDict["experiment_key"] = experimentInfo.ExperimentKey
+			decisionInfoDict["variation_key"] = nil
+			if experimentInfo.VariationKey != "" {
+				decisionInfoDict["variation_key"] = experimentInfo.VariationKey
+			}
+		}
 	case notification.Feature:
-		featureInfoDict := decisionNotification.DecisionInfo["feature"].(map[string]interface{})
-		source := ""
-		if decisionSource, ok := featureInfoDict["source"].(decision.Source); ok {
-			source = string(decisionSource)
-		} else {
-			source = featureInfoDict["source"].(string)
+		if featureInfo != nil {
+			updateFeatureInfo(featureInfo)
 		}
-		decisionInfoDict["source"] = source
-		decisionInfoDict["feature_enabled"] = featureInfoDict["featureEnabled"]
-		decisionInfoDict["feature_key"] = featureInfoDict["featureKey"]
-		updateSourceInfo(source)
 	case notification.FeatureVariable:
-		featureInfoDict := decisionNotification.DecisionInfo["feature"].(map[string]interface{})
-		source := ""
-		if decisionSource, ok := featureInfoDict["source"].(decision.Source); ok {
-			source = string(decisionSource)
-		} else {
-			source = featureInfoDict["source"].(string)
-		}
-		decisionInfoDict["source"] = source
-		decisionInfoDict["variable_key"] = featureInfoDict["variableKey"]
-		if variableType, ok := featureInfoDict["variableType"].(entities.VariableType); ok {
-			decisionInfoDict["variable_type"] = string(variableType)
-		} else {
-			decisionInfoDict["variable_type"] = featureInfoDict["variableType"].(string)
+		if featureInfo != nil {
+			decisionInfoDict["variable_key"] = featureInfo.VariableKey
+			decisionInfoDict["variable_type"] = string(featureInfo.VariableType)
+			decisionInfoDict["variable_value"] = featureInfo.VariableValue
+			updateFeatureInfo(featureInfo)
 		}
-		decisionInfoDict["variable_value"] = featureInfoDict["variableValue"]
-		decisionInfoDict["feature_enabled"] = featureInfoDict["featureEnabled"]
-		decisionInfoDict["feature_key"] = featureInfoDict["featureKey"]
-		updateSourceInfo(source)
 	default:
 	}
 	return decisionInfoDict
 }
 
-// GetListenersCalled - Returns listeners called
-func (n *NotificationManager) GetListenersCalled() []interface{} {
-	listenerCalled := n.listenersCalled
-	// Since for every scenario, a new sdk instance is created, emptying listenersCalled is required for scenario's
-	// where multiple requests are executed but no session is to be maintained among them.
-	// @TODO: Make it optional once event-batching(sessioned) tests are implemented.
-	n.listenersCalled = nil
-	return listenerCalled
+// getExperimentInfo reads the experiment info from the untyped decision info, returns nil if it is missing
+func getExperimentInfo(decisionInfo map[string]interface{}) *notification.ExperimentDecisionInfo {
+	experimentKey, ok := decisionInfo["experimentKey"].(string)
+	if !ok {
+		return nil
+	}
+	variationKey, _ := decisionInfo["variationKey"].(string)
+	return &notification.ExperimentDecisionInfo{ExperimentKey: experimentKey, VariationKey: variationKey}
+}
+
+// getFeatureInfo reads the feature info from the untyped decision info, returns nil if it is missing
+func getFeatureInfo(decisionInfo map[string]interface{}) *notification.FeatureDecisionInfo {
+	featureInfoDict, ok := decisionInfo["feature"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	featureInfo := &notification.FeatureDecisionInfo{
+		SourceInfo:    getSourceInfo(featureInfoDict["sourceInfo"]),
+		VariableValue: featureInfoDict["variableValue"],
+	}
+	featureInfo.FeatureKey, _ = featureInfoDict["featureKey"].(string)
+	featureInfo.FeatureEnabled, _ = featureInfoDict["featureEnabled"].(bool)
+	featureInfo.VariableKey, _ = featureInfoDict["variableKey"].(string)
+
+	switch source := featureInfoDict["source"].(type) {
+	case decision.Source:
+		featureInfo.Source = string(source)
+	case string:
+		featureInfo.Source = source
+	}
+
+	switch variableType := featureInfoDict["variableType"].(type) {
+	case entities.VariableType:
+		featureInfo.VariableType = variableType
+	case string:
+		featureInfo.VariableType = entities.VariableType(variableType)
+	}
+	return featureInfo
+}
+
+// getSourceInfo reads the source info of a feature decision, which may come as either a map[string]string or a
+// map[string]interface{}, returns nil if it is in neither shape or is missing a key
+func getSourceInfo(sourceInfo interface{}) *notification.ExperimentDecisionInfo {
+	var experimentKey, variationKey string
+	var hasExperimentKey, hasVariationKey bool
+
+	switch sourceInfoDict := sourceInfo.(type) {
+	case map[string]string:
+		experimentKey, hasExperimentKey = sourceInfoDict["experimentKey"]
+		variationKey, hasVariationKey = sourceInfoDict["variationKey"]
+	case map[string]interface{}:
+		experimentKey, hasExperimentKey = sourceInfoDict["experimentKey"].(string)
+		variationKey, hasVariationKey = sourceInfoDict["variationKey"].(string)
+	}
+
+	if !hasExperimentKey || !hasVariationKey {
+		return nil
+	}
+	return &notification.ExperimentDecisionInfo{ExperimentKey: experimentKey, VariationKey: variationKey}
 }
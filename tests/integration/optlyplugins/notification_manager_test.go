@@ -0,0 +1,105 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package optlyplugins
+
+import (
+	"testing"
+
+	"github.com/optimizely/go-sdk/pkg/decision"
+	"github.com/optimizely/go-sdk/pkg/entities"
+	"github.com/optimizely/go-sdk/pkg/event"
+	"github.com/optimizely/go-sdk/pkg/notification"
+	"github.com/optimizely/go-sdk/tests/integration/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDecisionInfoForNotificationSourceInfoShapes(t *testing.T) {
+	expectedSourceInfo := map[string]interface{}{"experiment_key": "exp_key", "variation_key": "var_key"}
+
+	sourceInfos := []interface{}{
+		map[string]string{"experimentKey": "exp_key", "variationKey": "var_key"},
+		map[string]interface{}{"experimentKey": "exp_key", "variationKey": "var_key"},
+	}
+	for _, sourceInfo := range sourceInfos {
+		decisionNotification := notification.DecisionNotification{
+			Type: notification.Feature,
+			DecisionInfo: map[string]interface{}{"feature": map[string]interface{}{
+				"featureKey":     "feature_key",
+				"featureEnabled": true,
+				"source":         decision.FeatureTest,
+				"sourceInfo":     sourceInfo,
+			}},
+		}
+
+		decisionInfo := getDecisionInfoForNotification(decisionNotification)
+		assert.Equal(t, "feature_key", decisionInfo["feature_key"])
+		assert.Equal(t, true, decisionInfo["feature_enabled"])
+		assert.Equal(t, string(decision.FeatureTest), decisionInfo["source"])
+		assert.Equal(t, expectedSourceInfo, decisionInfo["source_info"])
+	}
+}
+
+func TestGetDecisionInfoForNotificationInvalidSourceInfo(t *testing.T) {
+	decisionNotification := notification.DecisionNotification{
+		Type: notification.Feature,
+		DecisionInfo: map[string]interface{}{"feature": map[string]interface{}{
+			"featureKey": "feature_key",
+			"source":     "feature-test",
+			"sourceInfo": []string{"exp_key", "var_key"},
+		}},
+	}
+
+	decisionInfo := getDecisionInfoForNotification(decisionNotification)
+	assert.Equal(t, "feature_key", decisionInfo["feature_key"])
+	assert.Equal(t, map[string]interface{}{}, decisionInfo["source_info"])
+}
+
+func TestGetDecisionInfoForNotificationPrefersTypedInfo(t *testing.T) {
+	decisionNotification := notification.DecisionNotification{
+		Type:           notification.ABTest,
+		DecisionInfo:   map[string]interface{}{"experimentKey": 1},
+		ExperimentInfo: &notification.ExperimentDecisionInfo{ExperimentKey: "exp_key", VariationKey: "var_key"},
+	}
+
+	decisionInfo := getDecisionInfoForNotification(decisionNotification)
+	assert.Equal(t, "exp_key", decisionInfo["experiment_key"])
+	assert.Equal(t, "var_key", decisionInfo["variation_key"])
+}
+
+func TestGetListenersCalled(t *testing.T) {
+	notificationManager := &NotificationManager{}
+	notificationManager.decisionCallback(notification.DecisionNotification{Type: notification.Feature})
+	notificationManager.trackCallback("event_key", entities.UserContext{ID: "user"}, nil, event.ConversionEvent{})
+
+	// each read clears only its own data by default
+	assert.Len(t, notificationManager.GetListenersCalled(), 2)
+	assert.Empty(t, notificationManager.GetListenersCalled())
+	assert.Equal(t, map[string]int{models.KeyDecision: 1, models.KeyTrack: 1}, notificationManager.GetListenersCalledCounts())
+	assert.Empty(t, notificationManager.GetListenersCalledCounts())
+
+	notificationManager.decisionCallback(notification.DecisionNotification{Type: notification.Feature})
+	assert.Equal(t, map[string]int{models.KeyDecision: 1}, notificationManager.GetListenersCalledCounts())
+	assert.Len(t, notificationManager.GetListenersCalled(), 1)
+
+	notificationManager.KeepOnRead = true
+	notificationManager.decisionCallback(notification.DecisionNotification{Type: notification.Feature})
+	notificationManager.trackCallback("event_key", entities.UserContext{ID: "user"}, nil, event.ConversionEvent{})
+	assert.Len(t, notificationManager.GetListenersCalled(), 2)
+	notificationManager.decisionCallback(notification.DecisionNotification{Type: notification.Feature})
+	assert.Len(t, notificationManager.GetListenersCalled(), 3)
+	assert.Equal(t, map[string]int{models.KeyDecision: 2, models.KeyTrack: 1}, notificationManager.GetListenersCalledCounts())
+}
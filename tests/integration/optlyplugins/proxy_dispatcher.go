@@ -17,6 +17,8 @@
 package optlyplugins
 
 import (
+	"sync"
+
 	"github.com/optimizely/go-sdk/pkg/event"
 )
 
@@ -25,21 +27,41 @@ type EventReceiver interface {
 	GetEvents() []event.Batch
 }
 
+// DispatchRecorder returns the log events dispatched, with their endpoint and payload
+type DispatchRecorder interface {
+	GetDispatchedEvents() []event.LogEvent
+}
+
 // ProxyEventDispatcher represents a valid HTTP implementation of the Dispatcher interface
 type ProxyEventDispatcher struct {
-	events []event.Batch
+	lock      sync.Mutex
+	events    []event.Batch
+	logEvents []event.LogEvent
 }
 
 // DispatchEvent dispatches event with callback
 func (d *ProxyEventDispatcher) DispatchEvent(event event.LogEvent) (bool, error) {
+	// the events are dispatched by the goroutine of the event processor
+	d.lock.Lock()
+	defer d.lock.Unlock()
 	d.events = append(d.events, event.Event)
+	d.logEvents = append(d.logEvents, event)
 	return true, nil
 }
 
 // GetEvents returns dispatched events
 func (d *ProxyEventDispatcher) GetEvents() []event.Batch {
+	d.lock.Lock()
+	defer d.lock.Unlock()
 	if d.events == nil {
 		d.events = []event.Batch{}
 	}
 	return d.events
 }
+
+// GetDispatchedEvents returns the log events dispatched, in the order they were dispatched
+func (d *ProxyEventDispatcher) GetDispatchedEvents() []event.LogEvent {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return append([]event.LogEvent{}, d.logEvents...)
+}
@@ -0,0 +1,43 @@
+/****************************************************************************
+ * Copyright 2020, Optimizely, Inc. and contributors                        *
+ *                                                                          *
+ * Licensed under the Apache License, Version 2.0 (the "License");          *
+ * you may not use this file except in compliance with the License.         *
+ * You may obtain a copy of the License at                                  *
+ *                                                                          *
+ *    http://www.apache.org/licenses/LICENSE-2.0                            *
+ *                                                                          *
+ * Unless required by applicable law or agreed to in writing, software      *
+ * distributed under the License is distributed on an "AS IS" BASIS,        *
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. *
+ * See the License for the specific language governing permissions and      *
+ * limitations under the License.                                           *
+ ***************************************************************************/
+
+package optlyplugins
+
+import (
+	"testing"
+
+	"github.com/optimizely/go-sdk/pkg/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyEventDispatcherRecordsDispatchedEvents(t *testing.T) {
+	dispatcher := &ProxyEventDispatcher{}
+	assert.Empty(t, dispatcher.GetDispatchedEvents())
+	assert.Equal(t, []event.Batch{}, dispatcher.GetEvents())
+
+	logEvents := []event.LogEvent{
+		{EndPoint: "https://logx.optimizely.com/v1/events", Event: event.Batch{Revision: "1"}},
+		{EndPoint: "https://eu.logx.optimizely.com/v1/events", Event: event.Batch{Revision: "2"}},
+	}
+	for _, logEvent := range logEvents {
+		success, err := dispatcher.DispatchEvent(logEvent)
+		assert.True(t, success)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, logEvents, dispatcher.GetDispatchedEvents())
+	assert.Equal(t, []event.Batch{{Revision: "1"}, {Revision: "2"}}, dispatcher.GetEvents())
+}
@@ -27,3 +27,8 @@ type LookupErrorUserProfileService struct {
 func (s *LookupErrorUserProfileService) Lookup(userID string) decision.UserProfile {
 	return decision.UserProfile{}
 }
+
+// BatchLookup is used to retrieve past bucketing decisions for several users
+func (s *LookupErrorUserProfileService) BatchLookup(userIDs []string) map[string]decision.UserProfile {
+	return map[string]decision.UserProfile{}
+}
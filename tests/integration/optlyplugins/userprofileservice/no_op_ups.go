@@ -28,6 +28,11 @@ func (s *NoOpUserProfileService) Lookup(userID string) decision.UserProfile {
 	return decision.UserProfile{}
 }
 
+// BatchLookup is used to retrieve past bucketing decisions for several users
+func (s *NoOpUserProfileService) BatchLookup(userIDs []string) map[string]decision.UserProfile {
+	return map[string]decision.UserProfile{}
+}
+
 // Save is used to save bucketing decisions for users
 func (s *NoOpUserProfileService) Save(userProfile decision.UserProfile) {
 }
@@ -36,6 +36,19 @@ func (s *NormalUserProfileService) Lookup(userID string) decision.UserProfile {
 	return profile
 }
 
+// BatchLookup is used to retrieve past bucketing decisions for several users
+func (s *NormalUserProfileService) BatchLookup(userIDs []string) map[string]decision.UserProfile {
+	profiles := make(map[string]decision.UserProfile, len(userIDs))
+	s.RLock()
+	for _, userID := range userIDs {
+		if profile, ok := s.profiles[userID]; ok {
+			profiles[userID] = profile
+		}
+	}
+	s.RUnlock()
+	return profiles
+}
+
 // Save is used to save bucketing decisions for users
 func (s *NormalUserProfileService) Save(userProfile decision.UserProfile) {
 	if userProfile.ID == "" {
@@ -22,13 +22,13 @@ import (
 	"os"
 	"path"
 	"path/filepath"
-	"strconv"
 
 	"github.com/optimizely/go-sdk/pkg/client"
 	"github.com/optimizely/go-sdk/pkg/config"
 	"github.com/optimizely/go-sdk/pkg/decision"
 	"github.com/optimizely/go-sdk/pkg/entities"
 	"github.com/optimizely/go-sdk/pkg/event"
+	"github.com/optimizely/go-sdk/pkg/notification"
 	"github.com/optimizely/go-sdk/tests/integration/models"
 	"github.com/optimizely/go-sdk/tests/integration/optlyplugins"
 	"github.com/optimizely/go-sdk/tests/integration/optlyplugins/userprofileservice"
@@ -38,9 +38,6 @@ import (
 // Cached instance of optly wrapper
 var clientInstance *ClientWrapper
 
-// Since notificationManager is mapped against sdkKey, we need a unique sdkKey for every scenario
-var sdkKey int
-
 // ClientWrapper - wrapper around the optimizely client that keeps track of various custom components used with the client
 type ClientWrapper struct {
 	client              *client.OptimizelyClient
@@ -62,7 +59,6 @@ func GetInstance(apiOptions models.APIOptions) *ClientWrapper {
 		return clientInstance
 	}
 
-	sdkKey++
 	datafileDir := os.Getenv("DATAFILES_DIR")
 	datafile, err := ioutil.ReadFile(filepath.Clean(path.Join(datafileDir, apiOptions.DatafileName)))
 	if err != nil {
@@ -73,10 +69,13 @@ func GetInstance(apiOptions models.APIOptions) *ClientWrapper {
 		log.Fatal(err)
 	}
 
+	// every scenario uses a client with its own notification center, so that no listener outlives its scenario
+	notificationCenter := notification.NewNotificationCenter()
 	eventProcessor := event.NewBatchEventProcessor(
 		event.WithBatchSize(models.EventProcessorDefaultBatchSize),
 		event.WithQueueSize(models.EventProcessorDefaultQueueSize),
 		event.WithFlushInterval(models.EventProcessorDefaultFlushInterval),
+		event.WithNotificationCenter(notificationCenter),
 	)
 
 	optimizelyFactory := &client.OptimizelyFactory{
@@ -97,12 +96,15 @@ func GetInstance(apiOptions models.APIOptions) *ClientWrapper {
 		decision.WithOverrideStore(overrideStore),
 	)
 
-	// @TODO: Add sdkKey dynamically once event-batching support is implemented
-	compositeService := decision.NewCompositeService(strconv.Itoa(sdkKey), decision.WithCompositeExperimentService(compositeExperimentService))
+	compositeService := decision.NewCompositeService("",
+		decision.WithCompositeExperimentService(compositeExperimentService),
+		decision.WithNotificationCenter(notificationCenter),
+	)
 	client, err := optimizelyFactory.Client(
 		client.WithConfigManager(configManager),
 		client.WithDecisionService(compositeService),
 		client.WithEventProcessor(eventProcessor),
+		client.WithNotificationCenter(notificationCenter),
 	)
 	if err != nil {
 		log.Fatal(err)
@@ -179,6 +181,9 @@ func (c *ClientWrapper) InvokeAPI(request models.APIOptions) (models.APIResponse
 	// TODO: For event batching, it should be conditional.
 	c.client.Close()
 	response.ListenerCalled = c.notificationManager.GetListenersCalled()
+	if recorder, ok := c.eventDispatcher.(optlyplugins.DispatchRecorder); ok {
+		response.DispatchedEvents = recorder.GetDispatchedEvents()
+	}
 	return response, err
 }
 